@@ -28,75 +28,17 @@ func makeArrayCodec(st map[string]*Codec, enclosingNamespace string, schemaMap m
 	}
 
 	return &Codec{
-		typeName: &name{"array", nullNamespace},
-		nativeFromBinary: func(buf []byte) (interface{}, []byte, error) {
-			var value interface{}
-			var err error
-
-			// block count and block size
-			if value, buf, err = longNativeFromBinary(buf); err != nil {
-				return nil, nil, fmt.Errorf("cannot decode binary array block count: %s", err)
-			}
-			blockCount := value.(int64)
-			if blockCount < 0 {
-				// NOTE: A negative block count implies there is a long encoded
-				// block size following the negative block count. We have no use
-				// for the block size in this decoder, so we read and discard
-				// the value.
-				if blockCount == math.MinInt64 {
-					// The minimum number for any signed numerical type can never be made positive
-					return nil, nil, fmt.Errorf("cannot decode binary array with block count: %d", blockCount)
-				}
-				blockCount = -blockCount // convert to its positive equivalent
-				if _, buf, err = longNativeFromBinary(buf); err != nil {
-					return nil, nil, fmt.Errorf("cannot decode binary array block size: %s", err)
-				}
-			}
-			// Ensure block count does not exceed some sane value.
-			if blockCount > MaxBlockCount {
-				return nil, nil, fmt.Errorf("cannot decode binary array when block count exceeds MaxBlockCount: %d > %d", blockCount, MaxBlockCount)
-			}
-			// NOTE: While the attempt of a RAM optimization shown below is not
-			// necessary, many encoders will encode all items in a single block.
-			// We can optimize amount of RAM allocated by runtime for the array
-			// by initializing the array for that number of items.
-			arrayValues := make([]interface{}, 0, blockCount)
-
-			for blockCount != 0 {
-				// Decode `blockCount` datum values from buffer
-				for i := int64(0); i < blockCount; i++ {
-					if value, buf, err = itemCodec.nativeFromBinary(buf); err != nil {
-						return nil, nil, fmt.Errorf("cannot decode binary array item %d: %s", i+1, err)
-					}
-					arrayValues = append(arrayValues, value)
-				}
-				// Decode next blockCount from buffer, because there may be more blocks
-				if value, buf, err = longNativeFromBinary(buf); err != nil {
-					return nil, nil, fmt.Errorf("cannot decode binary array block count: %s", err)
-				}
-				blockCount = value.(int64)
-				if blockCount < 0 {
-					// NOTE: A negative block count implies there is a long
-					// encoded block size following the negative block count. We
-					// have no use for the block size in this decoder, so we
-					// read and discard the value.
-					if blockCount == math.MinInt64 {
-						// The minimum number for any signed numerical type can
-						// never be made positive
-						return nil, nil, fmt.Errorf("cannot decode binary array with block count: %d", blockCount)
-					}
-					blockCount = -blockCount // convert to its positive equivalent
-					if _, buf, err = longNativeFromBinary(buf); err != nil {
-						return nil, nil, fmt.Errorf("cannot decode binary array block size: %s", err)
-					}
-				}
-				// Ensure block count does not exceed some sane value.
-				if blockCount > MaxBlockCount {
-					return nil, nil, fmt.Errorf("cannot decode binary array when block count exceeds MaxBlockCount: %d > %d", blockCount, MaxBlockCount)
-				}
-			}
-			return arrayValues, buf, nil
-		},
+		typeName:  &name{"array", nullNamespace},
+		kind:      "array",
+		itemCodec: itemCodec,
+		// NOTE: Item codec functions are looked up when invoked, because a
+		// recursive record item codec has yet to be filled in at this point.
+		nativeFromBinary: arrayNativeFromBinary(func(buf []byte) (interface{}, []byte, error) {
+			return itemCodec.nativeFromBinary(buf)
+		}),
+		skipBinary: blocksSkipBinary("array", func(buf []byte) ([]byte, error) {
+			return itemCodec.skipBinary(buf)
+		}),
 		binaryFromNative: func(buf []byte, datum interface{}) ([]byte, error) {
 			arrayValues, err := convertArray(datum)
 			if err != nil {
@@ -200,6 +142,79 @@ func makeArrayCodec(st map[string]*Codec, enclosingNamespace string, schemaMap m
 	}, nil
 }
 
+// arrayNativeFromBinary returns a function that decodes a binary array, using
+// itemNativeFromBinary to decode each of its items.
+func arrayNativeFromBinary(itemNativeFromBinary toNativeFn) toNativeFn {
+	return func(buf []byte) (interface{}, []byte, error) {
+		var value interface{}
+		var err error
+
+		// block count and block size
+		if value, buf, err = longNativeFromBinary(buf); err != nil {
+			return nil, nil, fmt.Errorf("cannot decode binary array block count: %s", err)
+		}
+		blockCount := value.(int64)
+		if blockCount < 0 {
+			// NOTE: A negative block count implies there is a long encoded
+			// block size following the negative block count. We have no use
+			// for the block size in this decoder, so we read and discard
+			// the value.
+			if blockCount == math.MinInt64 {
+				// The minimum number for any signed numerical type can never be made positive
+				return nil, nil, fmt.Errorf("cannot decode binary array with block count: %d", blockCount)
+			}
+			blockCount = -blockCount // convert to its positive equivalent
+			if _, buf, err = longNativeFromBinary(buf); err != nil {
+				return nil, nil, fmt.Errorf("cannot decode binary array block size: %s", err)
+			}
+		}
+		// Ensure block count does not exceed some sane value.
+		if blockCount > MaxBlockCount {
+			return nil, nil, fmt.Errorf("cannot decode binary array when block count exceeds MaxBlockCount: %d > %d", blockCount, MaxBlockCount)
+		}
+		// NOTE: While the attempt of a RAM optimization shown below is not
+		// necessary, many encoders will encode all items in a single block.
+		// We can optimize amount of RAM allocated by runtime for the array
+		// by initializing the array for that number of items.
+		arrayValues := make([]interface{}, 0, blockCount)
+
+		for blockCount != 0 {
+			// Decode `blockCount` datum values from buffer
+			for i := int64(0); i < blockCount; i++ {
+				if value, buf, err = itemNativeFromBinary(buf); err != nil {
+					return nil, nil, fmt.Errorf("cannot decode binary array item %d: %s", i+1, err)
+				}
+				arrayValues = append(arrayValues, value)
+			}
+			// Decode next blockCount from buffer, because there may be more blocks
+			if value, buf, err = longNativeFromBinary(buf); err != nil {
+				return nil, nil, fmt.Errorf("cannot decode binary array block count: %s", err)
+			}
+			blockCount = value.(int64)
+			if blockCount < 0 {
+				// NOTE: A negative block count implies there is a long
+				// encoded block size following the negative block count. We
+				// have no use for the block size in this decoder, so we
+				// read and discard the value.
+				if blockCount == math.MinInt64 {
+					// The minimum number for any signed numerical type can
+					// never be made positive
+					return nil, nil, fmt.Errorf("cannot decode binary array with block count: %d", blockCount)
+				}
+				blockCount = -blockCount // convert to its positive equivalent
+				if _, buf, err = longNativeFromBinary(buf); err != nil {
+					return nil, nil, fmt.Errorf("cannot decode binary array block size: %s", err)
+				}
+			}
+			// Ensure block count does not exceed some sane value.
+			if blockCount > MaxBlockCount {
+				return nil, nil, fmt.Errorf("cannot decode binary array when block count exceeds MaxBlockCount: %d > %d", blockCount, MaxBlockCount)
+			}
+		}
+		return arrayValues, buf, nil
+	}
+}
+
 // convertArray converts interface{} to []interface{} if possible.
 func convertArray(datum interface{}) ([]interface{}, error) {
 	arrayValues, ok := datum.([]interface{})
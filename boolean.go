@@ -32,6 +32,13 @@ func booleanNativeFromBinary(buf []byte) (interface{}, []byte, error) {
 	}
 }
 
+func booleanSkipBinary(buf []byte) ([]byte, error) {
+	if len(buf) < 1 {
+		return nil, io.ErrShortBuffer
+	}
+	return buf[1:], nil
+}
+
 func booleanBinaryFromNative(buf []byte, datum interface{}) ([]byte, error) {
 	value, ok := datum.(bool)
 	if !ok {
@@ -50,6 +50,31 @@ func stringNativeFromBinary(buf []byte) (interface{}, []byte, error) {
 	return string(d.([]byte)), b, nil
 }
 
+func bytesSkipBinary(buf []byte) ([]byte, error) {
+	size, buf, err := longFromBinary(buf)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode binary bytes: %s", err)
+	}
+	if size < 0 {
+		return nil, fmt.Errorf("cannot decode binary bytes: negative size: %d", size)
+	}
+	if size > MaxBlockSize {
+		return nil, fmt.Errorf("cannot decode binary bytes when size exceeds MaxBlockSize: %d > %d", size, MaxBlockSize)
+	}
+	if size > int64(len(buf)) {
+		return nil, fmt.Errorf("cannot decode binary bytes: %s", io.ErrShortBuffer)
+	}
+	return buf[size:], nil
+}
+
+func stringSkipBinary(buf []byte) ([]byte, error) {
+	buf, err := bytesSkipBinary(buf)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode binary string: %s", err)
+	}
+	return buf, nil
+}
+
 ////////////////////////////////////////
 // Binary Encode
 ////////////////////////////////////////
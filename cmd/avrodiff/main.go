@@ -0,0 +1,82 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+// avrodiff reads an old and a new Avro schema file, and prints the semantic
+// differences between them, one per line, or as a JSON array. Schemas that
+// differ only in formatting have no differences.
+//
+//	avrodiff -json user-v1.avsc user-v2.avsc
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/linkedin/goavro/v2"
+)
+
+func usage() {
+	executable, err := os.Executable()
+	if err != nil {
+		executable = os.Args[0]
+	}
+	base := filepath.Base(executable)
+	fmt.Fprintf(os.Stderr, "Usage of %s:\n", base)
+	fmt.Fprintf(os.Stderr, "\t%s [-json] old.avsc new.avsc\n", base)
+	flag.PrintDefaults()
+	os.Exit(2)
+}
+
+func main() {
+	asJSON := flag.Bool("json", false, "print the differences as a JSON array")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() != 2 {
+		usage()
+	}
+
+	schemas := make([]string, 2)
+	for i, arg := range flag.Args() {
+		schema, err := ioutil.ReadFile(arg)
+		if err != nil {
+			bail(err)
+		}
+		schemas[i] = string(schema)
+	}
+
+	changes, err := goavro.DiffSchemas(schemas[0], schemas[1])
+	if err != nil {
+		bail(err)
+	}
+
+	if *asJSON {
+		if changes == nil {
+			changes = []goavro.SchemaChange{} // print an empty array rather than null
+		}
+		buf, err := json.MarshalIndent(changes, "", "  ")
+		if err != nil {
+			bail(err)
+		}
+		fmt.Println(string(buf))
+		return
+	}
+	for _, change := range changes {
+		fmt.Println(change)
+	}
+}
+
+func bail(err error) {
+	fmt.Fprintf(os.Stderr, "%s\n", err)
+	os.Exit(1)
+}
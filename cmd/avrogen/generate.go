@@ -0,0 +1,585 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+)
+
+// generator writes the Go source for the types described by a parser.
+type generator struct {
+	buf  bytes.Buffer
+	temp int // counter used to name temporary variables
+}
+
+// generate returns formatted Go source, in the named package, for the types
+// described by the parser.
+func generate(packageName string, p *parser) ([]byte, error) {
+	g := new(generator)
+	g.printf("// Code generated by avrogen. DO NOT EDIT.\n\n")
+	g.printf("package %s\n\n", packageName)
+	g.printf("import (\n\"fmt\"\n\"io\"\n\"math\"\n)\n")
+
+	for _, t := range p.named {
+		switch t.kind {
+		case "record":
+			g.record(t)
+		case "enum":
+			g.enum(t)
+		case "fixed":
+			g.fixed(t)
+		}
+		g.namedType(t)
+	}
+	for _, t := range p.unionsOrder {
+		g.union(t)
+	}
+	g.printf("%s", helpers)
+
+	formatted, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("should not get here: cannot format generated source: %s", err)
+	}
+	return formatted, nil
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// tempName returns a unique name for a temporary variable.
+func (g *generator) tempName(prefix string) string {
+	g.temp++
+	return prefix + strconv.Itoa(g.temp)
+}
+
+// comment writes text as a Go comment, wrapping lines longer than the
+// conventional width.
+func (g *generator) comment(text string) {
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		var width int
+		g.printf("//")
+		for _, word := range strings.Fields(line) {
+			if width > 0 && width+1+len(word) > commentWidth {
+				g.printf("\n//")
+				width = 0
+			}
+			g.printf(" %s", word)
+			width += 1 + len(word)
+		}
+		g.printf("\n")
+	}
+}
+
+// commentf writes a formatted Go comment.
+func (g *generator) commentf(format string, args ...interface{}) {
+	g.comment(fmt.Sprintf(format, args...))
+}
+
+// commentWidth is the width after which comment lines are wrapped, excluding
+// the leading slashes.
+const commentWidth = 77
+
+// article returns name preceded by the appropriate indefinite article.
+func article(name string) string {
+	if name != "" && strings.ContainsRune("AEIOUaeiou", rune(name[0])) {
+		return "an " + name
+	}
+	return "a " + name
+}
+
+// goType returns the Go type used to represent values of the Avro type.
+func goType(t *avroType) string {
+	switch t.kind {
+	case "null":
+		return "struct{}"
+	case "boolean":
+		return "bool"
+	case "int":
+		return "int32"
+	case "long":
+		return "int64"
+	case "float":
+		return "float32"
+	case "double":
+		return "float64"
+	case "bytes":
+		return "[]byte"
+	case "string":
+		return "string"
+	case "array":
+		return "[]" + goType(t.items)
+	case "map":
+		return "map[string]" + goType(t.items)
+	case "union":
+		if t.isNullable() {
+			return "*" + goType(t.members[t.nonNull()])
+		}
+	}
+	return t.goName
+}
+
+func (g *generator) record(t *avroType) {
+	if t.doc != "" {
+		g.comment(t.doc)
+	} else {
+		g.commentf("%s is generated from the Avro record %q.", t.goName, t.fullName)
+	}
+	g.printf("type %s struct {\n", t.goName)
+	for _, field := range t.fields {
+		if field.doc != "" {
+			g.comment(field.doc)
+		}
+		g.printf("%s %s `avro:%q`\n", field.goName, goType(field.typ), field.name)
+	}
+	g.printf("}\n\n")
+
+	body := g.body(func(g *generator) {
+		for _, field := range t.fields {
+			g.encode(field.typ, "r."+field.goName)
+		}
+	})
+	g.printf("// MarshalAvro appends the binary encoding of r to buf.\n")
+	g.printf("func (r *%s) MarshalAvro(buf []byte) ([]byte, error) {\n", t.goName)
+	g.printf("%s", declareErr(body))
+	g.printf("%sreturn buf, nil\n}\n\n", body)
+
+	body = g.body(func(g *generator) {
+		for _, field := range t.fields {
+			g.decode(field.typ, "r."+field.goName)
+		}
+	})
+	g.commentf("UnmarshalAvro decodes the binary encoding of %s from buf into r, and returns the remaining bytes.", article(t.goName))
+	g.printf("func (r *%s) UnmarshalAvro(buf []byte) ([]byte, error) {\n", t.goName)
+	g.printf("%s", declareErr(body))
+	g.printf("%sreturn buf, nil\n}\n\n", body)
+}
+
+func (g *generator) enum(t *avroType) {
+	if t.doc != "" {
+		g.comment(t.doc)
+	} else {
+		g.commentf("%s is generated from the Avro enum %q.", t.goName, t.fullName)
+	}
+	g.printf("type %s string\n\n", t.goName)
+	g.printf("// Symbols of %s.\nconst (\n", t.goName)
+	for _, symbol := range t.symbols {
+		g.printf("%s%s %s = %q\n", t.goName, goIdentifier(symbol), t.goName, symbol)
+	}
+	g.printf(")\n\n")
+
+	g.printf("// MarshalAvro appends the binary encoding of e to buf.\n")
+	g.printf("func (e %s) MarshalAvro(buf []byte) ([]byte, error) {\nswitch e {\n", t.goName)
+	for i, symbol := range t.symbols {
+		g.printf("case %s%s:\nreturn avroAppendLong(buf, %d), nil\n", t.goName, goIdentifier(symbol), i)
+	}
+	g.printf("}\nreturn nil, fmt.Errorf(\"cannot encode binary enum %%q: value ought to be member of symbols: %%q\", %q, string(e))\n}\n\n", t.fullName)
+
+	g.commentf("UnmarshalAvro decodes the binary encoding of %s from buf into e, and returns the remaining bytes.", article(t.goName))
+	g.printf("func (e *%s) UnmarshalAvro(buf []byte) ([]byte, error) {\n", t.goName)
+	g.printf("index, buf, err := avroReadLong(buf)\nif err != nil {\nreturn nil, err\n}\nswitch index {\n")
+	for i, symbol := range t.symbols {
+		g.printf("case %d:\n*e = %s%s\n", i, t.goName, goIdentifier(symbol))
+	}
+	g.printf("default:\nreturn nil, fmt.Errorf(\"cannot decode binary enum %%q: index ought to be between 0 and %d; read index: %%d\", %q, index)\n}\n", len(t.symbols)-1, t.fullName)
+	g.printf("return buf, nil\n}\n\n")
+}
+
+func (g *generator) fixed(t *avroType) {
+	if t.doc != "" {
+		g.comment(t.doc)
+	} else {
+		g.commentf("%s is generated from the Avro fixed %q.", t.goName, t.fullName)
+	}
+	g.printf("type %s [%d]byte\n\n", t.goName, t.size)
+
+	g.printf("// MarshalAvro appends the binary encoding of f to buf.\n")
+	g.printf("func (f %s) MarshalAvro(buf []byte) ([]byte, error) {\nreturn append(buf, f[:]...), nil\n}\n\n", t.goName)
+
+	g.commentf("UnmarshalAvro decodes the binary encoding of %s from buf into f, and returns the remaining bytes.", article(t.goName))
+	g.printf("func (f *%s) UnmarshalAvro(buf []byte) ([]byte, error) {\n", t.goName)
+	g.printf("if len(buf) < %d {\nreturn nil, fmt.Errorf(\"cannot decode binary fixed %%q: %%s\", %q, io.ErrShortBuffer)\n}\n", t.size, t.fullName)
+	g.printf("copy(f[:], buf)\nreturn buf[%d:], nil\n}\n\n", t.size)
+}
+
+// namedType writes the schema, fingerprint, and single-object encoding
+// methods of a named type.
+func (g *generator) namedType(t *avroType) {
+	// NOTE: Receiver names match those of the MarshalAvro and UnmarshalAvro
+	// methods of the type.
+	v := map[string]string{"record": "r", "enum": "e", "fixed": "f"}[t.kind]
+	receiver, pointerReceiver := v+" "+t.goName, v+" *"+t.goName
+	if t.kind == "record" {
+		receiver = pointerReceiver
+	}
+	prefix := strings.ToLower(t.goName[:1]) + t.goName[1:]
+
+	g.printf("const %sAvroSchema = %s\n\n", prefix, quote(t.schema))
+	g.printf("// AvroSchema returns the Avro schema of %s.\n", t.goName)
+	g.printf("func (%s) AvroSchema() string {\nreturn %sAvroSchema\n}\n\n", receiver[2:], prefix)
+	g.commentf("AvroRabin returns the Rabin fingerprint of the Parsing Canonical Form of the Avro schema of %s.", t.goName)
+	g.printf("func (%s) AvroRabin() uint64 {\nreturn %#x\n}\n\n", receiver[2:], t.rabin)
+
+	g.printf("// MarshalAvroSingle appends the single-object encoding of %s to buf.\n", v)
+	g.printf("func (%s) MarshalAvroSingle(buf []byte) ([]byte, error) {\n", receiver)
+	g.printf("return %s.MarshalAvro(avroAppendSingleObjectHeader(buf, %#x))\n}\n\n", v, t.rabin)
+
+	g.commentf("UnmarshalAvroSingle decodes the single-object encoding of %s from buf into %s, and returns the remaining bytes.", article(t.goName), v)
+	g.printf("func (%s) UnmarshalAvroSingle(buf []byte) ([]byte, error) {\n", pointerReceiver)
+	g.printf("buf, err := avroConsumeSingleObjectHeader(buf, %#x)\nif err != nil {\nreturn nil, err\n}\nreturn %s.UnmarshalAvro(buf)\n}\n\n", t.rabin, v)
+}
+
+func (g *generator) union(t *avroType) {
+	nullIndex := -1
+	for i, member := range t.members {
+		if member.kind == "null" {
+			nullIndex = i
+		}
+	}
+
+	if nullIndex >= 0 {
+		g.commentf("%s is generated from an Avro union of %s. At most one of its fields ought to be set, and when none are, it represents null.", t.goName, unionDescription(t))
+	} else {
+		g.commentf("%s is generated from an Avro union of %s. Exactly one of its fields ought to be set.", t.goName, unionDescription(t))
+	}
+	g.printf("type %s struct {\n", t.goName)
+	for _, member := range t.members {
+		if member.kind != "null" {
+			g.printf("%s *%s\n", member.label(), goType(member))
+		}
+	}
+	g.printf("}\n\n")
+
+	body := g.body(func(g *generator) {
+		g.printf("switch {\n")
+		for i, member := range t.members {
+			if member.kind == "null" {
+				continue
+			}
+			g.printf("case u.%s != nil:\nbuf = avroAppendLong(buf, %d)\n", member.label(), i)
+			g.encode(member, "(*u."+member.label()+")")
+		}
+		if nullIndex >= 0 {
+			g.printf("default:\nbuf = avroAppendLong(buf, %d)\n}\n", nullIndex)
+		} else {
+			g.printf("default:\nreturn nil, fmt.Errorf(\"cannot encode binary union %s: a field ought to be set\")\n}\n", t.goName)
+		}
+	})
+	g.printf("// MarshalAvro appends the binary encoding of u to buf.\n")
+	g.printf("func (u *%s) MarshalAvro(buf []byte) ([]byte, error) {\n", t.goName)
+	g.printf("%s", declareErr(body))
+	g.printf("%sreturn buf, nil\n}\n\n", body)
+
+	body = g.body(func(g *generator) {
+		g.printf("switch index {\n")
+		for i, member := range t.members {
+			g.printf("case %d:\n", i)
+			if member.kind == "null" {
+				continue
+			}
+			g.printf("u.%s = new(%s)\n", member.label(), goType(member))
+			g.decode(member, "(*u."+member.label()+")")
+		}
+		g.printf("default:\nreturn nil, fmt.Errorf(\"cannot decode binary union %s: index ought to be between 0 and %d; read index: %%d\", index)\n}\n", t.goName, len(t.members)-1)
+	})
+	g.commentf("UnmarshalAvro decodes the binary encoding of %s from buf into u, and returns the remaining bytes.", article(t.goName))
+	g.printf("func (u *%s) UnmarshalAvro(buf []byte) ([]byte, error) {\n", t.goName)
+	g.printf("index, buf, err := avroReadLong(buf)\nif err != nil {\nreturn nil, err\n}\n*u = %s{}\n", t.goName)
+	g.printf("%sreturn buf, nil\n}\n\n", body)
+}
+
+func unionDescription(t *avroType) string {
+	labels := make([]string, len(t.members))
+	for i, member := range t.members {
+		labels[i] = member.label()
+	}
+	return strings.Join(labels, ", ")
+}
+
+// body returns the source written by fn, rather than writing it to the
+// generator's buffer.
+func (g *generator) body(fn func(*generator)) string {
+	sub := &generator{temp: g.temp}
+	fn(sub)
+	g.temp = sub.temp
+	return sub.buf.String()
+}
+
+// declareErr returns the declaration of the err variable, when body uses it.
+func declareErr(body string) string {
+	if strings.Contains(body, "err != nil") {
+		return "var err error\n"
+	}
+	return ""
+}
+
+// encode writes statements that append the binary encoding of expr, which is
+// an addressable Go expression for a value of the Avro type, to buf.
+func (g *generator) encode(t *avroType, expr string) {
+	switch t.kind {
+	case "null":
+	case "boolean", "int", "long", "float", "double", "bytes", "string":
+		g.printf("buf = avroAppend%s(buf, %s)\n", goIdentifier(t.kind), expr)
+	case "array":
+		i := g.tempName("i")
+		g.printf("if len(%s) > 0 {\nbuf = avroAppendLong(buf, int64(len(%s)))\n", expr, expr)
+		g.printf("for %s := range %s {\n", i, expr)
+		g.encode(t.items, expr+"["+i+"]")
+		g.printf("}\n}\nbuf = append(buf, 0)\n")
+	case "map":
+		k, v := g.tempName("k"), g.tempName("v")
+		g.printf("if len(%s) > 0 {\nbuf = avroAppendLong(buf, int64(len(%s)))\n", expr, expr)
+		g.printf("for %s, %s := range %s {\nbuf = avroAppendString(buf, %s)\n", k, v, expr, k)
+		g.encode(t.items, v)
+		g.printf("}\n}\nbuf = append(buf, 0)\n")
+	case "union":
+		if t.isNullable() {
+			nonNull := t.nonNull()
+			g.printf("if %s == nil {\nbuf = avroAppendLong(buf, %d)\n} else {\nbuf = avroAppendLong(buf, %d)\n", expr, 1-nonNull, nonNull)
+			g.encode(t.members[nonNull], "(*"+expr+")")
+			g.printf("}\n")
+			return
+		}
+		fallthrough
+	default:
+		g.printf("if buf, err = %s.MarshalAvro(buf); err != nil {\nreturn nil, err\n}\n", expr)
+	}
+}
+
+// decode writes statements that decode a value of the Avro type from buf into
+// target, which is an addressable Go expression.
+func (g *generator) decode(t *avroType, target string) {
+	switch t.kind {
+	case "null":
+	case "boolean", "int", "long", "float", "double", "bytes", "string":
+		g.printf("if %s, buf, err = avroRead%s(buf); err != nil {\nreturn nil, err\n}\n", target, goIdentifier(t.kind))
+	case "array":
+		n, v := g.tempName("n"), g.tempName("v")
+		g.printf("%s = nil\nfor {\nvar %s int64\n", target, n)
+		g.printf("if %s, buf, err = avroReadBlockCount(buf); err != nil {\nreturn nil, err\n}\nif %s == 0 {\nbreak\n}\n", n, n)
+		g.printf("for ; %s > 0; %s-- {\nvar %s %s\n", n, n, v, goType(t.items))
+		g.decode(t.items, v)
+		g.printf("%s = append(%s, %s)\n}\n}\n", target, target, v)
+	case "map":
+		n, k, v := g.tempName("n"), g.tempName("k"), g.tempName("v")
+		g.printf("%s = make(%s)\nfor {\nvar %s int64\n", target, goType(t), n)
+		g.printf("if %s, buf, err = avroReadBlockCount(buf); err != nil {\nreturn nil, err\n}\nif %s == 0 {\nbreak\n}\n", n, n)
+		g.printf("for ; %s > 0; %s-- {\nvar %s string\n", n, n, k)
+		g.printf("if %s, buf, err = avroReadString(buf); err != nil {\nreturn nil, err\n}\n", k)
+		g.printf("var %s %s\n", v, goType(t.items))
+		g.decode(t.items, v)
+		g.printf("%s[%s] = %s\n}\n}\n", target, k, v)
+	case "union":
+		if t.isNullable() {
+			nonNull := t.nonNull()
+			index := g.tempName("index")
+			g.printf("var %s int64\nif %s, buf, err = avroReadLong(buf); err != nil {\nreturn nil, err\n}\n", index, index)
+			g.printf("switch %s {\ncase %d:\n%s = nil\ncase %d:\n", index, 1-nonNull, target, nonNull)
+			g.printf("%s = new(%s)\n", target, goType(t.members[nonNull]))
+			g.decode(t.members[nonNull], "(*"+target+")")
+			g.printf("default:\nreturn nil, fmt.Errorf(\"cannot decode binary union: index ought to be between 0 and 1; read index: %%d\", %s)\n}\n", index)
+			return
+		}
+		fallthrough
+	default:
+		g.printf("if buf, err = %s.UnmarshalAvro(buf); err != nil {\nreturn nil, err\n}\n", target)
+	}
+}
+
+// quote returns a Go string literal for s, preferring a raw string literal.
+func quote(s string) string {
+	if !strings.ContainsAny(s, "`\r") {
+		return "`" + s + "`"
+	}
+	return strconv.Quote(s)
+}
+
+// helpers are written once to each generated file, and encode and decode the
+// Avro primitive types without using interface{}.
+const helpers = `
+func avroAppendBoolean(buf []byte, v bool) []byte {
+	if v {
+		return append(buf, 1)
+	}
+	return append(buf, 0)
+}
+
+func avroAppendInt(buf []byte, v int32) []byte {
+	return avroAppendVarint(buf, uint64((uint32(v)<<1)^uint32(v>>31)))
+}
+
+func avroAppendLong(buf []byte, v int64) []byte {
+	return avroAppendVarint(buf, (uint64(v)<<1)^uint64(v>>63))
+}
+
+func avroAppendVarint(buf []byte, u uint64) []byte {
+	for u >= 0x80 {
+		buf = append(buf, byte(u)|0x80)
+		u >>= 7
+	}
+	return append(buf, byte(u))
+}
+
+func avroAppendFloat(buf []byte, v float32) []byte {
+	u := math.Float32bits(v)
+	return append(buf, byte(u), byte(u>>8), byte(u>>16), byte(u>>24))
+}
+
+func avroAppendDouble(buf []byte, v float64) []byte {
+	u := math.Float64bits(v)
+	return append(buf, byte(u), byte(u>>8), byte(u>>16), byte(u>>24), byte(u>>32), byte(u>>40), byte(u>>48), byte(u>>56))
+}
+
+func avroAppendBytes(buf []byte, v []byte) []byte {
+	return append(avroAppendLong(buf, int64(len(v))), v...)
+}
+
+func avroAppendString(buf []byte, v string) []byte {
+	return append(avroAppendLong(buf, int64(len(v))), v...)
+}
+
+func avroAppendSingleObjectHeader(buf []byte, rabin uint64) []byte {
+	buf = append(buf, 0xC3, 0x01)
+	for i := uint(0); i < 64; i += 8 {
+		buf = append(buf, byte(rabin>>i))
+	}
+	return buf
+}
+
+func avroReadBoolean(buf []byte) (bool, []byte, error) {
+	if len(buf) < 1 {
+		return false, nil, fmt.Errorf("cannot decode binary boolean: %s", io.ErrShortBuffer)
+	}
+	switch buf[0] {
+	case 0:
+		return false, buf[1:], nil
+	case 1:
+		return true, buf[1:], nil
+	}
+	return false, nil, fmt.Errorf("cannot decode binary boolean: expected: Go byte(1) or byte(0); received: byte(%d)", buf[0])
+}
+
+func avroReadInt(buf []byte) (int32, []byte, error) {
+	v, buf, err := avroReadLong(buf)
+	if err != nil {
+		return 0, nil, err
+	}
+	if v < math.MinInt32 || v > math.MaxInt32 {
+		return 0, nil, fmt.Errorf("cannot decode binary int: value overflows int: %d", v)
+	}
+	return int32(v), buf, nil
+}
+
+func avroReadLong(buf []byte) (int64, []byte, error) {
+	var u uint64
+	var shift uint
+	for i := 0; i < len(buf) && i < 10; i++ {
+		b := buf[i]
+		u |= uint64(b&0x7F) << shift
+		if b&0x80 == 0 {
+			return int64(u>>1) ^ -int64(u&1), buf[i+1:], nil
+		}
+		shift += 7
+	}
+	if len(buf) < 10 {
+		return 0, nil, fmt.Errorf("cannot decode binary long: %s", io.ErrShortBuffer)
+	}
+	return 0, nil, fmt.Errorf("cannot decode binary long: value overflows long")
+}
+
+func avroReadFloat(buf []byte) (float32, []byte, error) {
+	if len(buf) < 4 {
+		return 0, nil, fmt.Errorf("cannot decode binary float: %s", io.ErrShortBuffer)
+	}
+	u := uint32(buf[0]) | uint32(buf[1])<<8 | uint32(buf[2])<<16 | uint32(buf[3])<<24
+	return math.Float32frombits(u), buf[4:], nil
+}
+
+func avroReadDouble(buf []byte) (float64, []byte, error) {
+	if len(buf) < 8 {
+		return 0, nil, fmt.Errorf("cannot decode binary double: %s", io.ErrShortBuffer)
+	}
+	var u uint64
+	for i := uint(0); i < 8; i++ {
+		u |= uint64(buf[i]) << (8 * i)
+	}
+	return math.Float64frombits(u), buf[8:], nil
+}
+
+func avroReadBytes(buf []byte) ([]byte, []byte, error) {
+	size, buf, err := avroReadLong(buf)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot decode binary bytes: %s", err)
+	}
+	if size < 0 {
+		return nil, nil, fmt.Errorf("cannot decode binary bytes: negative size: %d", size)
+	}
+	if size > int64(len(buf)) {
+		return nil, nil, fmt.Errorf("cannot decode binary bytes: %s", io.ErrShortBuffer)
+	}
+	v := make([]byte, size)
+	copy(v, buf)
+	return v, buf[size:], nil
+}
+
+func avroReadString(buf []byte) (string, []byte, error) {
+	size, buf, err := avroReadLong(buf)
+	if err != nil {
+		return "", nil, fmt.Errorf("cannot decode binary string: %s", err)
+	}
+	if size < 0 {
+		return "", nil, fmt.Errorf("cannot decode binary string: negative size: %d", size)
+	}
+	if size > int64(len(buf)) {
+		return "", nil, fmt.Errorf("cannot decode binary string: %s", io.ErrShortBuffer)
+	}
+	return string(buf[:size]), buf[size:], nil
+}
+
+// avroReadBlockCount returns the number of items in the next block of an array
+// or map, skipping the block size that accompanies negative block counts.
+func avroReadBlockCount(buf []byte) (int64, []byte, error) {
+	count, buf, err := avroReadLong(buf)
+	if err != nil {
+		return 0, nil, fmt.Errorf("cannot decode binary block count: %s", err)
+	}
+	if count < 0 {
+		if count == math.MinInt64 {
+			return 0, nil, fmt.Errorf("cannot decode binary block count: %d", count)
+		}
+		count = -count
+		if _, buf, err = avroReadLong(buf); err != nil {
+			return 0, nil, fmt.Errorf("cannot decode binary block size: %s", err)
+		}
+	}
+	return count, buf, nil
+}
+
+func avroConsumeSingleObjectHeader(buf []byte, rabin uint64) ([]byte, error) {
+	if len(buf) < 10 {
+		return nil, fmt.Errorf("cannot decode single-object encoding: %s", io.ErrShortBuffer)
+	}
+	if buf[0] != 0xC3 || buf[1] != 0x01 {
+		return nil, fmt.Errorf("cannot decode single-object encoding: invalid magic bytes: %#v", buf[:2])
+	}
+	var fingerprint uint64
+	for i := uint(0); i < 8; i++ {
+		fingerprint |= uint64(buf[2+i]) << (8 * i)
+	}
+	if fingerprint != rabin {
+		return nil, fmt.Errorf("cannot decode single-object encoding: fingerprint ought to be %#x; received: %#x", rabin, fingerprint)
+	}
+	return buf[10:], nil
+}
+`
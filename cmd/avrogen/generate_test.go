@@ -0,0 +1,85 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateFixtureUpToDate(t *testing.T) {
+	dir := filepath.Join("internal", "fixture")
+	schema, err := ioutil.ReadFile(filepath.Join(dir, "fixture.avsc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := newParser()
+	if err = p.parseSchema(schema); err != nil {
+		t.Fatal(err)
+	}
+	got, err := generate("fixture", p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := ioutil.ReadFile(filepath.Join(dir, "fixture.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("generated source differs from %s; run go generate in %s", filepath.Join(dir, "fixture.go"), dir)
+	}
+}
+
+func TestGenerateMultipleSchemas(t *testing.T) {
+	p := newParser()
+	if err := p.parseSchema([]byte(`{"type":"enum","name":"com.example.Suit","symbols":["SPADES","HEARTS"]}`)); err != nil {
+		t.Fatal(err)
+	}
+	// later schemas may refer to types defined by earlier ones
+	if err := p.parseSchema([]byte(`{"type":"record","name":"com.example.Card","fields":[{"name":"suit","type":"Suit"},{"name":"rank","type":"int"}]}`)); err != nil {
+		t.Fatal(err)
+	}
+	source, err := generate("cards", p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stub := range []string{"package cards", "type Suit string", "SuitSPADES", "type Card struct", "`avro:\"suit\"`"} {
+		if !bytes.Contains(source, []byte(stub)) {
+			t.Errorf("GOT: %s; WANT: %q", source, stub)
+		}
+	}
+}
+
+func TestParseSchemaErrors(t *testing.T) {
+	cases := []struct {
+		schema, err string
+	}{
+		{`{`, "cannot unmarshal schema JSON"},
+		{`"Missing"`, `unknown type: "Missing"`},
+		{`{"type":"record","name":"R"}`, `record "R" ought to have fields array`},
+		{`{"type":"record","name":"R","fields":[{"name":"f","type":"Missing"}]}`, `record "R" field "f": unknown type: "Missing"`},
+		{`{"type":"record","name":"R","fields":[{"name":"a_b","type":"int"},{"name":"aB","type":"int"}]}`, "Go field name ought to be unique: AB"},
+		{`{"type":"record","name":"R","fields":[{"name":"f","type":{"type":"record","name":"R","fields":[]}}]}`, `record "R" ought to be defined only once`},
+		{`{"type":"record","name":"a.R","fields":[{"name":"f","type":{"type":"fixed","name":"b.R","size":1}}]}`, "Go type name R"},
+		{`{"type":"enum","name":"E","symbols":[]}`, `enum "E" ought to have non-empty symbols array`},
+		{`{"type":"fixed","name":"F","size":-1}`, `fixed "F" ought to have non-negative integer size`},
+		{`["int","int"]`, "union member 2 ought to be unique"},
+		{`["int",["string"]]`, "union member 2 ought not be a union"},
+	}
+	for _, c := range cases {
+		err := newParser().parseSchema([]byte(c.schema))
+		if err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("%s: GOT: %v; WANT: %q", c.schema, err, c.err)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+// Package fixture holds Go source generated by avrogen from fixture.avsc, and
+// tests that the generated code encodes and decodes the same bytes as a goavro
+// Codec.
+package fixture
+
+//go:generate go run ../.. -package fixture -o fixture.go fixture.avsc
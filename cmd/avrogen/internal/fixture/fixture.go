@@ -0,0 +1,777 @@
+// Code generated by avrogen. DO NOT EDIT.
+
+package fixture
+
+import (
+	"fmt"
+	"io"
+	"math"
+)
+
+// User is a fixture covering every Avro type.
+type User struct {
+	Active bool  `avro:"active"`
+	Age    int32 `avro:"age"`
+	// Unique identifier.
+	Id        int64                    `avro:"id"`
+	Score     float32                  `avro:"score"`
+	Balance   float64                  `avro:"balance"`
+	Avatar    []byte                   `avro:"avatar"`
+	Name      string                   `avro:"name"`
+	CreatedAt int64                    `avro:"created_at"`
+	Color     Color                    `avro:"color"`
+	Hash      Hash                     `avro:"hash"`
+	Tags      []string                 `avro:"tags"`
+	Counts    map[string]int64         `avro:"counts"`
+	Email     *string                  `avro:"email"`
+	Manager   *User                    `avro:"manager"`
+	Favorite  UnionNullStringLongColor `avro:"favorite"`
+	Key       UnionIntHash             `avro:"key"`
+	Addresses []Address                `avro:"addresses"`
+	Palette   map[string][]Color       `avro:"palette"`
+}
+
+// MarshalAvro appends the binary encoding of r to buf.
+func (r *User) MarshalAvro(buf []byte) ([]byte, error) {
+	var err error
+	buf = avroAppendBoolean(buf, r.Active)
+	buf = avroAppendInt(buf, r.Age)
+	buf = avroAppendLong(buf, r.Id)
+	buf = avroAppendFloat(buf, r.Score)
+	buf = avroAppendDouble(buf, r.Balance)
+	buf = avroAppendBytes(buf, r.Avatar)
+	buf = avroAppendString(buf, r.Name)
+	buf = avroAppendLong(buf, r.CreatedAt)
+	if buf, err = r.Color.MarshalAvro(buf); err != nil {
+		return nil, err
+	}
+	if buf, err = r.Hash.MarshalAvro(buf); err != nil {
+		return nil, err
+	}
+	if len(r.Tags) > 0 {
+		buf = avroAppendLong(buf, int64(len(r.Tags)))
+		for i1 := range r.Tags {
+			buf = avroAppendString(buf, r.Tags[i1])
+		}
+	}
+	buf = append(buf, 0)
+	if len(r.Counts) > 0 {
+		buf = avroAppendLong(buf, int64(len(r.Counts)))
+		for k2, v3 := range r.Counts {
+			buf = avroAppendString(buf, k2)
+			buf = avroAppendLong(buf, v3)
+		}
+	}
+	buf = append(buf, 0)
+	if r.Email == nil {
+		buf = avroAppendLong(buf, 0)
+	} else {
+		buf = avroAppendLong(buf, 1)
+		buf = avroAppendString(buf, (*r.Email))
+	}
+	if r.Manager == nil {
+		buf = avroAppendLong(buf, 0)
+	} else {
+		buf = avroAppendLong(buf, 1)
+		if buf, err = (*r.Manager).MarshalAvro(buf); err != nil {
+			return nil, err
+		}
+	}
+	if buf, err = r.Favorite.MarshalAvro(buf); err != nil {
+		return nil, err
+	}
+	if buf, err = r.Key.MarshalAvro(buf); err != nil {
+		return nil, err
+	}
+	if len(r.Addresses) > 0 {
+		buf = avroAppendLong(buf, int64(len(r.Addresses)))
+		for i4 := range r.Addresses {
+			if buf, err = r.Addresses[i4].MarshalAvro(buf); err != nil {
+				return nil, err
+			}
+		}
+	}
+	buf = append(buf, 0)
+	if len(r.Palette) > 0 {
+		buf = avroAppendLong(buf, int64(len(r.Palette)))
+		for k5, v6 := range r.Palette {
+			buf = avroAppendString(buf, k5)
+			if len(v6) > 0 {
+				buf = avroAppendLong(buf, int64(len(v6)))
+				for i7 := range v6 {
+					if buf, err = v6[i7].MarshalAvro(buf); err != nil {
+						return nil, err
+					}
+				}
+			}
+			buf = append(buf, 0)
+		}
+	}
+	buf = append(buf, 0)
+	return buf, nil
+}
+
+// UnmarshalAvro decodes the binary encoding of an User from buf into r, and
+// returns the remaining bytes.
+func (r *User) UnmarshalAvro(buf []byte) ([]byte, error) {
+	var err error
+	if r.Active, buf, err = avroReadBoolean(buf); err != nil {
+		return nil, err
+	}
+	if r.Age, buf, err = avroReadInt(buf); err != nil {
+		return nil, err
+	}
+	if r.Id, buf, err = avroReadLong(buf); err != nil {
+		return nil, err
+	}
+	if r.Score, buf, err = avroReadFloat(buf); err != nil {
+		return nil, err
+	}
+	if r.Balance, buf, err = avroReadDouble(buf); err != nil {
+		return nil, err
+	}
+	if r.Avatar, buf, err = avroReadBytes(buf); err != nil {
+		return nil, err
+	}
+	if r.Name, buf, err = avroReadString(buf); err != nil {
+		return nil, err
+	}
+	if r.CreatedAt, buf, err = avroReadLong(buf); err != nil {
+		return nil, err
+	}
+	if buf, err = r.Color.UnmarshalAvro(buf); err != nil {
+		return nil, err
+	}
+	if buf, err = r.Hash.UnmarshalAvro(buf); err != nil {
+		return nil, err
+	}
+	r.Tags = nil
+	for {
+		var n8 int64
+		if n8, buf, err = avroReadBlockCount(buf); err != nil {
+			return nil, err
+		}
+		if n8 == 0 {
+			break
+		}
+		for ; n8 > 0; n8-- {
+			var v9 string
+			if v9, buf, err = avroReadString(buf); err != nil {
+				return nil, err
+			}
+			r.Tags = append(r.Tags, v9)
+		}
+	}
+	r.Counts = make(map[string]int64)
+	for {
+		var n10 int64
+		if n10, buf, err = avroReadBlockCount(buf); err != nil {
+			return nil, err
+		}
+		if n10 == 0 {
+			break
+		}
+		for ; n10 > 0; n10-- {
+			var k11 string
+			if k11, buf, err = avroReadString(buf); err != nil {
+				return nil, err
+			}
+			var v12 int64
+			if v12, buf, err = avroReadLong(buf); err != nil {
+				return nil, err
+			}
+			r.Counts[k11] = v12
+		}
+	}
+	var index13 int64
+	if index13, buf, err = avroReadLong(buf); err != nil {
+		return nil, err
+	}
+	switch index13 {
+	case 0:
+		r.Email = nil
+	case 1:
+		r.Email = new(string)
+		if (*r.Email), buf, err = avroReadString(buf); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("cannot decode binary union: index ought to be between 0 and 1; read index: %d", index13)
+	}
+	var index14 int64
+	if index14, buf, err = avroReadLong(buf); err != nil {
+		return nil, err
+	}
+	switch index14 {
+	case 0:
+		r.Manager = nil
+	case 1:
+		r.Manager = new(User)
+		if buf, err = (*r.Manager).UnmarshalAvro(buf); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("cannot decode binary union: index ought to be between 0 and 1; read index: %d", index14)
+	}
+	if buf, err = r.Favorite.UnmarshalAvro(buf); err != nil {
+		return nil, err
+	}
+	if buf, err = r.Key.UnmarshalAvro(buf); err != nil {
+		return nil, err
+	}
+	r.Addresses = nil
+	for {
+		var n15 int64
+		if n15, buf, err = avroReadBlockCount(buf); err != nil {
+			return nil, err
+		}
+		if n15 == 0 {
+			break
+		}
+		for ; n15 > 0; n15-- {
+			var v16 Address
+			if buf, err = v16.UnmarshalAvro(buf); err != nil {
+				return nil, err
+			}
+			r.Addresses = append(r.Addresses, v16)
+		}
+	}
+	r.Palette = make(map[string][]Color)
+	for {
+		var n17 int64
+		if n17, buf, err = avroReadBlockCount(buf); err != nil {
+			return nil, err
+		}
+		if n17 == 0 {
+			break
+		}
+		for ; n17 > 0; n17-- {
+			var k18 string
+			if k18, buf, err = avroReadString(buf); err != nil {
+				return nil, err
+			}
+			var v19 []Color
+			v19 = nil
+			for {
+				var n20 int64
+				if n20, buf, err = avroReadBlockCount(buf); err != nil {
+					return nil, err
+				}
+				if n20 == 0 {
+					break
+				}
+				for ; n20 > 0; n20-- {
+					var v21 Color
+					if buf, err = v21.UnmarshalAvro(buf); err != nil {
+						return nil, err
+					}
+					v19 = append(v19, v21)
+				}
+			}
+			r.Palette[k18] = v19
+		}
+	}
+	return buf, nil
+}
+
+const userAvroSchema = `{"doc":"User is a fixture covering every Avro type.","fields":[{"name":"active","type":"boolean"},{"name":"age","type":"int"},{"doc":"Unique identifier.","name":"id","type":"long"},{"name":"score","type":"float"},{"name":"balance","type":"double"},{"name":"avatar","type":"bytes"},{"name":"name","type":"string"},{"name":"created_at","type":{"logicalType":"timestamp-millis","type":"long"}},{"name":"color","type":{"name":"com.example.Color","symbols":["RED","GREEN","BLUE"],"type":"enum"}},{"name":"hash","type":{"name":"com.example.crypto.Hash","size":4,"type":"fixed"}},{"name":"tags","type":{"items":"string","type":"array"}},{"name":"counts","type":{"type":"map","values":"long"}},{"default":null,"name":"email","type":["null","string"]},{"default":null,"name":"manager","type":["null","com.example.User"]},{"default":null,"name":"favorite","type":["null","string","long","com.example.Color"]},{"name":"key","type":["int","com.example.crypto.Hash"]},{"name":"addresses","type":{"items":{"fields":[{"name":"street","type":"string"},{"name":"zip","type":["null","int"]}],"name":"com.example.Address","type":"record"},"type":"array"}},{"name":"palette","type":{"type":"map","values":{"items":"com.example.Color","type":"array"}}}],"name":"com.example.User","type":"record"}`
+
+// AvroSchema returns the Avro schema of User.
+func (*User) AvroSchema() string {
+	return userAvroSchema
+}
+
+// AvroRabin returns the Rabin fingerprint of the Parsing Canonical Form of the
+// Avro schema of User.
+func (*User) AvroRabin() uint64 {
+	return 0x4c66e2db19d0efa9
+}
+
+// MarshalAvroSingle appends the single-object encoding of r to buf.
+func (r *User) MarshalAvroSingle(buf []byte) ([]byte, error) {
+	return r.MarshalAvro(avroAppendSingleObjectHeader(buf, 0x4c66e2db19d0efa9))
+}
+
+// UnmarshalAvroSingle decodes the single-object encoding of an User from buf
+// into r, and returns the remaining bytes.
+func (r *User) UnmarshalAvroSingle(buf []byte) ([]byte, error) {
+	buf, err := avroConsumeSingleObjectHeader(buf, 0x4c66e2db19d0efa9)
+	if err != nil {
+		return nil, err
+	}
+	return r.UnmarshalAvro(buf)
+}
+
+// Color is generated from the Avro enum "com.example.Color".
+type Color string
+
+// Symbols of Color.
+const (
+	ColorRED   Color = "RED"
+	ColorGREEN Color = "GREEN"
+	ColorBLUE  Color = "BLUE"
+)
+
+// MarshalAvro appends the binary encoding of e to buf.
+func (e Color) MarshalAvro(buf []byte) ([]byte, error) {
+	switch e {
+	case ColorRED:
+		return avroAppendLong(buf, 0), nil
+	case ColorGREEN:
+		return avroAppendLong(buf, 1), nil
+	case ColorBLUE:
+		return avroAppendLong(buf, 2), nil
+	}
+	return nil, fmt.Errorf("cannot encode binary enum %q: value ought to be member of symbols: %q", "com.example.Color", string(e))
+}
+
+// UnmarshalAvro decodes the binary encoding of a Color from buf into e, and
+// returns the remaining bytes.
+func (e *Color) UnmarshalAvro(buf []byte) ([]byte, error) {
+	index, buf, err := avroReadLong(buf)
+	if err != nil {
+		return nil, err
+	}
+	switch index {
+	case 0:
+		*e = ColorRED
+	case 1:
+		*e = ColorGREEN
+	case 2:
+		*e = ColorBLUE
+	default:
+		return nil, fmt.Errorf("cannot decode binary enum %q: index ought to be between 0 and 2; read index: %d", "com.example.Color", index)
+	}
+	return buf, nil
+}
+
+const colorAvroSchema = `{"name":"com.example.Color","symbols":["RED","GREEN","BLUE"],"type":"enum"}`
+
+// AvroSchema returns the Avro schema of Color.
+func (Color) AvroSchema() string {
+	return colorAvroSchema
+}
+
+// AvroRabin returns the Rabin fingerprint of the Parsing Canonical Form of the
+// Avro schema of Color.
+func (Color) AvroRabin() uint64 {
+	return 0x1a10e16c71a70d57
+}
+
+// MarshalAvroSingle appends the single-object encoding of e to buf.
+func (e Color) MarshalAvroSingle(buf []byte) ([]byte, error) {
+	return e.MarshalAvro(avroAppendSingleObjectHeader(buf, 0x1a10e16c71a70d57))
+}
+
+// UnmarshalAvroSingle decodes the single-object encoding of a Color from buf
+// into e, and returns the remaining bytes.
+func (e *Color) UnmarshalAvroSingle(buf []byte) ([]byte, error) {
+	buf, err := avroConsumeSingleObjectHeader(buf, 0x1a10e16c71a70d57)
+	if err != nil {
+		return nil, err
+	}
+	return e.UnmarshalAvro(buf)
+}
+
+// Hash is generated from the Avro fixed "com.example.crypto.Hash".
+type Hash [4]byte
+
+// MarshalAvro appends the binary encoding of f to buf.
+func (f Hash) MarshalAvro(buf []byte) ([]byte, error) {
+	return append(buf, f[:]...), nil
+}
+
+// UnmarshalAvro decodes the binary encoding of a Hash from buf into f, and
+// returns the remaining bytes.
+func (f *Hash) UnmarshalAvro(buf []byte) ([]byte, error) {
+	if len(buf) < 4 {
+		return nil, fmt.Errorf("cannot decode binary fixed %q: %s", "com.example.crypto.Hash", io.ErrShortBuffer)
+	}
+	copy(f[:], buf)
+	return buf[4:], nil
+}
+
+const hashAvroSchema = `{"name":"com.example.crypto.Hash","size":4,"type":"fixed"}`
+
+// AvroSchema returns the Avro schema of Hash.
+func (Hash) AvroSchema() string {
+	return hashAvroSchema
+}
+
+// AvroRabin returns the Rabin fingerprint of the Parsing Canonical Form of the
+// Avro schema of Hash.
+func (Hash) AvroRabin() uint64 {
+	return 0xb78c3b7313f79973
+}
+
+// MarshalAvroSingle appends the single-object encoding of f to buf.
+func (f Hash) MarshalAvroSingle(buf []byte) ([]byte, error) {
+	return f.MarshalAvro(avroAppendSingleObjectHeader(buf, 0xb78c3b7313f79973))
+}
+
+// UnmarshalAvroSingle decodes the single-object encoding of a Hash from buf
+// into f, and returns the remaining bytes.
+func (f *Hash) UnmarshalAvroSingle(buf []byte) ([]byte, error) {
+	buf, err := avroConsumeSingleObjectHeader(buf, 0xb78c3b7313f79973)
+	if err != nil {
+		return nil, err
+	}
+	return f.UnmarshalAvro(buf)
+}
+
+// Address is generated from the Avro record "com.example.Address".
+type Address struct {
+	Street string `avro:"street"`
+	Zip    *int32 `avro:"zip"`
+}
+
+// MarshalAvro appends the binary encoding of r to buf.
+func (r *Address) MarshalAvro(buf []byte) ([]byte, error) {
+	buf = avroAppendString(buf, r.Street)
+	if r.Zip == nil {
+		buf = avroAppendLong(buf, 0)
+	} else {
+		buf = avroAppendLong(buf, 1)
+		buf = avroAppendInt(buf, (*r.Zip))
+	}
+	return buf, nil
+}
+
+// UnmarshalAvro decodes the binary encoding of an Address from buf into r, and
+// returns the remaining bytes.
+func (r *Address) UnmarshalAvro(buf []byte) ([]byte, error) {
+	var err error
+	if r.Street, buf, err = avroReadString(buf); err != nil {
+		return nil, err
+	}
+	var index22 int64
+	if index22, buf, err = avroReadLong(buf); err != nil {
+		return nil, err
+	}
+	switch index22 {
+	case 0:
+		r.Zip = nil
+	case 1:
+		r.Zip = new(int32)
+		if (*r.Zip), buf, err = avroReadInt(buf); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("cannot decode binary union: index ought to be between 0 and 1; read index: %d", index22)
+	}
+	return buf, nil
+}
+
+const addressAvroSchema = `{"fields":[{"name":"street","type":"string"},{"name":"zip","type":["null","int"]}],"name":"com.example.Address","type":"record"}`
+
+// AvroSchema returns the Avro schema of Address.
+func (*Address) AvroSchema() string {
+	return addressAvroSchema
+}
+
+// AvroRabin returns the Rabin fingerprint of the Parsing Canonical Form of the
+// Avro schema of Address.
+func (*Address) AvroRabin() uint64 {
+	return 0x59aa1d348e188440
+}
+
+// MarshalAvroSingle appends the single-object encoding of r to buf.
+func (r *Address) MarshalAvroSingle(buf []byte) ([]byte, error) {
+	return r.MarshalAvro(avroAppendSingleObjectHeader(buf, 0x59aa1d348e188440))
+}
+
+// UnmarshalAvroSingle decodes the single-object encoding of an Address from
+// buf into r, and returns the remaining bytes.
+func (r *Address) UnmarshalAvroSingle(buf []byte) ([]byte, error) {
+	buf, err := avroConsumeSingleObjectHeader(buf, 0x59aa1d348e188440)
+	if err != nil {
+		return nil, err
+	}
+	return r.UnmarshalAvro(buf)
+}
+
+// UnionNullStringLongColor is generated from an Avro union of Null, String,
+// Long, Color. At most one of its fields ought to be set, and when none are,
+// it represents null.
+type UnionNullStringLongColor struct {
+	String *string
+	Long   *int64
+	Color  *Color
+}
+
+// MarshalAvro appends the binary encoding of u to buf.
+func (u *UnionNullStringLongColor) MarshalAvro(buf []byte) ([]byte, error) {
+	var err error
+	switch {
+	case u.String != nil:
+		buf = avroAppendLong(buf, 1)
+		buf = avroAppendString(buf, (*u.String))
+	case u.Long != nil:
+		buf = avroAppendLong(buf, 2)
+		buf = avroAppendLong(buf, (*u.Long))
+	case u.Color != nil:
+		buf = avroAppendLong(buf, 3)
+		if buf, err = (*u.Color).MarshalAvro(buf); err != nil {
+			return nil, err
+		}
+	default:
+		buf = avroAppendLong(buf, 0)
+	}
+	return buf, nil
+}
+
+// UnmarshalAvro decodes the binary encoding of an UnionNullStringLongColor
+// from buf into u, and returns the remaining bytes.
+func (u *UnionNullStringLongColor) UnmarshalAvro(buf []byte) ([]byte, error) {
+	index, buf, err := avroReadLong(buf)
+	if err != nil {
+		return nil, err
+	}
+	*u = UnionNullStringLongColor{}
+	switch index {
+	case 0:
+	case 1:
+		u.String = new(string)
+		if (*u.String), buf, err = avroReadString(buf); err != nil {
+			return nil, err
+		}
+	case 2:
+		u.Long = new(int64)
+		if (*u.Long), buf, err = avroReadLong(buf); err != nil {
+			return nil, err
+		}
+	case 3:
+		u.Color = new(Color)
+		if buf, err = (*u.Color).UnmarshalAvro(buf); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("cannot decode binary union UnionNullStringLongColor: index ought to be between 0 and 3; read index: %d", index)
+	}
+	return buf, nil
+}
+
+// UnionIntHash is generated from an Avro union of Int, Hash. Exactly one of
+// its fields ought to be set.
+type UnionIntHash struct {
+	Int  *int32
+	Hash *Hash
+}
+
+// MarshalAvro appends the binary encoding of u to buf.
+func (u *UnionIntHash) MarshalAvro(buf []byte) ([]byte, error) {
+	var err error
+	switch {
+	case u.Int != nil:
+		buf = avroAppendLong(buf, 0)
+		buf = avroAppendInt(buf, (*u.Int))
+	case u.Hash != nil:
+		buf = avroAppendLong(buf, 1)
+		if buf, err = (*u.Hash).MarshalAvro(buf); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("cannot encode binary union UnionIntHash: a field ought to be set")
+	}
+	return buf, nil
+}
+
+// UnmarshalAvro decodes the binary encoding of an UnionIntHash from buf into
+// u, and returns the remaining bytes.
+func (u *UnionIntHash) UnmarshalAvro(buf []byte) ([]byte, error) {
+	index, buf, err := avroReadLong(buf)
+	if err != nil {
+		return nil, err
+	}
+	*u = UnionIntHash{}
+	switch index {
+	case 0:
+		u.Int = new(int32)
+		if (*u.Int), buf, err = avroReadInt(buf); err != nil {
+			return nil, err
+		}
+	case 1:
+		u.Hash = new(Hash)
+		if buf, err = (*u.Hash).UnmarshalAvro(buf); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("cannot decode binary union UnionIntHash: index ought to be between 0 and 1; read index: %d", index)
+	}
+	return buf, nil
+}
+
+func avroAppendBoolean(buf []byte, v bool) []byte {
+	if v {
+		return append(buf, 1)
+	}
+	return append(buf, 0)
+}
+
+func avroAppendInt(buf []byte, v int32) []byte {
+	return avroAppendVarint(buf, uint64((uint32(v)<<1)^uint32(v>>31)))
+}
+
+func avroAppendLong(buf []byte, v int64) []byte {
+	return avroAppendVarint(buf, (uint64(v)<<1)^uint64(v>>63))
+}
+
+func avroAppendVarint(buf []byte, u uint64) []byte {
+	for u >= 0x80 {
+		buf = append(buf, byte(u)|0x80)
+		u >>= 7
+	}
+	return append(buf, byte(u))
+}
+
+func avroAppendFloat(buf []byte, v float32) []byte {
+	u := math.Float32bits(v)
+	return append(buf, byte(u), byte(u>>8), byte(u>>16), byte(u>>24))
+}
+
+func avroAppendDouble(buf []byte, v float64) []byte {
+	u := math.Float64bits(v)
+	return append(buf, byte(u), byte(u>>8), byte(u>>16), byte(u>>24), byte(u>>32), byte(u>>40), byte(u>>48), byte(u>>56))
+}
+
+func avroAppendBytes(buf []byte, v []byte) []byte {
+	return append(avroAppendLong(buf, int64(len(v))), v...)
+}
+
+func avroAppendString(buf []byte, v string) []byte {
+	return append(avroAppendLong(buf, int64(len(v))), v...)
+}
+
+func avroAppendSingleObjectHeader(buf []byte, rabin uint64) []byte {
+	buf = append(buf, 0xC3, 0x01)
+	for i := uint(0); i < 64; i += 8 {
+		buf = append(buf, byte(rabin>>i))
+	}
+	return buf
+}
+
+func avroReadBoolean(buf []byte) (bool, []byte, error) {
+	if len(buf) < 1 {
+		return false, nil, fmt.Errorf("cannot decode binary boolean: %s", io.ErrShortBuffer)
+	}
+	switch buf[0] {
+	case 0:
+		return false, buf[1:], nil
+	case 1:
+		return true, buf[1:], nil
+	}
+	return false, nil, fmt.Errorf("cannot decode binary boolean: expected: Go byte(1) or byte(0); received: byte(%d)", buf[0])
+}
+
+func avroReadInt(buf []byte) (int32, []byte, error) {
+	v, buf, err := avroReadLong(buf)
+	if err != nil {
+		return 0, nil, err
+	}
+	if v < math.MinInt32 || v > math.MaxInt32 {
+		return 0, nil, fmt.Errorf("cannot decode binary int: value overflows int: %d", v)
+	}
+	return int32(v), buf, nil
+}
+
+func avroReadLong(buf []byte) (int64, []byte, error) {
+	var u uint64
+	var shift uint
+	for i := 0; i < len(buf) && i < 10; i++ {
+		b := buf[i]
+		u |= uint64(b&0x7F) << shift
+		if b&0x80 == 0 {
+			return int64(u>>1) ^ -int64(u&1), buf[i+1:], nil
+		}
+		shift += 7
+	}
+	if len(buf) < 10 {
+		return 0, nil, fmt.Errorf("cannot decode binary long: %s", io.ErrShortBuffer)
+	}
+	return 0, nil, fmt.Errorf("cannot decode binary long: value overflows long")
+}
+
+func avroReadFloat(buf []byte) (float32, []byte, error) {
+	if len(buf) < 4 {
+		return 0, nil, fmt.Errorf("cannot decode binary float: %s", io.ErrShortBuffer)
+	}
+	u := uint32(buf[0]) | uint32(buf[1])<<8 | uint32(buf[2])<<16 | uint32(buf[3])<<24
+	return math.Float32frombits(u), buf[4:], nil
+}
+
+func avroReadDouble(buf []byte) (float64, []byte, error) {
+	if len(buf) < 8 {
+		return 0, nil, fmt.Errorf("cannot decode binary double: %s", io.ErrShortBuffer)
+	}
+	var u uint64
+	for i := uint(0); i < 8; i++ {
+		u |= uint64(buf[i]) << (8 * i)
+	}
+	return math.Float64frombits(u), buf[8:], nil
+}
+
+func avroReadBytes(buf []byte) ([]byte, []byte, error) {
+	size, buf, err := avroReadLong(buf)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot decode binary bytes: %s", err)
+	}
+	if size < 0 {
+		return nil, nil, fmt.Errorf("cannot decode binary bytes: negative size: %d", size)
+	}
+	if size > int64(len(buf)) {
+		return nil, nil, fmt.Errorf("cannot decode binary bytes: %s", io.ErrShortBuffer)
+	}
+	v := make([]byte, size)
+	copy(v, buf)
+	return v, buf[size:], nil
+}
+
+func avroReadString(buf []byte) (string, []byte, error) {
+	size, buf, err := avroReadLong(buf)
+	if err != nil {
+		return "", nil, fmt.Errorf("cannot decode binary string: %s", err)
+	}
+	if size < 0 {
+		return "", nil, fmt.Errorf("cannot decode binary string: negative size: %d", size)
+	}
+	if size > int64(len(buf)) {
+		return "", nil, fmt.Errorf("cannot decode binary string: %s", io.ErrShortBuffer)
+	}
+	return string(buf[:size]), buf[size:], nil
+}
+
+// avroReadBlockCount returns the number of items in the next block of an array
+// or map, skipping the block size that accompanies negative block counts.
+func avroReadBlockCount(buf []byte) (int64, []byte, error) {
+	count, buf, err := avroReadLong(buf)
+	if err != nil {
+		return 0, nil, fmt.Errorf("cannot decode binary block count: %s", err)
+	}
+	if count < 0 {
+		if count == math.MinInt64 {
+			return 0, nil, fmt.Errorf("cannot decode binary block count: %d", count)
+		}
+		count = -count
+		if _, buf, err = avroReadLong(buf); err != nil {
+			return 0, nil, fmt.Errorf("cannot decode binary block size: %s", err)
+		}
+	}
+	return count, buf, nil
+}
+
+func avroConsumeSingleObjectHeader(buf []byte, rabin uint64) ([]byte, error) {
+	if len(buf) < 10 {
+		return nil, fmt.Errorf("cannot decode single-object encoding: %s", io.ErrShortBuffer)
+	}
+	if buf[0] != 0xC3 || buf[1] != 0x01 {
+		return nil, fmt.Errorf("cannot decode single-object encoding: invalid magic bytes: %#v", buf[:2])
+	}
+	var fingerprint uint64
+	for i := uint(0); i < 8; i++ {
+		fingerprint |= uint64(buf[2+i]) << (8 * i)
+	}
+	if fingerprint != rabin {
+		return nil, fmt.Errorf("cannot decode single-object encoding: fingerprint ought to be %#x; received: %#x", rabin, fingerprint)
+	}
+	return buf[10:], nil
+}
@@ -0,0 +1,207 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package fixture
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/linkedin/goavro/v2"
+)
+
+func newUser() User {
+	email := "bob@example.com"
+	favorite := ColorGREEN
+	key := Hash{5, 6, 7, 8}
+	zip := int32(94043)
+	return User{
+		Active:    true,
+		Age:       -42,
+		Id:        1 << 40,
+		Score:     3.5,
+		Balance:   -1234.5,
+		Avatar:    []byte{0xde, 0xad},
+		Name:      "Bob",
+		CreatedAt: 1577934245000,
+		Color:     ColorBLUE,
+		Hash:      Hash{1, 2, 3, 4},
+		Tags:      []string{"a", "b"},
+		Counts:    map[string]int64{"x": 13},
+		Email:     &email,
+		Manager:   &User{Name: "Alice", Color: ColorRED, Counts: map[string]int64{}, Palette: map[string][]Color{}, Key: UnionIntHash{Hash: &key}},
+		Favorite:  UnionNullStringLongColor{Color: &favorite},
+		Key:       UnionIntHash{Hash: &key},
+		Addresses: []Address{{Street: "Main"}, {Street: "Elm", Zip: &zip}},
+		Palette:   map[string][]Color{"warm": {ColorRED, ColorGREEN}},
+	}
+}
+
+func newUserNative() map[string]interface{} {
+	return map[string]interface{}{
+		"active":     true,
+		"age":        int32(-42),
+		"id":         int64(1 << 40),
+		"score":      float32(3.5),
+		"balance":    -1234.5,
+		"avatar":     []byte{0xde, 0xad},
+		"name":       "Bob",
+		"created_at": time.Unix(1577934245, 0),
+		"color":      "BLUE",
+		"hash":       []byte{1, 2, 3, 4},
+		"tags":       []interface{}{"a", "b"},
+		"counts":     map[string]interface{}{"x": int64(13)},
+		"email":      goavro.Union("string", "bob@example.com"),
+		"manager": goavro.Union("com.example.User", map[string]interface{}{
+			"active":     false,
+			"age":        int32(0),
+			"id":         int64(0),
+			"score":      float32(0),
+			"balance":    0.0,
+			"avatar":     []byte{},
+			"name":       "Alice",
+			"created_at": time.Unix(0, 0),
+			"color":      "RED",
+			"hash":       []byte{0, 0, 0, 0},
+			"tags":       []interface{}{},
+			"counts":     map[string]interface{}{},
+			"key":        goavro.Union("com.example.crypto.Hash", []byte{5, 6, 7, 8}),
+			"addresses":  []interface{}{},
+			"palette":    map[string]interface{}{},
+		}),
+		"favorite": goavro.Union("com.example.Color", "GREEN"),
+		"key":      goavro.Union("com.example.crypto.Hash", []byte{5, 6, 7, 8}),
+		"addresses": []interface{}{
+			map[string]interface{}{"street": "Main", "zip": nil},
+			map[string]interface{}{"street": "Elm", "zip": goavro.Union("int", int32(94043))},
+		},
+		"palette": map[string]interface{}{"warm": []interface{}{"RED", "GREEN"}},
+	}
+}
+
+func TestUserMarshalAvro(t *testing.T) {
+	codec, err := goavro.NewCodec(new(User).AvroSchema())
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := newUser()
+	native := newUserNative()
+
+	buf, err := user.MarshalAvro(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := codec.BinaryFromNative(nil, native)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf, expected) {
+		t.Errorf("GOT: %#v; WANT: %#v", buf, expected)
+	}
+}
+
+func TestUserUnmarshalAvro(t *testing.T) {
+	codec, err := goavro.NewCodec(new(User).AvroSchema())
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := newUser()
+	native := newUserNative()
+
+	buf, err := codec.BinaryFromNative(nil, native)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded User
+	remaining, err := decoded.UnmarshalAvro(append(buf, 0xff))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(remaining, []byte{0xff}) {
+		t.Errorf("GOT: %#v; WANT: %#v", remaining, []byte{0xff})
+	}
+	// NOTE: Empty bytes decode as non-nil slices.
+	user.Manager.Avatar = []byte{}
+	if !reflect.DeepEqual(decoded, user) {
+		t.Errorf("GOT: %#v; WANT: %#v", decoded, user)
+	}
+
+	for i := 0; i < len(buf); i++ {
+		if _, err = decoded.UnmarshalAvro(buf[:i]); err == nil {
+			t.Errorf("GOT: %v; WANT: error decoding %d of %d bytes", err, i, len(buf))
+		}
+	}
+}
+
+func TestUserMarshalAvroErrors(t *testing.T) {
+	user := newUser()
+	user.Color = "PURPLE"
+	_, err := user.MarshalAvro(nil)
+	ensureError(t, err, "ought to be member of symbols")
+
+	user = newUser()
+	user.Key = UnionIntHash{}
+	_, err = user.MarshalAvro(nil)
+	ensureError(t, err, "a field ought to be set")
+}
+
+func TestSingleObjectEncoding(t *testing.T) {
+	codec, err := goavro.NewCodec(new(Address).AvroSchema())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := new(Address).AvroRabin(), codec.Rabin; got != want {
+		t.Errorf("GOT: %#x; WANT: %#x", got, want)
+	}
+
+	address := Address{Street: "Main"}
+	buf, err := address.MarshalAvroSingle(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := codec.SingleFromNative(nil, map[string]interface{}{"street": "Main", "zip": nil})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf, expected) {
+		t.Errorf("GOT: %#v; WANT: %#v", buf, expected)
+	}
+
+	var decoded Address
+	if _, err = decoded.UnmarshalAvroSingle(buf); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, address) {
+		t.Errorf("GOT: %#v; WANT: %#v", decoded, address)
+	}
+
+	var color Color
+	_, err = color.UnmarshalAvroSingle(buf)
+	ensureError(t, err, "fingerprint ought to be")
+}
+
+func ensureError(tb testing.TB, err error, contains ...string) {
+	tb.Helper()
+	if len(contains) == 0 || contains[0] == "" {
+		if err != nil {
+			tb.Fatalf("GOT: %v; WANT: %v", err, contains)
+		}
+	} else if err == nil {
+		tb.Errorf("GOT: %v; WANT: %v", err, contains)
+	} else {
+		for _, stub := range contains {
+			if !strings.Contains(err.Error(), stub) {
+				tb.Errorf("GOT: %v; WANT: %q", err, stub)
+			}
+		}
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+// avrogen reads one or more Avro schema files, and writes Go source code
+// declaring a type for each record, enum, and fixed type they define, along
+// with wrapper types for unions. Each generated type has MarshalAvro and
+// UnmarshalAvro methods that encode and decode the same bytes as a goavro
+// Codec, without using interface{} values.
+//
+// Named types defined by earlier schema files may be referred to by later
+// schema files.
+//
+//	avrogen -package events -o events_avro.go user.avsc login.avsc
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+func usage() {
+	executable, err := os.Executable()
+	if err != nil {
+		executable = os.Args[0]
+	}
+	base := filepath.Base(executable)
+	fmt.Fprintf(os.Stderr, "Usage of %s:\n", base)
+	fmt.Fprintf(os.Stderr, "\t%s [-package name] [-o output.go] file1.avsc [file2.avsc ...]\n", base)
+	flag.PrintDefaults()
+	os.Exit(2)
+}
+
+func main() {
+	packageName := flag.String("package", "main", "name of the package of the generated source")
+	output := flag.String("o", "", "file to write the generated source to, rather than standard output")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		usage()
+	}
+
+	p := newParser()
+	for _, arg := range flag.Args() {
+		schema, err := ioutil.ReadFile(arg)
+		if err != nil {
+			bail(err)
+		}
+		if err = p.parseSchema(schema); err != nil {
+			bail(fmt.Errorf("cannot parse %s: %s", arg, err))
+		}
+	}
+
+	source, err := generate(*packageName, p)
+	if err != nil {
+		bail(err)
+	}
+
+	if *output == "" {
+		_, err = os.Stdout.Write(source)
+	} else {
+		err = ioutil.WriteFile(*output, source, 0644)
+	}
+	if err != nil {
+		bail(err)
+	}
+}
+
+func bail(err error) {
+	fmt.Fprintf(os.Stderr, "%s\n", err)
+	os.Exit(1)
+}
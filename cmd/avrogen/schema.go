@@ -0,0 +1,472 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/linkedin/goavro/v2"
+)
+
+// avroType describes one Avro type, and the Go type generated for it.
+type avroType struct {
+	kind    string // primitive type name, "record", "enum", "fixed", "array", "map", or "union"
+	goName  string // Go type name of named types and union wrappers
+	doc     string
+	fields  []*avroField // record fields
+	symbols []string     // enum symbols
+	size    int          // fixed size
+	items   *avroType    // array items, or map values
+	members []*avroType  // union members
+
+	// The following are only used for named types.
+	fullName           string
+	enclosingNamespace string                 // namespace in effect where the type is defined
+	schemaMap          map[string]interface{} // definition as it appears in the schema
+	schema             string                 // standalone schema, defining every type it refers to
+	rabin              uint64                 // Rabin fingerprint of the standalone schema
+}
+
+// avroField describes one field of an Avro record.
+type avroField struct {
+	name   string
+	goName string
+	doc    string
+	typ    *avroType
+}
+
+// isNullable returns true when the type is a union of null and exactly one
+// other type, which is generated as a pointer to that other type.
+func (t *avroType) isNullable() bool {
+	return t.kind == "union" && len(t.members) == 2 && (t.members[0].kind == "null") != (t.members[1].kind == "null")
+}
+
+// nonNull returns the index of the union member that is not null, for a
+// nullable union.
+func (t *avroType) nonNull() int {
+	if t.members[0].kind == "null" {
+		return 1
+	}
+	return 0
+}
+
+// parser builds avroType descriptions from Avro schemas. Named types defined by
+// earlier schemas may be referred to by later schemas.
+type parser struct {
+	types       map[string]*avroType // named types by full name
+	named       []*avroType          // named types in definition order
+	unions      map[string]*avroType // union wrappers by Go name
+	unionsOrder []*avroType          // union wrappers in definition order
+	goNames     map[string]string    // Go type names to the Avro type they describe
+}
+
+func newParser() *parser {
+	return &parser{
+		types:   make(map[string]*avroType),
+		unions:  make(map[string]*avroType),
+		goNames: make(map[string]string),
+	}
+}
+
+// parseSchema parses one JSON encoded Avro schema, such as the contents of an
+// .avsc file.
+func (p *parser) parseSchema(schema []byte) error {
+	var node interface{}
+	if err := json.Unmarshal(schema, &node); err != nil {
+		return fmt.Errorf("cannot unmarshal schema JSON: %s", err)
+	}
+	if _, err := p.parse(node, ""); err != nil {
+		return err
+	}
+	// NOTE: Complete the standalone schema of each named type only after the
+	// entire schema is parsed, so each type is checked by goavro.
+	for _, t := range p.named {
+		if t.schema != "" {
+			continue
+		}
+		standalone, err := p.standalone(t.schemaMap, t.enclosingNamespace, make(map[string]bool))
+		if err != nil {
+			return err
+		}
+		buf, err := json.Marshal(standalone)
+		if err != nil {
+			return err
+		}
+		codec, err := goavro.NewCodec(string(buf))
+		if err != nil {
+			return fmt.Errorf("cannot create codec for %q: %s", t.fullName, err)
+		}
+		t.schema = string(buf)
+		t.rabin = codec.Rabin
+	}
+	return nil
+}
+
+func isPrimitive(typeName string) bool {
+	switch typeName {
+	case "null", "boolean", "int", "long", "float", "double", "bytes", "string":
+		return true
+	}
+	return false
+}
+
+func (p *parser) parse(node interface{}, namespace string) (*avroType, error) {
+	switch v := node.(type) {
+	case string:
+		if isPrimitive(v) {
+			return &avroType{kind: v}, nil
+		}
+		if t := p.lookup(v, namespace); t != nil {
+			return t, nil
+		}
+		return nil, fmt.Errorf("unknown type: %q", v)
+	case []interface{}:
+		return p.parseUnion(v, namespace)
+	case map[string]interface{}:
+		return p.parseMap(v, namespace)
+	default:
+		return nil, fmt.Errorf("unknown schema type: %T", node)
+	}
+}
+
+// lookup returns the named type that typeName refers to from within namespace,
+// or nil when there is no such type.
+func (p *parser) lookup(typeName, namespace string) *avroType {
+	if !strings.Contains(typeName, ".") && namespace != "" {
+		if t, ok := p.types[namespace+"."+typeName]; ok {
+			return t
+		}
+	}
+	return p.types[typeName]
+}
+
+func (p *parser) parseMap(schemaMap map[string]interface{}, namespace string) (*avroType, error) {
+	typeValue, ok := schemaMap["type"]
+	if !ok {
+		return nil, errors.New("schema ought to have type key")
+	}
+	typeName, ok := typeValue.(string)
+	if !ok {
+		return p.parse(typeValue, namespace)
+	}
+	switch typeName {
+	case "record", "error", "enum", "fixed":
+		return p.parseNamed(typeName, schemaMap, namespace)
+	case "array":
+		items, err := p.parse(schemaMap["items"], namespace)
+		if err != nil {
+			return nil, fmt.Errorf("array items: %s", err)
+		}
+		return &avroType{kind: "array", items: items}, nil
+	case "map":
+		values, err := p.parse(schemaMap["values"], namespace)
+		if err != nil {
+			return nil, fmt.Errorf("map values: %s", err)
+		}
+		return &avroType{kind: "map", items: values}, nil
+	default:
+		// NOTE: Logical types are generated using their underlying type.
+		return p.parse(typeName, namespace)
+	}
+}
+
+func (p *parser) parseNamed(typeName string, schemaMap map[string]interface{}, namespace string) (*avroType, error) {
+	name, _ := schemaMap["name"].(string)
+	if name == "" {
+		return nil, fmt.Errorf("%s ought to have non-empty name", typeName)
+	}
+	fullName := name
+	if !strings.Contains(name, ".") {
+		ns := namespace
+		if s, ok := schemaMap["namespace"].(string); ok {
+			ns = s
+		}
+		if ns != "" {
+			fullName = ns + "." + name
+		}
+	}
+	if _, ok := p.types[fullName]; ok {
+		return nil, fmt.Errorf("%s %q ought to be defined only once", typeName, fullName)
+	}
+	typeNamespace := ""
+	if index := strings.LastIndexByte(fullName, '.'); index > -1 {
+		typeNamespace = fullName[:index]
+	}
+
+	t := &avroType{
+		kind:               typeName,
+		goName:             goIdentifier(fullName[strings.LastIndexByte(fullName, '.')+1:]),
+		fullName:           fullName,
+		enclosingNamespace: namespace,
+		schemaMap:          schemaMap,
+	}
+	t.doc, _ = schemaMap["doc"].(string)
+	if t.kind == "error" {
+		t.kind = "record"
+	}
+	if err := p.registerGoName(t.goName, fullName); err != nil {
+		return nil, err
+	}
+	// NOTE: Register the type before parsing its fields, so recursive
+	// references to it resolve.
+	p.types[fullName] = t
+	p.named = append(p.named, t)
+
+	switch t.kind {
+	case "record":
+		fields, ok := schemaMap["fields"].([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("record %q ought to have fields array", fullName)
+		}
+		goNames := make(map[string]bool, len(fields))
+		for i, f := range fields {
+			fieldMap, ok := f.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("record %q field %d ought to be an object", fullName, i+1)
+			}
+			fieldName, _ := fieldMap["name"].(string)
+			if fieldName == "" {
+				return nil, fmt.Errorf("record %q field %d ought to have non-empty name", fullName, i+1)
+			}
+			fieldType, err := p.parse(fieldMap["type"], typeNamespace)
+			if err != nil {
+				return nil, fmt.Errorf("record %q field %q: %s", fullName, fieldName, err)
+			}
+			field := &avroField{name: fieldName, goName: goIdentifier(fieldName), typ: fieldType}
+			field.doc, _ = fieldMap["doc"].(string)
+			if goNames[field.goName] {
+				return nil, fmt.Errorf("record %q field %q: Go field name ought to be unique: %s", fullName, fieldName, field.goName)
+			}
+			goNames[field.goName] = true
+			t.fields = append(t.fields, field)
+		}
+	case "enum":
+		symbols, ok := schemaMap["symbols"].([]interface{})
+		if !ok || len(symbols) == 0 {
+			return nil, fmt.Errorf("enum %q ought to have non-empty symbols array", fullName)
+		}
+		for _, s := range symbols {
+			symbol, ok := s.(string)
+			if !ok || symbol == "" {
+				return nil, fmt.Errorf("enum %q symbols ought to be non-empty strings", fullName)
+			}
+			if err := p.registerGoName(t.goName+goIdentifier(symbol), fullName+"."+symbol); err != nil {
+				return nil, err
+			}
+			t.symbols = append(t.symbols, symbol)
+		}
+	case "fixed":
+		size, ok := schemaMap["size"].(float64)
+		if !ok || size < 0 || size != float64(int(size)) {
+			return nil, fmt.Errorf("fixed %q ought to have non-negative integer size", fullName)
+		}
+		t.size = int(size)
+	}
+	return t, nil
+}
+
+func (p *parser) parseUnion(schemas []interface{}, namespace string) (*avroType, error) {
+	t := &avroType{kind: "union"}
+	labels := make([]string, 0, len(schemas))
+	seen := make(map[string]bool, len(schemas))
+	for i, schema := range schemas {
+		member, err := p.parse(schema, namespace)
+		if err != nil {
+			return nil, fmt.Errorf("union member %d: %s", i+1, err)
+		}
+		if member.kind == "union" {
+			return nil, fmt.Errorf("union member %d ought not be a union", i+1)
+		}
+		label := member.label()
+		if seen[label] {
+			return nil, fmt.Errorf("union member %d ought to be unique: %s", i+1, label)
+		}
+		seen[label] = true
+		labels = append(labels, label)
+		t.members = append(t.members, member)
+	}
+	if len(t.members) == 0 {
+		return nil, errors.New("union ought to have at least one member")
+	}
+	if t.isNullable() {
+		return t, nil
+	}
+
+	// Identical unions share the same wrapper type.
+	t.goName = "Union" + strings.Join(labels, "")
+	if existing, ok := p.unions[t.goName]; ok {
+		return existing, nil
+	}
+	if err := p.registerGoName(t.goName, fmt.Sprintf("%v", labels)); err != nil {
+		return nil, err
+	}
+	p.unions[t.goName] = t
+	p.unionsOrder = append(p.unionsOrder, t)
+	return t, nil
+}
+
+func (p *parser) registerGoName(goName, description string) error {
+	if other, ok := p.goNames[goName]; ok {
+		return fmt.Errorf("Go type name %s of %s ought to be unique, but is also used by %s", goName, description, other)
+	}
+	p.goNames[goName] = description
+	return nil
+}
+
+// label returns a name for the type, used to name union wrappers and their
+// fields.
+func (t *avroType) label() string {
+	switch t.kind {
+	case "array":
+		return "Array" + t.items.label()
+	case "map":
+		return "Map" + t.items.label()
+	case "record", "enum", "fixed":
+		return t.goName
+	default:
+		return goIdentifier(t.kind)
+	}
+}
+
+// standalone returns the schema node with every named type it refers to that
+// is not in defined replaced by its definition, and every name fully
+// qualified, so the schema may be used on its own.
+func (p *parser) standalone(node interface{}, namespace string, defined map[string]bool) (interface{}, error) {
+	switch v := node.(type) {
+	case string:
+		if isPrimitive(v) {
+			return v, nil
+		}
+		t := p.lookup(v, namespace)
+		if t == nil {
+			return nil, fmt.Errorf("unknown type: %q", v)
+		}
+		if defined[t.fullName] {
+			return t.fullName, nil
+		}
+		return p.standalone(t.schemaMap, t.enclosingNamespace, defined)
+	case []interface{}:
+		members := make([]interface{}, len(v))
+		for i, member := range v {
+			var err error
+			if members[i], err = p.standalone(member, namespace, defined); err != nil {
+				return nil, err
+			}
+		}
+		return members, nil
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			out[key] = value
+		}
+		typeName, ok := v["type"].(string)
+		if !ok {
+			typeValue, err := p.standalone(v["type"], namespace, defined)
+			if err != nil {
+				return nil, err
+			}
+			out["type"] = typeValue
+			return out, nil
+		}
+		switch typeName {
+		case "record", "error", "enum", "fixed":
+			fullName := p.fullNameOf(v, namespace)
+			defined[fullName] = true
+			out["name"] = fullName
+			delete(out, "namespace")
+			if fields, ok := v["fields"].([]interface{}); ok {
+				typeNamespace := ""
+				if index := strings.LastIndexByte(fullName, '.'); index > -1 {
+					typeNamespace = fullName[:index]
+				}
+				outFields := make([]interface{}, len(fields))
+				for i, f := range fields {
+					fieldMap, _ := f.(map[string]interface{})
+					outField := make(map[string]interface{}, len(fieldMap))
+					for key, value := range fieldMap {
+						outField[key] = value
+					}
+					fieldType, err := p.standalone(fieldMap["type"], typeNamespace, defined)
+					if err != nil {
+						return nil, err
+					}
+					outField["type"] = fieldType
+					outFields[i] = outField
+				}
+				out["fields"] = outFields
+			}
+		case "array":
+			items, err := p.standalone(v["items"], namespace, defined)
+			if err != nil {
+				return nil, err
+			}
+			out["items"] = items
+		case "map":
+			values, err := p.standalone(v["values"], namespace, defined)
+			if err != nil {
+				return nil, err
+			}
+			out["values"] = values
+		default:
+			if !isPrimitive(typeName) {
+				typeValue, err := p.standalone(typeName, namespace, defined)
+				if err != nil {
+					return nil, err
+				}
+				out["type"] = typeValue
+			}
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("unknown schema type: %T", node)
+	}
+}
+
+// fullNameOf returns the full name of the named type defined by schemaMap
+// within namespace.
+func (p *parser) fullNameOf(schemaMap map[string]interface{}, namespace string) string {
+	name, _ := schemaMap["name"].(string)
+	if strings.Contains(name, ".") {
+		return name
+	}
+	if s, ok := schemaMap["namespace"].(string); ok {
+		namespace = s
+	}
+	if namespace == "" {
+		return name
+	}
+	return namespace + "." + name
+}
+
+// goIdentifier returns an exported Go identifier for an Avro name, which only
+// contains the characters [A-Za-z0-9_], by removing underscores and
+// capitalizing the letter following each of them.
+func goIdentifier(name string) string {
+	var sb strings.Builder
+	upper := true
+	for _, r := range name {
+		if r == '_' {
+			upper = true
+			continue
+		}
+		if upper && r >= 'a' && r <= 'z' {
+			r -= 'a' - 'A'
+		}
+		upper = false
+		sb.WriteRune(r)
+	}
+	if sb.Len() == 0 || (name[0] >= '0' && name[0] <= '9') {
+		return "X" + sb.String()
+	}
+	return sb.String()
+}
@@ -0,0 +1,83 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+// avroinfer reads JSON values, such as newline delimited JSON, from one or more
+// files, or from standard input when no files are named, and prints the
+// narrowest Avro schema that accepts all of them. Every value can be decoded
+// using a Codec created from the schema by NewCodecForStandardJSONFull.
+//
+//	avroinfer -name com.example.Event events-1.ndjson events-2.ndjson > event.avsc
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/linkedin/goavro/v2"
+)
+
+func usage() {
+	executable, err := os.Executable()
+	if err != nil {
+		executable = os.Args[0]
+	}
+	base := filepath.Base(executable)
+	fmt.Fprintf(os.Stderr, "Usage of %s:\n", base)
+	fmt.Fprintf(os.Stderr, "\t%s [-name full.Name] [-compact] [file1.json [file2.json ...]]\n", base)
+	flag.PrintDefaults()
+	os.Exit(2)
+}
+
+func main() {
+	name := flag.String("name", "Record", "full name of the record inferred from top level JSON objects")
+	compact := flag.Bool("compact", false, "print the schema without indentation")
+	flag.Usage = usage
+	flag.Parse()
+
+	si := goavro.NewSchemaInferrer(*name)
+	if flag.NArg() == 0 {
+		if err := si.Infer(os.Stdin); err != nil {
+			bail(err)
+		}
+	}
+	for _, arg := range flag.Args() {
+		fh, err := os.Open(arg)
+		if err != nil {
+			bail(err)
+		}
+		err = si.Infer(fh)
+		fh.Close()
+		if err != nil {
+			bail(fmt.Errorf("cannot read %s: %s", arg, err))
+		}
+	}
+
+	schema, err := si.Schema()
+	if err != nil {
+		bail(err)
+	}
+	if *compact {
+		fmt.Println(schema)
+		return
+	}
+	var indented bytes.Buffer
+	if err = json.Indent(&indented, []byte(schema), "", "  "); err != nil {
+		bail(err)
+	}
+	fmt.Println(indented.String())
+}
+
+func bail(err error) {
+	fmt.Fprintf(os.Stderr, "%s\n", err)
+	os.Exit(1)
+}
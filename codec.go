@@ -16,6 +16,7 @@ import (
 	"fmt"
 	"math"
 	"strconv"
+	"sync"
 )
 
 var (
@@ -63,6 +64,13 @@ type CodecOption struct {
 	// When false (default), legacy encoding is used for backwards compatibility.
 	// Default: false (legacy encoding for backwards compatibility)
 	EnableDecimalBinarySpecCompliantEncoding bool
+
+	// EnableUUIDByteArray controls the native type values of the uuid logical
+	// type decode to, for both its string and fixed forms. When true, they
+	// decode to [16]byte. When false (default), they decode to their RFC 4122
+	// string form, such as "f81d4fae-7dec-11d0-a765-00a0c91e6bf6". Either type
+	// may be encoded regardless.
+	EnableUUIDByteArray bool
 }
 
 // Codec supports decoding binary and text Avro data to Go native data types,
@@ -74,12 +82,39 @@ type Codec struct {
 	schemaOriginal  string
 	schemaCanonical string
 	typeName        *name
+	aliases         []string // full names of aliases of named types
+
+	// The following fields describe the structure of the schema, and are
+	// retained so data encoded using one schema may be resolved into another.
+	kind        string         // Avro type: a primitive name, "record", "enum", "fixed", "array", "map", or "union"
+	fields      []*recordField // record fields, in schema order
+	symbols     []string       // enum symbols
+	enumDefault string         // enum default symbol, or empty string when none
+	size        uint           // fixed size
+	itemCodec   *Codec         // array items, or map values
+	members     *codecInfo     // union members
+
+	// writer is the Codec whose schema describes the binary data of a Codec
+	// created by NewCodecForResolution, and nil for other Codecs.
+	writer *Codec
 
 	nativeFromTextual func([]byte) (interface{}, []byte, error)
 	binaryFromNative  func([]byte, interface{}) ([]byte, error)
 	nativeFromBinary  func([]byte) (interface{}, []byte, error)
 	textualFromNative func([]byte, interface{}) ([]byte, error)
 
+	// skipBinary returns the bytes that follow a binary encoded datum, without
+	// decoding it.
+	skipBinary func([]byte) ([]byte, error)
+
+	// nativeFromDefault, when not nil, converts the default value of record
+	// fields of the type, as decoded from the schema, into a native value.
+	nativeFromDefault func(interface{}) (interface{}, error)
+
+	// structConverters caches the structConverter for each Go type translated
+	// to and from native values of this Codec.
+	structConverters sync.Map
+
 	Rabin uint64
 }
 
@@ -99,6 +134,7 @@ func DefaultCodecOption() *CodecOption {
 		EnableStringNull:                         true,
 		IgnoreExtraFieldsFromTextual:             false,
 		EnableDecimalBinarySpecCompliantEncoding: false,
+		EnableUUIDByteArray:                      false,
 	}
 }
 
@@ -266,9 +302,19 @@ func NewCodecFrom(schemaSpecification string, cb *codecBuilder) (*Codec, error)
 	if err != nil {
 		return nil, err
 	}
+	if err = c.setSchema(schemaSpecification, schema); err != nil {
+		return nil, err // should not get here because schema was validated above
+	}
+	return c, nil
+}
+
+// setSchema records the schema specification of the Codec, along with its
+// Parsing Canonical Form and fingerprint, given the decoded schema.
+func (c *Codec) setSchema(schemaSpecification string, schema interface{}) error {
+	var err error
 	c.schemaCanonical, err = parsingCanonicalForm(schema, "", make(map[string]string))
 	if err != nil {
-		return nil, err // should not get here because schema was validated above
+		return err
 	}
 
 	c.Rabin = rabin([]byte(c.schemaCanonical))
@@ -276,80 +322,96 @@ func NewCodecFrom(schemaSpecification string, cb *codecBuilder) (*Codec, error)
 	binary.LittleEndian.PutUint64(c.soeHeader[2:], c.Rabin)
 
 	c.schemaOriginal = schemaSpecification
-	return c, nil
+	return nil
 }
 
 func newSymbolTable() map[string]*Codec {
 	return map[string]*Codec{
 		"boolean": {
 			typeName:          &name{"boolean", nullNamespace},
+			kind:              "boolean",
 			schemaOriginal:    "boolean",
 			schemaCanonical:   "boolean",
 			binaryFromNative:  booleanBinaryFromNative,
 			nativeFromBinary:  booleanNativeFromBinary,
+			skipBinary:        booleanSkipBinary,
 			nativeFromTextual: booleanNativeFromTextual,
 			textualFromNative: booleanTextualFromNative,
 		},
 		"bytes": {
 			typeName:          &name{"bytes", nullNamespace},
+			kind:              "bytes",
 			schemaOriginal:    "bytes",
 			schemaCanonical:   "bytes",
 			binaryFromNative:  bytesBinaryFromNative,
 			nativeFromBinary:  bytesNativeFromBinary,
+			skipBinary:        bytesSkipBinary,
 			nativeFromTextual: bytesNativeFromTextual,
 			textualFromNative: bytesTextualFromNative,
 		},
 		"double": {
 			typeName:          &name{"double", nullNamespace},
+			kind:              "double",
 			schemaOriginal:    "double",
 			schemaCanonical:   "double",
 			binaryFromNative:  doubleBinaryFromNative,
 			nativeFromBinary:  doubleNativeFromBinary,
+			skipBinary:        doubleSkipBinary,
 			nativeFromTextual: doubleNativeFromTextual,
 			textualFromNative: doubleTextualFromNative,
 		},
 		"float": {
 			typeName:          &name{"float", nullNamespace},
+			kind:              "float",
 			schemaOriginal:    "float",
 			schemaCanonical:   "float",
 			binaryFromNative:  floatBinaryFromNative,
 			nativeFromBinary:  floatNativeFromBinary,
+			skipBinary:        floatSkipBinary,
 			nativeFromTextual: floatNativeFromTextual,
 			textualFromNative: floatTextualFromNative,
 		},
 		"int": {
 			typeName:          &name{"int", nullNamespace},
+			kind:              "int",
 			schemaOriginal:    "int",
 			schemaCanonical:   "int",
 			binaryFromNative:  intBinaryFromNative,
 			nativeFromBinary:  intNativeFromBinary,
+			skipBinary:        varintSkipBinary,
 			nativeFromTextual: intNativeFromTextual,
 			textualFromNative: intTextualFromNative,
 		},
 		"long": {
 			typeName:          &name{"long", nullNamespace},
+			kind:              "long",
 			schemaOriginal:    "long",
 			schemaCanonical:   "long",
 			binaryFromNative:  longBinaryFromNative,
 			nativeFromBinary:  longNativeFromBinary,
+			skipBinary:        varintSkipBinary,
 			nativeFromTextual: longNativeFromTextual,
 			textualFromNative: longTextualFromNative,
 		},
 		"null": {
 			typeName:          &name{"null", nullNamespace},
+			kind:              "null",
 			schemaOriginal:    "null",
 			schemaCanonical:   "null",
 			binaryFromNative:  nullBinaryFromNative,
 			nativeFromBinary:  nullNativeFromBinary,
+			skipBinary:        nullSkipBinary,
 			nativeFromTextual: nullNativeFromTextual,
 			textualFromNative: nullTextualFromNative,
 		},
 		"string": {
 			typeName:          &name{"string", nullNamespace},
+			kind:              "string",
 			schemaOriginal:    "string",
 			schemaCanonical:   "string",
 			binaryFromNative:  stringBinaryFromNative,
 			nativeFromBinary:  stringNativeFromBinary,
+			skipBinary:        stringSkipBinary,
 			nativeFromTextual: stringNativeFromTextual,
 			textualFromNative: stringTextualFromNative,
 		},
@@ -357,47 +419,57 @@ func newSymbolTable() map[string]*Codec {
 		// no dependence on schema.
 		"long.timestamp-millis": {
 			typeName:          &name{"long.timestamp-millis", nullNamespace},
+			kind:              "long",
 			schemaOriginal:    "long",
 			schemaCanonical:   "long",
 			nativeFromTextual: nativeFromTimeStampMillis(longNativeFromTextual),
 			binaryFromNative:  timeStampMillisFromNative(longBinaryFromNative),
 			nativeFromBinary:  nativeFromTimeStampMillis(longNativeFromBinary),
+			skipBinary:        varintSkipBinary,
 			textualFromNative: timeStampMillisFromNative(longTextualFromNative),
 		},
 		"long.timestamp-micros": {
 			typeName:          &name{"long.timestamp-micros", nullNamespace},
+			kind:              "long",
 			schemaOriginal:    "long",
 			schemaCanonical:   "long",
 			nativeFromTextual: nativeFromTimeStampMicros(longNativeFromTextual),
 			binaryFromNative:  timeStampMicrosFromNative(longBinaryFromNative),
 			nativeFromBinary:  nativeFromTimeStampMicros(longNativeFromBinary),
+			skipBinary:        varintSkipBinary,
 			textualFromNative: timeStampMicrosFromNative(longTextualFromNative),
 		},
 		"int.time-millis": {
 			typeName:          &name{"int.time-millis", nullNamespace},
+			kind:              "int",
 			schemaOriginal:    "int",
 			schemaCanonical:   "int",
 			nativeFromTextual: nativeFromTimeMillis(intNativeFromTextual),
 			binaryFromNative:  timeMillisFromNative(intBinaryFromNative),
 			nativeFromBinary:  nativeFromTimeMillis(intNativeFromBinary),
+			skipBinary:        varintSkipBinary,
 			textualFromNative: timeMillisFromNative(intTextualFromNative),
 		},
 		"long.time-micros": {
 			typeName:          &name{"long.time-micros", nullNamespace},
+			kind:              "long",
 			schemaOriginal:    "long",
 			schemaCanonical:   "long",
 			nativeFromTextual: nativeFromTimeMicros(longNativeFromTextual),
 			binaryFromNative:  timeMicrosFromNative(longBinaryFromNative),
 			nativeFromBinary:  nativeFromTimeMicros(longNativeFromBinary),
+			skipBinary:        varintSkipBinary,
 			textualFromNative: timeMicrosFromNative(longTextualFromNative),
 		},
 		"int.date": {
 			typeName:          &name{"int.date", nullNamespace},
+			kind:              "int",
 			schemaOriginal:    "int",
 			schemaCanonical:   "int",
 			nativeFromTextual: nativeFromDate(intNativeFromTextual),
 			binaryFromNative:  dateFromNative(intBinaryFromNative),
 			nativeFromBinary:  nativeFromDate(intNativeFromBinary),
+			skipBinary:        varintSkipBinary,
 			textualFromNative: dateFromNative(intTextualFromNative),
 		},
 	}
@@ -664,6 +736,18 @@ func (c *Codec) TypeName() name {
 	return *c.typeName
 }
 
+// Aliases returns the full names of the aliases of the named type described by
+// the schema used to create the Codec. It returns nil when the schema does not
+// describe a named type, or when that type has no aliases.
+func (c *Codec) Aliases() []string {
+	if len(c.aliases) == 0 {
+		return nil
+	}
+	aliases := make([]string, len(c.aliases))
+	copy(aliases, c.aliases)
+	return aliases
+}
+
 // convert a schema data structure to a codec, prefixing with specified
 // namespace
 func buildCodec(st map[string]*Codec, enclosingNamespace string, schema interface{}, cb *codecBuilder) (*Codec, error) {
@@ -715,8 +799,8 @@ func buildCodecForTypeDescribedByString(st map[string]*Codec, enclosingNamespace
 	}
 
 	// NOTE: When codec already exists, return it. This includes both primitive and
-	// logicalType codecs added in NewCodec, and user-defined types, added while
-	// building the codec.
+	// logicalType codecs added in NewCodec, and user-defined types, registered
+	// by their full names and aliases while building the codec.
 	if cd, ok := st[searchType]; ok {
 
 		// For "bytes.decimal" types verify that the scale and precision in this schema map match a cached codec before
@@ -760,6 +844,12 @@ func buildCodecForTypeDescribedByString(st map[string]*Codec, enclosingNamespace
 		return makeDecimalFixedCodec(st, enclosingNamespace, schemaMap, cb)
 	case "string.validated-string":
 		return makeValidatedStringCodec(st, enclosingNamespace, schemaMap)
+	case "string.uuid":
+		return makeUUIDStringCodec(st, cb), nil
+	case "fixed.uuid":
+		return makeUUIDFixedCodec(st, enclosingNamespace, schemaMap, cb)
+	case "fixed.duration":
+		return makeDurationFixedCodec(st, enclosingNamespace, schemaMap)
 	default:
 		if isLogicalType {
 			delete(schemaMap, "logicalType")
@@ -776,8 +866,20 @@ func registerNewCodec(st map[string]*Codec, schemaMap map[string]interface{}, en
 	if err != nil {
 		return nil, err
 	}
-	c := &Codec{typeName: n}
+	aliases, err := aliasesFromSchemaMap(n.namespace, schemaMap)
+	if err != nil {
+		return nil, err
+	}
+	c := &Codec{typeName: n, aliases: aliases}
 	st[n.fullName] = c
+	// NOTE: Register the codec using each of its aliases as well, so schemas
+	// may refer to this type by any of its names. An alias never replaces a
+	// type already registered using that name.
+	for _, alias := range aliases {
+		if _, ok := st[alias]; !ok {
+			st[alias] = c
+		}
+	}
 	return c, nil
 }
 
@@ -0,0 +1,264 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package goavro
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sync"
+)
+
+// Codex is a collection of Codecs indexed by the Rabin fingerprints of their
+// schemas, which decodes single-object encoded datums written using any of
+// their schemas. A Codex is safe for concurrent use.
+//
+//	codex := goavro.NewCodex()
+//	for _, schema := range schemas {
+//	    if _, err := codex.AddSchema(schema); err != nil {
+//	        return err
+//	    }
+//	}
+//	datum, _, err := codex.NativeFromSingle(buf)
+type Codex struct {
+	mu       sync.RWMutex
+	codecs   map[uint64]*Codec
+	reader   *Codec
+	resolved map[uint64]lookupResult // writer fingerprint -> Codec resolving into reader, or why it cannot
+
+	// indexes holds the Codecs by their fingerprints by algorithms other than
+	// Rabin, and is only built for an algorithm when first used.
+	indexes map[FingerprintAlgorithm]map[string]*Codec
+}
+
+// NewCodex returns an empty Codex.
+func NewCodex() *Codex {
+	return &Codex{codecs: make(map[uint64]*Codec)}
+}
+
+// Add adds the Codec to the Codex, indexed by its Rabin fingerprint. Adding a
+// Codec with the fingerprint of a Codec already in the Codex has no effect,
+// because both have the same canonical schema.
+func (cx *Codex) Add(codec *Codec) error {
+	if codec.writer != nil {
+		return fmt.Errorf("cannot add Codec created by NewCodecForResolution to Codex")
+	}
+	cx.mu.Lock()
+	defer cx.mu.Unlock()
+	if _, ok := cx.codecs[codec.Rabin]; ok {
+		return nil
+	}
+	cx.codecs[codec.Rabin] = codec
+	for alg, index := range cx.indexes {
+		fingerprint, _ := codec.Fingerprint(alg) // only known algorithms are indexed
+		index[string(fingerprint)] = codec
+	}
+	return nil
+}
+
+// AddSchema creates a Codec for the schema, adds it to the Codex, and returns
+// it.
+func (cx *Codex) AddSchema(schema string) (*Codec, error) {
+	codec, err := NewCodec(schema)
+	if err != nil {
+		return nil, fmt.Errorf("cannot add schema to Codex: %s", err)
+	}
+	if err = cx.Add(codec); err != nil {
+		return nil, err
+	}
+	return codec, nil
+}
+
+// AddDirectory adds the schema in each file with the ".avsc" extension in the
+// directory to the Codex, in lexical order of the file names. Each file ought
+// to hold a complete schema, because a schema may not refer to a named type
+// defined in another file. Sub-directories are not searched.
+func (cx *Codex) AddDirectory(dir string) error {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("cannot add schemas to Codex: %s", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".avsc" {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		schema, err := ioutil.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("cannot add schemas to Codex: %s", err)
+		}
+		if _, err = cx.AddSchema(string(schema)); err != nil {
+			return fmt.Errorf("cannot add schema from %q: %s", path, err)
+		}
+	}
+	return nil
+}
+
+// SetReader sets the Codec of the reader schema into which NativeFromSingle
+// resolves datums written using the schema of any Codec in the Codex, as
+// NewCodecForResolution does. When reader is nil, datums are decoded as they
+// were written.
+func (cx *Codex) SetReader(reader *Codec) error {
+	if reader != nil && reader.writer != nil {
+		return fmt.Errorf("cannot set Codex reader to Codec created by NewCodecForResolution")
+	}
+	cx.mu.Lock()
+	cx.reader = reader
+	cx.resolved = make(map[uint64]lookupResult)
+	cx.mu.Unlock()
+	return nil
+}
+
+// Codec returns the Codec in the Codex whose Rabin fingerprint is the provided
+// fingerprint, or false when there is none.
+func (cx *Codex) Codec(fingerprint uint64) (*Codec, bool) {
+	cx.mu.RLock()
+	codec, ok := cx.codecs[fingerprint]
+	cx.mu.RUnlock()
+	return codec, ok
+}
+
+// CodecByFingerprint returns the Codec in the Codex whose fingerprint by the
+// algorithm is the provided fingerprint, or false when there is none.
+func (cx *Codex) CodecByFingerprint(alg FingerprintAlgorithm, fingerprint []byte) (*Codec, bool) {
+	if alg == FingerprintRabin {
+		if len(fingerprint) != 8 {
+			return nil, false
+		}
+		return cx.Codec(binary.LittleEndian.Uint64(fingerprint))
+	}
+
+	cx.mu.RLock()
+	index, ok := cx.indexes[alg]
+	if ok {
+		codec, ok := index[string(fingerprint)]
+		cx.mu.RUnlock()
+		return codec, ok
+	}
+	cx.mu.RUnlock()
+
+	if alg.Size() == 0 {
+		return nil, false
+	}
+	cx.mu.Lock()
+	defer cx.mu.Unlock()
+	if index, ok = cx.indexes[alg]; !ok {
+		index = make(map[string]*Codec, len(cx.codecs))
+		for _, codec := range cx.codecs {
+			fp, _ := codec.Fingerprint(alg) // alg is known
+			index[string(fp)] = codec
+		}
+		if cx.indexes == nil {
+			cx.indexes = make(map[FingerprintAlgorithm]map[string]*Codec)
+		}
+		cx.indexes[alg] = index
+	}
+	codec, ok := index[string(fingerprint)]
+	return codec, ok
+}
+
+// Len returns the number of Codecs in the Codex.
+func (cx *Codex) Len() int {
+	cx.mu.RLock()
+	defer cx.mu.RUnlock()
+	return len(cx.codecs)
+}
+
+// NativeFromSingle converts Avro data from Single-Object-Encoded format from
+// the provided byte slice to Go native data types, using the Codec in the
+// Codex whose Rabin fingerprint is in the header of the datum. When the Codex
+// has a reader, the datum is resolved into the reader schema. On success, it
+// returns the decoded datum, along with a new byte slice with the decoded bytes
+// consumed, and a nil error value. On error, it returns nil for the datum
+// value, the original byte slice, and the error message. When the Codex has no
+// Codec with the fingerprint, the error is an ErrUnknownFingerprint.
+func (cx *Codex) NativeFromSingle(buf []byte) (interface{}, []byte, error) {
+	fingerprint, newBuf, err := FingerprintFromSOE(buf)
+	if err != nil {
+		return nil, buf, err
+	}
+	codec, err := cx.decoder(fingerprint)
+	if err != nil {
+		return nil, buf, err
+	}
+	value, newBuf, err := codec.nativeFromBinary(newBuf)
+	if err != nil {
+		return nil, buf, err // if error, return original byte slice
+	}
+	return value, newBuf, nil
+}
+
+// LookupCodec returns the Codec that decodes datums written using the schema
+// whose Rabin fingerprint is id, in a header of FramingSOE or of a
+// FingerprintFraming, resolving them into the reader schema when there is one,
+// so a Codex may be the CodecLookup of a FramedDecoder. Because a
+// FramedDecoder caches the Codecs it looks up, and its failures to, a new
+// FramedDecoder ought to be created after calling Add or SetReader.
+//
+//	decoder := goavro.NewFramedDecoder(codex, goavro.FramingSOE, framing)
+func (cx *Codex) LookupCodec(framing Framing, id uint64) (*Codec, error) {
+	if _, ok := framing.(FingerprintFraming); !ok && framing != FramingSOE {
+		return nil, fmt.Errorf("cannot look up schema of %s header in Codex", framing)
+	}
+	return cx.decoder(id)
+}
+
+// decoder returns the Codec that decodes datums written using the schema with
+// the fingerprint, resolving them into the reader schema when there is one.
+func (cx *Codex) decoder(fingerprint uint64) (*Codec, error) {
+	cx.mu.RLock()
+	writer, ok := cx.codecs[fingerprint]
+	reader := cx.reader
+	resolved, isResolved := cx.resolved[fingerprint]
+	cx.mu.RUnlock()
+
+	if !ok {
+		return nil, ErrUnknownFingerprint{ErrWrongCodec(fingerprint)}
+	}
+	if reader == nil || reader.Rabin == fingerprint {
+		return writer, nil
+	}
+	if !isResolved {
+		// NOTE: A schema that cannot be resolved into the reader schema fails
+		// the same way for every datum, until the reader is changed, so the
+		// error is remembered along with the Codecs.
+		codec, err := newResolvingCodec(writer, reader)
+		if err != nil {
+			err = fmt.Errorf("cannot decode schema %d for Codex reader: %s", fingerprint, err)
+		}
+		resolved = lookupResult{codec, err}
+		cx.mu.Lock()
+		// NOTE: Another goroutine may have changed the reader, in which case
+		// the result is returned for this datum, but not remembered.
+		if cx.reader == reader {
+			cx.resolved[fingerprint] = resolved
+		}
+		cx.mu.Unlock()
+	}
+	return resolved.codec, resolved.err
+}
+
+// ErrUnknownFingerprint is returned by Codex.NativeFromSingle when the Codex
+// has no Codec for the Rabin fingerprint in the header of the datum. It wraps
+// ErrWrongCodec, whose value is the fingerprint.
+type ErrUnknownFingerprint struct {
+	ErrWrongCodec
+}
+
+// Fingerprint returns the Rabin fingerprint for which the Codex has no Codec.
+func (e ErrUnknownFingerprint) Fingerprint() uint64 { return uint64(e.ErrWrongCodec) }
+
+// Unwrap returns the wrapped ErrWrongCodec.
+func (e ErrUnknownFingerprint) Unwrap() error { return e.ErrWrongCodec }
+
+func (e ErrUnknownFingerprint) Error() string {
+	return "cannot find Codec in Codex: " + e.ErrWrongCodec.Error()
+}
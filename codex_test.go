@@ -0,0 +1,238 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package goavro
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+const (
+	codexSchemaV1 = `{"type":"record","name":"r","fields":[{"name":"a","type":"int"}]}`
+	codexSchemaV2 = `{"type":"record","name":"r","fields":[{"name":"a","type":"long"},{"name":"b","type":"string","default":"none"}]}`
+)
+
+func ExampleCodex() {
+	codex := NewCodex()
+	for _, primitive := range []string{"int", "string"} {
+		if _, err := codex.AddSchema(`"` + primitive + `"`); err != nil {
+			fmt.Println(err)
+		}
+	}
+
+	for _, buf := range [][]byte{
+		[]byte("\xC3\x01" + "\x8F\x5C\x39\x3F\x1A\xD5\x75\x72" + "\x06"),
+		[]byte("\xC3\x01" + "\xC7\x03\x45\x63\x72\x48\x01\x8F" + "\x0ahello"),
+	} {
+		datum, _, err := codex.NativeFromSingle(buf)
+		if err != nil {
+			fmt.Println(err)
+		}
+		fmt.Println(datum)
+	}
+	// Output:
+	// 3
+	// hello
+}
+
+func TestCodexNativeFromSingle(t *testing.T) {
+	codex := NewCodex()
+	v1, err := codex.AddSchema(codexSchemaV1)
+	ensureError(t, err)
+	v2, err := NewCodec(codexSchemaV2)
+	ensureError(t, err)
+	ensureError(t, codex.Add(v2))
+	ensureError(t, codex.Add(v2)) // adding again has no effect
+	if actual, expected := codex.Len(), 2; actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+	if codec, ok := codex.Codec(v1.Rabin); !ok || codec != v1 {
+		t.Errorf("GOT: %v, %v; WANT: %v, %v", codec, ok, v1, true)
+	}
+
+	buf1, err := v1.SingleFromNative(nil, map[string]interface{}{"a": 1})
+	ensureError(t, err)
+	buf2, err := v2.SingleFromNative(nil, map[string]interface{}{"a": 2, "b": "two"})
+	ensureError(t, err)
+
+	datum, rest, err := codex.NativeFromSingle(buf1)
+	ensureError(t, err)
+	if expected := map[string]interface{}{"a": int32(1)}; !reflect.DeepEqual(datum, expected) {
+		t.Errorf("GOT: %v; WANT: %v", datum, expected)
+	}
+	if len(rest) != 0 {
+		t.Errorf("GOT: %v; WANT: %v", len(rest), 0)
+	}
+	datum, _, err = codex.NativeFromSingle(buf2)
+	ensureError(t, err)
+	if expected := map[string]interface{}{"a": int64(2), "b": "two"}; !reflect.DeepEqual(datum, expected) {
+		t.Errorf("GOT: %v; WANT: %v", datum, expected)
+	}
+
+	// Datums of every writer schema resolve into the reader schema.
+	ensureError(t, codex.SetReader(v2))
+	datum, _, err = codex.NativeFromSingle(buf1)
+	ensureError(t, err)
+	if expected := map[string]interface{}{"a": int64(1), "b": "none"}; !reflect.DeepEqual(datum, expected) {
+		t.Errorf("GOT: %v; WANT: %v", datum, expected)
+	}
+	datum, _, err = codex.NativeFromSingle(buf2)
+	ensureError(t, err)
+	if expected := map[string]interface{}{"a": int64(2), "b": "two"}; !reflect.DeepEqual(datum, expected) {
+		t.Errorf("GOT: %v; WANT: %v", datum, expected)
+	}
+
+	// A writer schema that cannot resolve into the reader schema.
+	ensureError(t, codex.SetReader(v1))
+	_, rest, err = codex.NativeFromSingle(buf2)
+	ensureError(t, err, "cannot decode schema", "for Codex reader", "cannot resolve")
+	if len(rest) != len(buf2) {
+		t.Errorf("GOT: %v; WANT: %v", len(rest), len(buf2))
+	}
+
+	ensureError(t, codex.SetReader(nil))
+	datum, _, err = codex.NativeFromSingle(buf2)
+	ensureError(t, err)
+	if expected := map[string]interface{}{"a": int64(2), "b": "two"}; !reflect.DeepEqual(datum, expected) {
+		t.Errorf("GOT: %v; WANT: %v", datum, expected)
+	}
+}
+
+func TestCodexErrors(t *testing.T) {
+	codex := NewCodex()
+	codec, err := NewCodec(`"string"`)
+	ensureError(t, err)
+	buf, err := codec.SingleFromNative(nil, "hello")
+	ensureError(t, err)
+
+	_, rest, err := codex.NativeFromSingle(buf)
+	ensureError(t, err, "cannot find Codec in Codex: wrong codec: ")
+	unknown, ok := err.(ErrUnknownFingerprint)
+	if !ok {
+		t.Fatalf("GOT: %#v; WANT: %T", err, unknown)
+	}
+	if unknown.Fingerprint() != codec.Rabin {
+		t.Errorf("GOT: %v; WANT: %v", unknown.Fingerprint(), codec.Rabin)
+	}
+	if wrong, ok := unknown.Unwrap().(ErrWrongCodec); !ok || uint64(wrong) != codec.Rabin {
+		t.Errorf("GOT: %#v; WANT: %v", unknown.Unwrap(), ErrWrongCodec(codec.Rabin))
+	}
+	if len(rest) != len(buf) {
+		t.Errorf("GOT: %v; WANT: %v", len(rest), len(buf))
+	}
+
+	_, _, err = codex.NativeFromSingle([]byte{0xC3})
+	if _, ok := err.(ErrNotSingleObjectEncoded); !ok {
+		t.Errorf("GOT: %#v; WANT: %T", err, ErrNotSingleObjectEncoded(""))
+	}
+
+	_, err = codex.AddSchema(`"nope"`)
+	ensureError(t, err, "cannot add schema to Codex")
+
+	resolving, err := NewCodecForResolution(`"int"`, `"long"`, nil)
+	ensureError(t, err)
+	ensureError(t, codex.Add(resolving), "cannot add Codec created by NewCodecForResolution to Codex")
+	ensureError(t, codex.SetReader(resolving), "cannot set Codex reader to Codec created by NewCodecForResolution")
+
+	ensureError(t, codex.Add(codec))
+	_, _, err = codex.NativeFromSingle(buf[:len(buf)-1])
+	ensureError(t, err, "short buffer")
+
+	// A schema that cannot be resolved into the reader schema is not resolved
+	// again for each datum, until the reader is changed.
+	reader, err := NewCodec(`"int"`)
+	ensureError(t, err)
+	ensureError(t, codex.SetReader(reader))
+	for i := 0; i < 2; i++ {
+		_, _, err = codex.NativeFromSingle(buf)
+		ensureError(t, err, "cannot decode schema", "for Codex reader")
+	}
+	if result, ok := codex.resolved[codec.Rabin]; !ok || result.err == nil {
+		t.Errorf("GOT: %v, %v; WANT: cached error", result, ok)
+	}
+	ensureError(t, codex.SetReader(nil))
+	if len(codex.resolved) != 0 {
+		t.Errorf("GOT: %v; WANT: %v", len(codex.resolved), 0)
+	}
+	datum, _, err := codex.NativeFromSingle(buf)
+	ensureError(t, err)
+	if datum != "hello" {
+		t.Errorf("GOT: %v; WANT: %v", datum, "hello")
+	}
+}
+
+func TestCodexAddDirectory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goavro-codex")
+	ensureError(t, err)
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"v1.avsc":    codexSchemaV1,
+		"v2.avsc":    codexSchemaV2,
+		"notes.txt":  "not a schema",
+		"sub/x.avsc": `"int"`,
+	}
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		ensureError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		ensureError(t, ioutil.WriteFile(path, []byte(contents), 0644))
+	}
+
+	codex := NewCodex()
+	ensureError(t, codex.AddDirectory(dir))
+	if actual, expected := codex.Len(), 2; actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+	for _, schema := range []string{codexSchemaV1, codexSchemaV2} {
+		codec, err := NewCodec(schema)
+		ensureError(t, err)
+		if _, ok := codex.Codec(codec.Rabin); !ok {
+			t.Errorf("GOT: %v; WANT: %v", ok, true)
+		}
+	}
+
+	ensureError(t, ioutil.WriteFile(filepath.Join(dir, "bad.avsc"), []byte(`{"type":"nope"}`), 0644))
+	ensureError(t, NewCodex().AddDirectory(dir), "cannot add schema from", "bad.avsc")
+	ensureError(t, NewCodex().AddDirectory(filepath.Join(dir, "missing")), "cannot add schemas to Codex")
+}
+
+func TestCodexConcurrency(t *testing.T) {
+	codex := NewCodex()
+	reader, err := NewCodec(codexSchemaV2)
+	ensureError(t, err)
+	writer, err := NewCodec(codexSchemaV1)
+	ensureError(t, err)
+	buf, err := writer.SingleFromNative(nil, map[string]interface{}{"a": 1})
+	ensureError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				switch (i + j) % 4 {
+				case 0:
+					_ = codex.Add(writer)
+				case 1:
+					_ = codex.SetReader(reader)
+				default:
+					_, _, _ = codex.NativeFromSingle(buf)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
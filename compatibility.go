@@ -0,0 +1,339 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package goavro
+
+import (
+	"fmt"
+	"strings"
+)
+
+// IncompatibilityType identifies the reason data written using one schema
+// cannot be read using another.
+type IncompatibilityType int
+
+const (
+	// FieldMissingDefault means a reader record field is missing from the
+	// writer record, such as after the field was removed from the writer
+	// schema, and the reader field has no default value.
+	FieldMissingDefault IncompatibilityType = iota + 1
+
+	// TypeMismatch means a writer type is neither the same as the reader type,
+	// nor may it be promoted to the reader type.
+	TypeMismatch
+
+	// NameMismatch means a writer record, enum, or fixed has a different name
+	// than the reader type, and the reader type has no alias for it.
+	NameMismatch
+
+	// MissingEnumSymbol means a writer enum has symbols that are not reader
+	// enum symbols, and the reader enum has no default symbol.
+	MissingEnumSymbol
+
+	// FixedSizeMismatch means a writer fixed has a different size than the
+	// reader fixed.
+	FixedSizeMismatch
+
+	// MissingUnionBranch means a writer type, or a member of a writer union,
+	// matches none of the members of a reader union, such as after the member
+	// was removed from the reader union.
+	MissingUnionBranch
+)
+
+func (t IncompatibilityType) String() string {
+	switch t {
+	case FieldMissingDefault:
+		return "field missing default"
+	case TypeMismatch:
+		return "type mismatch"
+	case NameMismatch:
+		return "name mismatch"
+	case MissingEnumSymbol:
+		return "missing enum symbol"
+	case FixedSizeMismatch:
+		return "fixed size mismatch"
+	case MissingUnionBranch:
+		return "missing union branch"
+	}
+	return fmt.Sprintf("IncompatibilityType(%d)", int(t))
+}
+
+// Incompatibility describes one reason data written using a writer schema
+// cannot be read using a reader schema.
+type Incompatibility struct {
+	Type IncompatibilityType
+
+	// Path locates the incompatibility within the reader schema, as the
+	// names of the record fields leading to it, separated and preceded by
+	// slashes, such as "/user/address". Arrays, maps, and unions are passed
+	// through without being named. The path of the top-level type is "/".
+	Path string
+
+	Message string
+
+	// Version is the index of the schema in the history of schemas provided
+	// to a transitive check, and 0 for CheckCompatibility.
+	Version int
+}
+
+func (i Incompatibility) String() string {
+	return i.Path + ": " + i.Type.String() + ": " + i.Message
+}
+
+// CompatibilityReport lists every reason data written using one schema cannot
+// be read using another.
+type CompatibilityReport struct {
+	Incompatibilities []Incompatibility
+}
+
+// Compatible returns true when the report has no incompatibilities.
+func (r *CompatibilityReport) Compatible() bool {
+	return len(r.Incompatibilities) == 0
+}
+
+func (r *CompatibilityReport) String() string {
+	if r.Compatible() {
+		return "compatible"
+	}
+	lines := make([]string, len(r.Incompatibilities))
+	for i, incompatibility := range r.Incompatibilities {
+		lines[i] = incompatibility.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// CheckCompatibility reports whether data written using the writer schema may
+// be read using the reader schema, in accordance with the schema resolution
+// rules of the Avro specification, as followed by NewCodecForResolution. It
+// returns an error only when either schema is invalid.
+//
+// Unlike NewCodecForResolution, which only fails when data actually uses a
+// writer union member the reader cannot read, every such member is reported as
+// an incompatibility.
+//
+//	report, err := goavro.CheckCompatibility(newSchema, oldSchema)
+//	if err != nil {
+//	    return err
+//	}
+//	if !report.Compatible() {
+//	    return fmt.Errorf("new schema cannot read old data:\n%s", report)
+//	}
+func CheckCompatibility(reader, writer string) (*CompatibilityReport, error) {
+	readerCodec, err := NewCodec(reader)
+	if err != nil {
+		return nil, fmt.Errorf("cannot check compatibility of reader schema: %s", err)
+	}
+	writerCodec, err := NewCodec(writer)
+	if err != nil {
+		return nil, fmt.Errorf("cannot check compatibility of writer schema: %s", err)
+	}
+	report := new(CompatibilityReport)
+	checkCompatibility(report, readerCodec, writerCodec, 0)
+	return report, nil
+}
+
+// CheckBackwardTransitive reports whether data written using every one of the
+// historical schemas may be read using the schema.
+func CheckBackwardTransitive(schema string, history []string) (*CompatibilityReport, error) {
+	return checkTransitive(schema, history, true, false)
+}
+
+// CheckForwardTransitive reports whether data written using the schema may be
+// read using every one of the historical schemas.
+func CheckForwardTransitive(schema string, history []string) (*CompatibilityReport, error) {
+	return checkTransitive(schema, history, false, true)
+}
+
+// CheckFullTransitive reports whether data written using the schema may be read
+// using every one of the historical schemas, and whether data written using
+// every one of the historical schemas may be read using the schema.
+func CheckFullTransitive(schema string, history []string) (*CompatibilityReport, error) {
+	return checkTransitive(schema, history, true, true)
+}
+
+func checkTransitive(schema string, history []string, backward, forward bool) (*CompatibilityReport, error) {
+	codec, err := NewCodec(schema)
+	if err != nil {
+		return nil, fmt.Errorf("cannot check compatibility of schema: %s", err)
+	}
+	report := new(CompatibilityReport)
+	for version, previous := range history {
+		previousCodec, err := NewCodec(previous)
+		if err != nil {
+			return nil, fmt.Errorf("cannot check compatibility of historical schema %d: %s", version, err)
+		}
+		if backward {
+			checkCompatibility(report, codec, previousCodec, version)
+		}
+		if forward {
+			checkCompatibility(report, previousCodec, codec, version)
+		}
+	}
+	return report, nil
+}
+
+func checkCompatibility(report *CompatibilityReport, reader, writer *Codec, version int) {
+	cc := &compatibilityChecker{seen: make(map[codecPair]struct{})}
+	cc.check(writer, reader, "/")
+	for _, incompatibility := range cc.incompatibilities {
+		incompatibility.Version = version
+		report.Incompatibilities = append(report.Incompatibilities, incompatibility)
+	}
+}
+
+// compatibilityChecker collects the incompatibilities between a writer schema
+// and a reader schema.
+type compatibilityChecker struct {
+	// NOTE: Checking recursive record schemas would never terminate without
+	// remembering the pairs of types already checked.
+	seen              map[codecPair]struct{}
+	incompatibilities []Incompatibility
+}
+
+func (cc *compatibilityChecker) add(t IncompatibilityType, path, format string, a ...interface{}) {
+	cc.incompatibilities = append(cc.incompatibilities, Incompatibility{Type: t, Path: path, Message: fmt.Sprintf(format, a...)})
+}
+
+// check mirrors resolver.resolve, but collects every incompatibility rather
+// than stopping at the first one.
+func (cc *compatibilityChecker) check(writer, reader *Codec, path string) {
+	key := codecPair{writer, reader}
+	if _, ok := cc.seen[key]; ok {
+		return
+	}
+	cc.seen[key] = struct{}{}
+
+	if writer.kind == "union" {
+		for _, writerMember := range writer.members.codecFromIndex {
+			if reader.kind == "union" {
+				cc.checkReaderUnion(writerMember, reader, path)
+			} else {
+				cc.check(writerMember, reader, path)
+			}
+		}
+		return
+	}
+	if reader.kind == "union" {
+		cc.checkReaderUnion(writer, reader, path)
+		return
+	}
+	if writer.kind != reader.kind {
+		if promotion(writer, reader) == nil {
+			cc.add(TypeMismatch, path, "writer %s cannot be read as reader %s", writer.typeName, reader.typeName)
+		}
+		return
+	}
+
+	switch reader.kind {
+	case "record":
+		if !namesMatch(writer, reader) {
+			cc.add(NameMismatch, path, "writer record %q ought to have the same name as reader record %q", writer.typeName, reader.typeName)
+			return
+		}
+		for _, readerField := range reader.fields {
+			fieldPath := path + readerField.name
+			if path != "/" {
+				fieldPath = path + "/" + readerField.name
+			}
+			writerField := writer.writerFieldForReaderField(readerField)
+			if writerField == nil {
+				if !readerField.hasDefault {
+					cc.add(FieldMissingDefault, fieldPath, "reader record %q field %q ought to have a default value, because writer record %q has no such field", reader.typeName, readerField.name, writer.typeName)
+				}
+				continue
+			}
+			cc.check(writerField.codec, readerField.codec, fieldPath)
+		}
+	case "enum":
+		if !namesMatch(writer, reader) {
+			cc.add(NameMismatch, path, "writer enum %q ought to have the same name as reader enum %q", writer.typeName, reader.typeName)
+			return
+		}
+		if reader.enumDefault != "" {
+			return
+		}
+		var missing []string
+		for _, symbol := range writer.symbols {
+			if !isEnumSymbol(reader.symbols, symbol) {
+				missing = append(missing, symbol)
+			}
+		}
+		if len(missing) > 0 {
+			cc.add(MissingEnumSymbol, path, "writer enum %q symbols ought to be member of reader symbols, or reader enum ought to have a default: %v; %v", writer.typeName, reader.symbols, missing)
+		}
+	case "fixed":
+		if !namesMatch(writer, reader) {
+			cc.add(NameMismatch, path, "writer fixed %q ought to have the same name as reader fixed %q", writer.typeName, reader.typeName)
+			return
+		}
+		if writer.size != reader.size {
+			cc.add(FixedSizeMismatch, path, "writer fixed %q ought to have the same size as reader fixed: %d != %d", writer.typeName, writer.size, reader.size)
+		}
+	case "array", "map":
+		cc.check(writer.itemCodec, reader.itemCodec, path)
+	}
+}
+
+// checkReaderUnion checks a writer, which is not a union, against the reader
+// union members, in the same order as resolver.resolveReaderUnion. When none
+// of them may read the writer, it reports the incompatibilities of the member
+// that is the same type as the writer, or a missing union branch when there is
+// no such member.
+func (cc *compatibilityChecker) checkReaderUnion(writer, reader *Codec, path string) {
+	var closest *compatibilityChecker
+	for _, sameKind := range []bool{true, false} {
+		for _, readerMember := range reader.members.codecFromIndex {
+			if sameKind != (readerMember.kind == writer.kind) {
+				continue
+			}
+			// NOTE: Each member is tried by a separate checker, so the
+			// incompatibilities of members that are not chosen are not
+			// reported.
+			trial := &compatibilityChecker{seen: make(map[codecPair]struct{}, len(cc.seen))}
+			for key := range cc.seen {
+				trial.seen[key] = struct{}{}
+			}
+			trial.check(writer, readerMember, path)
+			if len(trial.incompatibilities) == 0 {
+				cc.seen = trial.seen
+				return
+			}
+			named := readerMember.kind == "record" || readerMember.kind == "enum" || readerMember.kind == "fixed"
+			if closest == nil && sameKind && (!named || namesMatch(writer, readerMember)) {
+				closest = trial
+			}
+		}
+	}
+	if closest != nil {
+		cc.seen = closest.seen
+		cc.incompatibilities = append(cc.incompatibilities, closest.incompatibilities...)
+		return
+	}
+	cc.add(MissingUnionBranch, path, "writer %s ought to match a reader union member: %v", writer.typeName, reader.members.allowedTypes)
+}
+
+// writerFieldForReaderField returns the record field with the name of the
+// reader field, or failing that, the record field whose name is one of the
+// aliases of the reader field. It returns nil when the record has no such
+// field.
+func (c *Codec) writerFieldForReaderField(readerField *recordField) *recordField {
+	for _, field := range c.fields {
+		if field.name == readerField.name {
+			return field
+		}
+	}
+	for _, alias := range readerField.aliases {
+		for _, field := range c.fields {
+			if field.name == alias {
+				return field
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package goavro
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testCompatibility ensures the report lists exactly the expected types of
+// incompatibilities at the expected paths, in order.
+func testCompatibility(t *testing.T, report *CompatibilityReport, err error, expected ...Incompatibility) {
+	t.Helper()
+	ensureError(t, err)
+	var actual []Incompatibility
+	for _, incompatibility := range report.Incompatibilities {
+		incompatibility.Message = "" // messages are checked separately
+		actual = append(actual, incompatibility)
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+	if actual, expected := report.Compatible(), len(expected) == 0; actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+}
+
+func testReportContains(t *testing.T, report *CompatibilityReport, expected string) {
+	t.Helper()
+	if actual := report.String(); !strings.Contains(actual, expected) {
+		t.Errorf("GOT: %v; WANT: %q", actual, expected)
+	}
+}
+
+func TestCheckCompatibilityCompatible(t *testing.T) {
+	schemas := []struct {
+		reader, writer string
+	}{
+		{`"string"`, `"string"`},
+		{`"long"`, `"int"`},
+		{`"double"`, `"float"`},
+		{`"bytes"`, `"string"`},
+		{`["null","string"]`, `"string"`},
+		{`["null","long"]`, `"int"`},
+		{`"string"`, `["string"]`},
+		{`{"type":"array","items":"long"}`, `{"type":"array","items":"int"}`},
+		{`{"type":"map","values":["null","string"]}`, `{"type":"map","values":"string"}`},
+		{`{"type":"fixed","name":"f","size":4}`, `{"type":"fixed","name":"f","size":4}`},
+		{`{"type":"fixed","name":"g","aliases":["f"],"size":4}`, `{"type":"fixed","name":"f","size":4}`},
+		{`{"type":"enum","name":"e","symbols":["A","B","C"]}`, `{"type":"enum","name":"e","symbols":["A","B"]}`},
+		{`{"type":"enum","name":"e","symbols":["A","X"],"default":"X"}`, `{"type":"enum","name":"e","symbols":["A","B"]}`},
+		{`{"type":"record","name":"r","fields":[{"name":"a","type":"int"},{"name":"b","type":"string","default":""}]}`,
+			`{"type":"record","name":"r","fields":[{"name":"a","type":"int"},{"name":"c","type":"string"}]}`},
+		{`{"type":"record","name":"r","fields":[{"name":"b","aliases":["a"],"type":"long"}]}`,
+			`{"type":"record","name":"r","fields":[{"name":"a","type":"int"}]}`},
+		{`{"type":"record","name":"LongList","fields":[{"name":"next","type":["null","LongList"],"default":null}]}`,
+			`{"type":"record","name":"LongList","fields":[{"name":"next","type":["null","LongList"],"default":null}]}`},
+	}
+	for _, schema := range schemas {
+		report, err := CheckCompatibility(schema.reader, schema.writer)
+		testCompatibility(t, report, err)
+		if actual, expected := report.String(), "compatible"; actual != expected {
+			t.Errorf("GOT: %v; WANT: %v", actual, expected)
+		}
+	}
+}
+
+func TestCheckCompatibilityIncompatible(t *testing.T) {
+	report, err := CheckCompatibility(`"int"`, `"long"`)
+	testCompatibility(t, report, err, Incompatibility{Type: TypeMismatch, Path: "/"})
+
+	report, err = CheckCompatibility(`{"type":"fixed","name":"f","size":4}`, `{"type":"fixed","name":"f","size":8}`)
+	testCompatibility(t, report, err, Incompatibility{Type: FixedSizeMismatch, Path: "/"})
+
+	report, err = CheckCompatibility(`{"type":"fixed","name":"g","size":4}`, `{"type":"fixed","name":"f","size":4}`)
+	testCompatibility(t, report, err, Incompatibility{Type: NameMismatch, Path: "/"})
+
+	report, err = CheckCompatibility(`{"type":"enum","name":"e","symbols":["A"]}`, `{"type":"enum","name":"e","symbols":["A","B","C"]}`)
+	testCompatibility(t, report, err, Incompatibility{Type: MissingEnumSymbol, Path: "/"})
+	testReportContains(t, report, "[B C]")
+
+	report, err = CheckCompatibility(`["null","string"]`, `["null","string","int"]`)
+	testCompatibility(t, report, err, Incompatibility{Type: MissingUnionBranch, Path: "/"})
+	testReportContains(t, report, "writer int ought to match a reader union member: [null string]")
+
+	report, err = CheckCompatibility(`"string"`, `["null","string"]`)
+	testCompatibility(t, report, err, Incompatibility{Type: TypeMismatch, Path: "/"})
+}
+
+func TestCheckCompatibilityRecordPaths(t *testing.T) {
+	writer := `{"type":"record","name":"Event","fields":[
+		{"name":"id","type":"long"},
+		{"name":"user","type":["null",{"type":"record","name":"User","fields":[
+			{"name":"name","type":"string"},
+			{"name":"address","type":{"type":"record","name":"Address","fields":[
+				{"name":"city","type":"string"}]}}]}]},
+		{"name":"kind","type":{"type":"enum","name":"Kind","symbols":["A","B"]}},
+		{"name":"tags","type":{"type":"array","items":{"type":"fixed","name":"Tag","size":2}}}]}`
+	reader := `{"type":"record","name":"Event","fields":[
+		{"name":"id","type":"int"},
+		{"name":"user","type":["null",{"type":"record","name":"User","fields":[
+			{"name":"name","type":"string"},
+			{"name":"address","type":{"type":"record","name":"Address","fields":[
+				{"name":"city","type":"string"},
+				{"name":"zip","type":"string"}]}}]}]},
+		{"name":"kind","type":{"type":"enum","name":"Kind","symbols":["A"]}},
+		{"name":"tags","type":{"type":"array","items":{"type":"fixed","name":"Tag","size":3}}},
+		{"name":"extra","type":"string"}]}`
+
+	report, err := CheckCompatibility(reader, writer)
+	testCompatibility(t, report, err,
+		Incompatibility{Type: TypeMismatch, Path: "/id"},
+		// NOTE: The writer record matches no member of the reader union, so
+		// the incompatibilities of the reader record of the same name are
+		// reported.
+		Incompatibility{Type: FieldMissingDefault, Path: "/user/address/zip"},
+		Incompatibility{Type: MissingEnumSymbol, Path: "/kind"},
+		Incompatibility{Type: FixedSizeMismatch, Path: "/tags"},
+		Incompatibility{Type: FieldMissingDefault, Path: "/extra"},
+	)
+	testReportContains(t, report, `/extra: field missing default: reader record "Event" field "extra" ought to have a default value`)
+
+	// A writer record of another name matches no member of the reader union.
+	report, err = CheckCompatibility(
+		`{"type":"record","name":"r","fields":[{"name":"a","type":["null",{"type":"record","name":"s","fields":[]}]}]}`,
+		`{"type":"record","name":"r","fields":[{"name":"a","type":["null",{"type":"record","name":"t","fields":[]}]}]}`)
+	testCompatibility(t, report, err, Incompatibility{Type: MissingUnionBranch, Path: "/a"})
+}
+
+func TestCheckCompatibilityNamespaces(t *testing.T) {
+	// NOTE: Named types are compared by their unqualified names, so moving a
+	// type to another namespace is compatible, and references to named types
+	// resolve within their enclosing namespace.
+	report, err := CheckCompatibility(
+		`{"type":"record","name":"r","namespace":"n1","fields":[{"name":"a","type":{"type":"fixed","name":"f","size":2}},{"name":"b","type":"f"}]}`,
+		`{"type":"record","name":"r","namespace":"n2","fields":[{"name":"a","type":{"type":"fixed","name":"f","size":2}},{"name":"b","type":"n2.f"}]}`)
+	testCompatibility(t, report, err)
+
+	report, err = CheckCompatibility(
+		`{"type":"record","name":"r","namespace":"n1","fields":[{"name":"a","type":{"type":"fixed","name":"f","size":2}},{"name":"b","type":"f"}]}`,
+		`{"type":"record","name":"r","namespace":"n2","fields":[{"name":"a","type":{"type":"fixed","name":"f","size":2}},{"name":"b","type":{"type":"fixed","name":"g","namespace":"n3","size":2}}]}`)
+	testCompatibility(t, report, err, Incompatibility{Type: NameMismatch, Path: "/b"})
+}
+
+func TestCheckCompatibilityInvalidSchema(t *testing.T) {
+	_, err := CheckCompatibility(`"nope"`, `"string"`)
+	ensureError(t, err, "cannot check compatibility of reader schema")
+	_, err = CheckCompatibility(`"string"`, `"nope"`)
+	ensureError(t, err, "cannot check compatibility of writer schema")
+	_, err = CheckBackwardTransitive(`"string"`, []string{`"string"`, `"nope"`})
+	ensureError(t, err, "cannot check compatibility of historical schema 1")
+}
+
+func TestCheckTransitive(t *testing.T) {
+	history := []string{
+		`{"type":"record","name":"r","fields":[{"name":"a","type":"int"}]}`,
+		`{"type":"record","name":"r","fields":[{"name":"a","type":"int"},{"name":"b","type":"int","default":0}]}`,
+	}
+	schema := `{"type":"record","name":"r","fields":[{"name":"b","type":"int"}]}`
+
+	// The schema reads data written using the latest schema, but not data
+	// written using the first one, which lacks field b.
+	report, err := CheckBackwardTransitive(schema, history)
+	testCompatibility(t, report, err, Incompatibility{Type: FieldMissingDefault, Path: "/b", Version: 0})
+
+	// Neither historical schema reads data written using the schema, which
+	// lacks field a.
+	report, err = CheckForwardTransitive(schema, history)
+	testCompatibility(t, report, err,
+		Incompatibility{Type: FieldMissingDefault, Path: "/a", Version: 0},
+		Incompatibility{Type: FieldMissingDefault, Path: "/a", Version: 1},
+	)
+
+	report, err = CheckFullTransitive(schema, history)
+	testCompatibility(t, report, err,
+		Incompatibility{Type: FieldMissingDefault, Path: "/b", Version: 0},
+		Incompatibility{Type: FieldMissingDefault, Path: "/a", Version: 0},
+		Incompatibility{Type: FieldMissingDefault, Path: "/a", Version: 1},
+	)
+}
+
+func TestIncompatibilityTypeString(t *testing.T) {
+	if actual, expected := MissingUnionBranch.String(), "missing union branch"; actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+	if actual, expected := IncompatibilityType(42).String(), "IncompatibilityType(42)"; actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+}
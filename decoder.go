@@ -0,0 +1,229 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package goavro
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// decoderMinRead is the smallest number of bytes the Decoder asks for each
+// time it reads from its input stream.
+const decoderMinRead = 512
+
+// Decoder reads and decodes binary encoded datums from an input stream, such
+// as a socket or a pipe of concatenated datums.
+type Decoder struct {
+	r            io.Reader
+	codec        *Codec
+	buf          []byte // bytes read from r; buf[off:] are not yet decoded
+	off          int
+	rerr         error // error returned by r, after which it is not read again
+	singleObject bool
+	lengthPrefix bool
+}
+
+// NewDecoder returns a Decoder that reads binary encoded datums of the
+// provided Codec from r.
+//
+// The Decoder finds where each datum ends without decoding it, reading more
+// bytes from r only while the datum is incomplete, so datums need not be
+// framed and no buffer size needs to be guessed. It may read bytes beyond the
+// last datum it decodes, which Buffered returns.
+//
+//	codec, err := goavro.NewCodec(`"string"`)
+//	if err != nil {
+//	    fmt.Println(err)
+//	}
+//	decoder := goavro.NewDecoder(bytes.NewReader([]byte{0x02, 'a', 0x02, 'b'}), codec)
+//	for {
+//	    datum, err := decoder.Decode()
+//	    if err == io.EOF {
+//	        break
+//	    }
+//	    if err != nil {
+//	        fmt.Println(err)
+//	        break
+//	    }
+//	    fmt.Println(datum)
+//	}
+func NewDecoder(r io.Reader, codec *Codec) *Decoder {
+	return &Decoder{r: r, codec: codec}
+}
+
+// Buffered returns a reader of the bytes read from the input stream that have
+// not yet been decoded.
+func (d *Decoder) Buffered() io.Reader {
+	return bytes.NewReader(d.buf[d.off:])
+}
+
+// SetSingleObject sets whether each datum is expected to be preceded by a
+// single-object encoding header, as written by an Encoder with the same
+// setting. When the header has the fingerprint of a different schema, Decode
+// returns ErrWrongCodec.
+func (d *Decoder) SetSingleObject(singleObject bool) {
+	d.singleObject = singleObject
+}
+
+// SetLengthPrefix sets whether each datum is expected to be preceded by its
+// size in bytes, as written by an Encoder with the same setting.
+func (d *Decoder) SetLengthPrefix(lengthPrefix bool) {
+	d.lengthPrefix = lengthPrefix
+}
+
+// Decode reads the next binary encoded datum from the input stream and returns
+// its native Go form.
+//
+// Decode returns io.EOF when the input stream ends between datums, and
+// io.ErrUnexpectedEOF when it ends part way through a datum. Because a datum
+// of some schemas, such as "null", is encoded using zero bytes, Decode never
+// returns io.EOF for those schemas, unless a header or prefix is expected.
+func (d *Decoder) Decode() (interface{}, error) {
+	datum, err := d.next()
+	if err != nil {
+		return nil, err
+	}
+	native, buf, err := d.codec.nativeFromBinary(datum)
+	if err != nil {
+		return nil, err
+	}
+	if len(buf) > 0 {
+		return nil, fmt.Errorf("cannot decode binary datum: length prefix exceeds datum size by %d bytes", len(buf))
+	}
+	return native, nil
+}
+
+// next consumes the next datum, along with any prefix and header, and returns
+// a copy of the bytes of the datum.
+func (d *Decoder) next() ([]byte, error) {
+	// NOTE: Nothing is consumed until all of the datum has been read, so that
+	// fill tells whether the input stream ends between datums. The datum
+	// starts at d.buf[d.off+start], and when it has a length prefix, ends
+	// before d.buf[d.off+end].
+	var start, end int
+	if d.lengthPrefix {
+		var err error
+		if start, err = d.size(0, varintSkipBinary); err != nil {
+			return nil, err
+		}
+		size, _, _ := longFromBinary(d.buf[d.off:]) // already skipped, so cannot fail
+		if size < 0 {
+			return nil, fmt.Errorf("cannot decode binary datum: negative length prefix: %d", size)
+		}
+		if size > MaxBlockSize {
+			return nil, fmt.Errorf("cannot decode binary datum when length prefix exceeds MaxBlockSize: %d > %d", size, MaxBlockSize)
+		}
+		end = start + int(size)
+		if err = d.ensure(end); err != nil {
+			return nil, err
+		}
+	}
+	if d.singleObject {
+		if !d.lengthPrefix {
+			if err := d.ensure(soeHeaderLen); err != nil {
+				return nil, err
+			}
+			end = soeHeaderLen
+		}
+		header := d.buf[d.off+start : d.off+end]
+		fingerprint, _, err := FingerprintFromSOE(header)
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(header[:soeHeaderLen], d.codec.soeHeader) {
+			return nil, ErrWrongCodec(fingerprint)
+		}
+		start += soeHeaderLen
+	}
+	if !d.lengthPrefix {
+		size, err := d.size(start, d.codec.skipBinary)
+		if err != nil {
+			return nil, err
+		}
+		end = start + size
+	}
+
+	// NOTE: The native form of bytes and fixed values refers to the bytes of
+	// the datum, so each datum is decoded from its own copy of them, which
+	// the Decoder never overwrites.
+	datum := make([]byte, end-start)
+	copy(datum, d.buf[d.off+start:])
+	d.off += end
+	return datum, nil
+}
+
+// size returns the number of bytes that skipBinary skips from the start of the
+// bytes not yet decoded, after the first start of them, reading more bytes from
+// the input stream for as long as skipBinary runs out of them.
+func (d *Decoder) size(start int, skipBinary func([]byte) ([]byte, error)) (int, error) {
+	for {
+		rest, err := skipBinary(d.buf[d.off+start:])
+		if err == nil {
+			return len(d.buf) - d.off - start - len(rest), nil
+		}
+		if !isShortBuffer(err) {
+			return 0, err
+		}
+		if err = d.fill(); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// ensure reads from the input stream until at least size bytes are not yet
+// decoded.
+func (d *Decoder) ensure(size int) error {
+	for len(d.buf)-d.off < size {
+		if err := d.fill(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isShortBuffer returns true when err is caused by decoding a binary datum that
+// needs more bytes than the buffer has.
+//
+// NOTE: Binary decoding errors do not wrap the errors that cause them, but
+// they do include their messages, so a datum that is cut short is detected by
+// the message of io.ErrShortBuffer.
+func isShortBuffer(err error) bool {
+	return strings.Contains(err.Error(), io.ErrShortBuffer.Error())
+}
+
+// fill reads more bytes from the input stream. It returns io.EOF when the
+// input stream ends before any bytes of a datum are read, and
+// io.ErrUnexpectedEOF when it ends after some are.
+func (d *Decoder) fill() error {
+	if d.rerr == nil {
+		if cap(d.buf)-len(d.buf) < decoderMinRead {
+			// Move the bytes not yet decoded to the start of the buffer,
+			// growing it when they would still leave too little room.
+			buf := d.buf
+			if pending := len(d.buf) - d.off; cap(d.buf)-pending < decoderMinRead {
+				buf = make([]byte, 2*cap(d.buf)+decoderMinRead)
+			}
+			d.buf = buf[:copy(buf[:cap(buf)], d.buf[d.off:])]
+			d.off = 0
+		}
+		var n int
+		n, d.rerr = d.r.Read(d.buf[len(d.buf):cap(d.buf)])
+		d.buf = d.buf[:len(d.buf)+n]
+		if n > 0 || d.rerr == nil {
+			return nil
+		}
+	}
+	if d.rerr == io.EOF && len(d.buf) > d.off {
+		return io.ErrUnexpectedEOF
+	}
+	return d.rerr
+}
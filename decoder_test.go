@@ -0,0 +1,241 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package goavro
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"testing"
+	"testing/iotest"
+)
+
+func ExampleNewDecoder() {
+	codec, err := NewCodec(`"string"`)
+	if err != nil {
+		fmt.Println(err)
+	}
+	decoder := NewDecoder(bytes.NewReader([]byte{0x02, 'a', 0x04, 'b', 'c'}), codec)
+	for {
+		datum, err := decoder.Decode()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			fmt.Println(err)
+			break
+		}
+		fmt.Println(datum)
+	}
+	// Output:
+	// a
+	// bc
+}
+
+const decoderTestSchema = `{
+  "type": "record",
+  "name": "r1",
+  "fields": [
+    {"name": "b", "type": "boolean"},
+    {"name": "i", "type": "int"},
+    {"name": "f", "type": "float"},
+    {"name": "d", "type": "double"},
+    {"name": "s", "type": "string"},
+    {"name": "h", "type": {"type": "fixed", "name": "h4", "size": 4}},
+    {"name": "e", "type": {"type": "enum", "name": "e1", "symbols": ["A", "B"]}},
+    {"name": "a", "type": {"type": "array", "items": "bytes"}},
+    {"name": "m", "type": {"type": "map", "values": ["null", "long"]}},
+    {"name": "n", "type": ["null", "r1"]}
+  ]
+}`
+
+func decoderTestDatums() []interface{} {
+	inner := map[string]interface{}{
+		"b": false, "i": int32(-1), "f": float32(0.5), "d": 2.5, "s": "", "h": []byte("wxyz"), "e": "A",
+		"a": []interface{}{}, "m": map[string]interface{}{}, "n": nil,
+	}
+	outer := map[string]interface{}{
+		"b": true, "i": int32(300), "f": float32(1.5), "d": -3.25, "s": "hello", "h": []byte("abcd"), "e": "B",
+		"a": []interface{}{[]byte("x"), []byte("yz")},
+		"m": map[string]interface{}{"k": Union("long", int64(1)<<40)},
+		"n": Union("r1", inner),
+	}
+	return []interface{}{outer, inner}
+}
+
+func TestDecoderRoundTrip(t *testing.T) {
+	codec, err := NewCodec(decoderTestSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	datums := decoderTestDatums()
+	var stream []byte
+	for _, datum := range datums {
+		if stream, err = codec.BinaryFromNative(stream, datum); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// NOTE: A reader that is not also an io.ByteReader, and that returns one
+	// byte per Read, exercises the slowest path.
+	for _, r := range []io.Reader{bytes.NewReader(stream), iotest.OneByteReader(bytes.NewBuffer(stream))} {
+		decoder := NewDecoder(r, codec)
+		for i, expected := range datums {
+			datum, err := decoder.Decode()
+			if err != nil {
+				t.Fatalf("datum %d: %s", i+1, err)
+			}
+			// NOTE: Decoded unions are compared with encoded unions.
+			buf, err := codec.BinaryFromNative(nil, datum)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, _ := codec.BinaryFromNative(nil, expected)
+			if !bytes.Equal(buf, want) {
+				t.Errorf("datum %d: GOT: %v; WANT: %v", i+1, datum, expected)
+			}
+		}
+		if _, err = decoder.Decode(); err != io.EOF {
+			t.Errorf("GOT: %v; WANT: %v", err, io.EOF)
+		}
+	}
+}
+
+func TestDecoderBuffered(t *testing.T) {
+	codec, err := NewCodec(`{"type":"array","items":"long"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// two blocks, the second with a negative count followed by its size
+	decoder := NewDecoder(bytes.NewReader([]byte{0x02, 0x06, 0x03, 0x04, 0x08, 0x0a, 0x00, 0xff, 0xfe}), codec)
+	datum, err := decoder.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []interface{}{int64(3), int64(4), int64(5)}; !reflect.DeepEqual(datum, expected) {
+		t.Errorf("GOT: %v; WANT: %v", datum, expected)
+	}
+	rest, err := ioutil.ReadAll(decoder.Buffered())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []byte{0xff, 0xfe}; !bytes.Equal(rest, expected) {
+		t.Errorf("GOT: %v; WANT: %v", rest, expected)
+	}
+}
+
+func TestDecoderLargeDatums(t *testing.T) {
+	codec, err := NewCodec(`"bytes"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stream []byte
+	var datums [][]byte
+	for _, size := range []int{decoderMinRead - 3, 3 * decoderMinRead, 0, 10 * decoderMinRead} {
+		datum := bytes.Repeat([]byte{byte(size)}, size)
+		datums = append(datums, datum)
+		if stream, err = codec.BinaryFromNative(stream, datum); err != nil {
+			t.Fatal(err)
+		}
+	}
+	decoder := NewDecoder(bytes.NewReader(stream), codec)
+	var decoded []interface{}
+	for range datums {
+		datum, err := decoder.Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded = append(decoded, datum)
+	}
+	// NOTE: Values decoded earlier are not overwritten by later reads.
+	for i, datum := range datums {
+		if !bytes.Equal(decoded[i].([]byte), datum) {
+			t.Errorf("datum %d: GOT: %d bytes; WANT: %d bytes", i+1, len(decoded[i].([]byte)), len(datum))
+		}
+	}
+	if _, err = decoder.Decode(); err != io.EOF {
+		t.Errorf("GOT: %v; WANT: %v", err, io.EOF)
+	}
+}
+
+func TestDecoderUnexpectedEOF(t *testing.T) {
+	codec, err := NewCodec(decoderTestSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf, err := codec.BinaryFromNative(nil, decoderTestDatums()[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = NewDecoder(bytes.NewReader(nil), codec).Decode(); err != io.EOF {
+		t.Errorf("GOT: %v; WANT: %v", err, io.EOF)
+	}
+	for i := 1; i < len(buf); i++ {
+		_, err = NewDecoder(bytes.NewReader(buf[:i]), codec).Decode()
+		if err != io.ErrUnexpectedEOF {
+			t.Errorf("%d of %d bytes: GOT: %v; WANT: %v", i, len(buf), err, io.ErrUnexpectedEOF)
+		}
+		_, err = NewDecoder(iotest.OneByteReader(bytes.NewReader(buf[:i])), codec).Decode()
+		if err != io.ErrUnexpectedEOF {
+			t.Errorf("%d of %d bytes: GOT: %v; WANT: %v", i, len(buf), err, io.ErrUnexpectedEOF)
+		}
+	}
+}
+
+func TestDecoderErrors(t *testing.T) {
+	codec, err := NewCodec(`["null","long"]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = NewDecoder(bytes.NewReader([]byte{0x04}), codec).Decode()
+	ensureError(t, err, "index ought to be between 0 and 1")
+
+	_, err = NewDecoder(bytes.NewReader(bytes.Repeat([]byte{0xff}, 11)), codec).Decode()
+	ensureError(t, err, "value overflows long")
+
+	codec, err = NewCodec(`"string"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = NewDecoder(bytes.NewReader([]byte{0x01}), codec).Decode()
+	ensureError(t, err, "negative size")
+
+	codec, err = NewCodec(`"boolean"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = NewDecoder(bytes.NewReader([]byte{0x02}), codec).Decode()
+	ensureError(t, err, "cannot decode binary boolean")
+}
+
+func TestDecoderResolution(t *testing.T) {
+	codec, err := NewCodecForResolution(
+		`{"type":"record","name":"r1","fields":[{"name":"a","type":"int"},{"name":"b","type":"string"}]}`,
+		`{"type":"record","name":"r1","fields":[{"name":"b","type":"string"},{"name":"c","type":"long","default":13}]}`,
+		nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoder := NewDecoder(bytes.NewReader([]byte{0x02, 0x06, 'f', 'o', 'o', 0x04, 0x02, 'x'}), codec)
+	for _, expected := range []interface{}{
+		map[string]interface{}{"b": "foo", "c": int64(13)},
+		map[string]interface{}{"b": "x", "c": int64(13)},
+	} {
+		datum, err := decoder.Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(datum, expected) {
+			t.Errorf("GOT: %v; WANT: %v", datum, expected)
+		}
+	}
+}
@@ -0,0 +1,488 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package goavro
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// SchemaChangeType identifies the kind of a difference between two schemas.
+type SchemaChangeType int
+
+const (
+	// FieldAdded means a record field of the new schema is not a field of the
+	// old record.
+	FieldAdded SchemaChangeType = iota + 1
+
+	// FieldRemoved means a record field of the old schema is not a field of
+	// the new record.
+	FieldRemoved
+
+	// FieldRenamed means a record field of the new schema has a different
+	// name than a field of the old record, which is one of its aliases.
+	FieldRenamed
+
+	// TypeChanged means a type of the new schema differs from the old type,
+	// such as after a field was changed from "int" to "long", or made
+	// optional, or after a named type was renamed.
+	TypeChanged
+
+	// DefaultChanged means the default value of a record field, or the
+	// default symbol of an enum, was added, removed, or changed.
+	DefaultChanged
+
+	// SymbolAdded means a symbol of an enum of the new schema is not a symbol
+	// of the old enum.
+	SymbolAdded
+
+	// SymbolRemoved means a symbol of an enum of the old schema is not a
+	// symbol of the new enum.
+	SymbolRemoved
+
+	// DocChanged means the documentation of a named type or of a record field
+	// was added, removed, or changed.
+	DocChanged
+
+	// NamespaceChanged means a named type of the new schema has the same name
+	// as the old type, but in a different namespace.
+	NamespaceChanged
+)
+
+func (t SchemaChangeType) String() string {
+	switch t {
+	case FieldAdded:
+		return "field added"
+	case FieldRemoved:
+		return "field removed"
+	case FieldRenamed:
+		return "field renamed"
+	case TypeChanged:
+		return "type changed"
+	case DefaultChanged:
+		return "default changed"
+	case SymbolAdded:
+		return "symbol added"
+	case SymbolRemoved:
+		return "symbol removed"
+	case DocChanged:
+		return "doc changed"
+	case NamespaceChanged:
+		return "namespace changed"
+	}
+	return fmt.Sprintf("SchemaChangeType(%d)", int(t))
+}
+
+// MarshalText returns the same text as String, so a SchemaChangeType is
+// marshaled to JSON as a string.
+func (t SchemaChangeType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// SchemaChange describes one difference between an old schema and a new
+// schema.
+type SchemaChange struct {
+	Type SchemaChangeType `json:"type"`
+
+	// Path locates the change within the new schema, or within the old schema
+	// for a removed field, as the names of the record fields leading to it,
+	// separated and preceded by slashes, such as "/user/address". Arrays,
+	// maps, and unions are passed through without being named. The path of
+	// the top-level type is "/".
+	Path string `json:"path"`
+
+	// Old and New describe the changed part of the old and the new schema,
+	// such as the Parsing Canonical Form of a changed type, the JSON text of a
+	// changed default value, the name of a renamed field, or a changed doc.
+	// Old is empty for additions and New is empty for removals.
+	Old string `json:"old,omitempty"`
+	New string `json:"new,omitempty"`
+
+	Message string `json:"message"`
+}
+
+func (c SchemaChange) String() string {
+	return c.Path + ": " + c.Type.String() + ": " + c.Message
+}
+
+// DiffSchemas returns the semantic differences between the old schema, a, and
+// the new schema, b, rather than the differences between their JSON texts. It
+// returns an error only when either schema is invalid.
+//
+// Types are compared by their Parsing Canonical Form, extended with their
+// logical types, so schemas that differ only in formatting, in the order of
+// their attributes, or in whether names are written in full, have no
+// differences. Unlike the Parsing Canonical Form, documentation and default
+// values are compared. A field whose aliases include the name of an old field
+// that is not in the new record is reported as renamed. A named member of a
+// union is compared with the old member of the same full name, or failing
+// that, of the same unqualified name or one of its aliases, so a member moved
+// to another namespace is reported as such. A named type that occurs more than
+// once in a schema is compared where it first occurs.
+//
+//	changes, err := goavro.DiffSchemas(oldSchema, newSchema)
+//	if err != nil {
+//	    return err
+//	}
+//	for _, change := range changes {
+//	    fmt.Println(change)
+//	}
+func DiffSchemas(a, b string) ([]SchemaChange, error) {
+	oldTree, err := diffSchemaTree(a)
+	if err != nil {
+		return nil, fmt.Errorf("cannot diff old schema: %s", err)
+	}
+	newTree, err := diffSchemaTree(b)
+	if err != nil {
+		return nil, fmt.Errorf("cannot diff new schema: %s", err)
+	}
+	d := &schemaDiffer{seen: make(map[schemaPair]struct{})}
+	d.diff(oldTree, newTree, "/")
+	return d.changes, nil
+}
+
+// diffSchemaTree returns the tree of the schema, after ensuring it is valid.
+func diffSchemaTree(schema string) (Schema, error) {
+	codec, err := NewCodec(schema)
+	if err != nil {
+		return nil, err
+	}
+	return codec.SchemaTree()
+}
+
+// schemaPair is an old and a new node already compared.
+type schemaPair struct {
+	old, new Schema
+}
+
+// schemaDiffer collects the differences between an old and a new schema tree.
+type schemaDiffer struct {
+	// NOTE: Comparing recursive schemas would never terminate without
+	// remembering the pairs of nodes already compared.
+	seen    map[schemaPair]struct{}
+	changes []SchemaChange
+}
+
+func (d *schemaDiffer) add(t SchemaChangeType, path, old, new, format string, a ...interface{}) {
+	d.changes = append(d.changes, SchemaChange{Type: t, Path: path, Old: old, New: new, Message: fmt.Sprintf(format, a...)})
+}
+
+func (d *schemaDiffer) diff(old, new Schema, path string) {
+	key := schemaPair{old, new}
+	if _, ok := d.seen[key]; ok {
+		return
+	}
+	d.seen[key] = struct{}{}
+
+	if old.Type() != new.Type() {
+		d.typeChanged(old, new, path)
+		return
+	}
+
+	switch o := old.(type) {
+	case *PrimitiveSchema:
+		if !logicalTypesEqual(o.LogicalType, new.(*PrimitiveSchema).LogicalType) {
+			d.typeChanged(old, new, path)
+		}
+	case *RecordSchema:
+		n := new.(*RecordSchema)
+		d.diffNames("record", o.Name, n.Name, path)
+		d.diffDocs(fmt.Sprintf("record %q", n.Name), o.Doc, n.Doc, path)
+		d.diffFields(o, n, path)
+	case *EnumSchema:
+		n := new.(*EnumSchema)
+		d.diffNames("enum", o.Name, n.Name, path)
+		d.diffDocs(fmt.Sprintf("enum %q", n.Name), o.Doc, n.Doc, path)
+		for _, symbol := range o.Symbols {
+			if !isEnumSymbol(n.Symbols, symbol) {
+				d.add(SymbolRemoved, path, symbol, "", "enum %q symbol %q removed", n.Name, symbol)
+			}
+		}
+		for _, symbol := range n.Symbols {
+			if !isEnumSymbol(o.Symbols, symbol) {
+				d.add(SymbolAdded, path, "", symbol, "enum %q symbol %q added", n.Name, symbol)
+			}
+		}
+		// NOTE: The order of the symbols matters, because their indexes are
+		// encoded.
+		if !reflect.DeepEqual(commonSymbols(o.Symbols, n.Symbols), commonSymbols(n.Symbols, o.Symbols)) {
+			d.typeChanged(old, new, path)
+		}
+		if o.Default != n.Default {
+			d.add(DefaultChanged, path, o.Default, n.Default, "enum %q default changed from %s to %s", n.Name, describeDefault(o.Default, o.Default != ""), describeDefault(n.Default, n.Default != ""))
+		}
+	case *FixedSchema:
+		n := new.(*FixedSchema)
+		d.diffNames("fixed", o.Name, n.Name, path)
+		d.diffDocs(fmt.Sprintf("fixed %q", n.Name), o.Doc, n.Doc, path)
+		if o.Size != n.Size || !logicalTypesEqual(o.LogicalType, n.LogicalType) {
+			d.typeChanged(old, new, path)
+		}
+	case *ArraySchema:
+		d.diff(o.Items, new.(*ArraySchema).Items, path)
+	case *MapSchema:
+		d.diff(o.Values, new.(*MapSchema).Values, path)
+	case *UnionSchema:
+		d.diffUnions(o, new.(*UnionSchema), path)
+	}
+}
+
+func (d *schemaDiffer) typeChanged(old, new Schema, path string) {
+	oldText, newText := describeType(old), describeType(new)
+	d.add(TypeChanged, path, oldText, newText, "type changed from %s to %s", oldText, newText)
+}
+
+// diffNames reports a named type whose namespace or name changed.
+func (d *schemaDiffer) diffNames(typeName, old, new, path string) {
+	if old == new {
+		return
+	}
+	oldNamespace, oldName := splitFullName(old)
+	newNamespace, newName := splitFullName(new)
+	if oldName == newName {
+		d.add(NamespaceChanged, path, oldNamespace, newNamespace, "%s %q moved from namespace %q to %q", typeName, oldName, oldNamespace, newNamespace)
+		return
+	}
+	d.add(TypeChanged, path, old, new, "%s %q renamed to %q", typeName, old, new)
+}
+
+func (d *schemaDiffer) diffDocs(what, old, new, path string) {
+	if old != new {
+		d.add(DocChanged, path, old, new, "%s doc changed", what)
+	}
+}
+
+// diffFields compares the fields of the new record with the old fields of the
+// same name, or failing that, with the remaining old field whose name is one of
+// their aliases.
+func (d *schemaDiffer) diffFields(old, new *RecordSchema, path string) {
+	oldFields := make(map[string]*Field, len(old.Fields))
+	for _, field := range old.Fields {
+		oldFields[field.Name] = field
+	}
+	matched := make(map[string]*Field, len(new.Fields)) // old fields by new names
+	for _, field := range new.Fields {
+		if oldField, ok := oldFields[field.Name]; ok {
+			matched[field.Name] = oldField
+			delete(oldFields, field.Name)
+		}
+	}
+	for _, field := range new.Fields {
+		if _, ok := matched[field.Name]; ok {
+			continue
+		}
+		for _, alias := range field.Aliases {
+			if oldField, ok := oldFields[alias]; ok {
+				matched[field.Name] = oldField
+				delete(oldFields, alias)
+				break
+			}
+		}
+	}
+
+	for _, field := range new.Fields {
+		fieldPath := diffFieldPath(path, field.Name)
+		oldField, ok := matched[field.Name]
+		if !ok {
+			d.add(FieldAdded, fieldPath, "", field.Name, "record %q field %q added", new.Name, field.Name)
+			continue
+		}
+		if oldField.Name != field.Name {
+			d.add(FieldRenamed, fieldPath, oldField.Name, field.Name, "record %q field %q renamed to %q", new.Name, oldField.Name, field.Name)
+		}
+		d.diffDocs(fmt.Sprintf("record %q field %q", new.Name, field.Name), oldField.Doc, field.Doc, fieldPath)
+		if oldField.HasDefault != field.HasDefault || !reflect.DeepEqual(oldField.Default, field.Default) {
+			oldDefault, newDefault := describeDefault(oldField.Default, oldField.HasDefault), describeDefault(field.Default, field.HasDefault)
+			d.add(DefaultChanged, fieldPath, defaultText(oldField), defaultText(field), "record %q field %q default changed from %s to %s", new.Name, field.Name, oldDefault, newDefault)
+		}
+		d.diff(oldField.Type, field.Type, fieldPath)
+	}
+	for _, field := range old.Fields {
+		if _, ok := oldFields[field.Name]; ok {
+			d.add(FieldRemoved, diffFieldPath(path, field.Name), field.Name, "", "record %q field %q removed", new.Name, field.Name)
+		}
+	}
+}
+
+// diffUnions reports a union whose members, or their order, changed, and
+// compares the matching members.
+func (d *schemaDiffer) diffUnions(old, new *UnionSchema, path string) {
+	matches := unionMemberMatches(old.Members, new.Members)
+	// NOTE: The order of the members matters, because their indexes are
+	// encoded.
+	same := len(old.Members) == len(new.Members)
+	for i := 0; same && i < len(new.Members); i++ {
+		same = matches[i] == i
+	}
+	if !same {
+		d.typeChanged(old, new, path)
+	}
+	for i, member := range new.Members {
+		if matches[i] > -1 {
+			d.diff(old.Members[matches[i]], member, path)
+		}
+	}
+}
+
+// unionMemberMatches returns the index of the old member that matches each new
+// member, or -1 when none does. Members of the same name match. Failing that, a
+// new named type matches a remaining old named type of the same type and
+// unqualified name, or whose full name is one of its aliases, so a type moved
+// to another namespace, or renamed, is compared with its old type.
+func unionMemberMatches(old, new []Schema) []int {
+	matches := make([]int, len(new))
+	matched := make([]bool, len(old))
+	for i, member := range new {
+		matches[i] = -1
+		for j, oldMember := range old {
+			if !matched[j] && unionMemberName(oldMember) == unionMemberName(member) {
+				matches[i], matched[j] = j, true
+				break
+			}
+		}
+	}
+	for i, member := range new {
+		name, aliases, ok := namedSchemaNames(member)
+		if matches[i] > -1 || !ok {
+			continue
+		}
+		_, shortName := splitFullName(name)
+		for j, oldMember := range old {
+			oldName, _, ok := namedSchemaNames(oldMember)
+			if matched[j] || !ok || oldMember.Type() != member.Type() {
+				continue
+			}
+			_, oldShortName := splitFullName(oldName)
+			isMatch := oldShortName == shortName
+			for _, alias := range aliases {
+				isMatch = isMatch || alias == oldName
+			}
+			if isMatch {
+				matches[i], matched[j] = j, true
+				break
+			}
+		}
+	}
+	return matches
+}
+
+// namedSchemaNames returns the full name and aliases of a named type, or false
+// when the schema is not of a named type.
+func namedSchemaNames(schema Schema) (string, []string, bool) {
+	switch s := schema.(type) {
+	case *RecordSchema:
+		return s.Name, s.Aliases, true
+	case *EnumSchema:
+		return s.Name, s.Aliases, true
+	case *FixedSchema:
+		return s.Name, s.Aliases, true
+	}
+	return "", nil, false
+}
+
+// unionMemberName returns the name by which a union member is distinguished
+// from the other members: its full name for named types, and its type
+// otherwise.
+func unionMemberName(member Schema) string {
+	if name, _, ok := namedSchemaNames(member); ok {
+		return name
+	}
+	return member.Type()
+}
+
+// commonSymbols returns the symbols that are also other symbols, in order.
+func commonSymbols(symbols, other []string) []string {
+	var common []string
+	for _, symbol := range symbols {
+		if isEnumSymbol(other, symbol) {
+			common = append(common, symbol)
+		}
+	}
+	return common
+}
+
+func logicalTypesEqual(a, b *LogicalType) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// describeType returns the Parsing Canonical Form of the type, or for a
+// primitive or fixed type with a logical type, which the Parsing Canonical Form
+// omits, its JSON text without documentation or custom properties.
+func describeType(schema Schema) string {
+	switch s := schema.(type) {
+	case *PrimitiveSchema:
+		if s.LogicalType != nil {
+			schema = &PrimitiveSchema{Primitive: s.Primitive, LogicalType: s.LogicalType}
+			buf, _ := marshalSchema(schema) // tree of a valid schema
+			return string(buf)
+		}
+	case *FixedSchema:
+		if s.LogicalType != nil {
+			schema = &FixedSchema{Name: s.Name, Size: s.Size, LogicalType: s.LogicalType}
+			buf, _ := marshalSchema(schema) // tree of a valid schema
+			return string(buf)
+		}
+	}
+	buf, err := marshalSchema(schema)
+	if err != nil {
+		return "" // should not get here because tree is of a valid schema
+	}
+	var decoded interface{}
+	if err = json.Unmarshal(buf, &decoded); err != nil {
+		return ""
+	}
+	canonical, err := parsingCanonicalForm(decoded, "", make(map[string]string))
+	if err != nil {
+		return ""
+	}
+	return canonical
+}
+
+// defaultText returns the JSON text of the default value of the field, or the
+// empty string when it has none.
+func defaultText(field *Field) string {
+	if !field.HasDefault {
+		return ""
+	}
+	buf, err := json.Marshal(field.Default)
+	if err != nil {
+		return fmt.Sprintf("%v", field.Default)
+	}
+	return string(buf)
+}
+
+// describeDefault returns the JSON text of a default value for messages, or
+// "none" when there is no default value.
+func describeDefault(value interface{}, ok bool) string {
+	if !ok {
+		return "none"
+	}
+	return defaultText(&Field{Default: value, HasDefault: true})
+}
+
+// splitFullName returns the namespace and the name of a full name.
+func splitFullName(fullName string) (string, string) {
+	if index := strings.LastIndexByte(fullName, '.'); index > -1 {
+		return fullName[:index], fullName[index+1:]
+	}
+	return "", fullName
+}
+
+func diffFieldPath(path, name string) string {
+	if path == "/" {
+		return path + name
+	}
+	return path + "/" + name
+}
@@ -0,0 +1,150 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package goavro
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// testDiff ensures the schemas differ by exactly the expected changes, in
+// order, ignoring their messages.
+func testDiff(t *testing.T, a, b string, expected ...SchemaChange) {
+	t.Helper()
+	changes, err := DiffSchemas(a, b)
+	ensureError(t, err)
+	var actual []SchemaChange
+	for _, change := range changes {
+		change.Message = "" // messages are checked separately
+		actual = append(actual, change)
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+}
+
+func TestDiffSchemasFormattingOnly(t *testing.T) {
+	testDiff(t,
+		`{"type":"record","name":"com.example.User","fields":[{"name":"id","type":"long"},{"name":"address","type":{"type":"record","name":"Address","fields":[{"name":"city","type":"string"}]}},{"name":"work","type":["null","Address"],"default":null}]}`,
+		`{
+		  "namespace": "com.example",
+		  "name": "User",
+		  "fields": [
+		    {"type": {"type": "long"}, "name": "id"},
+		    {"name": "address", "type": {"name": "com.example.Address", "type": "record", "fields": [{"name": "city", "type": "string"}]}},
+		    {"name": "work", "default": null, "type": ["null", "com.example.Address"]}
+		  ],
+		  "type": "record"
+		}`)
+}
+
+func TestDiffSchemasFields(t *testing.T) {
+	testDiff(t,
+		`{"type":"record","name":"r","fields":[{"name":"a","type":"int"},{"name":"b","type":"string"},{"name":"c","type":"string","doc":"old"},{"name":"d","type":"int","default":1}]}`,
+		`{"type":"record","name":"r","fields":[{"name":"a","type":"long"},{"name":"bee","aliases":["b"],"type":"string"},{"name":"d","type":"int","default":2},{"name":"e","type":"int","default":0}]}`,
+		SchemaChange{Type: TypeChanged, Path: "/a", Old: `"int"`, New: `"long"`},
+		SchemaChange{Type: FieldRenamed, Path: "/bee", Old: "b", New: "bee"},
+		SchemaChange{Type: DefaultChanged, Path: "/d", Old: "1", New: "2"},
+		SchemaChange{Type: FieldAdded, Path: "/e", New: "e"},
+		SchemaChange{Type: FieldRemoved, Path: "/c", Old: "c"},
+	)
+}
+
+func TestDiffSchemasNested(t *testing.T) {
+	testDiff(t,
+		`{"type":"record","name":"com.a.User","doc":"A user.","fields":[{"name":"address","type":{"type":"record","name":"Address","fields":[{"name":"zip","type":"int"}]}},{"name":"roles","type":{"type":"array","items":{"type":"enum","name":"Role","symbols":["ADMIN","GUEST"]}}}]}`,
+		`{"type":"record","name":"com.b.User","fields":[{"name":"address","type":{"type":"record","name":"Address","fields":[{"name":"zip","type":["null","int"],"default":null}]}},{"name":"roles","type":{"type":"array","items":{"type":"enum","name":"Role","symbols":["ADMIN","MEMBER"],"default":"MEMBER"}}}]}`,
+		SchemaChange{Type: NamespaceChanged, Path: "/", Old: "com.a", New: "com.b"},
+		SchemaChange{Type: DocChanged, Path: "/", Old: "A user."},
+		SchemaChange{Type: NamespaceChanged, Path: "/address", Old: "com.a", New: "com.b"},
+		SchemaChange{Type: DefaultChanged, Path: "/address/zip", New: "null"},
+		SchemaChange{Type: TypeChanged, Path: "/address/zip", Old: `"int"`, New: `["null","int"]`},
+		SchemaChange{Type: NamespaceChanged, Path: "/roles", Old: "com.a", New: "com.b"},
+		SchemaChange{Type: SymbolRemoved, Path: "/roles", Old: "GUEST"},
+		SchemaChange{Type: SymbolAdded, Path: "/roles", New: "MEMBER"},
+		SchemaChange{Type: DefaultChanged, Path: "/roles", New: "MEMBER"},
+	)
+}
+
+func TestDiffSchemasUnionMembersMoved(t *testing.T) {
+	testDiff(t,
+		`{"type":"record","name":"com.a.User","fields":[{"name":"address","type":["null",{"type":"record","name":"Address","fields":[{"name":"zip","type":"int"}]}],"default":null}]}`,
+		`{"type":"record","name":"com.a.User","fields":[{"name":"address","type":["null",{"type":"record","name":"com.b.Address","fields":[{"name":"zip","type":"long"}]}],"default":null}]}`,
+		SchemaChange{Type: NamespaceChanged, Path: "/address", Old: "com.a", New: "com.b"},
+		SchemaChange{Type: TypeChanged, Path: "/address/zip", Old: `"int"`, New: `"long"`},
+	)
+	testDiff(t,
+		`["null",{"type":"enum","name":"Color","symbols":["RED"]}]`,
+		`["null",{"type":"enum","name":"Colour","aliases":["Color"],"symbols":["RED","BLUE"]}]`,
+		SchemaChange{Type: TypeChanged, Path: "/", Old: "Color", New: "Colour"},
+		SchemaChange{Type: SymbolAdded, Path: "/", New: "BLUE"},
+	)
+	// A moved member whose index changed also changes the union.
+	testDiff(t,
+		`["null",{"type":"fixed","name":"a.F","size":4}]`,
+		`[{"type":"fixed","name":"b.F","size":4},"null"]`,
+		SchemaChange{Type: TypeChanged, Path: "/", Old: `["null",{"name":"a.F","type":"fixed","size":4}]`, New: `[{"name":"b.F","type":"fixed","size":4},"null"]`},
+		SchemaChange{Type: NamespaceChanged, Path: "/", Old: "a", New: "b"},
+	)
+}
+
+func TestDiffSchemasTypes(t *testing.T) {
+	cases := []struct {
+		a, b     string
+		expected []SchemaChange
+	}{
+		{`"int"`, `"int"`, nil},
+		{`"int"`, `{"type":"int","logicalType":"date"}`, []SchemaChange{{Type: TypeChanged, Path: "/", Old: `"int"`, New: `{"type":"int","logicalType":"date"}`}}},
+		{`{"type":"fixed","name":"f","size":4}`, `{"type":"fixed","name":"f","size":8}`, []SchemaChange{{Type: TypeChanged, Path: "/", Old: `{"name":"f","type":"fixed","size":4}`, New: `{"name":"f","type":"fixed","size":8}`}}},
+		{`{"type":"fixed","name":"f","size":4}`, `{"type":"fixed","name":"g","size":4}`, []SchemaChange{{Type: TypeChanged, Path: "/", Old: "f", New: "g"}}},
+		{`{"type":"map","values":"int"}`, `{"type":"map","values":"long"}`, []SchemaChange{{Type: TypeChanged, Path: "/", Old: `"int"`, New: `"long"`}}},
+		{`["null","int"]`, `["int","null"]`, []SchemaChange{{Type: TypeChanged, Path: "/", Old: `["null","int"]`, New: `["int","null"]`}}},
+		{`["null",{"type":"array","items":"int"}]`, `["null",{"type":"array","items":"long"}]`, []SchemaChange{{Type: TypeChanged, Path: "/", Old: `"int"`, New: `"long"`}}},
+		{`{"type":"enum","name":"e","symbols":["A","B"]}`, `{"type":"enum","name":"e","symbols":["B","A"]}`, []SchemaChange{{Type: TypeChanged, Path: "/", Old: `{"name":"e","type":"enum","symbols":["A","B"]}`, New: `{"name":"e","type":"enum","symbols":["B","A"]}`}}},
+		{`"string"`, `{"type":"array","items":"string"}`, []SchemaChange{{Type: TypeChanged, Path: "/", Old: `"string"`, New: `{"type":"array","items":"string"}`}}},
+	}
+	for _, c := range cases {
+		testDiff(t, c.a, c.b, c.expected...)
+	}
+}
+
+func TestDiffSchemasRecursive(t *testing.T) {
+	testDiff(t,
+		`{"type":"record","name":"LongList","fields":[{"name":"value","type":"long"},{"name":"next","type":["null","LongList"]}]}`,
+		`{"type":"record","name":"LongList","fields":[{"name":"value","type":"long","doc":"The value."},{"name":"next","type":["null","LongList"]}]}`,
+		SchemaChange{Type: DocChanged, Path: "/value", New: "The value."},
+	)
+}
+
+func TestDiffSchemasMessages(t *testing.T) {
+	changes, err := DiffSchemas(
+		`{"type":"record","name":"r","fields":[{"name":"a","type":"int","default":1}]}`,
+		`{"type":"record","name":"r","fields":[{"name":"a","type":"int"}]}`)
+	ensureError(t, err)
+	if actual, expected := len(changes), 1; actual != expected {
+		t.Fatalf("GOT: %v; WANT: %v", actual, expected)
+	}
+	if actual, expected := changes[0].String(), `/a: default changed: record "r" field "a" default changed from 1 to none`; actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+	buf, err := json.Marshal(changes[0])
+	ensureError(t, err)
+	if actual, expected := string(buf), `{"type":"default changed","path":"/a","old":"1","message":"record \"r\" field \"a\" default changed from 1 to none"}`; actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+}
+
+func TestDiffSchemasInvalid(t *testing.T) {
+	_, err := DiffSchemas(`"int"`, `"integer"`)
+	ensureError(t, err, "cannot diff new schema")
+	_, err = DiffSchemas(`{`, `"int"`)
+	ensureError(t, err, "cannot diff old schema")
+}
@@ -0,0 +1,105 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package goavro
+
+import (
+	"io"
+)
+
+// Encoder encodes datums and writes their binary encoding to an output stream.
+type Encoder struct {
+	w            io.Writer
+	codec        *Codec
+	buf          []byte // reused for every datum
+	singleObject bool
+	lengthPrefix bool
+}
+
+// NewEncoder returns an Encoder that writes binary encoded datums of the
+// provided Codec to w.
+//
+// Each datum is encoded into a buffer that the Encoder reuses for every datum,
+// and is then written to w using as few calls to its Write method as w allows.
+//
+//	codec, err := goavro.NewCodec(`"string"`)
+//	if err != nil {
+//	    fmt.Println(err)
+//	}
+//	encoder := goavro.NewEncoder(os.Stdout, codec)
+//	encoder.SetLengthPrefix(true)
+//	for _, datum := range []string{"a", "bc"} {
+//	    if err = encoder.Encode(datum); err != nil {
+//	        fmt.Println(err)
+//	    }
+//	}
+func NewEncoder(w io.Writer, codec *Codec) *Encoder {
+	return &Encoder{w: w, codec: codec}
+}
+
+// SetSingleObject sets whether each datum is preceded by a single-object
+// encoding header, which holds the Rabin fingerprint of the Codec's schema, as
+// written by SingleFromNative.
+func (e *Encoder) SetSingleObject(singleObject bool) {
+	e.singleObject = singleObject
+}
+
+// SetLengthPrefix sets whether each datum, including its single-object encoding
+// header when enabled, is preceded by its size in bytes. The size is encoded
+// as an Avro long, so a length prefixed datum is encoded the same as Avro
+// bytes.
+func (e *Encoder) SetLengthPrefix(lengthPrefix bool) {
+	e.lengthPrefix = lengthPrefix
+}
+
+// Encode writes the binary encoding of the datum to the output stream. When
+// the datum cannot be encoded, nothing is written.
+func (e *Encoder) Encode(datum interface{}) error {
+	// NOTE: Room is left at the start of the buffer for the length prefix,
+	// whose size is not known until after the datum is encoded, so the prefix
+	// and datum may be written together.
+	start := 0
+	if e.lengthPrefix {
+		start = maxVarintLength
+	}
+	var room [maxVarintLength]byte
+	buf := append(e.buf[:0], room[:start]...)
+	if e.singleObject {
+		buf = append(buf, e.codec.soeHeader...)
+	}
+	buf, err := e.codec.binaryFromNative(buf, datum)
+	if err != nil {
+		return err
+	}
+	e.buf = buf
+
+	if e.lengthPrefix {
+		var prefix [maxVarintLength]byte
+		encoded, _ := longBinaryFromNative(prefix[:0], len(buf)-start)
+		start -= len(encoded)
+		copy(buf[start:], encoded)
+	}
+	return writeFull(e.w, buf[start:])
+}
+
+// writeFull writes all of buf to w, calling Write again when it writes fewer
+// bytes than provided without returning an error.
+func writeFull(w io.Writer, buf []byte) error {
+	for len(buf) > 0 {
+		n, err := w.Write(buf)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return io.ErrShortWrite
+		}
+		buf = buf[n:]
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package goavro
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func ExampleNewEncoder() {
+	codec, err := NewCodec(`"string"`)
+	if err != nil {
+		fmt.Println(err)
+	}
+	var stream bytes.Buffer
+	encoder := NewEncoder(&stream, codec)
+	encoder.SetLengthPrefix(true)
+	for _, datum := range []string{"a", "bc"} {
+		if err = encoder.Encode(datum); err != nil {
+			fmt.Println(err)
+		}
+	}
+	fmt.Printf("%#v\n", stream.Bytes())
+	// Output: []byte{0x4, 0x2, 0x61, 0x6, 0x4, 0x62, 0x63}
+}
+
+func TestEncoderFraming(t *testing.T) {
+	codec, err := NewCodec(decoderTestSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	datums := decoderTestDatums()
+
+	for _, c := range []struct{ singleObject, lengthPrefix bool }{{false, false}, {true, false}, {false, true}, {true, true}} {
+		var stream, expected bytes.Buffer
+		encoder := NewEncoder(&stream, codec)
+		encoder.SetSingleObject(c.singleObject)
+		encoder.SetLengthPrefix(c.lengthPrefix)
+		for _, datum := range datums {
+			if err = encoder.Encode(datum); err != nil {
+				t.Fatal(err)
+			}
+
+			var buf []byte
+			if c.singleObject {
+				buf, err = codec.SingleFromNative(nil, datum)
+			} else {
+				buf, err = codec.BinaryFromNative(nil, datum)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.lengthPrefix {
+				expected.Write(mustBinaryFromNative(t, `"bytes"`, buf))
+			} else {
+				expected.Write(buf)
+			}
+		}
+		if !bytes.Equal(stream.Bytes(), expected.Bytes()) {
+			t.Errorf("%+v: GOT: %#v; WANT: %#v", c, stream.Bytes(), expected.Bytes())
+		}
+
+		decoder := NewDecoder(&stream, codec)
+		decoder.SetSingleObject(c.singleObject)
+		decoder.SetLengthPrefix(c.lengthPrefix)
+		for range datums {
+			if _, err = decoder.Decode(); err != nil {
+				t.Fatalf("%+v: %s", c, err)
+			}
+		}
+		if _, err = decoder.Decode(); err != io.EOF {
+			t.Errorf("%+v: GOT: %v; WANT: %v", c, err, io.EOF)
+		}
+	}
+}
+
+func mustBinaryFromNative(t *testing.T, schema string, datum interface{}) []byte {
+	t.Helper()
+	codec, err := NewCodec(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf, err := codec.BinaryFromNative(nil, datum)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf
+}
+
+// partialWriter writes at most one byte per call, without returning an error.
+type partialWriter struct {
+	bytes.Buffer
+	calls int
+}
+
+func (w *partialWriter) Write(p []byte) (int, error) {
+	w.calls++
+	if len(p) > 1 {
+		p = p[:1]
+	}
+	return w.Buffer.Write(p)
+}
+
+// stuckWriter never writes any bytes, and never returns an error.
+type stuckWriter struct{}
+
+func (stuckWriter) Write(p []byte) (int, error) { return 0, nil }
+
+// failingWriter returns an error for every write.
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) { return 0, errors.New("broken pipe") }
+
+func TestEncoderShortWrites(t *testing.T) {
+	codec, err := NewCodec(`"string"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := new(partialWriter)
+	encoder := NewEncoder(w, codec)
+	encoder.SetLengthPrefix(true)
+	if err = encoder.Encode("abc"); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []byte{0x08, 0x06, 'a', 'b', 'c'}; !bytes.Equal(w.Bytes(), expected) {
+		t.Errorf("GOT: %#v; WANT: %#v", w.Bytes(), expected)
+	}
+	if w.calls != 5 {
+		t.Errorf("GOT: %d; WANT: %d calls", w.calls, 5)
+	}
+
+	ensureError(t, NewEncoder(stuckWriter{}, codec).Encode("abc"), io.ErrShortWrite.Error())
+	ensureError(t, NewEncoder(ShortWriter(new(bytes.Buffer), 2), codec).Encode("abc"), io.ErrShortWrite.Error())
+	ensureError(t, NewEncoder(failingWriter{}, codec).Encode("abc"), "broken pipe")
+}
+
+func TestEncoderReusesBuffer(t *testing.T) {
+	codec, err := NewCodec(`"long"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stream bytes.Buffer
+	encoder := NewEncoder(&stream, codec)
+	if err = encoder.Encode(int64(1)); err != nil {
+		t.Fatal(err)
+	}
+	allocs := testing.AllocsPerRun(100, func() {
+		stream.Reset()
+		if err := encoder.Encode(int64(1)); err != nil {
+			t.Fatal(err)
+		}
+	})
+	// NOTE: Boxing the datum in an interface{} may allocate.
+	if allocs > 1 {
+		t.Errorf("GOT: %v; WANT: at most 1 allocation per datum", allocs)
+	}
+}
+
+func TestEncoderErrors(t *testing.T) {
+	codec, err := NewCodec(`"long"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stream bytes.Buffer
+	encoder := NewEncoder(&stream, codec)
+	encoder.SetLengthPrefix(true)
+	ensureError(t, encoder.Encode("not a long"), "expected: Go numeric")
+	if stream.Len() != 0 {
+		t.Errorf("GOT: %#v; WANT: nothing written", stream.Bytes())
+	}
+
+	// a decoder expecting a different schema rejects the single-object header
+	other, err := NewCodec(`"string"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoder = NewEncoder(&stream, codec)
+	encoder.SetSingleObject(true)
+	if err = encoder.Encode(int64(1)); err != nil {
+		t.Fatal(err)
+	}
+	decoder := NewDecoder(&stream, other)
+	decoder.SetSingleObject(true)
+	_, err = decoder.Decode()
+	if _, ok := err.(ErrWrongCodec); !ok {
+		t.Errorf("GOT: %v; WANT: %T", err, ErrWrongCodec(0))
+	}
+
+	// a length prefix that covers more than the datum
+	decoder = NewDecoder(bytes.NewReader([]byte{0x04, 0x02, 0x02}), codec)
+	decoder.SetLengthPrefix(true)
+	_, err = decoder.Decode()
+	ensureError(t, err, "length prefix exceeds datum size by 1 bytes")
+}
@@ -43,6 +43,31 @@ func makeEnumCodec(st map[string]*Codec, enclosingNamespace string, schemaMap ma
 		symbols[i] = symbol
 	}
 
+	// enum type may have a default symbol, used when resolving a writer symbol
+	// that is not among its symbols
+	var defaultSymbol string
+	if d, ok := schemaMap["default"]; ok {
+		defaultSymbol, ok = d.(string)
+		if !ok {
+			return nil, fmt.Errorf("Enum %q default ought to be a string; received: %T", c.typeName, d)
+		}
+		if !isEnumSymbol(symbols, defaultSymbol) {
+			return nil, fmt.Errorf("Enum %q default ought to be member of symbols: %v; %q", c.typeName, symbols, defaultSymbol)
+		}
+	}
+
+	c.kind = "enum"
+	c.symbols = symbols
+	c.enumDefault = defaultSymbol
+
+	c.skipBinary = func(buf []byte) ([]byte, error) {
+		buf, err := varintSkipBinary(buf)
+		if err != nil {
+			return nil, fmt.Errorf("cannot decode binary enum %q index: %s", c.typeName, err)
+		}
+		return buf, nil
+	}
+
 	c.nativeFromBinary = func(buf []byte) (interface{}, []byte, error) {
 		var value interface{}
 		var err error
@@ -81,10 +106,8 @@ func makeEnumCodec(st map[string]*Codec, enclosingNamespace string, schemaMap ma
 			return nil, nil, fmt.Errorf("cannot decode textual enum: expected key: %s", err)
 		}
 		someString := value.(string)
-		for _, symbol := range symbols {
-			if symbol == someString {
-				return someString, buf, nil
-			}
+		if isEnumSymbol(symbols, someString) {
+			return someString, buf, nil
 		}
 		return nil, nil, fmt.Errorf("cannot decode textual enum %q: value ought to be member of symbols: %v; %q", c.typeName, symbols, someString)
 	}
@@ -103,3 +126,13 @@ func makeEnumCodec(st map[string]*Codec, enclosingNamespace string, schemaMap ma
 
 	return c, nil
 }
+
+// isEnumSymbol returns true when symbol is one of symbols.
+func isEnumSymbol(symbols []string, symbol string) bool {
+	for _, s := range symbols {
+		if s == symbol {
+			return true
+		}
+	}
+	return false
+}
@@ -62,6 +62,16 @@ func TestEnumTextCodec(t *testing.T) {
 	testTextDecodeFail(t, `{"type":"enum","name":"e1","symbols":["alpha","bravo"]}`, []byte(`"charlie"`), `cannot decode textual enum "e1": value ought to be member of symbols`)
 }
 
+func TestEnumDefault(t *testing.T) {
+	testSchemaValid(t, `{"type":"enum","name":"e1","symbols":["alpha","bravo"],"default":"bravo"}`)
+	testSchemaInvalid(t, `{"type":"enum","name":"e1","symbols":["alpha","bravo"],"default":13}`, `Enum "e1" default ought to be a string`)
+	testSchemaInvalid(t, `{"type":"enum","name":"e1","symbols":["alpha","bravo"],"default":"charlie"}`, `Enum "e1" default ought to be member of symbols`)
+
+	// The default symbol is used only when resolving a writer schema.
+	testTextDecodeFail(t, `{"type":"enum","name":"e1","symbols":["alpha","bravo"],"default":"bravo"}`, []byte(`"charlie"`), `cannot decode textual enum "e1": value ought to be member of symbols`)
+	testBinaryDecodeFail(t, `{"type":"enum","name":"e1","symbols":["alpha","bravo"],"default":"bravo"}`, []byte("\x04"), `cannot decode binary enum "e1": index ought to be between 0 and 1`)
+}
+
 func TestGH233(t *testing.T) {
 	// here's the fail case
 	// testTextCodecPass(t, `{"type":"record","name":"FooBar","namespace":"com.foo.bar","fields":[{"name":"event","type":["null",{"type":"enum","name":"FooBarEvent","symbols":["CREATED","UPDATED"]}]}]}`, map[string]interface{}{"event": Union("FooBarEvent", "CREATED")}, []byte(`{"event":{"FooBarEvent":"CREATED"}}`))
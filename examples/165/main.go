@@ -63,6 +63,18 @@ func main() {
 	}
 	_ = binary
 
+	// Rather than building the map by hand, the same bytes may be encoded from
+	// a Go struct, whose exported fields are mapped to the record fields by
+	// name, or by the name in their `avro` struct tags.
+	type loginEvent struct {
+		Username string `avro:"Username"`
+	}
+	fromStruct, err := codec.BinaryFromStruct(nil, loginEvent{Username: "superman"})
+	if err != nil {
+		panic(err)
+	}
+	_ = fromStruct
+
 	// Next, let's try encoding the same item using Single-Object Encoding,
 	// another format that is useful when sending a bunch of objects into a
 	// Kafka stream.  Note this method prefixes the binary bytes with a schema
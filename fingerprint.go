@@ -0,0 +1,191 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package goavro
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// FingerprintAlgorithm is an algorithm the Avro specification defines to
+// fingerprint the Parsing Canonical Form of a schema.
+type FingerprintAlgorithm int
+
+const (
+	// FingerprintRabin is the 64-bit CRC-64-AVRO Rabin fingerprint, which is
+	// also the Rabin field of a Codec. Its bytes are little-endian, as in
+	// single-object encoding.
+	FingerprintRabin FingerprintAlgorithm = iota
+
+	// FingerprintMD5 is the 128-bit MD5 fingerprint.
+	FingerprintMD5
+
+	// FingerprintSHA256 is the 256-bit SHA-256 fingerprint.
+	FingerprintSHA256
+)
+
+// String returns the name of the algorithm, as known by the Java
+// SchemaNormalization class.
+func (alg FingerprintAlgorithm) String() string {
+	switch alg {
+	case FingerprintRabin:
+		return "CRC-64-AVRO"
+	case FingerprintMD5:
+		return "MD5"
+	case FingerprintSHA256:
+		return "SHA-256"
+	}
+	return "FingerprintAlgorithm(" + strconv.Itoa(int(alg)) + ")"
+}
+
+// Size returns the number of bytes of a fingerprint by the algorithm, or 0 for
+// an unknown algorithm.
+func (alg FingerprintAlgorithm) Size() int {
+	switch alg {
+	case FingerprintRabin:
+		return 8
+	case FingerprintMD5:
+		return md5.Size
+	case FingerprintSHA256:
+		return sha256.Size
+	}
+	return 0
+}
+
+// fingerprint returns the fingerprint of the canonical schema by the
+// algorithm.
+func (alg FingerprintAlgorithm) fingerprint(canonical string) ([]byte, error) {
+	switch alg {
+	case FingerprintRabin:
+		fingerprint := make([]byte, 8)
+		binary.LittleEndian.PutUint64(fingerprint, rabin([]byte(canonical)))
+		return fingerprint, nil
+	case FingerprintMD5:
+		fingerprint := md5.Sum([]byte(canonical))
+		return fingerprint[:], nil
+	case FingerprintSHA256:
+		fingerprint := sha256.Sum256([]byte(canonical))
+		return fingerprint[:], nil
+	}
+	return nil, fmt.Errorf("cannot fingerprint schema: unknown algorithm: %s", alg)
+}
+
+// Fingerprint returns the fingerprint of the Parsing Canonical Form of the
+// schema of the Codec by the algorithm. For a Codec created by
+// NewCodecForResolution, it is the fingerprint of the writer schema, which
+// the Codec uses for binary encoding.
+//
+//	codec, err := goavro.NewCodec(`"int"`)
+//	if err != nil {
+//	    fmt.Println(err)
+//	}
+//	fingerprint, err := codec.Fingerprint(goavro.FingerprintSHA256)
+//	if err != nil {
+//	    fmt.Println(err)
+//	}
+//	fmt.Printf("%x\n", fingerprint)
+//	// Output: 3f2b87a9fe7cc9b13835598c3981cd45e3e355309e5090aa0933d7becb6fba45
+func (c *Codec) Fingerprint(alg FingerprintAlgorithm) ([]byte, error) {
+	if c.writer != nil {
+		return c.writer.Fingerprint(alg)
+	}
+	return alg.fingerprint(c.schemaCanonical)
+}
+
+// FingerprintSchema returns the fingerprint of the Parsing Canonical Form of
+// the schema by the algorithm.
+func FingerprintSchema(schema string, alg FingerprintAlgorithm) ([]byte, error) {
+	codec, err := NewCodec(schema)
+	if err != nil {
+		return nil, fmt.Errorf("cannot fingerprint schema: %s", err)
+	}
+	return codec.Fingerprint(alg)
+}
+
+// FingerprintFraming is a Framing like single-object encoding, whose header is
+// the Prefix followed by the fingerprint of the schema by the Algorithm, such
+// as for systems that key schemas by their MD5 or SHA-256 fingerprints. As for
+// FramingSOE, the ID of a schema is its Rabin fingerprint, and the Codex maps
+// between it and the fingerprint by the Algorithm, so it ought to hold the
+// Codecs of the schemas, except for FingerprintRabin. The Codex is also a
+// CodecLookup that finds the Codec of the datum after such a header.
+//
+//	framing := goavro.FingerprintFraming{Prefix: "\xC3\x01", Algorithm: goavro.FingerprintSHA256, Codex: codex}
+//	buf, err := codec.FramedFromNative(nil, framing, codec.Rabin, datum)
+//	if err != nil {
+//	    return err
+//	}
+//	datum, _, err = goavro.NewFramedDecoder(codex, framing).NativeFromFramed(buf)
+type FingerprintFraming struct {
+	Prefix    string
+	Algorithm FingerprintAlgorithm
+	Codex     *Codex
+}
+
+// String returns the name of the Framing.
+func (f FingerprintFraming) String() string {
+	return f.Algorithm.String() + " fingerprint"
+}
+
+// AppendHeader appends the header identifying the schema whose Rabin
+// fingerprint is id to buf, and returns the new byte slice.
+func (f FingerprintFraming) AppendHeader(buf []byte, id uint64) ([]byte, error) {
+	if f.Algorithm == FingerprintRabin {
+		var fingerprint [8]byte
+		binary.LittleEndian.PutUint64(fingerprint[:], id)
+		return append(append(buf, f.Prefix...), fingerprint[:]...), nil
+	}
+	if f.Codex == nil {
+		return buf, fmt.Errorf("cannot append %s header without Codex", f)
+	}
+	codec, ok := f.Codex.Codec(id)
+	if !ok {
+		return buf, fmt.Errorf("cannot append %s header: %s", f, ErrUnknownFingerprint{ErrWrongCodec(id)})
+	}
+	fingerprint, err := codec.Fingerprint(f.Algorithm)
+	if err != nil {
+		return buf, fmt.Errorf("cannot append %s header: %s", f, err)
+	}
+	return append(append(buf, f.Prefix...), fingerprint...), nil
+}
+
+// ParseHeader returns the Rabin fingerprint of the schema identified by the
+// header at the beginning of buf, along with a new byte slice with the header
+// consumed.
+func (f FingerprintFraming) ParseHeader(buf []byte) (uint64, []byte, error) {
+	size := f.Algorithm.Size()
+	if size == 0 {
+		return 0, buf, fmt.Errorf("cannot parse fingerprint header: unknown algorithm: %s", f.Algorithm)
+	}
+	headerLen := len(f.Prefix) + size
+	if len(buf) < headerLen {
+		return 0, buf, fmt.Errorf("cannot parse %s header: %s", f, io.ErrShortBuffer)
+	}
+	if !bytes.Equal(buf[:len(f.Prefix)], []byte(f.Prefix)) {
+		return 0, buf, fmt.Errorf("cannot parse %s header: unknown prefix: %#x", f, buf[:len(f.Prefix)])
+	}
+	fingerprint := buf[len(f.Prefix):headerLen]
+	if f.Algorithm == FingerprintRabin {
+		return binary.LittleEndian.Uint64(fingerprint), buf[headerLen:], nil
+	}
+	if f.Codex == nil {
+		return 0, buf, fmt.Errorf("cannot parse %s header without Codex", f)
+	}
+	codec, ok := f.Codex.CodecByFingerprint(f.Algorithm, fingerprint)
+	if !ok {
+		return 0, buf, fmt.Errorf("cannot find Codec in Codex: unknown %s: %x", f, fingerprint)
+	}
+	return codec.Rabin, buf[headerLen:], nil
+}
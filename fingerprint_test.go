@@ -0,0 +1,212 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package goavro
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func ExampleCodec_Fingerprint() {
+	codec, err := NewCodec(`"int"`)
+	if err != nil {
+		fmt.Println(err)
+	}
+	for _, alg := range []FingerprintAlgorithm{FingerprintRabin, FingerprintMD5, FingerprintSHA256} {
+		fingerprint, err := codec.Fingerprint(alg)
+		if err != nil {
+			fmt.Println(err)
+		}
+		fmt.Printf("%s: %x\n", alg, fingerprint)
+	}
+	// Output:
+	// CRC-64-AVRO: 8f5c393f1ad57572
+	// MD5: ef524ea1b91e73173d938ade36c1db32
+	// SHA-256: 3f2b87a9fe7cc9b13835598c3981cd45e3e355309e5090aa0933d7becb6fba45
+}
+
+func TestFingerprintSchema(t *testing.T) {
+	// The schema is fingerprinted in its Parsing Canonical Form, without the
+	// doc and with fields in canonical order.
+	schema := `{"type":"record","doc":"ignored","name":"r","fields":[{"type":"int","name":"a"}]}`
+	cases := []struct {
+		alg      FingerprintAlgorithm
+		expected string
+	}{
+		{FingerprintMD5, "a9bcb49a84b2ad5d1423110460bed789"},
+		{FingerprintSHA256, "8cdccb7cad6a7a8157c883ef691acbf20c28da634ad750fe34c1a439b78741f6"},
+	}
+	for _, c := range cases {
+		fingerprint, err := FingerprintSchema(schema, c.alg)
+		ensureError(t, err)
+		if actual := hex.EncodeToString(fingerprint); actual != c.expected {
+			t.Errorf("%s: GOT: %v; WANT: %v", c.alg, actual, c.expected)
+		}
+		if len(fingerprint) != c.alg.Size() {
+			t.Errorf("%s: GOT: %v; WANT: %v", c.alg, len(fingerprint), c.alg.Size())
+		}
+	}
+
+	// The Rabin fingerprint has the bytes of the Rabin field of the Codec.
+	codec, err := NewCodec(schema)
+	ensureError(t, err)
+	fingerprint, err := FingerprintSchema(schema, FingerprintRabin)
+	ensureError(t, err)
+	if expected := codec.soeHeader[soeMagicPrefix:]; !bytes.Equal(fingerprint, expected) {
+		t.Errorf("GOT: %x; WANT: %x", fingerprint, expected)
+	}
+
+	// A resolving Codec has the fingerprint of the writer schema.
+	resolving, err := NewCodecForResolution(schema, `{"type":"record","name":"r","fields":[]}`, nil)
+	ensureError(t, err)
+	fingerprint, err = resolving.Fingerprint(FingerprintMD5)
+	ensureError(t, err)
+	if actual, expected := hex.EncodeToString(fingerprint), cases[0].expected; actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+
+	_, err = FingerprintSchema(`"nope"`, FingerprintMD5)
+	ensureError(t, err, "cannot fingerprint schema")
+	_, err = codec.Fingerprint(FingerprintAlgorithm(7))
+	ensureError(t, err, "cannot fingerprint schema: unknown algorithm: FingerprintAlgorithm(7)")
+	if size := FingerprintAlgorithm(7).Size(); size != 0 {
+		t.Errorf("GOT: %v; WANT: %v", size, 0)
+	}
+}
+
+func TestFingerprintFraming(t *testing.T) {
+	codex := NewCodex()
+	codec, err := codex.AddSchema(`"string"`)
+	ensureError(t, err)
+
+	// With the single-object encoding prefix and Rabin fingerprints, the
+	// header is that of single-object encoding, and no Codex is needed.
+	soe := FingerprintFraming{Prefix: "\xC3\x01", Algorithm: FingerprintRabin}
+	buf, err := codec.FramedFromNative(nil, soe, codec.Rabin, "hello")
+	ensureError(t, err)
+	single, err := codec.SingleFromNative(nil, "hello")
+	ensureError(t, err)
+	if !bytes.Equal(buf, single) {
+		t.Errorf("GOT: %#v; WANT: %#v", buf, single)
+	}
+	id, _, err := soe.ParseHeader(buf)
+	ensureError(t, err)
+	if id != codec.Rabin {
+		t.Errorf("GOT: %v; WANT: %v", id, codec.Rabin)
+	}
+
+	framing := FingerprintFraming{Prefix: "AV", Algorithm: FingerprintSHA256, Codex: codex}
+	buf, err = codec.FramedFromNative([]byte("prefix"), framing, codec.Rabin, "hello")
+	ensureError(t, err)
+	if expected, _ := codec.Fingerprint(FingerprintSHA256); !bytes.Equal(buf[len("prefixAV"):len("prefixAV")+32], expected) {
+		t.Errorf("GOT: %x; WANT: %x", buf[len("prefixAV"):len("prefixAV")+32], expected)
+	}
+	id, rest, err := framing.ParseHeader(buf[len("prefix"):])
+	ensureError(t, err)
+	if id != codec.Rabin {
+		t.Errorf("GOT: %v; WANT: %v", id, codec.Rabin)
+	}
+	if expected := []byte("\x0ahello"); !bytes.Equal(rest, expected) {
+		t.Errorf("GOT: %#v; WANT: %#v", rest, expected)
+	}
+
+	_, _, err = framing.ParseHeader(buf[len("prefix") : len("prefix")+33])
+	ensureError(t, err, "cannot parse SHA-256 fingerprint header: short buffer")
+	_, _, err = framing.ParseHeader(buf)
+	ensureError(t, err, "cannot parse SHA-256 fingerprint header: unknown prefix: 0x7072")
+	_, _, err = FingerprintFraming{Algorithm: FingerprintAlgorithm(7)}.ParseHeader(buf)
+	ensureError(t, err, "cannot parse fingerprint header: unknown algorithm")
+	_, _, err = FingerprintFraming{Prefix: "AV", Algorithm: FingerprintSHA256}.ParseHeader(buf[len("prefix"):])
+	ensureError(t, err, "cannot parse SHA-256 fingerprint header without Codex")
+
+	buf, err = codec.FramedFromNative([]byte("prefix"), framing, codec.Rabin, 13)
+	ensureError(t, err, "cannot encode binary")
+	if string(buf) != "prefix" {
+		t.Errorf("GOT: %q; WANT: %q", buf, "prefix")
+	}
+	_, err = codec.FramedFromNative(nil, framing, 1, "hello")
+	ensureError(t, err, "cannot append SHA-256 fingerprint header: cannot find Codec in Codex")
+	_, err = codec.FramedFromNative(nil, FingerprintFraming{Algorithm: FingerprintMD5}, codec.Rabin, "hello")
+	ensureError(t, err, "cannot append MD5 fingerprint header without Codex")
+}
+
+func TestCodexLookupFingerprintFraming(t *testing.T) {
+	codex := NewCodex()
+	v1, err := codex.AddSchema(codexSchemaV1)
+	ensureError(t, err)
+	framing := FingerprintFraming{Prefix: "\xC3\x02", Algorithm: FingerprintMD5, Codex: codex}
+
+	buf1, err := v1.FramedFromNative(nil, framing, v1.Rabin, map[string]interface{}{"a": 1})
+	ensureError(t, err)
+	datum, _, err := NewFramedDecoder(codex, framing).NativeFromFramed(buf1)
+	ensureError(t, err)
+	if expected := map[string]interface{}{"a": int32(1)}; !reflect.DeepEqual(datum, expected) {
+		t.Errorf("GOT: %v; WANT: %v", datum, expected)
+	}
+
+	// A Codec added after the index of the algorithm was built is found.
+	v2, err := codex.AddSchema(codexSchemaV2)
+	ensureError(t, err)
+	buf2, err := v2.FramedFromNative(nil, framing, v2.Rabin, map[string]interface{}{"a": 2, "b": "two"})
+	ensureError(t, err)
+	datum, _, err = NewFramedDecoder(codex, framing).NativeFromFramed(buf2)
+	ensureError(t, err)
+	if expected := map[string]interface{}{"a": int64(2), "b": "two"}; !reflect.DeepEqual(datum, expected) {
+		t.Errorf("GOT: %v; WANT: %v", datum, expected)
+	}
+
+	// Datums resolve into the reader schema, whether their header is that of
+	// the FingerprintFraming or of single-object encoding.
+	ensureError(t, codex.SetReader(v2))
+	single, err := v1.SingleFromNative(nil, map[string]interface{}{"a": 1})
+	ensureError(t, err)
+	decoder := NewFramedDecoder(codex, framing, FramingSOE)
+	for _, buf := range [][]byte{buf1, single} {
+		datum, _, err = decoder.NativeFromFramed(buf)
+		ensureError(t, err)
+		if expected := map[string]interface{}{"a": int64(1), "b": "none"}; !reflect.DeepEqual(datum, expected) {
+			t.Errorf("GOT: %v; WANT: %v", datum, expected)
+		}
+	}
+
+	for _, alg := range []FingerprintAlgorithm{FingerprintRabin, FingerprintMD5, FingerprintSHA256} {
+		fingerprint, err := v2.Fingerprint(alg)
+		ensureError(t, err)
+		if codec, ok := codex.CodecByFingerprint(alg, fingerprint); !ok || codec != v2 {
+			t.Errorf("%s: GOT: %v, %v; WANT: %v, %v", alg, codec, ok, v2, true)
+		}
+		if _, ok := codex.CodecByFingerprint(alg, fingerprint[1:]); ok {
+			t.Errorf("%s: GOT: %v; WANT: %v", alg, ok, false)
+		}
+	}
+	if _, ok := codex.CodecByFingerprint(FingerprintAlgorithm(7), nil); ok {
+		t.Errorf("GOT: %v; WANT: %v", ok, false)
+	}
+
+	other, err := NewCodec(`"int"`)
+	ensureError(t, err)
+	otherFraming := FingerprintFraming{Prefix: "\xC3\x02", Algorithm: FingerprintMD5, Codex: NewCodex()}
+	ensureError(t, otherFraming.Codex.Add(other))
+	buf, err := other.FramedFromNative(nil, otherFraming, other.Rabin, 3)
+	ensureError(t, err)
+	_, rest, err := decoder.NativeFromFramed(buf)
+	ensureError(t, err, "cannot find Codec in Codex: unknown MD5 fingerprint: ef524ea1b91e73173d938ade36c1db32")
+	if !bytes.Equal(rest, buf) {
+		t.Errorf("GOT: %#v; WANT: %#v", rest, buf)
+	}
+	_, _, err = decoder.NativeFromFramed(buf1[:3])
+	ensureError(t, err, "short buffer")
+
+	_, err = codex.LookupCodec(FramingConfluent, v1.Rabin)
+	ensureError(t, err, "cannot look up schema of Confluent header in Codex")
+}
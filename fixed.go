@@ -25,6 +25,8 @@ func makeFixedCodec(st map[string]*Codec, enclosingNamespace string, schemaMap m
 	if err != nil {
 		return nil, err
 	}
+	c.kind = "fixed"
+	c.size = size
 
 	c.nativeFromBinary = func(buf []byte) (interface{}, []byte, error) {
 		if buflen := uint(len(buf)); size > buflen {
@@ -33,6 +35,13 @@ func makeFixedCodec(st map[string]*Codec, enclosingNamespace string, schemaMap m
 		return buf[:size], buf[size:], nil
 	}
 
+	c.skipBinary = func(buf []byte) ([]byte, error) {
+		if buflen := uint(len(buf)); size > buflen {
+			return nil, fmt.Errorf("cannot decode binary fixed %q: schema size exceeds remaining buffer size: %d > %d (short buffer)", c.typeName, size, buflen)
+		}
+		return buf[size:], nil
+	}
+
 	c.binaryFromNative = func(buf []byte, datum interface{}) ([]byte, error) {
 		var someBytes []byte
 		switch d := datum.(type) {
@@ -41,6 +41,20 @@ func floatNativeFromBinary(buf []byte) (interface{}, []byte, error) {
 	return math.Float32frombits(binary.LittleEndian.Uint32(buf[:floatEncodedLength])), buf[floatEncodedLength:], nil
 }
 
+func doubleSkipBinary(buf []byte) ([]byte, error) {
+	if len(buf) < doubleEncodedLength {
+		return nil, fmt.Errorf("cannot decode binary double: %s", io.ErrShortBuffer)
+	}
+	return buf[doubleEncodedLength:], nil
+}
+
+func floatSkipBinary(buf []byte) ([]byte, error) {
+	if len(buf) < floatEncodedLength {
+		return nil, fmt.Errorf("cannot decode binary float: %s", io.ErrShortBuffer)
+	}
+	return buf[floatEncodedLength:], nil
+}
+
 ////////////////////////////////////////
 // Binary Encode
 ////////////////////////////////////////
@@ -0,0 +1,273 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package goavro
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+)
+
+// Framing is a format of the header that precedes a binary encoded datum, and
+// identifies the schema used to encode it, such as when the datum is the key
+// or the value of a Kafka message. Three Framings are provided: FramingSOE,
+// FramingConfluent, and FramingApicurio, along with FingerprintFraming for
+// headers of other schema fingerprints, and other formats may be supported by
+// implementing this interface with a comparable type.
+type Framing interface {
+	// AppendHeader appends the header that identifies the schema with the
+	// provided ID to buf, and returns the new byte slice.
+	AppendHeader(buf []byte, id uint64) ([]byte, error)
+
+	// ParseHeader returns the ID of the schema identified by the header at the
+	// beginning of buf, along with a new byte slice with the header consumed.
+	// It returns an error when buf does not begin with a header of the
+	// Framing.
+	ParseHeader(buf []byte) (uint64, []byte, error)
+
+	// String returns the name of the Framing.
+	String() string
+}
+
+var (
+	// FramingSOE is the Avro single-object encoding, whose header is the two
+	// byte magic prefix 0xC3 0x01 followed by the little-endian 64-bit Rabin
+	// fingerprint of the canonical form of the schema, as written by
+	// Codec.SingleFromNative.
+	FramingSOE Framing = soeFraming{}
+
+	// FramingConfluent is the wire format of the Confluent Schema Registry,
+	// whose header is the magic byte 0x00 followed by the big-endian 32-bit ID
+	// of the schema in the registry.
+	FramingConfluent Framing = confluentFraming{}
+
+	// FramingApicurio is the wire format of the Apicurio Registry, whose header
+	// is the magic byte 0x00 followed by the big-endian 64-bit global ID of the
+	// schema in the registry.
+	FramingApicurio Framing = apicurioFraming{}
+)
+
+type soeFraming struct{}
+
+func (soeFraming) String() string { return "single-object encoding" }
+
+func (soeFraming) AppendHeader(buf []byte, id uint64) ([]byte, error) {
+	buf = append(buf, 0xC3, 0x01)
+	var fingerprint [8]byte
+	binary.LittleEndian.PutUint64(fingerprint[:], id)
+	return append(buf, fingerprint[:]...), nil
+}
+
+func (soeFraming) ParseHeader(buf []byte) (uint64, []byte, error) {
+	return FingerprintFromSOE(buf)
+}
+
+const (
+	confluentHeaderLen = 1 + 4 // magic byte plus 32-bit schema ID
+	apicurioHeaderLen  = 1 + 8 // magic byte plus 64-bit global ID
+)
+
+type confluentFraming struct{}
+
+func (confluentFraming) String() string { return "Confluent" }
+
+func (f confluentFraming) AppendHeader(buf []byte, id uint64) ([]byte, error) {
+	if id > math.MaxInt32 {
+		return buf, fmt.Errorf("cannot append %s header: schema ID ought to be at most %d: %d", f, math.MaxInt32, id)
+	}
+	var header [confluentHeaderLen]byte
+	binary.BigEndian.PutUint32(header[1:], uint32(id))
+	return append(buf, header[:]...), nil
+}
+
+func (f confluentFraming) ParseHeader(buf []byte) (uint64, []byte, error) {
+	if err := checkMagicByte(f, buf, confluentHeaderLen); err != nil {
+		return 0, buf, err
+	}
+	return uint64(binary.BigEndian.Uint32(buf[1:])), buf[confluentHeaderLen:], nil
+}
+
+type apicurioFraming struct{}
+
+func (apicurioFraming) String() string { return "Apicurio" }
+
+func (f apicurioFraming) AppendHeader(buf []byte, id uint64) ([]byte, error) {
+	if id > math.MaxInt64 {
+		return buf, fmt.Errorf("cannot append %s header: global ID ought to be at most %d: %d", f, int64(math.MaxInt64), id)
+	}
+	var header [apicurioHeaderLen]byte
+	binary.BigEndian.PutUint64(header[1:], id)
+	return append(buf, header[:]...), nil
+}
+
+func (f apicurioFraming) ParseHeader(buf []byte) (uint64, []byte, error) {
+	if err := checkMagicByte(f, buf, apicurioHeaderLen); err != nil {
+		return 0, buf, err
+	}
+	return binary.BigEndian.Uint64(buf[1:]), buf[apicurioHeaderLen:], nil
+}
+
+// checkMagicByte returns an error unless buf has at least headerLen bytes and
+// begins with the 0x00 magic byte of registry framings.
+func checkMagicByte(framing Framing, buf []byte, headerLen int) error {
+	if len(buf) < headerLen {
+		return fmt.Errorf("cannot parse %s header: %s", framing, io.ErrShortBuffer)
+	}
+	if buf[0] != 0 {
+		return fmt.Errorf("cannot parse %s header: unknown magic byte: %#x", framing, buf[0])
+	}
+	return nil
+}
+
+// FramedFromNative appends the header of the provided Framing, identifying the
+// schema of the Codec by id, followed by the binary encoded datum, to the
+// provided byte slice. For FramingSOE the id ought to be the Rabin fingerprint
+// of the Codec, and for registry framings it ought to be the ID under which
+// the schema of the Codec is registered. On success, it returns a new byte
+// slice with the encoded bytes appended, and a nil error value. On error, it
+// returns the original byte slice, and the error message.
+//
+//	buf, err := codec.FramedFromNative(nil, goavro.FramingConfluent, 42, datum)
+func (c *Codec) FramedFromNative(buf []byte, framing Framing, id uint64, datum interface{}) ([]byte, error) {
+	newBuf, err := framing.AppendHeader(buf, id)
+	if err != nil {
+		return buf, err
+	}
+	newBuf, err = c.binaryFromNative(newBuf, datum)
+	if err != nil {
+		return buf, err
+	}
+	return newBuf, nil
+}
+
+// CodecLookup returns the Codec of the schema identified by a header of a
+// Framing, such as a Schema Registry client. The ID is the Rabin fingerprint of
+// the schema for FramingSOE, and the ID under which the schema is registered
+// for FramingConfluent and FramingApicurio.
+type CodecLookup interface {
+	LookupCodec(framing Framing, id uint64) (*Codec, error)
+}
+
+// CodecLookupFunc adapts a function to a CodecLookup.
+type CodecLookupFunc func(framing Framing, id uint64) (*Codec, error)
+
+// LookupCodec returns f(framing, id).
+func (f CodecLookupFunc) LookupCodec(framing Framing, id uint64) (*Codec, error) {
+	return f(framing, id)
+}
+
+// FramedDecoder decodes binary encoded datums preceded by a header of any of
+// several Framings, detecting the Framing of each datum from its header, so a
+// single consumer may read datums of mixed formats. It is safe for concurrent
+// use.
+//
+// A FramedDecoder caches the result of looking up each schema with its
+// CodecLookup, including a failure, so that datums whose header parses as one
+// of a Framing they were not written with do not cause a lookup each. A schema
+// that becomes available to the CodecLookup after a failed lookup is found by a
+// new FramedDecoder.
+type FramedDecoder struct {
+	lookup   CodecLookup
+	framings []Framing
+	codecs   sync.Map // framedID -> lookupResult
+}
+
+// framedID identifies a schema by the Framing of its header and its ID.
+type framedID struct {
+	framing Framing
+	id      uint64
+}
+
+// lookupResult is the result of looking up the Codec of a schema.
+type lookupResult struct {
+	codec *Codec
+	err   error
+}
+
+// NewFramedDecoder returns a FramedDecoder that decodes datums preceded by a
+// header of one of the provided Framings, or of FramingSOE, FramingConfluent,
+// and FramingApicurio when none are provided, using the provided CodecLookup to
+// find the Codec of the schema identified by each header.
+//
+// The Framings are tried in order, and the first whose header the datum begins
+// with, and whose schema is found by the CodecLookup, decodes it. Because
+// FramingConfluent and FramingApicurio have the same magic byte, a datum of
+// either may parse as a header of the other, so the CodecLookup ought to
+// return an error for the Framings whose IDs it does not have, as a registry
+// Client does for Framings other than the one it is configured with.
+//
+//	decoder := goavro.NewFramedDecoder(client)
+//	datum, _, err := decoder.NativeFromFramed(message.Value)
+func NewFramedDecoder(lookup CodecLookup, framings ...Framing) *FramedDecoder {
+	if len(framings) == 0 {
+		framings = []Framing{FramingSOE, FramingConfluent, FramingApicurio}
+	}
+	return &FramedDecoder{lookup: lookup, framings: framings}
+}
+
+// Codec returns the Codec that decodes the datum after the header at the
+// beginning of buf, along with the Framing of the header, and a new byte slice
+// with the header consumed.
+func (fd *FramedDecoder) Codec(buf []byte) (*Codec, Framing, []byte, error) {
+	var parseErr, lookupErr error
+	for _, framing := range fd.framings {
+		id, newBuf, err := framing.ParseHeader(buf)
+		if err != nil {
+			if parseErr == nil {
+				parseErr = err
+			}
+			continue
+		}
+		key := framedID{framing, id}
+		value, ok := fd.codecs.Load(key)
+		if !ok {
+			codec, err := fd.lookup.LookupCodec(framing, id)
+			if err != nil {
+				err = fmt.Errorf("cannot look up schema %d of %s header: %s", id, framing, err)
+			}
+			value, _ = fd.codecs.LoadOrStore(key, lookupResult{codec, err})
+		}
+		result := value.(lookupResult)
+		if result.err != nil {
+			if lookupErr == nil {
+				lookupErr = result.err
+			}
+			continue
+		}
+		return result.codec, framing, newBuf, nil
+	}
+	// NOTE: When the datum begins with the header of a Framing, the failure to
+	// look up its schema is more helpful than the failures to parse the header
+	// of the other Framings.
+	if lookupErr != nil {
+		return nil, nil, buf, fmt.Errorf("cannot decode framed datum: %s", lookupErr)
+	}
+	return nil, nil, buf, fmt.Errorf("cannot decode framed datum: %s", parseErr)
+}
+
+// NativeFromFramed detects the Framing of the header at the beginning of buf,
+// and converts the binary encoded datum after it to Go native data types in
+// accordance with the schema the header identifies. On success, it returns the
+// decoded datum, along with a new byte slice with the decoded bytes consumed,
+// and a nil error value. On error, it returns nil for the datum value, the
+// original byte slice, and the error message.
+func (fd *FramedDecoder) NativeFromFramed(buf []byte) (interface{}, []byte, error) {
+	codec, _, newBuf, err := fd.Codec(buf)
+	if err != nil {
+		return nil, buf, err
+	}
+	value, newBuf, err := codec.nativeFromBinary(newBuf)
+	if err != nil {
+		return nil, buf, err // if error, return original byte slice
+	}
+	return value, newBuf, nil
+}
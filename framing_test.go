@@ -0,0 +1,200 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package goavro
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func ExampleFramedDecoder() {
+	codec, err := NewCodec(`"string"`)
+	if err != nil {
+		fmt.Println(err)
+	}
+	lookup := CodecLookupFunc(func(framing Framing, id uint64) (*Codec, error) {
+		if (framing == FramingSOE && id == codec.Rabin) || (framing == FramingConfluent && id == 7) {
+			return codec, nil
+		}
+		return nil, fmt.Errorf("unknown schema: %d", id)
+	})
+	decoder := NewFramedDecoder(lookup)
+
+	soe, _ := codec.FramedFromNative(nil, FramingSOE, codec.Rabin, "from SOE")
+	confluent, _ := codec.FramedFromNative(nil, FramingConfluent, 7, "from Confluent")
+	for _, buf := range [][]byte{soe, confluent} {
+		datum, _, err := decoder.NativeFromFramed(buf)
+		if err != nil {
+			fmt.Println(err)
+		}
+		fmt.Println(datum)
+	}
+	// Output:
+	// from SOE
+	// from Confluent
+}
+
+func TestFramingHeaders(t *testing.T) {
+	cases := []struct {
+		framing Framing
+		id      uint64
+		header  []byte
+	}{
+		{FramingSOE, 0x0102030405060708, []byte{0xC3, 0x01, 8, 7, 6, 5, 4, 3, 2, 1}},
+		{FramingConfluent, 0x01020304, []byte{0, 1, 2, 3, 4}},
+		{FramingApicurio, 0x0102030405060708, []byte{0, 1, 2, 3, 4, 5, 6, 7, 8}},
+	}
+	for _, c := range cases {
+		buf, err := c.framing.AppendHeader([]byte("prefix"), c.id)
+		ensureError(t, err)
+		if expected := append([]byte("prefix"), c.header...); !bytes.Equal(buf, expected) {
+			t.Errorf("%s: GOT: %#v; WANT: %#v", c.framing, buf, expected)
+		}
+
+		id, buf, err := c.framing.ParseHeader(append(c.header, "rest"...))
+		ensureError(t, err)
+		if id != c.id {
+			t.Errorf("%s: GOT: %#x; WANT: %#x", c.framing, id, c.id)
+		}
+		if string(buf) != "rest" {
+			t.Errorf("%s: GOT: %q; WANT: %q", c.framing, buf, "rest")
+		}
+
+		// Every truncated header is too short.
+		for i := 0; i < len(c.header); i++ {
+			_, _, err = c.framing.ParseHeader(c.header[:i])
+			ensureError(t, err, "short buffer")
+		}
+	}
+
+	_, _, err := FramingConfluent.ParseHeader([]byte{1, 0, 0, 0, 1})
+	ensureError(t, err, "cannot parse Confluent header: unknown magic byte: 0x1")
+	_, _, err = FramingApicurio.ParseHeader([]byte{0xC3, 1, 0, 0, 0, 0, 0, 0, 1})
+	ensureError(t, err, "cannot parse Apicurio header: unknown magic byte: 0xc3")
+	_, _, err = FramingSOE.ParseHeader([]byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 1})
+	ensureError(t, err, "cannot decode buffer as single-object encoding")
+
+	_, err = FramingConfluent.AppendHeader(nil, math.MaxInt32+1)
+	ensureError(t, err, "cannot append Confluent header: schema ID ought to be at most 2147483647")
+	_, err = FramingApicurio.AppendHeader(nil, math.MaxInt64+1)
+	ensureError(t, err, "cannot append Apicurio header: global ID ought to be at most 9223372036854775807")
+}
+
+func TestFramedFromNativeMatchesSingleFromNative(t *testing.T) {
+	codec, err := NewCodec(`"int"`)
+	ensureError(t, err)
+	framed, err := codec.FramedFromNative(nil, FramingSOE, codec.Rabin, 3)
+	ensureError(t, err)
+	single, err := codec.SingleFromNative(nil, 3)
+	ensureError(t, err)
+	if !bytes.Equal(framed, single) {
+		t.Errorf("GOT: %#v; WANT: %#v", framed, single)
+	}
+
+	buf, err := codec.FramedFromNative([]byte("prefix"), FramingConfluent, 1, "not an int")
+	ensureError(t, err, "cannot encode binary int")
+	if string(buf) != "prefix" {
+		t.Errorf("GOT: %q; WANT: %q", buf, "prefix")
+	}
+}
+
+func TestFramedDecoderMixedFramings(t *testing.T) {
+	v1, err := NewCodec(`{"type":"record","name":"r","fields":[{"name":"a","type":"long"}]}`)
+	ensureError(t, err)
+	v2, err := NewCodec(`{"type":"record","name":"r","fields":[{"name":"a","type":"long"},{"name":"b","type":"string"}]}`)
+	ensureError(t, err)
+
+	var lookups int
+	lookup := CodecLookupFunc(func(framing Framing, id uint64) (*Codec, error) {
+		lookups++
+		switch {
+		case framing == FramingSOE && id == v1.Rabin:
+			return v1, nil
+		case framing == FramingConfluent && id == 1:
+			return v1, nil
+		case framing == FramingApicurio && id == 1000:
+			return v2, nil
+		}
+		return nil, fmt.Errorf("unknown schema")
+	})
+	decoder := NewFramedDecoder(lookup)
+
+	datum1 := map[string]interface{}{"a": int64(1)}
+	datum2 := map[string]interface{}{"a": int64(2), "b": "two"}
+	soe, err := v1.FramedFromNative(nil, FramingSOE, v1.Rabin, datum1)
+	ensureError(t, err)
+	confluent, err := v1.FramedFromNative(nil, FramingConfluent, 1, datum1)
+	ensureError(t, err)
+	apicurio, err := v2.FramedFromNative(nil, FramingApicurio, 1000, datum2)
+	ensureError(t, err)
+
+	cases := []struct {
+		buf     []byte
+		framing Framing
+		datum   interface{}
+	}{
+		{soe, FramingSOE, datum1},
+		{confluent, FramingConfluent, datum1},
+		{apicurio, FramingApicurio, datum2},
+	}
+	for i := 0; i < 2; i++ {
+		for _, c := range cases {
+			codec, framing, _, err := decoder.Codec(c.buf)
+			ensureError(t, err)
+			if framing != c.framing {
+				t.Errorf("GOT: %v; WANT: %v", framing, c.framing)
+			}
+			if codec == nil {
+				t.Fatalf("GOT: %v; WANT: Codec", codec)
+			}
+			datum, rest, err := decoder.NativeFromFramed(c.buf)
+			ensureError(t, err)
+			if !reflect.DeepEqual(datum, c.datum) {
+				t.Errorf("GOT: %v; WANT: %v", datum, c.datum)
+			}
+			if len(rest) != 0 {
+				t.Errorf("GOT: %v; WANT: %v", len(rest), 0)
+			}
+		}
+	}
+	// Lookups are cached, including the failed lookup of the Apicurio datum
+	// parsed as a Confluent header.
+	if lookups != 4 {
+		t.Errorf("GOT: %v; WANT: %v", lookups, 4)
+	}
+
+	for i := 0; i < 2; i++ {
+		_, _, err = decoder.NativeFromFramed([]byte{0, 0, 0, 0, 9, 0, 0, 0, 0, 0})
+		ensureError(t, err, "cannot decode framed datum: cannot look up schema 9 of Confluent header: unknown schema")
+	}
+	// The datum parses as both a Confluent and an Apicurio header, and neither
+	// failed lookup is repeated.
+	if lookups != 6 {
+		t.Errorf("GOT: %v; WANT: %v", lookups, 6)
+	}
+	_, _, err = decoder.NativeFromFramed([]byte{1, 2, 3})
+	ensureError(t, err, "cannot decode framed datum: cannot decode buffer as single-object encoding")
+
+	// Only the provided Framings are detected.
+	confluentOnly := NewFramedDecoder(lookup, FramingConfluent)
+	_, _, err = confluentOnly.NativeFromFramed(soe)
+	ensureError(t, err, "cannot decode framed datum: cannot parse Confluent header: unknown magic byte: 0xc3")
+
+	// On failure to decode the datum, the original buffer is returned.
+	truncated := confluent[:len(confluent)-1]
+	_, rest, err := decoder.NativeFromFramed(truncated)
+	ensureError(t, err, "short buffer")
+	if !bytes.Equal(rest, truncated) {
+		t.Errorf("GOT: %#v; WANT: %#v", rest, truncated)
+	}
+}
@@ -0,0 +1,324 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+// Package idl compiles Avro IDL files, such as .avdl files, into JSON protocols
+// and schemas, and into goavro Codecs.
+//
+// A file either declares a protocol, whose named types and messages become a
+// JSON protocol, as in a .avpr file, or uses the schema syntax, which declares
+// named types, and optionally the main schema of the file, outside of a
+// protocol:
+//
+//	namespace com.example;
+//	schema User;
+//
+//	/** A user of the service. */
+//	record User {
+//	    long id;
+//	    string name = "";
+//	    string? email = null;
+//	    @logicalType("timestamp-micros") long created;
+//	    array<Role> roles = [];
+//	}
+//
+//	enum Role { ADMIN, MEMBER, GUEST } = GUEST;
+//
+// Records, errors, enums, fixed types, arrays, maps, unions, optional types,
+// the date, time_ms, timestamp_ms, local_timestamp_ms, uuid, and decimal
+// logical types, the @namespace, @aliases, @order, and @logicalType
+// annotations along with other custom properties, default values,
+// documentation comments, and the import of IDL files, JSON protocols, and
+// JSON schemas are supported.
+//
+//	file, err := idl.ParseFile("user.avdl")
+//	if err != nil {
+//	    return err // an *idl.Error when the file is invalid
+//	}
+//	codec, err := file.Codec()
+package idl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/linkedin/goavro/v2"
+)
+
+// Error is returned when an IDL file, or a file it imports, is invalid, and
+// locates the mistake in the file.
+type Error struct {
+	Filename string
+	Line     int
+	Column   int
+	Message  string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s:%d:%d: %s", e.Filename, e.Line, e.Column, e.Message)
+}
+
+// File is a compiled IDL file.
+type File struct {
+	// Namespace is the namespace of the protocol declared by the file, or the
+	// namespace declared using the schema syntax.
+	Namespace string
+
+	// Protocol is the JSON text of the protocol declared by the file, as in a
+	// .avpr file, or empty when the file uses the schema syntax.
+	Protocol string
+
+	// Schema is the JSON text of the main schema declared using the schema
+	// syntax, or empty when there is none.
+	Schema string
+
+	c *compiler
+}
+
+// ParseFile reads and compiles the IDL file. Imported files are read relative
+// to the directory of the file.
+func ParseFile(filename string) (*File, error) {
+	src, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read IDL file: %s", err)
+	}
+	return Parse(filename, src)
+}
+
+// Parse compiles the IDL text read from the named file. Imported files are
+// read relative to the directory of the file.
+func Parse(filename string, src []byte) (*File, error) {
+	tokens, err := tokenize(filename, src)
+	if err != nil {
+		return nil, err
+	}
+	c := &compiler{
+		byName:   make(map[string]*namedType),
+		messages: &object{},
+		imported: make(map[string]struct{}),
+	}
+	p := &parser{c: c, filename: filename, tokens: tokens}
+	protocol, mainSchema, err := p.file()
+	if err != nil {
+		return nil, err
+	}
+	if err = c.resolve(); err != nil {
+		return nil, err
+	}
+
+	f := &File{Namespace: p.namespace, c: c}
+	if mainSchema != nil {
+		buf, err := json.Marshal(c.inline(mainSchema, make(map[string]struct{}), false))
+		if err != nil {
+			return nil, err // should not get here because every value is JSON
+		}
+		f.Schema = string(buf)
+	}
+	if protocol != nil {
+		defined := make(map[string]struct{})
+		types := []interface{}{}
+		for _, t := range c.types {
+			if _, ok := defined[t.name]; !ok {
+				defined[t.name] = struct{}{}
+				types = append(types, c.inline(t.schema, defined, true))
+			}
+		}
+		protocol.set("types", types)
+		messages := &object{}
+		for _, m := range c.messages.members {
+			message := m.value.(*object).clone()
+			for _, key := range []string{"request", "response", "errors"} {
+				if value, ok := message.get(key); ok {
+					if key == "request" {
+						value = c.inlineFields(value.([]interface{}), defined, true)
+					} else {
+						value = c.inline(value, defined, true)
+					}
+					message.set(key, value)
+				}
+			}
+			messages.set(m.key, message)
+		}
+		protocol.set("messages", messages)
+		buf, err := json.Marshal(protocol)
+		if err != nil {
+			return nil, err // should not get here because every value is JSON
+		}
+		f.Protocol = string(buf)
+	}
+	return f, nil
+}
+
+// TypeNames returns the full names of the named types declared by the file and
+// the files it imports, in the order they are declared.
+func (f *File) TypeNames() []string {
+	names := make([]string, len(f.c.types))
+	for i, t := range f.c.types {
+		names[i] = t.name
+	}
+	return names
+}
+
+// TypeSchema returns the JSON text of the schema of the named type, which
+// defines the named types it refers to. Error types are records in the schema.
+func (f *File) TypeSchema(name string) (string, error) {
+	if _, ok := f.c.byName[name]; !ok {
+		return "", fmt.Errorf("cannot find named type in IDL file: %q", name)
+	}
+	buf, err := json.Marshal(f.c.inline(&typeRef{resolved: name}, make(map[string]struct{}), false))
+	if err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+// TypeCodec returns a Codec for the named type.
+func (f *File) TypeCodec(name string) (*goavro.Codec, error) {
+	schema, err := f.TypeSchema(name)
+	if err != nil {
+		return nil, err
+	}
+	return goavro.NewCodec(schema)
+}
+
+// Codec returns a Codec for the main schema declared using the schema syntax.
+func (f *File) Codec() (*goavro.Codec, error) {
+	if f.Schema == "" {
+		return nil, fmt.Errorf("cannot create Codec for IDL file without main schema")
+	}
+	return goavro.NewCodec(f.Schema)
+}
+
+// compiler holds the named types and messages declared by an IDL file and the
+// files it imports.
+type compiler struct {
+	types    []*namedType
+	byName   map[string]*namedType
+	messages *object
+	imported map[string]struct{} // absolute names of files already imported
+	refs     []*typeRef
+}
+
+// namedType is a record, error, enum, or fixed type declared by a file.
+type namedType struct {
+	name   string
+	schema *object
+}
+
+// typeRef is a reference to a named type, which is resolved after every type
+// has been declared, because a type may be referred to before it is declared.
+type typeRef struct {
+	name, namespace string
+	filename        string
+	at              token
+	resolved        string // full name of the type
+}
+
+// MarshalJSON writes the full name of the type referred to.
+func (r *typeRef) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.resolved)
+}
+
+// reference returns a reference to the named type, relative to the namespace.
+func (c *compiler) reference(p *parser, name token, namespace string) *typeRef {
+	r := &typeRef{name: name.text, namespace: namespace, filename: p.filename, at: name}
+	c.refs = append(c.refs, r)
+	return r
+}
+
+func (c *compiler) declare(p *parser, name string, schema *object, at token) error {
+	if _, ok := c.byName[name]; ok {
+		return p.errorf(at, "type ought to be declared once: %q", name)
+	}
+	t := &namedType{name: name, schema: schema}
+	c.types = append(c.types, t)
+	c.byName[name] = t
+	return nil
+}
+
+// resolve finds the named type of each reference, first in the namespace of
+// the reference, and then as a full name.
+func (c *compiler) resolve() error {
+	for _, r := range c.refs {
+		if r.namespace != "" && strings.IndexByte(r.name, '.') == -1 {
+			if _, ok := c.byName[r.namespace+"."+r.name]; ok {
+				r.resolved = r.namespace + "." + r.name
+				continue
+			}
+		}
+		if _, ok := c.byName[r.name]; !ok {
+			return &Error{Filename: r.filename, Line: r.at.line, Column: r.at.column, Message: fmt.Sprintf("type ought to be declared: %q", r.name)}
+		}
+		r.resolved = r.name
+	}
+	return nil
+}
+
+// inline returns the schema with each named type it refers to defined where
+// it first occurs, when not already defined. Error types are records unless
+// errors is true.
+func (c *compiler) inline(schema interface{}, defined map[string]struct{}, errors bool) interface{} {
+	switch s := schema.(type) {
+	case *typeRef:
+		if _, ok := defined[s.resolved]; ok {
+			return s.resolved
+		}
+		defined[s.resolved] = struct{}{}
+		return c.inline(c.byName[s.resolved].schema, defined, errors)
+	case []interface{}:
+		members := make([]interface{}, len(s))
+		for i, member := range s {
+			members[i] = c.inline(member, defined, errors)
+		}
+		return members
+	case *object:
+		switch s.getString("type") {
+		case "array":
+			items, _ := s.get("items")
+			o := s.clone()
+			o.set("items", c.inline(items, defined, errors))
+			return o
+		case "map":
+			values, _ := s.get("values")
+			o := s.clone()
+			o.set("values", c.inline(values, defined, errors))
+			return o
+		case "record", "error":
+			o := s.clone()
+			if !errors {
+				o.set("type", "record")
+			}
+			if fields, ok := getArray(s, "fields"); ok {
+				o.set("fields", c.inlineFields(fields, defined, errors))
+			}
+			return o
+		}
+	}
+	return schema
+}
+
+// inlineFields returns the record fields, or message parameters, with the named
+// types of their types defined where they first occur.
+func (c *compiler) inlineFields(fields []interface{}, defined map[string]struct{}, errors bool) []interface{} {
+	inlined := make([]interface{}, len(fields))
+	for i, field := range fields {
+		o, ok := field.(*object)
+		if !ok {
+			inlined[i] = field
+			continue
+		}
+		o = o.clone()
+		if schema, ok := o.get("type"); ok {
+			o.set("type", c.inline(schema, defined, errors))
+		}
+		inlined[i] = o
+	}
+	return inlined
+}
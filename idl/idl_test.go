@@ -0,0 +1,241 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package idl
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/linkedin/goavro/v2"
+)
+
+func ensureError(tb testing.TB, err error, contains ...string) {
+	tb.Helper()
+	if len(contains) == 0 || (len(contains) == 1 && contains[0] == "") {
+		if err != nil {
+			tb.Fatalf("GOT: %v; WANT: %v", err, contains)
+		}
+		return
+	}
+	if err == nil {
+		tb.Errorf("GOT: %v; WANT: %v", err, contains)
+		return
+	}
+	for _, stub := range contains {
+		if stub != "" && !strings.Contains(err.Error(), stub) {
+			tb.Errorf("GOT: %v; WANT: %q", err, stub)
+		}
+	}
+}
+
+// writeFiles writes the files to a new temporary directory, and returns its
+// name.
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "goavro-idl")
+	ensureError(t, err)
+	for name, contents := range files {
+		ensureError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(contents), 0644))
+	}
+	return dir
+}
+
+func TestParseProtocol(t *testing.T) {
+	f, err := Parse("mail.avdl", []byte(`
+		/**
+		 * Sends mail.
+		 */
+		@namespace("com.example") @version("1")
+		protocol Mail {
+		    /** A user. */
+		    record User {
+		        long id;
+		        string? email = null;
+		    }
+
+		    error Bounced { string reason; }
+
+		    /** Sends a message. */
+		    boolean send(User to, string body = "") throws Bounced;
+		    void ping() oneway;
+		}`))
+	ensureError(t, err)
+	if actual, expected := f.Namespace, "com.example"; actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+	if actual, expected := f.Protocol, `{"protocol":"Mail","namespace":"com.example","doc":"Sends mail.","version":"1","types":[`+
+		`{"type":"record","name":"com.example.User","doc":"A user.","fields":[{"name":"id","type":"long"},{"name":"email","type":["null","string"],"default":null}]},`+
+		`{"type":"error","name":"com.example.Bounced","fields":[{"name":"reason","type":"string"}]}],"messages":{`+
+		`"send":{"doc":"Sends a message.","request":[{"name":"to","type":"com.example.User"},{"name":"body","type":"string","default":""}],"response":"boolean","errors":["com.example.Bounced"]},`+
+		`"ping":{"request":[],"response":"null","one-way":true}}}`; actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+	if actual, expected := f.Schema, ""; actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+	protocol, err := goavro.NewProtocol(f.Protocol)
+	ensureError(t, err)
+	message, ok := protocol.Message("send")
+	if !ok {
+		t.Fatalf("GOT: %v; WANT: %v", ok, true)
+	}
+	if actual, expected := message.Request().Schema(), `{"type":"record","name":"com.example.send","fields":[`+
+		`{"name":"to","type":{"type":"record","name":"com.example.User","doc":"A user.","fields":[{"name":"id","type":"long"},{"name":"email","type":["null","string"],"default":null}]}},`+
+		`{"name":"body","type":"string","default":""}]}`; actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+	_, err = f.Codec()
+	ensureError(t, err, "without main schema")
+
+	// Error types are records when used as schemas.
+	schema, err := f.TypeSchema("com.example.Bounced")
+	ensureError(t, err)
+	if expected := `{"type":"record","name":"com.example.Bounced","fields":[{"name":"reason","type":"string"}]}`; schema != expected {
+		t.Errorf("GOT: %v; WANT: %v", schema, expected)
+	}
+	_, err = f.TypeSchema("com.example.Missing")
+	ensureError(t, err, `cannot find named type in IDL file: "com.example.Missing"`)
+}
+
+func TestParseSchemaSyntax(t *testing.T) {
+	f, err := Parse("user.avdl", []byte(`
+		namespace com.example;
+		schema User;
+
+		record User {
+		    long id;
+		    string @aliases(["fullName"]) @order("descending") name = "";
+		    @logicalType("timestamp-micros") long created;
+		    array<Role> roles = [];
+		    map<Role> other = {};
+		    union { null, com.other.Address } home = null;
+		    com.other.Address? work = {"city": "Paris"};
+		    date born;
+		    time_ms alarm;
+		    timestamp_ms seen;
+		    local_timestamp_ms local;
+		    uuid token;
+		    decimal(9, 2) balance;
+		    int a = 1, b = -2;
+		    string `+"`error`"+`;
+		}
+
+		/** A role. */
+		enum Role { ADMIN, MEMBER, GUEST } = GUEST;
+
+		@namespace("com.other") @aliases(["com.old.Address"])
+		record Address { string city; }
+		`))
+	ensureError(t, err)
+	if actual, expected := f.Protocol, ""; actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+	if actual, expected := f.TypeNames(), []string{"com.example.User", "com.example.Role", "com.other.Address"}; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+	expected := `{"type":"record","name":"com.example.User","fields":[` +
+		`{"name":"id","type":"long"},` +
+		`{"name":"name","type":"string","default":"","aliases":["fullName"],"order":"descending"},` +
+		`{"name":"created","type":{"type":"long","logicalType":"timestamp-micros"}},` +
+		`{"name":"roles","type":{"type":"array","items":{"type":"enum","name":"com.example.Role","doc":"A role.","symbols":["ADMIN","MEMBER","GUEST"],"default":"GUEST"}},"default":[]},` +
+		`{"name":"other","type":{"type":"map","values":"com.example.Role"},"default":{}},` +
+		`{"name":"home","type":["null",{"type":"record","name":"com.other.Address","aliases":["com.old.Address"],"fields":[{"name":"city","type":"string"}]}],"default":null},` +
+		`{"name":"work","type":["com.other.Address","null"],"default":{"city":"Paris"}},` +
+		`{"name":"born","type":{"type":"int","logicalType":"date"}},` +
+		`{"name":"alarm","type":{"type":"int","logicalType":"time-millis"}},` +
+		`{"name":"seen","type":{"type":"long","logicalType":"timestamp-millis"}},` +
+		`{"name":"local","type":{"type":"long","logicalType":"local-timestamp-millis"}},` +
+		`{"name":"token","type":{"type":"string","logicalType":"uuid"}},` +
+		`{"name":"balance","type":{"type":"bytes","logicalType":"decimal","precision":9,"scale":2}},` +
+		`{"name":"a","type":"int","default":1},` +
+		`{"name":"b","type":"int","default":-2},` +
+		`{"name":"error","type":"string"}]}`
+	if f.Schema != expected {
+		t.Errorf("GOT: %v; WANT: %v", f.Schema, expected)
+	}
+
+	codec, err := f.Codec()
+	ensureError(t, err)
+	if actual, expected := codec.Schema(), expected; actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+	codec, err = f.TypeCodec("com.example.Role")
+	ensureError(t, err)
+	buf, err := codec.BinaryFromNative(nil, "MEMBER")
+	ensureError(t, err)
+	if actual, expected := buf, []byte{2}; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+}
+
+func TestParseImports(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"money.avsc": `{"type":"record","name":"Money","namespace":"com.shared","fields":[
+			{"name":"amount","type":"long"},
+			{"name":"currency","type":{"type":"enum","name":"Currency","symbols":["EUR","USD"]}}]}`,
+		"clock.avpr": `{"protocol":"Clock","namespace":"com.clock","types":[
+			{"type":"fixed","name":"Instant","size":8}],
+			"messages":{"now":{"request":[],"response":"Instant"}}}`,
+		"common.avdl": `@namespace("com.common") protocol Common {
+			import schema "money.avsc";
+			record Price { com.shared.Money value; }
+		}`,
+		"shop.avdl": `@namespace("com.shop") protocol Shop {
+			import idl "common.avdl";
+			import protocol "clock.avpr";
+			import schema "money.avsc"; // imported once
+			record Item { com.common.Price price; com.clock.Instant added; }
+			Item find(string name);
+		}`,
+	})
+	defer os.RemoveAll(dir)
+
+	f, err := ParseFile(filepath.Join(dir, "shop.avdl"))
+	ensureError(t, err)
+	if actual, expected := f.TypeNames(), []string{"com.shared.Currency", "com.shared.Money", "com.common.Price", "com.clock.Instant", "com.shop.Item"}; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+	if actual, expected := f.Protocol, `"messages":{"now":{"request":[],"response":"com.clock.Instant"},"find":{"request":[{"name":"name","type":"string"}],"response":"com.shop.Item"}}}`; !strings.HasSuffix(actual, expected) {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+	_, err = f.TypeCodec("com.shop.Item")
+	ensureError(t, err)
+
+	_, err = ParseFile(filepath.Join(dir, "missing.avdl"))
+	ensureError(t, err, "cannot read IDL file")
+}
+
+func TestParseErrorsHaveLocation(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"bad.avdl":    "protocol Bad {\n  record R { int }\n}",
+		"import.avdl": "protocol Import {\n  import idl \"bad.avdl\";\n  import schema \"missing.avsc\";\n}",
+	})
+	defer os.RemoveAll(dir)
+
+	_, err := ParseFile(filepath.Join(dir, "import.avdl"))
+	e, ok := err.(*Error)
+	if !ok {
+		t.Fatalf("GOT: %#v; WANT: %T", err, e)
+	}
+	if actual, expected := *e, (Error{Filename: filepath.Join(dir, "bad.avdl"), Line: 2, Column: 18, Message: `expected field name; found "}"`}); actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+	if actual, expected := e.Error(), filepath.Join(dir, "bad.avdl")+`:2:18: expected field name; found "}"`; actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+
+	ensureError(t, ioutil.WriteFile(filepath.Join(dir, "bad.avdl"), []byte("protocol Bad {}"), 0644))
+	_, err = ParseFile(filepath.Join(dir, "import.avdl"))
+	ensureError(t, err, "import.avdl:3:17: cannot read imported file")
+}
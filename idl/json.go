@@ -0,0 +1,155 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package idl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// NOTE: Schemas are built as JSON values whose objects keep the order of their
+// members, so the JSON text written for a schema reads in the same order as
+// the IDL it was compiled from: an object is an *object, an array is an
+// []interface{}, a number is a json.Number, and strings, booleans, and null
+// are what encoding/json decodes them as.
+
+// member is a member of a JSON object.
+type member struct {
+	key   string
+	value interface{}
+}
+
+// object is a JSON object that keeps the order of its members.
+type object struct {
+	members []member
+}
+
+func (o *object) get(key string) (interface{}, bool) {
+	for _, m := range o.members {
+		if m.key == key {
+			return m.value, true
+		}
+	}
+	return nil, false
+}
+
+func (o *object) getString(key string) string {
+	value, _ := o.get(key)
+	s, _ := value.(string)
+	return s
+}
+
+func getArray(o *object, key string) ([]interface{}, bool) {
+	value, _ := o.get(key)
+	a, ok := value.([]interface{})
+	return a, ok
+}
+
+// set replaces the value of the member with the key, or appends a member when
+// there is none.
+func (o *object) set(key string, value interface{}) {
+	for i, m := range o.members {
+		if m.key == key {
+			o.members[i].value = value
+			return
+		}
+	}
+	o.members = append(o.members, member{key, value})
+}
+
+func (o *object) remove(key string) {
+	for i, m := range o.members {
+		if m.key == key {
+			o.members = append(o.members[:i], o.members[i+1:]...)
+			return
+		}
+	}
+}
+
+// clone returns a shallow copy of the object.
+func (o *object) clone() *object {
+	return &object{members: append([]member(nil), o.members...)}
+}
+
+// MarshalJSON writes the members of the object in order.
+func (o *object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, m := range o.members {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(m.key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		value, err := json.Marshal(m.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// decodeJSON decodes JSON text, keeping the order of the members of objects.
+func decodeJSON(text []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(text))
+	decoder.UseNumber()
+	value, err := decodeValue(decoder)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("JSON text ought to have a single value")
+	}
+	return value, nil
+}
+
+func decodeValue(decoder *json.Decoder) (interface{}, error) {
+	t, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t {
+	case json.Delim('{'):
+		o := new(object)
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			o.set(key.(string), value)
+		}
+		_, err = decoder.Token() // closing brace
+		return o, err
+	case json.Delim('['):
+		a := []interface{}{}
+		for decoder.More() {
+			value, err := decodeValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			a = append(a, value)
+		}
+		_, err = decoder.Token() // closing bracket
+		return a, err
+	}
+	return t, nil
+}
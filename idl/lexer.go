@@ -0,0 +1,216 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package idl
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdentifier
+	tokenString
+	tokenNumber
+	tokenPunctuation
+)
+
+func (k tokenKind) String() string {
+	switch k {
+	case tokenEOF:
+		return "end of file"
+	case tokenIdentifier:
+		return "identifier"
+	case tokenString:
+		return "string"
+	case tokenNumber:
+		return "number"
+	}
+	return "punctuation"
+}
+
+// token is a lexical token of an IDL file. The text of a string token is its
+// value, without quotes or escapes.
+type token struct {
+	kind         tokenKind
+	text         string
+	line, column int
+	quoted       bool   // identifier was quoted with backticks
+	doc          string // documentation comment preceding the token
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return t.kind.String()
+	case tokenString:
+		return fmt.Sprintf("string %q", t.text)
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// lexer splits the text of an IDL file into tokens.
+type lexer struct {
+	filename     string
+	src          string
+	offset       int
+	line, column int
+}
+
+// tokenize returns the tokens of the text, ending with an end of file token.
+func tokenize(filename string, src []byte) ([]token, error) {
+	l := &lexer{filename: filename, src: string(src), line: 1, column: 1}
+	var tokens []token
+	for {
+		t, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, t)
+		if t.kind == tokenEOF {
+			return tokens, nil
+		}
+	}
+}
+
+func (l *lexer) errorf(line, column int, format string, a ...interface{}) error {
+	return &Error{Filename: l.filename, Line: line, Column: column, Message: fmt.Sprintf(format, a...)}
+}
+
+// advance moves past n bytes, keeping track of the line and column.
+func (l *lexer) advance(n int) {
+	for _, r := range l.src[l.offset : l.offset+n] {
+		if r == '\n' {
+			l.line++
+			l.column = 1
+		} else {
+			l.column++
+		}
+	}
+	l.offset += n
+}
+
+func (l *lexer) next() (token, error) {
+	var doc string
+	for {
+		// Skip white space and comments, remembering the last documentation
+		// comment.
+		for l.offset < len(l.src) && strings.IndexByte(" \t\r\n\f", l.src[l.offset]) > -1 {
+			l.advance(1)
+		}
+		rest := l.src[l.offset:]
+		if strings.HasPrefix(rest, "//") {
+			end := strings.IndexByte(rest, '\n')
+			if end == -1 {
+				end = len(rest)
+			}
+			l.advance(end)
+			continue
+		}
+		if strings.HasPrefix(rest, "/*") {
+			end := strings.Index(rest[2:], "*/")
+			if end == -1 {
+				return token{}, l.errorf(l.line, l.column, "comment ought to be terminated")
+			}
+			comment := rest[:end+4]
+			if strings.HasPrefix(comment, "/**") && comment != "/**/" {
+				doc = docFromComment(comment)
+			}
+			l.advance(len(comment))
+			continue
+		}
+		break
+	}
+
+	t := token{line: l.line, column: l.column, doc: doc}
+	if l.offset == len(l.src) {
+		t.kind = tokenEOF
+		return t, nil
+	}
+	rest := l.src[l.offset:]
+	c := rest[0]
+	switch {
+	case isIdentifierStart(c):
+		n := 1
+		for n < len(rest) && (isIdentifierPart(rest[n]) || rest[n] == '.' || rest[n] == '-') {
+			n++
+		}
+		t.kind, t.text = tokenIdentifier, rest[:n]
+		l.advance(n)
+	case c == '`':
+		end := strings.IndexByte(rest[1:], '`')
+		if end < 1 {
+			return token{}, l.errorf(t.line, t.column, "quoted identifier ought to be terminated and non-empty")
+		}
+		t.kind, t.text, t.quoted = tokenIdentifier, rest[1:end+1], true
+		l.advance(end + 2)
+	case c == '"':
+		n := 1
+		for n < len(rest) && rest[n] != '"' && rest[n] != '\n' {
+			if rest[n] == '\\' {
+				n++
+			}
+			n++
+		}
+		if n >= len(rest) || rest[n] != '"' {
+			return token{}, l.errorf(t.line, t.column, "string ought to be terminated")
+		}
+		if err := json.Unmarshal([]byte(rest[:n+1]), &t.text); err != nil {
+			return token{}, l.errorf(t.line, t.column, "string ought to be valid: %s", err)
+		}
+		t.kind = tokenString
+		l.advance(n + 1)
+	case c == '-' || isDigit(c):
+		n := 1
+		for n < len(rest) && (isDigit(rest[n]) || strings.IndexByte(".eE+-", rest[n]) > -1) {
+			n++
+		}
+		if !json.Valid([]byte(rest[:n])) {
+			return token{}, l.errorf(t.line, t.column, "number ought to be valid: %q", rest[:n])
+		}
+		t.kind, t.text = tokenNumber, rest[:n]
+		l.advance(n)
+	case strings.IndexByte("{}()[]<>,;=:@?", c) > -1:
+		t.kind, t.text = tokenPunctuation, rest[:1]
+		l.advance(1)
+	default:
+		return token{}, l.errorf(t.line, t.column, "unexpected character: %q", c)
+	}
+	return t, nil
+}
+
+// docFromComment returns the text of a documentation comment, without its
+// delimiters, and without the white space and asterisks that begin its lines.
+func docFromComment(comment string) string {
+	lines := strings.Split(comment[3:len(comment)-2], "\n")
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if i > 0 {
+			line = strings.TrimSpace(strings.TrimLeft(line, "*"))
+		}
+		lines[i] = line
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+func isIdentifierStart(c byte) bool {
+	return c == '_' || ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z')
+}
+
+func isIdentifierPart(c byte) bool {
+	return isIdentifierStart(c) || isDigit(c)
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
@@ -0,0 +1,53 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package idl
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tokens, err := tokenize("t.avdl", []byte("// comment\n/** Doc\n  * more. */ @java-class(\"a\\\"b\") `error` com.example.X -1.5e3;\n/* not doc */ x"))
+	ensureError(t, err)
+	expected := []token{
+		{kind: tokenPunctuation, text: "@", line: 3, column: 14, doc: "Doc\nmore."},
+		{kind: tokenIdentifier, text: "java-class", line: 3, column: 15},
+		{kind: tokenPunctuation, text: "(", line: 3, column: 25},
+		{kind: tokenString, text: `a"b`, line: 3, column: 26},
+		{kind: tokenPunctuation, text: ")", line: 3, column: 32},
+		{kind: tokenIdentifier, text: "error", line: 3, column: 34, quoted: true},
+		{kind: tokenIdentifier, text: "com.example.X", line: 3, column: 42},
+		{kind: tokenNumber, text: "-1.5e3", line: 3, column: 56},
+		{kind: tokenPunctuation, text: ";", line: 3, column: 62},
+		{kind: tokenIdentifier, text: "x", line: 4, column: 15},
+		{kind: tokenEOF, line: 4, column: 16},
+	}
+	if !reflect.DeepEqual(tokens, expected) {
+		t.Errorf("GOT: %v; WANT: %v", tokens, expected)
+	}
+}
+
+func TestTokenizeErrors(t *testing.T) {
+	cases := []struct {
+		src, expected string
+	}{
+		{"/* open", "t.avdl:1:1: comment ought to be terminated"},
+		{"x \"open", "t.avdl:1:3: string ought to be terminated"},
+		{"\"\\q\"", "t.avdl:1:1: string ought to be valid"},
+		{"``", "t.avdl:1:1: quoted identifier ought to be terminated and non-empty"},
+		{"1.2.3", `t.avdl:1:1: number ought to be valid: "1.2.3"`},
+		{"\n  #", `t.avdl:2:3: unexpected character: '#'`},
+	}
+	for _, c := range cases {
+		_, err := tokenize("t.avdl", []byte(c.src))
+		ensureError(t, err, c.expected)
+	}
+}
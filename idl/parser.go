@@ -0,0 +1,959 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package idl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/linkedin/goavro/v2"
+)
+
+var primitiveTypeNames = map[string]struct{}{
+	"null": {}, "boolean": {}, "int": {}, "long": {}, "float": {}, "double": {}, "bytes": {}, "string": {},
+}
+
+// logicalTypeKeywords maps the keywords of logical types to their schemas.
+var logicalTypeKeywords = map[string][2]string{
+	"date":               {"int", "date"},
+	"time_ms":            {"int", "time-millis"},
+	"timestamp_ms":       {"long", "timestamp-millis"},
+	"local_timestamp_ms": {"long", "local-timestamp-millis"},
+	"uuid":               {"string", "uuid"},
+}
+
+// annotation is a schema property declared with an at sign, such as
+// @namespace("com.example").
+type annotation struct {
+	name  string
+	value interface{}
+	at    token
+}
+
+// parser parses the tokens of one IDL file, adding the named types and
+// messages it declares to the compiler.
+type parser struct {
+	c         *compiler
+	filename  string
+	tokens    []token
+	pos       int
+	namespace string // namespace of the file, or of the protocol it declares
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) errorf(at token, format string, a ...interface{}) error {
+	return &Error{Filename: p.filename, Line: at.line, Column: at.column, Message: fmt.Sprintf(format, a...)}
+}
+
+// isKeyword returns true when the token is the keyword, rather than an
+// identifier quoted with backticks.
+func isKeyword(t token, keyword string) bool {
+	return t.kind == tokenIdentifier && !t.quoted && t.text == keyword
+}
+
+func isPunctuation(t token, text string) bool {
+	return t.kind == tokenPunctuation && t.text == text
+}
+
+// expect consumes the punctuation or keyword, or returns an error.
+func (p *parser) expect(text string) (token, error) {
+	t := p.next()
+	if isPunctuation(t, text) || isKeyword(t, text) {
+		return t, nil
+	}
+	return t, p.errorf(t, "expected %q; found %s", text, t)
+}
+
+// identifier consumes an identifier, which may be a dotted name, and is
+// described by what for error messages.
+func (p *parser) identifier(what string) (token, error) {
+	t := p.next()
+	if t.kind != tokenIdentifier {
+		return t, p.errorf(t, "expected %s; found %s", what, t)
+	}
+	return t, nil
+}
+
+// file parses the file, which either declares a protocol, or declares named
+// types using the schema syntax. It returns the protocol declaration, or nil
+// for the schema syntax.
+func (p *parser) file() (*object, interface{}, error) {
+	var mainSchema interface{}
+	for {
+		t := p.peek()
+		switch {
+		case t.kind == tokenEOF:
+			return nil, mainSchema, nil
+		case isKeyword(t, "namespace"):
+			p.next()
+			name, err := p.identifier("namespace")
+			if err != nil {
+				return nil, nil, err
+			}
+			p.namespace = name.text
+			if _, err = p.expect(";"); err != nil {
+				return nil, nil, err
+			}
+		case isKeyword(t, "schema"):
+			p.next()
+			var err error
+			if mainSchema, err = p.typeWithAnnotations(p.namespace); err != nil {
+				return nil, nil, err
+			}
+			if _, err = p.expect(";"); err != nil {
+				return nil, nil, err
+			}
+		case isKeyword(t, "import"):
+			if err := p.importFile(); err != nil {
+				return nil, nil, err
+			}
+		default:
+			annotations, err := p.annotations()
+			if err != nil {
+				return nil, nil, err
+			}
+			if isKeyword(p.peek(), "protocol") {
+				protocol, err := p.protocol(t.doc, annotations)
+				if err != nil {
+					return nil, nil, err
+				}
+				if end := p.peek(); end.kind != tokenEOF {
+					return nil, nil, p.errorf(end, "expected %s; found %s", tokenEOF, end)
+				}
+				return protocol, nil, nil
+			}
+			if err = p.namedType(t.doc, annotations); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+}
+
+// protocol parses a protocol declaration, after its annotations.
+func (p *parser) protocol(doc string, annotations []annotation) (*object, error) {
+	p.next() // protocol keyword
+	name, err := p.identifier("protocol name")
+	if err != nil {
+		return nil, err
+	}
+	protocol := &object{}
+	protocol.set("protocol", name.text)
+	var properties []annotation
+	for _, a := range annotations {
+		if a.name != "namespace" {
+			properties = append(properties, a)
+			continue
+		}
+		namespace, ok := a.value.(string)
+		if !ok {
+			return nil, p.errorf(a.at, "namespace ought to be a string")
+		}
+		p.namespace = namespace
+	}
+	if p.namespace != "" {
+		protocol.set("namespace", p.namespace)
+	}
+	if doc != "" {
+		protocol.set("doc", doc)
+	}
+	for _, a := range properties {
+		protocol.set(a.name, a.value)
+	}
+	if _, err = p.expect("{"); err != nil {
+		return nil, err
+	}
+	for !isPunctuation(p.peek(), "}") {
+		t := p.peek()
+		if isKeyword(t, "import") {
+			if err = p.importFile(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if t.kind == tokenEOF {
+			return nil, p.errorf(t, "expected %q; found %s", "}", t)
+		}
+		annotations, err := p.annotations()
+		if err != nil {
+			return nil, err
+		}
+		switch next := p.peek(); {
+		case isKeyword(next, "record"), isKeyword(next, "error"), isKeyword(next, "enum"), isKeyword(next, "fixed"):
+			err = p.namedType(t.doc, annotations)
+		default:
+			err = p.message(t.doc, annotations)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	p.next() // closing brace
+	return protocol, nil
+}
+
+// annotations parses zero or more annotations.
+func (p *parser) annotations() ([]annotation, error) {
+	var annotations []annotation
+	for isPunctuation(p.peek(), "@") {
+		at := p.next()
+		name, err := p.identifier("annotation name")
+		if err != nil {
+			return nil, err
+		}
+		if _, err = p.expect("("); err != nil {
+			return nil, err
+		}
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		if _, err = p.expect(")"); err != nil {
+			return nil, err
+		}
+		annotations = append(annotations, annotation{name: name.text, value: value, at: at})
+	}
+	return annotations, nil
+}
+
+// value parses a JSON value, such as a default value or the value of an
+// annotation.
+func (p *parser) value() (interface{}, error) {
+	t := p.next()
+	switch {
+	case t.kind == tokenString:
+		return t.text, nil
+	case t.kind == tokenNumber:
+		return json.Number(t.text), nil
+	case isKeyword(t, "true"):
+		return true, nil
+	case isKeyword(t, "false"):
+		return false, nil
+	case isKeyword(t, "null"):
+		return nil, nil
+	case isPunctuation(t, "["):
+		a := []interface{}{}
+		for !isPunctuation(p.peek(), "]") {
+			if len(a) > 0 {
+				if _, err := p.expect(","); err != nil {
+					return nil, err
+				}
+			}
+			value, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			a = append(a, value)
+		}
+		p.next() // closing bracket
+		return a, nil
+	case isPunctuation(t, "{"):
+		o := &object{}
+		for !isPunctuation(p.peek(), "}") {
+			if len(o.members) > 0 {
+				if _, err := p.expect(","); err != nil {
+					return nil, err
+				}
+			}
+			key := p.next()
+			if key.kind != tokenString {
+				return nil, p.errorf(key, "expected %s; found %s", tokenString, key)
+			}
+			if _, err := p.expect(":"); err != nil {
+				return nil, err
+			}
+			value, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			o.set(key.text, value)
+		}
+		p.next() // closing brace
+		return o, nil
+	}
+	return nil, p.errorf(t, "expected JSON value; found %s", t)
+}
+
+// namedType parses the declaration of a record, error, enum, or fixed type,
+// after its annotations.
+func (p *parser) namedType(doc string, annotations []annotation) error {
+	keyword := p.next()
+	var typeName string
+	switch {
+	case isKeyword(keyword, "record"), isKeyword(keyword, "error"), isKeyword(keyword, "enum"), isKeyword(keyword, "fixed"):
+		typeName = keyword.text
+	default:
+		return p.errorf(keyword, "expected record, error, enum, fixed, or protocol; found %s", keyword)
+	}
+	nameToken, err := p.identifier(typeName + " name")
+	if err != nil {
+		return err
+	}
+
+	namespace := p.namespace
+	var aliases interface{}
+	var properties []annotation
+	for _, a := range annotations {
+		switch a.name {
+		case "namespace":
+			s, ok := a.value.(string)
+			if !ok {
+				return p.errorf(a.at, "namespace ought to be a string")
+			}
+			namespace = s
+		case "aliases":
+			aliases = a.value
+		default:
+			properties = append(properties, a)
+		}
+	}
+	fullName := nameToken.text
+	if strings.IndexByte(fullName, '.') == -1 && namespace != "" {
+		fullName = namespace + "." + fullName
+	}
+	if err = goavro.CheckName(fullName); err != nil {
+		return p.errorf(nameToken, "%s ought to have valid name: %s", typeName, err)
+	}
+
+	schema := &object{}
+	schema.set("type", typeName)
+	schema.set("name", fullName)
+	if doc != "" {
+		schema.set("doc", doc)
+	}
+	if aliases != nil {
+		schema.set("aliases", aliases)
+	}
+
+	switch typeName {
+	case "enum":
+		err = p.enumBody(schema)
+	case "fixed":
+		err = p.fixedBody(schema)
+	default:
+		err = p.recordBody(schema, namespaceOf(fullName))
+	}
+	if err != nil {
+		return err
+	}
+	for _, a := range properties {
+		schema.set(a.name, a.value)
+	}
+	return p.c.declare(p, fullName, schema, nameToken)
+}
+
+func (p *parser) recordBody(schema *object, namespace string) error {
+	if _, err := p.expect("{"); err != nil {
+		return err
+	}
+	fields := []interface{}{}
+	for !isPunctuation(p.peek(), "}") {
+		if p.peek().kind == tokenEOF {
+			t := p.peek()
+			return p.errorf(t, "expected %q; found %s", "}", t)
+		}
+		declared, err := p.variables(namespace, ";")
+		if err != nil {
+			return err
+		}
+		fields = append(fields, declared...)
+	}
+	p.next() // closing brace
+	schema.set("fields", fields)
+	return nil
+}
+
+// variables parses a declaration of one or more record fields or message
+// parameters of the same type, ending with the terminator, which is not
+// consumed when it is a closing parenthesis.
+func (p *parser) variables(namespace, terminator string) ([]interface{}, error) {
+	doc := p.peek().doc
+	t, optional, err := p.annotatedType(namespace)
+	if err != nil {
+		return nil, err
+	}
+	var variables []interface{}
+	for {
+		variable, err := p.variable(doc, t, optional)
+		if err != nil {
+			return nil, err
+		}
+		variables = append(variables, variable)
+		if terminator == ";" && isPunctuation(p.peek(), ",") {
+			p.next()
+			continue
+		}
+		break
+	}
+	if terminator == ";" {
+		if _, err = p.expect(";"); err != nil {
+			return nil, err
+		}
+	}
+	return variables, nil
+}
+
+// variable parses the name of a record field or message parameter, along
+// with its annotations and default value.
+func (p *parser) variable(doc string, t interface{}, optional bool) (*object, error) {
+	if d := p.peek().doc; d != "" {
+		doc = d
+	}
+	annotations, err := p.annotations()
+	if err != nil {
+		return nil, err
+	}
+	name, err := p.identifier("field name")
+	if err != nil {
+		return nil, err
+	}
+	if strings.IndexByte(name.text, '.') > -1 {
+		return nil, p.errorf(name, "field name ought not to contain a dot: %q", name.text)
+	}
+	var defaultValue interface{}
+	var hasDefault bool
+	if isPunctuation(p.peek(), "=") {
+		p.next()
+		if defaultValue, err = p.value(); err != nil {
+			return nil, err
+		}
+		hasDefault = true
+	}
+	if optional && hasDefault && defaultValue != nil {
+		// NOTE: The default value of a union is of its first member, so an
+		// optional type whose default value is not null puts null last.
+		members := t.([]interface{})
+		t = []interface{}{members[1], members[0]}
+	}
+
+	field := &object{}
+	field.set("name", name.text)
+	field.set("type", t)
+	if doc != "" {
+		field.set("doc", doc)
+	}
+	if hasDefault {
+		field.set("default", defaultValue)
+	}
+	for _, a := range annotations {
+		field.set(a.name, a.value)
+	}
+	return field, nil
+}
+
+func (p *parser) enumBody(schema *object) error {
+	if _, err := p.expect("{"); err != nil {
+		return err
+	}
+	symbols := []interface{}{}
+	for !isPunctuation(p.peek(), "}") {
+		if len(symbols) > 0 {
+			if _, err := p.expect(","); err != nil {
+				return err
+			}
+		}
+		symbol, err := p.identifier("enum symbol")
+		if err != nil {
+			return err
+		}
+		if err = checkSymbol(symbol.text); err != nil {
+			return p.errorf(symbol, "enum symbol ought to be valid: %s", err)
+		}
+		symbols = append(symbols, symbol.text)
+	}
+	p.next() // closing brace
+	schema.set("symbols", symbols)
+	if isPunctuation(p.peek(), "=") {
+		p.next()
+		symbol, err := p.identifier("enum default symbol")
+		if err != nil {
+			return err
+		}
+		schema.set("default", symbol.text)
+		if _, err = p.expect(";"); err != nil {
+			return err
+		}
+	} else if isPunctuation(p.peek(), ";") {
+		p.next()
+	}
+	return nil
+}
+
+func checkSymbol(symbol string) error {
+	if strings.IndexByte(symbol, '.') > -1 {
+		return fmt.Errorf("symbol ought not to contain a dot: %q", symbol)
+	}
+	return goavro.CheckName(symbol)
+}
+
+func (p *parser) fixedBody(schema *object) error {
+	if _, err := p.expect("("); err != nil {
+		return err
+	}
+	size := p.next()
+	if size.kind != tokenNumber {
+		return p.errorf(size, "expected fixed size; found %s", size)
+	}
+	schema.set("size", json.Number(size.text))
+	if _, err := p.expect(")"); err != nil {
+		return err
+	}
+	_, err := p.expect(";")
+	return err
+}
+
+// message parses the declaration of a protocol message, after its
+// annotations.
+func (p *parser) message(doc string, annotations []annotation) error {
+	var response interface{}
+	if t := p.peek(); isKeyword(t, "void") {
+		p.next()
+		response = "null"
+	} else {
+		var err error
+		if response, err = p.typeWithAnnotations(p.namespace); err != nil {
+			return err
+		}
+	}
+	name, err := p.identifier("message name")
+	if err != nil {
+		return err
+	}
+	if _, err = p.expect("("); err != nil {
+		return err
+	}
+	request := []interface{}{}
+	for !isPunctuation(p.peek(), ")") {
+		if len(request) > 0 {
+			if _, err = p.expect(","); err != nil {
+				return err
+			}
+		}
+		parameters, err := p.variables(p.namespace, ")")
+		if err != nil {
+			return err
+		}
+		request = append(request, parameters...)
+	}
+	p.next() // closing parenthesis
+
+	message := &object{}
+	if doc != "" {
+		message.set("doc", doc)
+	}
+	message.set("request", request)
+	message.set("response", response)
+	switch t := p.peek(); {
+	case isKeyword(t, "oneway"):
+		p.next()
+		if response != "null" {
+			return p.errorf(t, "one-way message ought to return void: %q", name.text)
+		}
+		message.set("one-way", true)
+	case isKeyword(t, "throws"):
+		p.next()
+		var errors []interface{}
+		for {
+			errorName, err := p.identifier("error name")
+			if err != nil {
+				return err
+			}
+			errors = append(errors, p.c.reference(p, errorName, p.namespace))
+			if !isPunctuation(p.peek(), ",") {
+				break
+			}
+			p.next()
+		}
+		message.set("errors", errors)
+	}
+	if _, err = p.expect(";"); err != nil {
+		return err
+	}
+	for _, a := range annotations {
+		message.set(a.name, a.value)
+	}
+	if _, ok := p.c.messages.get(name.text); ok {
+		return p.errorf(name, "message ought to be declared once: %q", name.text)
+	}
+	p.c.messages.set(name.text, message)
+	return nil
+}
+
+// typeWithAnnotations parses a type that may be preceded by annotations, but
+// may not be optional.
+func (p *parser) typeWithAnnotations(namespace string) (interface{}, error) {
+	t := p.peek()
+	schema, optional, err := p.annotatedType(namespace)
+	if err != nil {
+		return nil, err
+	}
+	if optional {
+		return nil, p.errorf(t, "type ought not to be optional here")
+	}
+	return schema, nil
+}
+
+// annotatedType parses a type preceded by annotations, which are added to
+// the schema of the type, and returns whether the type is optional.
+func (p *parser) annotatedType(namespace string) (interface{}, bool, error) {
+	annotations, err := p.annotations()
+	if err != nil {
+		return nil, false, err
+	}
+	at := p.peek()
+	schema, err := p.typeSchema(namespace)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(annotations) > 0 {
+		switch s := schema.(type) {
+		case string:
+			o := &object{}
+			o.set("type", s)
+			schema = o
+		case *object:
+			schema = s.clone()
+		default:
+			return nil, false, p.errorf(annotations[0].at, "annotations ought to precede a primitive, array, or map type: %s", at)
+		}
+		for _, a := range annotations {
+			schema.(*object).set(a.name, a.value)
+		}
+	}
+	if isPunctuation(p.peek(), "?") {
+		p.next()
+		if _, ok := schema.([]interface{}); ok {
+			return nil, false, p.errorf(at, "union ought not to be optional")
+		}
+		return []interface{}{"null", schema}, true, nil
+	}
+	return schema, false, nil
+}
+
+// typeSchema parses a type, without its annotations.
+func (p *parser) typeSchema(namespace string) (interface{}, error) {
+	t, err := p.identifier("type")
+	if err != nil {
+		return nil, err
+	}
+	if t.quoted {
+		return p.c.reference(p, t, namespace), nil
+	}
+	if _, ok := primitiveTypeNames[t.text]; ok {
+		return t.text, nil
+	}
+	if schema, ok := logicalTypeKeywords[t.text]; ok {
+		o := &object{}
+		o.set("type", schema[0])
+		o.set("logicalType", schema[1])
+		return o, nil
+	}
+	switch t.text {
+	case "array", "map":
+		if _, err = p.expect("<"); err != nil {
+			return nil, err
+		}
+		element, err := p.typeWithAnnotations(namespace)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = p.expect(">"); err != nil {
+			return nil, err
+		}
+		o := &object{}
+		o.set("type", t.text)
+		if t.text == "array" {
+			o.set("items", element)
+		} else {
+			o.set("values", element)
+		}
+		return o, nil
+	case "union":
+		if _, err = p.expect("{"); err != nil {
+			return nil, err
+		}
+		members := []interface{}{}
+		for !isPunctuation(p.peek(), "}") {
+			if len(members) > 0 {
+				if _, err = p.expect(","); err != nil {
+					return nil, err
+				}
+			}
+			member, err := p.typeWithAnnotations(namespace)
+			if err != nil {
+				return nil, err
+			}
+			members = append(members, member)
+		}
+		p.next() // closing brace
+		return members, nil
+	case "decimal":
+		if _, err = p.expect("("); err != nil {
+			return nil, err
+		}
+		precision := p.next()
+		if precision.kind != tokenNumber {
+			return nil, p.errorf(precision, "expected decimal precision; found %s", precision)
+		}
+		if _, err = p.expect(","); err != nil {
+			return nil, err
+		}
+		scale := p.next()
+		if scale.kind != tokenNumber {
+			return nil, p.errorf(scale, "expected decimal scale; found %s", scale)
+		}
+		if _, err = p.expect(")"); err != nil {
+			return nil, err
+		}
+		o := &object{}
+		o.set("type", "bytes")
+		o.set("logicalType", "decimal")
+		o.set("precision", json.Number(precision.text))
+		o.set("scale", json.Number(scale.text))
+		return o, nil
+	}
+	return p.c.reference(p, t, namespace), nil
+}
+
+// importFile parses an import statement, and adds the named types and
+// messages of the imported file.
+func (p *parser) importFile() error {
+	p.next() // import keyword
+	kind, err := p.identifier("import kind")
+	if err != nil {
+		return err
+	}
+	if kind.text != "idl" && kind.text != "protocol" && kind.text != "schema" {
+		return p.errorf(kind, "import kind ought to be idl, protocol, or schema: %q", kind.text)
+	}
+	pathToken := p.next()
+	if pathToken.kind != tokenString {
+		return p.errorf(pathToken, "expected import file name; found %s", pathToken)
+	}
+	if _, err = p.expect(";"); err != nil {
+		return err
+	}
+
+	path := pathToken.text
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(p.filename), path)
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		if _, ok := p.c.imported[abs]; ok {
+			return nil
+		}
+		p.c.imported[abs] = struct{}{}
+	}
+	src, err := ioutil.ReadFile(path)
+	if err != nil {
+		return p.errorf(pathToken, "cannot read imported file: %s", err)
+	}
+
+	switch kind.text {
+	case "idl":
+		tokens, err := tokenize(path, src)
+		if err != nil {
+			return err
+		}
+		imported := &parser{c: p.c, filename: path, tokens: tokens}
+		_, _, err = imported.file()
+		return err
+	case "protocol":
+		return p.importProtocol(src, pathToken)
+	}
+	schema, err := decodeJSON(src)
+	if err != nil {
+		return p.errorf(pathToken, "cannot decode imported schema: %s", err)
+	}
+	_, err = p.importSchema(schema, "", pathToken)
+	return err
+}
+
+// importProtocol adds the named types and messages of a JSON protocol.
+func (p *parser) importProtocol(src []byte, at token) error {
+	value, err := decodeJSON(src)
+	if err != nil {
+		return p.errorf(at, "cannot decode imported protocol: %s", err)
+	}
+	protocol, ok := value.(*object)
+	if !ok {
+		return p.errorf(at, "imported protocol ought to be a JSON object")
+	}
+	namespace := protocol.getString("namespace")
+	if types, ok := protocol.get("types"); ok {
+		list, ok := types.([]interface{})
+		if !ok {
+			return p.errorf(at, "imported protocol types ought to be a JSON array")
+		}
+		for _, schema := range list {
+			if _, err = p.importSchema(schema, namespace, at); err != nil {
+				return err
+			}
+		}
+	}
+	messages, ok := protocol.get("messages")
+	if !ok {
+		return nil
+	}
+	messagesObject, ok := messages.(*object)
+	if !ok {
+		return p.errorf(at, "imported protocol messages ought to be a JSON object")
+	}
+	for _, m := range messagesObject.members {
+		message, ok := m.value.(*object)
+		if !ok {
+			return p.errorf(at, "imported protocol message ought to be a JSON object: %q", m.key)
+		}
+		message = message.clone()
+		if request, ok := getArray(message, "request"); ok {
+			parameters := make([]interface{}, len(request))
+			for i, parameter := range request {
+				if parameters[i], err = p.importField(parameter, namespace, at); err != nil {
+					return err
+				}
+			}
+			message.set("request", parameters)
+		}
+		if response, ok := message.get("response"); ok {
+			if response, err = p.importSchema(response, namespace, at); err != nil {
+				return err
+			}
+			message.set("response", response)
+		}
+		if errors, ok := getArray(message, "errors"); ok {
+			imported := make([]interface{}, len(errors))
+			for i, e := range errors {
+				if imported[i], err = p.importSchema(e, namespace, at); err != nil {
+					return err
+				}
+			}
+			message.set("errors", imported)
+		}
+		if _, ok := p.c.messages.get(m.key); ok {
+			return p.errorf(at, "message ought to be declared once: %q", m.key)
+		}
+		p.c.messages.set(m.key, message)
+	}
+	return nil
+}
+
+// importSchema returns the JSON schema with each named type it defines
+// declared, and replaced by a reference to it.
+func (p *parser) importSchema(schema interface{}, namespace string, at token) (interface{}, error) {
+	switch s := schema.(type) {
+	case string:
+		if _, ok := primitiveTypeNames[s]; ok {
+			return s, nil
+		}
+		return p.c.reference(p, token{kind: tokenIdentifier, text: s, line: at.line, column: at.column}, namespace), nil
+	case []interface{}:
+		members := make([]interface{}, len(s))
+		for i, member := range s {
+			var err error
+			if members[i], err = p.importSchema(member, namespace, at); err != nil {
+				return nil, err
+			}
+		}
+		return members, nil
+	case *object:
+		typeName, _ := s.get("type")
+		switch typeName {
+		case "record", "error", "enum", "fixed":
+			fullName := s.getString("name")
+			if strings.IndexByte(fullName, '.') == -1 {
+				if ns := s.getString("namespace"); ns != "" {
+					fullName = ns + "." + fullName
+				} else if namespace != "" {
+					fullName = namespace + "." + fullName
+				}
+			}
+			if err := goavro.CheckName(fullName); err != nil {
+				return nil, p.errorf(at, "imported %s ought to have valid name: %s", typeName, err)
+			}
+			declared := s.clone()
+			declared.set("name", fullName)
+			declared.remove("namespace")
+			if fields, ok := s.get("fields"); ok {
+				list, ok := fields.([]interface{})
+				if !ok {
+					return nil, p.errorf(at, "imported record fields ought to be a JSON array: %q", fullName)
+				}
+				imported := make([]interface{}, len(list))
+				for i, field := range list {
+					var err error
+					if imported[i], err = p.importField(field, namespaceOf(fullName), at); err != nil {
+						return nil, err
+					}
+				}
+				declared.set("fields", imported)
+			}
+			if err := p.c.declare(p, fullName, declared, at); err != nil {
+				return nil, err
+			}
+			return &typeRef{resolved: fullName}, nil
+		case "array", "map":
+			key := "items"
+			if typeName == "map" {
+				key = "values"
+			}
+			element, _ := s.get(key)
+			element, err := p.importSchema(element, namespace, at)
+			if err != nil {
+				return nil, err
+			}
+			imported := s.clone()
+			imported.set(key, element)
+			return imported, nil
+		}
+		if nested, ok := typeName.(*object); ok {
+			return p.importSchema(nested, namespace, at)
+		}
+		return s, nil
+	}
+	return nil, p.errorf(at, "imported schema ought to be a JSON string, array, or object: %T", schema)
+}
+
+// importField returns a JSON record field or message parameter, with the named
+// types of its type declared.
+func (p *parser) importField(field interface{}, namespace string, at token) (interface{}, error) {
+	o, ok := field.(*object)
+	if !ok {
+		return nil, p.errorf(at, "imported field ought to be a JSON object")
+	}
+	schema, _ := o.get("type")
+	schema, err := p.importSchema(schema, namespace, at)
+	if err != nil {
+		return nil, err
+	}
+	imported := o.clone()
+	imported.set("type", schema)
+	return imported, nil
+}
+
+// namespaceOf returns the namespace of the full name.
+func namespaceOf(fullName string) string {
+	if index := strings.LastIndexByte(fullName, '.'); index > -1 {
+		return fullName[:index]
+	}
+	return ""
+}
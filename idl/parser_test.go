@@ -0,0 +1,43 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package idl
+
+import "testing"
+
+func TestParseErrors(t *testing.T) {
+	cases := []struct {
+		src, expected string
+	}{
+		{"protocol P {", `t.avdl:1:13: expected "}"; found end of file`},
+		{"protocol P {} x", `t.avdl:1:15: expected end of file; found "x"`},
+		{"protocol P { record R { int a } }", `t.avdl:1:31: expected ";"; found "}"`},
+		{"protocol P { record 1R {} }", `t.avdl:1:21: expected record name; found "1"`},
+		{"protocol P { record R {} record R {} }", `t.avdl:1:33: type ought to be declared once: "R"`},
+		{"protocol P { record R { S s; } }", `t.avdl:1:25: type ought to be declared: "S"`},
+		{"protocol P { record R { int a.b; } }", `t.avdl:1:29: field name ought not to contain a dot: "a.b"`},
+		{"protocol P { enum E { A, A.B } }", `t.avdl:1:26: enum symbol ought to be valid`},
+		{"protocol P { fixed F(x); }", `t.avdl:1:22: expected fixed size; found "x"`},
+		{"protocol P { int m() oneway; }", `t.avdl:1:22: one-way message ought to return void: "m"`},
+		{"protocol P { void m(); void m(); }", `t.avdl:1:29: message ought to be declared once: "m"`},
+		{"protocol P { void m() throws E; }", `t.avdl:1:30: type ought to be declared: "E"`},
+		{"protocol P { record R { @foo(1) union { null, int } u; } }", `t.avdl:1:25: annotations ought to precede a primitive, array, or map type`},
+		{"protocol P { record R { union { null, int }? u; } }", `t.avdl:1:25: union ought not to be optional`},
+		{"protocol P { record R { array<int?> a; } }", `t.avdl:1:31: type ought not to be optional here`},
+		{"protocol P { record R { int a = ; } }", `t.avdl:1:33: expected JSON value; found ";"`},
+		{"@namespace(1) protocol P {}", `t.avdl:1:1: namespace ought to be a string`},
+		{"protocol P { import avro \"x\"; }", `t.avdl:1:21: import kind ought to be idl, protocol, or schema: "avro"`},
+		{"schema int?;", `t.avdl:1:8: type ought not to be optional here`},
+		{"interface P {}", `t.avdl:1:1: expected record, error, enum, fixed, or protocol; found "interface"`},
+	}
+	for _, c := range cases {
+		_, err := Parse("t.avdl", []byte(c.src))
+		ensureError(t, err, c.expected)
+	}
+}
@@ -0,0 +1,367 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package goavro
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// InferSchema reads a stream of JSON values from r, such as newline delimited
+// JSON, and returns the narrowest Avro schema that accepts every value. The
+// provided name is used as the full name of the record inferred from JSON
+// objects at the top level. See SchemaInferrer for the inference rules.
+func InferSchema(r io.Reader, name string) (string, error) {
+	si := NewSchemaInferrer(name)
+	if err := si.Infer(r); err != nil {
+		return "", err
+	}
+	return si.Schema()
+}
+
+// SchemaInferrer accumulates the shapes of JSON values, and infers the
+// narrowest Avro schema that accepts all of them.
+//
+// JSON numbers become int when every sample is an integer that fits in 32
+// bits, long when every sample is an integer that fits in 64 bits, and double
+// otherwise. JSON objects become records, whose fields are the union of the
+// object keys of every sample, in the order they were first seen. Record fields
+// that are null or missing in some samples become unions of null and the
+// inferred type, with a default value of null. Nested records are named after
+// the record field that contains them, in a namespace named after the enclosing
+// record. When samples of one value have different JSON types, the value
+// becomes a union of the inferred types.
+//
+// The inferred schema decodes every sample using a Codec created by
+// NewCodecForStandardJSONFull.
+type SchemaInferrer struct {
+	name  string
+	root  *inferredType
+	count int
+}
+
+// NewSchemaInferrer returns a SchemaInferrer that uses the provided name as the
+// full name of the record inferred from JSON objects at the top level.
+func NewSchemaInferrer(name string) *SchemaInferrer {
+	return &SchemaInferrer{name: name, root: new(inferredType)}
+}
+
+// Infer reads a stream of JSON values from r until EOF, and widens the
+// inferred schema to accept each of them.
+func (si *SchemaInferrer) Infer(r io.Reader) error {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err == nil {
+			err = si.root.add(decoder, token)
+		}
+		if err != nil {
+			return fmt.Errorf("cannot infer schema from JSON value %d: %s", si.count+1, err)
+		}
+		si.count++
+	}
+}
+
+// Schema returns the Avro schema inferred from the JSON values read so far.
+func (si *SchemaInferrer) Schema() (string, error) {
+	if si.count == 0 {
+		return "", fmt.Errorf("cannot infer schema without any JSON values")
+	}
+	var namespace string
+	name := si.name
+	if index := strings.LastIndexByte(name, '.'); index > -1 {
+		namespace, name = name[:index], name[index+1:]
+	}
+	e := &inferredSchemaEmitter{names: make(map[string]bool)}
+	if err := e.emit(si.root, name, namespace, ""); err != nil {
+		return "", fmt.Errorf("cannot infer schema: %s", err)
+	}
+	schema := e.buf.String()
+	if _, err := NewCodecForStandardJSONFull(schema); err != nil {
+		return "", fmt.Errorf("cannot infer schema: %s", err)
+	}
+	return schema, nil
+}
+
+// Ranks of the numeric types, from narrowest to widest.
+const (
+	inferredNone = iota
+	inferredInt
+	inferredLong
+	inferredDouble
+)
+
+// inferredType is the set of JSON types seen at one position of the samples.
+type inferredType struct {
+	null, boolean, str bool
+	number             int           // rank of the widest number seen
+	items              *inferredType // non-nil once an array is seen
+	record             *inferredRecord
+}
+
+type inferredRecord struct {
+	count  int // number of objects seen
+	names  []string
+	fields map[string]*inferredField
+}
+
+type inferredField struct {
+	count int // number of objects that have the field
+	typ   *inferredType
+}
+
+// add widens the inferred type to accept the JSON value that starts with the
+// provided token.
+//
+// NOTE: JSON values are read token by token, rather than unmarshaled, to keep
+// the order of object keys.
+func (t *inferredType) add(decoder *json.Decoder, token json.Token) error {
+	switch v := token.(type) {
+	case nil:
+		t.null = true
+	case bool:
+		t.boolean = true
+	case string:
+		t.str = true
+	case json.Number:
+		if rank := numberRank(v); rank > t.number {
+			t.number = rank
+		}
+	case json.Delim:
+		if v == '[' {
+			if t.items == nil {
+				t.items = new(inferredType)
+			}
+			return t.items.addArray(decoder)
+		}
+		if t.record == nil {
+			t.record = &inferredRecord{fields: make(map[string]*inferredField)}
+		}
+		return t.record.add(decoder)
+	}
+	return nil
+}
+
+// addArray widens the inferred type of the items to accept every item of a
+// JSON array, whose opening bracket has been read.
+func (t *inferredType) addArray(decoder *json.Decoder) error {
+	for decoder.More() {
+		token, err := nextToken(decoder)
+		if err != nil {
+			return err
+		}
+		if err = t.add(decoder, token); err != nil {
+			return err
+		}
+	}
+	_, err := nextToken(decoder) // closing bracket
+	return err
+}
+
+// add widens the inferred record to accept a JSON object, whose opening brace
+// has been read.
+func (r *inferredRecord) add(decoder *json.Decoder) error {
+	r.count++
+	seen := make(map[string]bool)
+	for decoder.More() {
+		token, err := nextToken(decoder)
+		if err != nil {
+			return err
+		}
+		name := token.(string) // object keys are always strings
+		field, ok := r.fields[name]
+		if !ok {
+			field = &inferredField{typ: new(inferredType)}
+			r.fields[name] = field
+			r.names = append(r.names, name)
+		}
+		if !seen[name] {
+			seen[name] = true
+			field.count++
+		}
+		if token, err = nextToken(decoder); err != nil {
+			return err
+		}
+		if err = field.typ.add(decoder, token); err != nil {
+			return err
+		}
+	}
+	_, err := nextToken(decoder) // closing brace
+	return err
+}
+
+// nextToken returns the next token from within a JSON value.
+func nextToken(decoder *json.Decoder) (json.Token, error) {
+	token, err := decoder.Token()
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return token, err
+}
+
+// numberRank returns the rank of the narrowest numeric type that represents
+// the JSON number.
+func numberRank(n json.Number) int {
+	i, err := strconv.ParseInt(n.String(), 10, 64)
+	switch {
+	case err != nil:
+		return inferredDouble
+	case i < math.MinInt32 || i > math.MaxInt32:
+		return inferredLong
+	}
+	return inferredInt
+}
+
+type inferredSchemaEmitter struct {
+	buf   bytes.Buffer
+	names map[string]bool // full names of the records already defined
+}
+
+func (e *inferredSchemaEmitter) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&e.buf, format, args...)
+}
+
+// quote writes s as a JSON string.
+func (e *inferredSchemaEmitter) quote(s string) {
+	encoded, _ := json.Marshal(s) // marshaling a string cannot fail
+	e.buf.Write(encoded)
+}
+
+// emit writes the schema of the inferred type, using name and namespace for the
+// record inferred from JSON objects.
+func (e *inferredSchemaEmitter) emit(t *inferredType, name, namespace, enclosingNamespace string) error {
+	members := t.members()
+	if len(members) == 0 {
+		// NOTE: Only empty arrays were seen for the items of an array.
+		e.quote("null")
+		return nil
+	}
+	if len(members) > 1 {
+		e.printf("[")
+	}
+	for i, member := range members {
+		if i > 0 {
+			e.printf(",")
+		}
+		var err error
+		switch member {
+		case "array":
+			e.printf(`{"type":"array","items":`)
+			if err = e.emit(t.items, name, namespace, enclosingNamespace); err != nil {
+				return err
+			}
+			e.printf("}")
+		case "record":
+			err = e.record(t.record, name, namespace, enclosingNamespace)
+		default:
+			e.quote(member)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if len(members) > 1 {
+		e.printf("]")
+	}
+	return nil
+}
+
+// members returns the names of the Avro types of the inferred type, with null
+// first so it may be the default value.
+func (t *inferredType) members() []string {
+	var members []string
+	if t.null {
+		members = append(members, "null")
+	}
+	if t.boolean {
+		members = append(members, "boolean")
+	}
+	switch t.number {
+	case inferredInt:
+		members = append(members, "int")
+	case inferredLong:
+		members = append(members, "long")
+	case inferredDouble:
+		members = append(members, "double")
+	}
+	if t.str {
+		members = append(members, "string")
+	}
+	if t.items != nil {
+		members = append(members, "array")
+	}
+	if t.record != nil {
+		members = append(members, "record")
+	}
+	return members
+}
+
+func (e *inferredSchemaEmitter) record(r *inferredRecord, name, namespace, enclosingNamespace string) error {
+	if err := checkNameComponent(name); err != nil {
+		return err
+	}
+	// NOTE: An array of objects and an object may be seen at the same
+	// position, and their records ought to have different names.
+	base := name
+	fullName := qualifiedName(namespace, name)
+	for i := 2; e.names[fullName]; i++ {
+		name = base + strconv.Itoa(i)
+		fullName = qualifiedName(namespace, name)
+	}
+	e.names[fullName] = true
+
+	e.printf(`{"type":"record","name":`)
+	e.quote(name)
+	if namespace != enclosingNamespace {
+		e.printf(`,"namespace":`)
+		e.quote(namespace)
+	}
+	e.printf(`,"fields":[`)
+	for i, fieldName := range r.names {
+		if err := checkNameComponent(fieldName); err != nil {
+			return fmt.Errorf("record %q field: %s", fullName, err)
+		}
+		field := r.fields[fieldName]
+		if field.count < r.count {
+			field.typ.null = true // missing from some objects
+		}
+		if i > 0 {
+			e.printf(",")
+		}
+		e.printf(`{"name":`)
+		e.quote(fieldName)
+		e.printf(`,"type":`)
+		if err := e.emit(field.typ, fieldName, fullName, namespace); err != nil {
+			return err
+		}
+		if field.typ.null {
+			e.printf(`,"default":null`)
+		}
+		e.printf("}")
+	}
+	e.printf("]}")
+	return nil
+}
+
+func qualifiedName(namespace, name string) string {
+	if namespace == "" {
+		return name
+	}
+	return namespace + "." + name
+}
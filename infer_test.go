@@ -0,0 +1,127 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package goavro
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func ExampleInferSchema() {
+	samples := `{"id": 1, "name": "Bob", "address": {"city": "Boston"}}
+{"id": 3000000000, "name": null, "address": {"city": "Austin", "zip": 73301}}
+{"id": 3, "score": 1.5}`
+
+	schema, err := InferSchema(strings.NewReader(samples), "com.example.User")
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(schema)
+	// Output: {"type":"record","name":"User","namespace":"com.example","fields":[{"name":"id","type":"long"},{"name":"name","type":["null","string"],"default":null},{"name":"address","type":["null",{"type":"record","name":"address","namespace":"com.example.User","fields":[{"name":"city","type":"string"},{"name":"zip","type":["null","int"],"default":null}]}],"default":null},{"name":"score","type":["null","double"],"default":null}]}
+}
+
+func testInferSchema(t *testing.T, samples []string, expected string) {
+	t.Helper()
+	schema, err := InferSchema(strings.NewReader(strings.Join(samples, "\n")), "Record")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if schema != expected {
+		t.Errorf("GOT: %s; WANT: %s", schema, expected)
+	}
+	codec, err := NewCodecForStandardJSONFull(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, sample := range samples {
+		if _, _, err = codec.NativeFromTextual([]byte(sample)); err != nil {
+			t.Errorf("%s: %s", sample, err)
+		}
+	}
+}
+
+func TestInferSchemaPrimitives(t *testing.T) {
+	testInferSchema(t, []string{`true`, `false`}, `"boolean"`)
+	testInferSchema(t, []string{`"a"`}, `"string"`)
+	testInferSchema(t, []string{`null`}, `"null"`)
+	testInferSchema(t, []string{`1`, `-2147483648`}, `"int"`)
+	testInferSchema(t, []string{`1`, `2147483648`}, `"long"`)
+	testInferSchema(t, []string{`1`, `9223372036854775808`}, `"double"`)
+	testInferSchema(t, []string{`1`, `2147483648`, `1.5`}, `"double"`)
+	testInferSchema(t, []string{`1e3`}, `"double"`)
+	testInferSchema(t, []string{`"a"`, `1`, `null`, `true`}, `["null","boolean","int","string"]`)
+}
+
+func TestInferSchemaArrays(t *testing.T) {
+	testInferSchema(t, []string{`[]`}, `{"type":"array","items":"null"}`)
+	testInferSchema(t, []string{`[]`, `[1, null]`}, `{"type":"array","items":["null","int"]}`)
+	testInferSchema(t, []string{`[[1], []]`}, `{"type":"array","items":{"type":"array","items":"int"}}`)
+	testInferSchema(t, []string{`[{"a": 1}, {"b": "x"}]`}, `{"type":"array","items":{"type":"record","name":"Record","fields":[{"name":"a","type":["null","int"],"default":null},{"name":"b","type":["null","string"],"default":null}]}}`)
+}
+
+func TestInferSchemaRecords(t *testing.T) {
+	// fields keep the order of the JSON objects
+	testInferSchema(t, []string{`{"z": 1, "a": 2}`, `{"m": 3, "a": 4, "z": 5}`},
+		`{"type":"record","name":"Record","fields":[{"name":"z","type":"int"},{"name":"a","type":"int"},{"name":"m","type":["null","int"],"default":null}]}`)
+
+	// nested records are named after their fields
+	testInferSchema(t, []string{`{"a": {"b": {"c": true}}}`, `{"a": {"b": null}}`},
+		`{"type":"record","name":"Record","fields":[{"name":"a","type":{"type":"record","name":"a","namespace":"Record","fields":[{"name":"b","type":["null",{"type":"record","name":"b","namespace":"Record.a","fields":[{"name":"c","type":"boolean"}]}],"default":null}]}}]}`)
+
+	// objects and arrays of objects at the same position have different names
+	testInferSchema(t, []string{`{"a": {"b": 1}}`, `{"a": [{"c": 2}]}`},
+		`{"type":"record","name":"Record","fields":[{"name":"a","type":[{"type":"array","items":{"type":"record","name":"a","namespace":"Record","fields":[{"name":"c","type":"int"}]}},{"type":"record","name":"a2","namespace":"Record","fields":[{"name":"b","type":"int"}]}]}]}`)
+
+	// a field that is only ever null or missing
+	testInferSchema(t, []string{`{"a": null}`, `{}`},
+		`{"type":"record","name":"Record","fields":[{"name":"a","type":"null","default":null}]}`)
+}
+
+func TestInferSchemaErrors(t *testing.T) {
+	_, err := InferSchema(strings.NewReader(""), "Record")
+	ensureError(t, err, "without any JSON values")
+
+	_, err = InferSchema(strings.NewReader(`{"a": 1} {"a": }`), "Record")
+	ensureError(t, err, "JSON value 2")
+
+	_, err = InferSchema(strings.NewReader(`{"a": [1, 2`), "Record")
+	ensureError(t, err, "unexpected end of JSON input")
+
+	_, err = InferSchema(strings.NewReader(`{"first-name": "Bob"}`), "Record")
+	ensureError(t, err, `record "Record" field`, "first-name")
+
+	_, err = InferSchema(strings.NewReader(`{}`), "")
+	ensureError(t, err, "cannot infer schema")
+}
+
+func TestSchemaInferrerIncremental(t *testing.T) {
+	si := NewSchemaInferrer("Record")
+	if err := si.Infer(strings.NewReader(`{"a": 1}`)); err != nil {
+		t.Fatal(err)
+	}
+	schema, err := si.Schema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"type":"record","name":"Record","fields":[{"name":"a","type":"int"}]}`; schema != expected {
+		t.Errorf("GOT: %s; WANT: %s", schema, expected)
+	}
+
+	if err = si.Infer(strings.NewReader(`{"a": 1.5, "b": "x"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if schema, err = si.Schema(); err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"type":"record","name":"Record","fields":[{"name":"a","type":"double"},{"name":"b","type":["null","string"],"default":null}]}`; schema != expected {
+		t.Errorf("GOT: %s; WANT: %s", schema, expected)
+	}
+}
@@ -41,10 +41,24 @@ func intNativeFromBinary(buf []byte) (interface{}, []byte, error) {
 }
 
 func longNativeFromBinary(buf []byte) (interface{}, []byte, error) {
+	value, buf, err := longFromBinary(buf)
+	if err != nil {
+		return nil, nil, err
+	}
+	return value, buf, nil
+}
+
+// maxVarintLength is the number of bytes of the longest variable length
+// zig-zag encoded long.
+const maxVarintLength = 10
+
+// longFromBinary decodes a binary long without converting it to an empty
+// interface, which would allocate for most values.
+func longFromBinary(buf []byte) (int64, []byte, error) {
 	var offset int
 	var value uint64
 	var shift uint
-	for offset = 0; offset < len(buf); offset++ {
+	for offset = 0; offset < len(buf) && offset < maxVarintLength; offset++ {
 		b := buf[offset]
 		value |= uint64(b&intMask) << shift
 		if b&intFlag == 0 {
@@ -52,7 +66,24 @@ func longNativeFromBinary(buf []byte) (interface{}, []byte, error) {
 		}
 		shift += 7
 	}
-	return nil, nil, io.ErrShortBuffer
+	if offset == maxVarintLength {
+		return 0, nil, fmt.Errorf("cannot decode binary long: value overflows long")
+	}
+	return 0, nil, io.ErrShortBuffer
+}
+
+// varintSkipBinary skips a binary int or long, both of which are encoded as
+// variable length zig-zag integers.
+func varintSkipBinary(buf []byte) ([]byte, error) {
+	for offset := 0; offset < len(buf); offset++ {
+		if buf[offset]&intFlag == 0 {
+			return buf[offset+1:], nil
+		}
+		if offset == maxVarintLength-1 {
+			return nil, fmt.Errorf("cannot decode binary long: value overflows long")
+		}
+	}
+	return nil, io.ErrShortBuffer
 }
 
 ////////////////////////////////////////
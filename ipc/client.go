@@ -0,0 +1,210 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package ipc
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/linkedin/goavro/v2"
+)
+
+// transport sends requests to a Server and receives its responses.
+type transport interface {
+	// roundTrip sends the request, and returns the response, or nil without
+	// reading a response when expectResponse is false.
+	roundTrip(request []byte, expectResponse bool) ([]byte, error)
+
+	// stateful returns true when the handshake is exchanged once, before the
+	// first call, rather than before every call.
+	stateful() bool
+}
+
+// Client calls the messages of a protocol on a Server. It is safe to use a
+// Client from multiple goroutines, although calls made using the stream
+// transport are sent one at a time, while calls made using the HTTP transport
+// are sent concurrently.
+type Client struct {
+	protocol  *goavro.Protocol
+	transport transport
+	callMu    sync.Mutex // held during calls on a stateful transport
+
+	mu           sync.Mutex // guards the state of the handshake
+	remote       *remote    // protocol of the server, which is assumed to be the same until known
+	serverHash   []byte
+	sendProtocol bool // whether to send the protocol of the client with the handshake
+	connected    bool // whether the handshake is done on a stateful transport
+}
+
+// NewClient returns a Client that calls the messages of the protocol on the
+// Server at the other end of the connection, such as a net.Conn, using the
+// stream transport. The caller is responsible for closing the connection.
+func NewClient(conn io.ReadWriter, protocol *goavro.Protocol) *Client {
+	return newClient(&streamTransport{conn}, protocol)
+}
+
+func newClient(t transport, protocol *goavro.Protocol) *Client {
+	return &Client{
+		protocol:   protocol,
+		transport:  t,
+		remote:     newRemote(protocol),
+		serverHash: protocol.MD5(),
+	}
+}
+
+// Call calls the message with the name, given its native request, which is a
+// record whose fields are the parameters of the message, and returns the
+// native response. It returns an *Error when the Server responds with an
+// error, and a nil response without waiting for the Server when the message is
+// one-way and the handshake is done.
+func (c *Client) Call(message string, request interface{}) (interface{}, error) {
+	response, _, err := c.CallWithMeta(message, nil, request)
+	return response, err
+}
+
+// CallWithMeta calls the message with the name like Call, sending the call
+// metadata, and also returns the metadata of the response.
+func (c *Client) CallWithMeta(message string, meta map[string][]byte, request interface{}) (interface{}, map[string][]byte, error) {
+	m, ok := c.protocol.Message(message)
+	if !ok {
+		return nil, nil, fmt.Errorf("cannot call message: protocol has no message %q", message)
+	}
+	call, err := appendMeta(nil, meta)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot write call metadata: %s", err)
+	}
+	if call, err = stringCodec.BinaryFromNative(call, message); err != nil {
+		return nil, nil, fmt.Errorf("cannot write message name: %s", err)
+	}
+	if call, err = m.Request().BinaryFromNative(call, request); err != nil {
+		return nil, nil, fmt.Errorf("cannot write request of message %q: %s", message, err)
+	}
+
+	if c.transport.stateful() {
+		// NOTE: Calls on a connection are sent one at a time, so the first of
+		// them does the handshake, and their responses are read in order.
+		c.callMu.Lock()
+		defer c.callMu.Unlock()
+	}
+	for attempt := 0; ; attempt++ {
+		var buf []byte
+		c.mu.Lock()
+		connected := c.connected
+		if !connected {
+			handshake := &handshakeRequest{clientHash: c.protocol.MD5(), serverHash: c.serverHash}
+			if c.sendProtocol {
+				handshake.clientProtocol = c.protocol.Text()
+			}
+			buf, err = handshake.appendBinary(nil)
+		}
+		c.mu.Unlock()
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot write handshake request: %s", err)
+		}
+		expectResponse := !(connected && m.OneWay())
+		response, err := c.transport.roundTrip(append(buf, call...), expectResponse)
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot call message %q: %s", message, err)
+		}
+		if !expectResponse {
+			return nil, nil, nil
+		}
+		if !connected {
+			handshake, rest, err := readHandshakeResponse(response)
+			if err != nil {
+				return nil, nil, err
+			}
+			c.mu.Lock()
+			err = c.handshake(handshake)
+			c.mu.Unlock()
+			if err != nil {
+				return nil, nil, err
+			}
+			if handshake.match == matchNone {
+				if attempt > 0 {
+					return nil, nil, fmt.Errorf("cannot call message %q: server does not accept client protocol", message)
+				}
+				continue // send again with the protocol of the client
+			}
+			response = rest
+		}
+		c.mu.Lock()
+		r := c.remote
+		c.mu.Unlock()
+		return readResponse(r, m, response)
+	}
+}
+
+// handshake records what the handshake response tells about the protocol of
+// the Server. The caller ought to hold c.mu.
+func (c *Client) handshake(response *handshakeResponse) error {
+	if response.serverProtocol != "" {
+		protocol, err := goavro.NewProtocol(response.serverProtocol)
+		if err != nil {
+			return fmt.Errorf("cannot read server protocol: %s", err)
+		}
+		c.remote = newRemote(protocol)
+	}
+	if response.serverHash != nil {
+		c.serverHash = response.serverHash
+	}
+	c.sendProtocol = response.match == matchNone
+	c.connected = response.match != matchNone && c.transport.stateful()
+	return nil
+}
+
+// readResponse returns the native response, and the response metadata, read
+// from buf, whose response or error is written using the protocol of the
+// Server.
+func readResponse(r *remote, m *goavro.Message, buf []byte) (interface{}, map[string][]byte, error) {
+	meta, buf, err := readMeta(buf)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot read response metadata: %s", err)
+	}
+	if len(buf) == 0 {
+		return nil, nil, fmt.Errorf("cannot read response of message %q: %s", m.Name(), io.ErrShortBuffer)
+	}
+	part := "response"
+	if buf[0] != 0 {
+		part = "errors"
+	}
+	reader, err := r.reader(m, part)
+	if err != nil {
+		return nil, nil, err
+	}
+	datum, _, err := reader.NativeFromBinary(buf[1:])
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot read %s of message %q: %s", part, m.Name(), err)
+	}
+	if part == "errors" {
+		typeName, value := unionValue(datum)
+		return nil, meta, &Error{Type: typeName, Value: value}
+	}
+	return datum, meta, nil
+}
+
+// streamTransport sends requests over a connection, on which the handshake is
+// exchanged once.
+type streamTransport struct {
+	conn io.ReadWriter
+}
+
+func (t *streamTransport) stateful() bool { return true }
+
+func (t *streamTransport) roundTrip(request []byte, expectResponse bool) ([]byte, error) {
+	if err := writeFrames(t.conn, request); err != nil {
+		return nil, err
+	}
+	if !expectResponse {
+		return nil, nil
+	}
+	return readFrames(t.conn)
+}
@@ -0,0 +1,277 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package ipc
+
+import (
+	"errors"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/linkedin/goavro/v2"
+)
+
+const testProtocol = `{"protocol":"Mail","namespace":"com.example","types":[
+	{"type":"record","name":"Message","fields":[{"name":"to","type":"string"},{"name":"body","type":"string"}]},
+	{"type":"error","name":"Bounced","fields":[{"name":"reason","type":"string"}]}],
+	"messages":{
+	"send":{"request":[{"name":"message","type":"Message"}],"response":"long","errors":["Bounced"]},
+	"echo":{"request":[{"name":"text","type":"string"}],"response":"string"},
+	"ping":{"request":[{"name":"text","type":"string"}],"response":"null","one-way":true}}}`
+
+// testClientProtocol is a later version of testProtocol, whose messages have an
+// additional field, and which declares a message the Server does not know.
+const testClientProtocol = `{"protocol":"Mail","namespace":"com.example","types":[
+	{"type":"record","name":"Message","fields":[{"name":"to","type":"string"},{"name":"cc","type":"string","default":""},{"name":"body","type":"string"}]},
+	{"type":"error","name":"Bounced","fields":[{"name":"reason","type":"string"},{"name":"code","type":"int","default":0}]}],
+	"messages":{
+	"send":{"request":[{"name":"message","type":"Message"}],"response":"long","errors":["Bounced"]},
+	"ping":{"request":[{"name":"text","type":"string"}],"response":"null","one-way":true},
+	"forward":{"request":[],"response":"null"}}}`
+
+func ensureError(tb testing.TB, err error, contains ...string) {
+	tb.Helper()
+	if len(contains) == 0 || (len(contains) == 1 && contains[0] == "") {
+		if err != nil {
+			tb.Fatalf("GOT: %v; WANT: %v", err, contains)
+		}
+		return
+	}
+	if err == nil {
+		tb.Errorf("GOT: %v; WANT: %v", err, contains)
+		return
+	}
+	for _, stub := range contains {
+		if stub != "" && !strings.Contains(err.Error(), stub) {
+			tb.Errorf("GOT: %v; WANT: %q", err, stub)
+		}
+	}
+}
+
+func newTestProtocol(t *testing.T, text string) *goavro.Protocol {
+	t.Helper()
+	protocol, err := goavro.NewProtocol(text)
+	ensureError(t, err)
+	return protocol
+}
+
+// newTestServer returns a Server of testProtocol, along with a channel which
+// receives the text of each ping.
+func newTestServer(t *testing.T) (*Server, chan string) {
+	t.Helper()
+	pings := make(chan string, 10)
+	server := NewServer(newTestProtocol(t, testProtocol))
+	ensureError(t, server.Handle("send", func(call *Call) (interface{}, error) {
+		message := call.Request.(map[string]interface{})["message"].(map[string]interface{})
+		if message["to"] == "nobody" {
+			return nil, &Error{Type: "com.example.Bounced", Value: map[string]interface{}{"reason": "no such user"}}
+		}
+		if message["to"] == "" {
+			return nil, errors.New("recipient is empty")
+		}
+		call.ResponseMeta = map[string][]byte{"trace": append([]byte("reply to "), call.Meta["trace"]...)}
+		return int64(len(message["body"].(string))), nil
+	}))
+	ensureError(t, server.Handle("ping", func(call *Call) (interface{}, error) {
+		pings <- call.Request.(map[string]interface{})["text"].(string)
+		return nil, nil
+	}))
+	ensureError(t, server.Handle("missing", nil), `cannot handle message: protocol has no message "missing"`)
+	return server, pings
+}
+
+// pipeClient returns a Client of the protocol connected to the Server using
+// the stream transport over a pipe, along with a function that closes the pipe
+// and ensures the Server returned without an error.
+func pipeClient(t *testing.T, server *Server, protocol *goavro.Protocol) (*Client, func()) {
+	t.Helper()
+	clientConn, serverConn := net.Pipe()
+	done := make(chan error, 1)
+	go func() { done <- server.ServeConn(serverConn) }()
+	return NewClient(clientConn, protocol), func() {
+		t.Helper()
+		ensureError(t, clientConn.Close())
+		ensureError(t, <-done)
+	}
+}
+
+func TestClientStream(t *testing.T) {
+	server, pings := newTestServer(t)
+	protocol := newTestProtocol(t, testProtocol)
+	client, closeClient := pipeClient(t, server, protocol)
+	defer closeClient()
+
+	// The first call of a one-way message has a response, because it
+	// completes the handshake.
+	response, err := client.Call("ping", map[string]interface{}{"text": "first"})
+	ensureError(t, err)
+	if response != nil {
+		t.Errorf("GOT: %v; WANT: %v", response, nil)
+	}
+	if actual, expected := <-pings, "first"; actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+
+	response, meta, err := client.CallWithMeta("send", map[string][]byte{"trace": []byte("42")}, map[string]interface{}{
+		"message": map[string]interface{}{"to": "someone", "body": "Hello!"},
+	})
+	ensureError(t, err)
+	if actual, expected := response, int64(6); actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+	if actual, expected := meta, map[string][]byte{"trace": []byte("reply to 42")}; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+
+	// Later calls of a one-way message have no response.
+	_, err = client.Call("ping", map[string]interface{}{"text": "second"})
+	ensureError(t, err)
+	if actual, expected := <-pings, "second"; actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+
+	response, err = client.Call("echo", map[string]interface{}{"text": "hi"})
+	ensureError(t, err, `message not implemented: "echo"`)
+	if e, ok := err.(*Error); !ok || e.Type != "string" {
+		t.Errorf("GOT: %#v; WANT: system error", err)
+	}
+	if response != nil {
+		t.Errorf("GOT: %v; WANT: %v", response, nil)
+	}
+
+	_, err = client.Call("missing", nil)
+	ensureError(t, err, `cannot call message: protocol has no message "missing"`)
+	_, err = client.Call("send", map[string]interface{}{})
+	ensureError(t, err, `cannot write request of message "send"`)
+}
+
+func TestClientErrors(t *testing.T) {
+	server, _ := newTestServer(t)
+	client, closeClient := pipeClient(t, server, newTestProtocol(t, testProtocol))
+	defer closeClient()
+
+	_, err := client.Call("send", map[string]interface{}{
+		"message": map[string]interface{}{"to": "nobody", "body": ""},
+	})
+	e, ok := err.(*Error)
+	if !ok {
+		t.Fatalf("GOT: %#v; WANT: %T", err, e)
+	}
+	if actual, expected := *e, (Error{Type: "com.example.Bounced", Value: map[string]interface{}{"reason": "no such user"}}); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+	ensureError(t, err, "com.example.Bounced: map[reason:no such user]")
+
+	// Errors that are not declared by the message are system errors.
+	_, err = client.Call("send", map[string]interface{}{
+		"message": map[string]interface{}{"to": "", "body": ""},
+	})
+	if actual, expected := err, error(SystemError("recipient is empty")); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+}
+
+func TestClientProtocolResolution(t *testing.T) {
+	server, pings := newTestServer(t)
+	client, closeClient := pipeClient(t, server, newTestProtocol(t, testClientProtocol))
+	defer closeClient()
+
+	// The Server does not know the protocol of the Client, which sends it
+	// after the first handshake, and each reads the data of the other using
+	// its own protocol.
+	response, err := client.Call("send", map[string]interface{}{
+		"message": map[string]interface{}{"to": "someone", "cc": "other", "body": "Hello"},
+	})
+	ensureError(t, err)
+	if actual, expected := response, int64(5); actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+	_, err = client.Call("send", map[string]interface{}{
+		"message": map[string]interface{}{"to": "nobody", "cc": "", "body": ""},
+	})
+	if actual, expected := err, error(&Error{Type: "com.example.Bounced", Value: map[string]interface{}{"reason": "no such user", "code": int32(0)}}); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+
+	_, err = client.Call("forward", map[string]interface{}{})
+	ensureError(t, err, `unknown message: "forward"`)
+
+	_, err = client.Call("ping", map[string]interface{}{"text": "resolved"})
+	ensureError(t, err)
+	if actual, expected := <-pings, "resolved"; actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+
+	// A second Client with the same protocol is known by the Server.
+	client, closeOther := pipeClient(t, server, newTestProtocol(t, testClientProtocol))
+	defer closeOther()
+	response, err = client.Call("send", map[string]interface{}{
+		"message": map[string]interface{}{"to": "someone", "cc": "", "body": "Hi"},
+	})
+	ensureError(t, err)
+	if actual, expected := response, int64(2); actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+}
+
+func TestServerHandshakeErrors(t *testing.T) {
+	server, _ := newTestServer(t)
+	hash := server.protocol.MD5()
+
+	_, err := server.respond(new(connection), []byte{1, 2, 3})
+	ensureError(t, err, "cannot read handshake request")
+
+	request, err := (&handshakeRequest{clientHash: make([]byte, 16), clientProtocol: `{"protocol":1}`, serverHash: hash}).appendBinary(nil)
+	ensureError(t, err)
+	_, err = server.respond(new(connection), request)
+	ensureError(t, err, "cannot read client protocol")
+
+	// A handshake without a call is responded to with a handshake.
+	request, err = (&handshakeRequest{clientHash: hash, serverHash: make([]byte, 16)}).appendBinary(nil)
+	ensureError(t, err)
+	buf, err := server.respond(new(connection), request)
+	ensureError(t, err)
+	response, rest, err := readHandshakeResponse(buf)
+	ensureError(t, err)
+	if actual, expected := *response, (handshakeResponse{match: matchClient, serverProtocol: testProtocol, serverHash: hash}); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+	if len(rest) != 0 {
+		t.Errorf("GOT: %v; WANT: %v", rest, nil)
+	}
+}
+
+func TestServerMaxClientProtocols(t *testing.T) {
+	defer func(max int) { MaxClientProtocols = max }(MaxClientProtocols)
+	MaxClientProtocols = 1
+	server, _ := newTestServer(t)
+
+	// Each of these protocols has a distinct hash, and the Server remembers
+	// only the last of them, along with its own.
+	texts := []string{testClientProtocol, strings.Replace(testClientProtocol, `"forward"`, `"redirect"`, 1), testClientProtocol}
+	for _, text := range texts {
+		client, closeClient := pipeClient(t, server, newTestProtocol(t, text))
+		response, err := client.Call("send", map[string]interface{}{
+			"message": map[string]interface{}{"to": "someone", "cc": "", "body": "Hi"},
+		})
+		ensureError(t, err)
+		if actual, expected := response, int64(2); actual != expected {
+			t.Errorf("GOT: %v; WANT: %v", actual, expected)
+		}
+		closeClient()
+		if actual, expected := len(server.remotes), 2; actual != expected {
+			t.Errorf("GOT: %v; WANT: %v", actual, expected)
+		}
+		if _, ok := server.remotes[string(server.hash)]; !ok {
+			t.Errorf("GOT: %v; WANT: %v", ok, true)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package ipc
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// bufferSize is the largest size of the buffers a message is written in.
+const bufferSize = 8192
+
+// MaxMessageSize is the largest size of a message read from a connection, which
+// guards against allocating memory for corrupt or malicious buffer lengths.
+var MaxMessageSize = 64 * 1024 * 1024
+
+// appendFrames appends the message to buf as a series of buffers, each
+// preceded by its big-endian 32-bit length, followed by an empty buffer that
+// ends the message.
+func appendFrames(buf, message []byte) []byte {
+	var length [4]byte
+	for len(message) > 0 {
+		n := len(message)
+		if n > bufferSize {
+			n = bufferSize
+		}
+		binary.BigEndian.PutUint32(length[:], uint32(n))
+		buf = append(buf, length[:]...)
+		buf = append(buf, message[:n]...)
+		message = message[n:]
+	}
+	return append(buf, 0, 0, 0, 0)
+}
+
+// writeFrames writes the message as a series of buffers.
+func writeFrames(w io.Writer, message []byte) error {
+	_, err := w.Write(appendFrames(make([]byte, 0, len(message)+4*(len(message)/bufferSize+2)), message))
+	return err
+}
+
+// readFrames reads a message written as a series of buffers. It returns io.EOF
+// when there are no more messages to read.
+func readFrames(r io.Reader) ([]byte, error) {
+	var message []byte
+	var length [4]byte
+	for first := true; ; first = false {
+		if _, err := io.ReadFull(r, length[:]); err != nil {
+			if err == io.EOF && !first {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		n := int64(binary.BigEndian.Uint32(length[:]))
+		if n == 0 {
+			return message, nil
+		}
+		if int64(len(message))+n > int64(MaxMessageSize) {
+			return nil, fmt.Errorf("cannot read message: size exceeds MaxMessageSize: %d > %d", int64(len(message))+n, MaxMessageSize)
+		}
+		start := len(message)
+		message = append(message, make([]byte, n)...)
+		if _, err := io.ReadFull(r, message[start:]); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+	}
+}
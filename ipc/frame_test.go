@@ -0,0 +1,60 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package ipc
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestFrames(t *testing.T) {
+	if actual, expected := appendFrames(nil, []byte("abc")), []byte{0, 0, 0, 3, 'a', 'b', 'c', 0, 0, 0, 0}; !bytes.Equal(actual, expected) {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+	if actual, expected := appendFrames(nil, nil), []byte{0, 0, 0, 0}; !bytes.Equal(actual, expected) {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+
+	// Large messages are written in several buffers.
+	large := bytes.Repeat([]byte{'x'}, 2*bufferSize+1)
+	var conn bytes.Buffer
+	ensureError(t, writeFrames(&conn, large))
+	ensureError(t, writeFrames(&conn, []byte("second")))
+	if actual, expected := conn.Len(), len(large)+4*4+len("second")+2*4; actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+	message, err := readFrames(&conn)
+	ensureError(t, err)
+	if !bytes.Equal(message, large) {
+		t.Errorf("GOT: %d bytes; WANT: %d bytes", len(message), len(large))
+	}
+	message, err = readFrames(&conn)
+	ensureError(t, err)
+	if actual, expected := string(message), "second"; actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+	if _, err = readFrames(&conn); err != io.EOF {
+		t.Errorf("GOT: %v; WANT: %v", err, io.EOF)
+	}
+}
+
+func TestFramesErrors(t *testing.T) {
+	_, err := readFrames(bytes.NewReader([]byte{0, 0, 0, 3, 'a'}))
+	ensureError(t, err, "unexpected EOF")
+
+	_, err = readFrames(bytes.NewReader([]byte{0, 0, 0, 1, 'a'}))
+	ensureError(t, err, "unexpected EOF")
+
+	defer func(size int) { MaxMessageSize = size }(MaxMessageSize)
+	MaxMessageSize = 4
+	_, err = readFrames(bytes.NewReader([]byte{0, 0, 0, 3, 'a', 'b', 'c', 0, 0, 0, 2, 'd', 'e', 0, 0, 0, 0}))
+	ensureError(t, err, "size exceeds MaxMessageSize: 5 > 4")
+}
@@ -0,0 +1,112 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package ipc
+
+import (
+	"fmt"
+
+	"github.com/linkedin/goavro/v2"
+)
+
+// Results of a handshake, which tell whether the Server knows the protocol of
+// the Client, and whether the Client knows the protocol of the Server.
+const (
+	matchBoth   = "BOTH"   // both protocols are known
+	matchClient = "CLIENT" // the Client protocol is known, the Server protocol is sent
+	matchNone   = "NONE"   // the Client protocol is not known, and ought to be sent
+)
+
+// handshakeRequest precedes the first call of a Client on a connection, or
+// every call over HTTP.
+type handshakeRequest struct {
+	clientHash     []byte
+	clientProtocol string // empty when not sent
+	serverHash     []byte
+	meta           map[string][]byte
+}
+
+// handshakeResponse precedes the response to a call with a handshake.
+type handshakeResponse struct {
+	match          string
+	serverProtocol string // empty when not sent
+	serverHash     []byte // nil when not sent
+	meta           map[string][]byte
+}
+
+func (h *handshakeRequest) appendBinary(buf []byte) ([]byte, error) {
+	native := map[string]interface{}{
+		"clientHash":     h.clientHash,
+		"clientProtocol": nil,
+		"serverHash":     h.serverHash,
+		"meta":           nil,
+	}
+	if h.clientProtocol != "" {
+		native["clientProtocol"] = goavro.Union("string", h.clientProtocol)
+	}
+	if h.meta != nil {
+		native["meta"] = goavro.Union("map", metaNative(h.meta))
+	}
+	return handshakeRequestCodec.BinaryFromNative(buf, native)
+}
+
+func readHandshakeRequest(buf []byte) (*handshakeRequest, []byte, error) {
+	native, buf, err := handshakeRequestCodec.NativeFromBinary(buf)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot read handshake request: %s", err)
+	}
+	record := native.(map[string]interface{})
+	h := &handshakeRequest{
+		clientHash: record["clientHash"].([]byte),
+		serverHash: record["serverHash"].([]byte),
+	}
+	if _, value := unionValue(record["clientProtocol"]); value != nil {
+		h.clientProtocol = value.(string)
+	}
+	_, value := unionValue(record["meta"])
+	h.meta = metaFromNative(value)
+	return h, buf, nil
+}
+
+func (h *handshakeResponse) appendBinary(buf []byte) ([]byte, error) {
+	native := map[string]interface{}{
+		"match":          h.match,
+		"serverProtocol": nil,
+		"serverHash":     nil,
+		"meta":           nil,
+	}
+	if h.serverProtocol != "" {
+		native["serverProtocol"] = goavro.Union("string", h.serverProtocol)
+	}
+	if h.serverHash != nil {
+		native["serverHash"] = goavro.Union("org.apache.avro.ipc.MD5", h.serverHash)
+	}
+	if h.meta != nil {
+		native["meta"] = goavro.Union("map", metaNative(h.meta))
+	}
+	return handshakeResponseCodec.BinaryFromNative(buf, native)
+}
+
+func readHandshakeResponse(buf []byte) (*handshakeResponse, []byte, error) {
+	native, buf, err := handshakeResponseCodec.NativeFromBinary(buf)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot read handshake response: %s", err)
+	}
+	record := native.(map[string]interface{})
+	h := &handshakeResponse{match: record["match"].(string)}
+	if _, value := unionValue(record["serverProtocol"]); value != nil {
+		h.serverProtocol = value.(string)
+	}
+	if _, value := unionValue(record["serverHash"]); value != nil {
+		h.serverHash = value.([]byte)
+	}
+	_, value := unionValue(record["meta"])
+	h.meta = metaFromNative(value)
+	return h, buf, nil
+}
@@ -0,0 +1,78 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package ipc
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/linkedin/goavro/v2"
+)
+
+// contentType is the media type of the requests and responses of the HTTP
+// transport.
+const contentType = "avro/binary"
+
+// NewHTTPClient returns a Client that calls the messages of the protocol on the
+// Server at the URL, using the HTTP transport. When httpClient is nil,
+// http.DefaultClient is used.
+func NewHTTPClient(url string, protocol *goavro.Protocol, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return newClient(&httpTransport{url: url, client: httpClient}, protocol)
+}
+
+// httpTransport sends each request as the body of a POST request, prefixed by
+// a handshake.
+type httpTransport struct {
+	url    string
+	client *http.Client
+}
+
+func (t *httpTransport) stateful() bool { return false }
+
+func (t *httpTransport) roundTrip(request []byte, _ bool) ([]byte, error) {
+	resp, err := t.client.Post(t.url, contentType, bytes.NewReader(appendFrames(nil, request)))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("HTTP status %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return readFrames(resp.Body)
+}
+
+// ServeHTTP responds to the call in the body of the POST request using the
+// HTTP transport, which makes the Server an http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	request, err := readFrames(r.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("cannot read request: %s", err), http.StatusBadRequest)
+		return
+	}
+	response, err := s.respond(new(connection), request)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	_, _ = w.Write(appendFrames(nil, response))
+}
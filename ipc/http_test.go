@@ -0,0 +1,147 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package ipc
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClientHTTP(t *testing.T) {
+	server, pings := newTestServer(t)
+	var requests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if actual, expected := r.Header.Get("Content-Type"), contentType; actual != expected {
+			t.Errorf("GOT: %v; WANT: %v", actual, expected)
+		}
+		server.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+
+	// The Server does not know the protocol of the Client, so the first call
+	// is sent again with the protocol.
+	client := NewHTTPClient(ts.URL, newTestProtocol(t, testClientProtocol), nil)
+	response, err := client.Call("send", map[string]interface{}{
+		"message": map[string]interface{}{"to": "someone", "cc": "", "body": "Hello"},
+	})
+	ensureError(t, err)
+	if actual, expected := response, int64(5); actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+	if actual, expected := atomic.LoadInt32(&requests), int32(2); actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+
+	// Every call over HTTP has a response, even for one-way messages.
+	_, err = client.Call("ping", map[string]interface{}{"text": "over HTTP"})
+	ensureError(t, err)
+	if actual, expected := <-pings, "over HTTP"; actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+	_, err = client.Call("send", map[string]interface{}{
+		"message": map[string]interface{}{"to": "nobody", "cc": "", "body": ""},
+	})
+	ensureError(t, err, "com.example.Bounced")
+	if actual, expected := atomic.LoadInt32(&requests), int32(4); actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+
+	// A Client with the same protocol as the Server never sends it.
+	client = NewHTTPClient(ts.URL, newTestProtocol(t, testProtocol), ts.Client())
+	response, err = client.Call("send", map[string]interface{}{
+		"message": map[string]interface{}{"to": "someone", "body": "Hi"},
+	})
+	ensureError(t, err)
+	if actual, expected := response, int64(2); actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+	if actual, expected := atomic.LoadInt32(&requests), int32(5); actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+}
+
+func TestClientHTTPConcurrent(t *testing.T) {
+	server, _ := newTestServer(t)
+	arrived := make(chan struct{}, 2)
+	release := make(chan struct{})
+	var once sync.Once
+	releaseAll := func() { once.Do(func() { close(release) }) }
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		arrived <- struct{}{}
+		<-release
+		server.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+	defer releaseAll()
+
+	// Both calls reach the Server before either is responded to.
+	client := NewHTTPClient(ts.URL, newTestProtocol(t, testProtocol), nil)
+	responses := make(chan interface{}, 2)
+	errs := make(chan error, 2)
+	for _, body := range []string{"one", "three"} {
+		go func(body string) {
+			response, err := client.Call("send", map[string]interface{}{
+				"message": map[string]interface{}{"to": "someone", "body": body},
+			})
+			errs <- err
+			responses <- response
+		}(body)
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case <-arrived:
+		case <-time.After(5 * time.Second):
+			t.Fatal("GOT: calls sent one at a time; WANT: calls sent concurrently")
+		}
+	}
+	releaseAll()
+	ensureError(t, <-errs)
+	ensureError(t, <-errs)
+	sum := (<-responses).(int64) + (<-responses).(int64)
+	if actual, expected := sum, int64(8); actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+}
+
+func TestServeHTTPErrors(t *testing.T) {
+	server, _ := newTestServer(t)
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL)
+	ensureError(t, err)
+	resp.Body.Close()
+	if actual, expected := resp.StatusCode, http.StatusMethodNotAllowed; actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+
+	resp, err = http.Post(ts.URL, contentType, bytes.NewReader([]byte{0, 0, 0, 3, 1}))
+	ensureError(t, err)
+	resp.Body.Close()
+	if actual, expected := resp.StatusCode, http.StatusBadRequest; actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	_, err = NewHTTPClient(failing.URL, newTestProtocol(t, testProtocol), nil).Call("echo", map[string]interface{}{"text": "hi"})
+	ensureError(t, err, `cannot call message "echo": HTTP status 500 Internal Server Error: boom`)
+
+	_, err = NewHTTPClient("http://127.0.0.1:0", newTestProtocol(t, testProtocol), nil).Call("echo", map[string]interface{}{"text": "hi"})
+	ensureError(t, err, `cannot call message "echo"`)
+}
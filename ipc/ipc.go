@@ -0,0 +1,229 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+// Package ipc implements Avro RPC, by which a Client calls the messages of a
+// goavro Protocol, and a Server responds to them, so Go programs may talk to
+// other implementations of Avro RPC, such as services written using the Java
+// Avro library.
+//
+// Messages are written as a series of framed buffers. Before its first call,
+// a Client exchanges a handshake with the Server, which identifies the
+// protocol of each by its MD5 hash, and sends the text of the protocol only
+// when the other end does not already know it. When the protocols differ,
+// each end reads the data written by the other using schema resolution.
+//
+// Two transports are provided. The stream transport uses a connection, such
+// as a net.Conn, on which the handshake is exchanged once, and over which
+// one-way messages have no response. The HTTP transport sends each call as a
+// POST request, whose body is prefixed by a handshake.
+//
+//	server := ipc.NewServer(protocol)
+//	err := server.Handle("send", func(call *ipc.Call) (interface{}, error) {
+//	    request := call.Request.(map[string]interface{})
+//	    return deliver(request["message"])
+//	})
+//	if err != nil {
+//	    return err
+//	}
+//	go server.Serve(listener)
+//
+//	conn, err := net.Dial("tcp", address)
+//	if err != nil {
+//	    return err
+//	}
+//	client := ipc.NewClient(conn, protocol)
+//	response, err := client.Call("send", map[string]interface{}{
+//	    "message": message,
+//	})
+package ipc
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/linkedin/goavro/v2"
+)
+
+// Call is a call of a message received by a Server.
+type Call struct {
+	// Message is the message of the protocol of the Server being called.
+	Message *goavro.Message
+
+	// Request is the native request of the call, which is a record whose
+	// fields are the parameters of the message.
+	Request interface{}
+
+	// Meta is the metadata sent by the Client with the call.
+	Meta map[string][]byte
+
+	// ResponseMeta is the metadata sent to the Client with the response, which
+	// the Handler may set.
+	ResponseMeta map[string][]byte
+}
+
+// Handler responds to the calls of a message. It returns the native response,
+// or an error. An *Error whose Type is an error declared by the message is
+// sent to the Client as that error, and any other error is sent as a system
+// error, using the text of the error.
+type Handler func(call *Call) (interface{}, error)
+
+// Error is an error sent in response to a call, which is either one of the
+// errors declared by the message, or a system error.
+type Error struct {
+	// Type is the full name of the error type declared by the message, or
+	// "string" for a system error.
+	Type string
+
+	// Value is the native value of the error, which is a string for a system
+	// error.
+	Value interface{}
+}
+
+// SystemError returns an *Error for a system error with the message.
+func SystemError(message string) *Error {
+	return &Error{Type: "string", Value: message}
+}
+
+func (e *Error) Error() string {
+	if s, ok := e.Value.(string); ok && e.Type == "string" {
+		return s
+	}
+	return fmt.Sprintf("%s: %v", e.Type, e.Value)
+}
+
+// NOTE: The schemas of the handshake, and of the parts of calls and responses
+// that are the same for every message, are constant, so their Codecs are
+// created once.
+var (
+	handshakeRequestCodec = mustCodec(`{"type":"record","name":"HandshakeRequest","namespace":"org.apache.avro.ipc","fields":[` +
+		`{"name":"clientHash","type":{"type":"fixed","name":"MD5","size":16}},` +
+		`{"name":"clientProtocol","type":["null","string"]},` +
+		`{"name":"serverHash","type":"MD5"},` +
+		`{"name":"meta","type":["null",{"type":"map","values":"bytes"}]}]}`)
+	handshakeResponseCodec = mustCodec(`{"type":"record","name":"HandshakeResponse","namespace":"org.apache.avro.ipc","fields":[` +
+		`{"name":"match","type":{"type":"enum","name":"HandshakeMatch","symbols":["BOTH","CLIENT","NONE"]}},` +
+		`{"name":"serverProtocol","type":["null","string"]},` +
+		`{"name":"serverHash","type":["null",{"type":"fixed","name":"MD5","size":16}]},` +
+		`{"name":"meta","type":["null",{"type":"map","values":"bytes"}]}]}`)
+	metaCodec         = mustCodec(`{"type":"map","values":"bytes"}`)
+	stringCodec       = mustCodec(`"string"`)
+	systemErrorsCodec = mustCodec(`["string"]`)
+)
+
+func mustCodec(schema string) *goavro.Codec {
+	codec, err := goavro.NewCodec(schema)
+	if err != nil {
+		panic(err) // should not get here because the schemas are constant
+	}
+	return codec
+}
+
+// appendMeta appends the binary encoding of the metadata to buf.
+func appendMeta(buf []byte, meta map[string][]byte) ([]byte, error) {
+	return metaCodec.BinaryFromNative(buf, metaNative(meta))
+}
+
+// metaNative returns the metadata as the native value of a map of bytes.
+func metaNative(meta map[string][]byte) map[string]interface{} {
+	native := make(map[string]interface{}, len(meta))
+	for k, v := range meta {
+		native[k] = v
+	}
+	return native
+}
+
+// metaFromNative returns the metadata decoded from a map of bytes values.
+func metaFromNative(native interface{}) map[string][]byte {
+	m, _ := native.(map[string]interface{})
+	if len(m) == 0 {
+		return nil
+	}
+	meta := make(map[string][]byte, len(m))
+	for k, v := range m {
+		meta[k], _ = v.([]byte)
+	}
+	return meta
+}
+
+// readMeta decodes metadata from the beginning of buf, and returns it along
+// with the remaining bytes.
+func readMeta(buf []byte) (map[string][]byte, []byte, error) {
+	native, buf, err := metaCodec.NativeFromBinary(buf)
+	if err != nil {
+		return nil, nil, err
+	}
+	return metaFromNative(native), buf, nil
+}
+
+// remote is the protocol of the other end of connections, along with the
+// Codecs that read the data written using it.
+type remote struct {
+	protocol *goavro.Protocol
+
+	mu      sync.Mutex
+	readers map[string]*goavro.Codec // message name and part -> Codec
+}
+
+func newRemote(protocol *goavro.Protocol) *remote {
+	return &remote{protocol: protocol, readers: make(map[string]*goavro.Codec)}
+}
+
+// reader returns the Codec that reads the part of the local message, which is
+// "request", "response", or "errors", when written using the remote protocol.
+func (r *remote) reader(local *goavro.Message, part string) (*goavro.Codec, error) {
+	key := local.Name() + "/" + part
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if codec, ok := r.readers[key]; ok {
+		return codec, nil
+	}
+	// NOTE: When the remote protocol has no such message, the other end can
+	// only have written a system error, which every errors union reads alike.
+	writer := messagePart(local, part)
+	if message, ok := r.protocol.Message(local.Name()); ok {
+		writer = messagePart(message, part)
+	}
+	codec, err := resolve(writer, messagePart(local, part))
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %s of message %q: %s", part, local.Name(), err)
+	}
+	r.readers[key] = codec
+	return codec, nil
+}
+
+func messagePart(message *goavro.Message, part string) *goavro.Codec {
+	switch part {
+	case "request":
+		return message.Request()
+	case "response":
+		return message.Response()
+	}
+	return message.Errors()
+}
+
+// resolve returns a Codec that reads data written using the writer Codec as
+// data of the reader Codec, which is the reader Codec itself when both have
+// the same schema.
+func resolve(writer, reader *goavro.Codec) (*goavro.Codec, error) {
+	if writer.Rabin == reader.Rabin {
+		return reader, nil
+	}
+	return goavro.NewCodecForResolution(writer.Schema(), reader.Schema(), nil)
+}
+
+// unionValue returns the name of the member of the union and its value, given
+// the native value of a union.
+func unionValue(native interface{}) (string, interface{}) {
+	if m, ok := native.(map[string]interface{}); ok {
+		for name, value := range m {
+			return name, value
+		}
+	}
+	return "null", nil
+}
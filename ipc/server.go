@@ -0,0 +1,274 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package ipc
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+
+	"github.com/linkedin/goavro/v2"
+)
+
+// MaxClientProtocols is the largest number of protocols of clients, other than
+// its own, a Server remembers, which guards against clients that send many
+// distinct protocols to use up its memory. When a Server receives another
+// protocol, it forgets one of those it remembers, and a client whose protocol
+// was forgotten sends it again.
+var MaxClientProtocols = 256
+
+// Server responds to the calls of the messages of a protocol, using the
+// Handler of each message. It is safe to use a Server from multiple goroutines,
+// and to serve multiple connections at once.
+type Server struct {
+	protocol *goavro.Protocol
+	hash     []byte
+
+	mu       sync.RWMutex
+	handlers map[string]Handler
+	remotes  map[string]*remote // protocols of clients by their MD5 hashes
+}
+
+// connection is the state of a connection to a Server.
+type connection struct {
+	remote *remote // protocol of the client once the handshake is done
+}
+
+// NewServer returns a Server of the protocol, with no Handlers.
+func NewServer(protocol *goavro.Protocol) *Server {
+	s := &Server{
+		protocol: protocol,
+		hash:     protocol.MD5(),
+		handlers: make(map[string]Handler),
+		remotes:  make(map[string]*remote),
+	}
+	s.remotes[string(s.hash)] = newRemote(protocol)
+	return s
+}
+
+// Handle registers the Handler of the calls of the message with the name,
+// replacing the Handler registered before, if any. Calls of messages without
+// a Handler are responded to with a system error.
+func (s *Server) Handle(message string, handler Handler) error {
+	if _, ok := s.protocol.Message(message); !ok {
+		return fmt.Errorf("cannot handle message: protocol has no message %q", message)
+	}
+	s.mu.Lock()
+	s.handlers[message] = handler
+	s.mu.Unlock()
+	return nil
+}
+
+// Serve accepts connections from the listener, and serves each of them using
+// the stream transport in a new goroutine, until the listener returns an
+// error, which Serve returns.
+func (s *Server) Serve(listener net.Listener) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+		go func() {
+			defer conn.Close()
+			_ = s.ServeConn(conn)
+		}()
+	}
+}
+
+// ServeConn responds to the calls read from the connection using the stream
+// transport. It returns nil when the client closes the connection, or an error
+// when the connection cannot be read or written, or a handshake is invalid.
+func (s *Server) ServeConn(conn io.ReadWriter) error {
+	c := new(connection)
+	for {
+		request, err := readFrames(conn)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("cannot read request: %s", err)
+		}
+		response, err := s.respond(c, request)
+		if err != nil {
+			return err
+		}
+		if response == nil {
+			continue // one-way message
+		}
+		if err = writeFrames(conn, response); err != nil {
+			return fmt.Errorf("cannot write response: %s", err)
+		}
+	}
+}
+
+// respond returns the response to the request read from the connection, or
+// nil when a one-way message is called on a connection whose handshake is
+// done. A request begins with a handshake until the handshake is done.
+func (s *Server) respond(c *connection, buf []byte) ([]byte, error) {
+	var response []byte
+	wasConnected := c.remote != nil
+	if !wasConnected {
+		request, rest, err := readHandshakeRequest(buf)
+		if err != nil {
+			return nil, err
+		}
+		handshake, r, err := s.handshake(request)
+		if err != nil {
+			return nil, err
+		}
+		if response, err = handshake.appendBinary(nil); err != nil {
+			return nil, fmt.Errorf("cannot write handshake response: %s", err)
+		}
+		if r == nil || len(rest) == 0 {
+			return response, nil // client ought to send its protocol, or only sent a handshake
+		}
+		c.remote = r
+		buf = rest
+	}
+	return s.call(response, c.remote, buf, wasConnected)
+}
+
+// handshake returns the handshake response to the request, along with the
+// protocol of the client, or nil when it is not known.
+func (s *Server) handshake(request *handshakeRequest) (*handshakeResponse, *remote, error) {
+	key := string(request.clientHash)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.remotes[key]
+	if !ok && request.clientProtocol != "" {
+		protocol, err := goavro.NewProtocol(request.clientProtocol)
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot read client protocol: %s", err)
+		}
+		r = newRemote(protocol)
+		s.remember(key, r)
+	}
+	response := &handshakeResponse{match: matchBoth}
+	switch {
+	case r == nil:
+		response.match = matchNone
+	case !bytes.Equal(request.serverHash, s.hash):
+		response.match = matchClient
+	}
+	if response.match != matchBoth {
+		response.serverProtocol = s.protocol.Text()
+		response.serverHash = s.hash
+	}
+	return response, r, nil
+}
+
+// remember adds the protocol of a client to those the Server remembers by their
+// hashes, after forgetting others when there are already MaxClientProtocols of
+// them. The caller ought to hold s.mu.
+func (s *Server) remember(key string, r *remote) {
+	own := string(s.hash)
+	for k := range s.remotes {
+		if len(s.remotes)-1 < MaxClientProtocols {
+			break
+		}
+		if k != own {
+			delete(s.remotes, k) // connections keep using their remote
+		}
+	}
+	if len(s.remotes)-1 < MaxClientProtocols {
+		s.remotes[key] = r
+	}
+}
+
+// call appends the response to the call read from buf to response, and
+// returns it, or nil when there is no response to a one-way message.
+func (s *Server) call(response []byte, r *remote, buf []byte, wasConnected bool) ([]byte, error) {
+	meta, buf, err := readMeta(buf)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read call metadata: %s", err)
+	}
+	native, buf, err := stringCodec.NativeFromBinary(buf)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read message name: %s", err)
+	}
+	name := native.(string)
+	response, err = s.dispatch(response, r, name, meta, buf)
+
+	// NOTE: The client does not read the response to a one-way message of its
+	// protocol once the handshake is done, even when it is an error.
+	if message, ok := r.protocol.Message(name); ok && message.OneWay() && wasConnected {
+		return nil, err
+	}
+	return response, err
+}
+
+// dispatch appends the response of the Handler of the message with the name to
+// response, given the call metadata and the encoded request.
+func (s *Server) dispatch(response []byte, r *remote, name string, meta map[string][]byte, buf []byte) ([]byte, error) {
+	message, ok := s.protocol.Message(name)
+	if !ok {
+		return appendSystemError(response, fmt.Sprintf("unknown message: %q", name))
+	}
+	reader, err := r.reader(message, "request")
+	if err != nil {
+		return appendError(response, message, nil, err)
+	}
+	request, _, err := reader.NativeFromBinary(buf)
+	if err != nil {
+		return appendError(response, message, nil, fmt.Errorf("cannot read request of message %q: %s", name, err))
+	}
+
+	s.mu.RLock()
+	handler := s.handlers[name]
+	s.mu.RUnlock()
+	if handler == nil {
+		return appendError(response, message, nil, fmt.Errorf("message not implemented: %q", name))
+	}
+	call := &Call{Message: message, Request: request, Meta: meta}
+	datum, err := handler(call)
+	if err != nil {
+		return appendError(response, message, call.ResponseMeta, err)
+	}
+	buf, err = appendMeta(response, call.ResponseMeta)
+	if err != nil {
+		return nil, fmt.Errorf("cannot write response metadata: %s", err)
+	}
+	if buf, err = message.Response().BinaryFromNative(append(buf, 0), datum); err != nil {
+		return appendError(response, message, call.ResponseMeta, fmt.Errorf("cannot write response of message %q: %s", name, err))
+	}
+	return buf, nil
+}
+
+// appendError appends the response metadata, the error flag, and the error to
+// response. An error that is not an *Error is written as a system error, as is
+// an *Error the errors of the message cannot encode.
+func appendError(response []byte, message *goavro.Message, meta map[string][]byte, err error) ([]byte, error) {
+	e, ok := err.(*Error)
+	if !ok {
+		e = SystemError(err.Error())
+	}
+	response, err = appendMeta(response, meta)
+	if err != nil {
+		return nil, fmt.Errorf("cannot write response metadata: %s", err)
+	}
+	response = append(response, 1)
+	buf, err := message.Errors().BinaryFromNative(response, goavro.Union(e.Type, e.Value))
+	if err != nil {
+		return systemErrorsCodec.BinaryFromNative(response, goavro.Union("string", fmt.Sprintf("cannot write error of message %q: %s", message.Name(), err)))
+	}
+	return buf, nil
+}
+
+// appendSystemError appends empty response metadata, the error flag, and the
+// system error to response.
+func appendSystemError(response []byte, message string) ([]byte, error) {
+	response, err := appendMeta(response, nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot write response metadata: %s", err)
+	}
+	return systemErrorsCodec.BinaryFromNative(append(response, 1), goavro.Union("string", message))
+}
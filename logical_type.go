@@ -10,10 +10,13 @@
 package goavro
 
 import (
+	"encoding/binary"
 	"errors"
 	"fmt"
+	"math"
 	"math/big"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -270,6 +273,8 @@ func makeDecimalBytesCodec(st map[string]*Codec, enclosingNamespace string, sche
 	if err != nil {
 		return nil, fmt.Errorf("Bytes ought to have valid name: %s", err)
 	}
+	c.kind = "bytes"
+	c.skipBinary = bytesSkipBinary
 
 	// Add an additional cached codec for this "bytes.decimal" keyed also by "precision" and "scale"
 	decimalSearchType := fmt.Sprintf("bytes.decimal.%d.%d", precision, scale)
@@ -320,6 +325,9 @@ func decimalBytesFromNative(fromNativeFn fromNativeFn, toBytesFn toBytesFn, _, s
 		if !ok {
 			return nil, fmt.Errorf("cannot transform to bytes, expected *big.Rat, received %T", d)
 		}
+		if r == nil {
+			return nil, fmt.Errorf("cannot transform to bytes, received nil *big.Rat")
+		}
 		// Reduce accuracy to precision by dividing and multiplying by digit length
 		num := big.NewInt(0).Set(r.Num())
 		denom := big.NewInt(0).Set(r.Denom())
@@ -342,6 +350,9 @@ func decimalTextualFromNative(scale int) fromNativeFn {
 		if !ok {
 			return nil, fmt.Errorf("cannot transform to textual decimal, expected *big.Rat, received %T", d)
 		}
+		if r == nil {
+			return nil, fmt.Errorf("cannot transform to textual decimal, received nil *big.Rat")
+		}
 		// Format as decimal string with proper scale
 		return stringTextualFromNative(b, r.FloatString(scale))
 	}
@@ -426,6 +437,8 @@ func makeValidatedStringCodec(st map[string]*Codec, enclosingNamespace string, s
 	if err != nil {
 		return nil, err
 	}
+	c.kind = "string"
+	c.skipBinary = stringSkipBinary
 
 	c.binaryFromNative = validatedStringBinaryFromNative(c.binaryFromNative)
 	c.textualFromNative = validatedStringTextualFromNative(c.textualFromNative)
@@ -474,6 +487,345 @@ func validatedStringNativeFromTextual(fn toNativeFn, pattern string) toNativeFn
 	}
 }
 
+// ////////////////////////////////////////////////////////////////////////////////////////////
+// uuid logical type - to/from string, or [16]byte
+// ////////////////////////////////////////////////////////////////////////////////////////////
+
+// uuidLength is the length of the RFC 4122 string form of a UUID, such as
+// "f81d4fae-7dec-11d0-a765-00a0c91e6bf6".
+const uuidLength = 36
+
+// parseUUID returns the 16 bytes of the UUID in RFC 4122 string form.
+func parseUUID(s string) ([16]byte, error) {
+	var u [16]byte
+	if len(s) != uuidLength {
+		return u, fmt.Errorf("UUID ought to have %d characters: %q", uuidLength, s)
+	}
+	for i, j := 0, 0; i < uuidLength; {
+		if i == 8 || i == 13 || i == 18 || i == 23 {
+			if s[i] != '-' {
+				return u, fmt.Errorf("UUID ought to have hyphens at positions 9, 14, 19, and 24: %q", s)
+			}
+			i++
+			continue
+		}
+		hi, ok1 := fromHexChar(s[i])
+		lo, ok2 := fromHexChar(s[i+1])
+		if !ok1 || !ok2 {
+			return u, fmt.Errorf("UUID ought to have hexadecimal digits: %q", s)
+		}
+		u[j] = hi<<4 | lo
+		i += 2
+		j++
+	}
+	return u, nil
+}
+
+func fromHexChar(c byte) (byte, bool) {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0', true
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10, true
+	case 'A' <= c && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
+
+// formatUUID returns the RFC 4122 string form of the UUID, using lowercase
+// hexadecimal digits.
+func formatUUID(u [16]byte) string {
+	const hexDigits = "0123456789abcdef"
+	buf := make([]byte, 0, uuidLength)
+	for i, b := range u {
+		if i == 4 || i == 6 || i == 8 || i == 10 {
+			buf = append(buf, '-')
+		}
+		buf = append(buf, hexDigits[b>>4], hexDigits[b&0x0f])
+	}
+	return string(buf)
+}
+
+// uuidFromNative returns the 16 bytes of the UUID provided as a string in RFC
+// 4122 form, or as a [16]byte.
+func uuidFromNative(datum interface{}) ([16]byte, error) {
+	switch v := datum.(type) {
+	case string:
+		return parseUUID(v)
+	case [16]byte:
+		return v, nil
+	}
+	return [16]byte{}, fmt.Errorf("expected: string or [16]byte; received: %T", datum)
+}
+
+// uuidAsByteArray returns true when uuid logical types ought to decode to
+// [16]byte rather than string.
+func uuidAsByteArray(cb *codecBuilder) bool {
+	return cb != nil && cb.option != nil && cb.option.EnableUUIDByteArray
+}
+
+func makeUUIDStringCodec(st map[string]*Codec, cb *codecBuilder) *Codec {
+	asByteArray := uuidAsByteArray(cb)
+	c := &Codec{
+		typeName:          &name{"string.uuid", nullNamespace},
+		kind:              "string",
+		schemaOriginal:    "string",
+		schemaCanonical:   "string",
+		binaryFromNative:  uuidStringFromNative(stringBinaryFromNative, "binary"),
+		nativeFromBinary:  nativeFromUUIDString(stringNativeFromBinary, "binary", asByteArray),
+		skipBinary:        stringSkipBinary,
+		textualFromNative: uuidStringFromNative(stringTextualFromNative, "textual"),
+		nativeFromTextual: nativeFromUUIDString(stringNativeFromTextual, "textual", asByteArray),
+	}
+	// NOTE: The Codec does not depend on the schema, so it is shared by the
+	// other uuid strings of the schema, like the primitive Codecs.
+	st["string.uuid"] = c
+	return c
+}
+
+func uuidStringFromNative(fn fromNativeFn, encoding string) fromNativeFn {
+	return func(b []byte, d interface{}) ([]byte, error) {
+		u, err := uuidFromNative(d)
+		if err != nil {
+			return nil, fmt.Errorf("cannot encode %s uuid: %s", encoding, err)
+		}
+		s, ok := d.(string)
+		if !ok {
+			s = formatUUID(u)
+		}
+		return fn(b, s)
+	}
+}
+
+func nativeFromUUIDString(fn toNativeFn, encoding string, asByteArray bool) toNativeFn {
+	return func(bytes []byte) (interface{}, []byte, error) {
+		l, b, err := fn(bytes)
+		if err != nil {
+			return l, b, err
+		}
+		u, err := parseUUID(l.(string))
+		if err != nil {
+			return nil, bytes, fmt.Errorf("cannot decode %s uuid: %s", encoding, err)
+		}
+		if asByteArray {
+			return u, b, nil
+		}
+		return l, b, nil
+	}
+}
+
+func makeUUIDFixedCodec(st map[string]*Codec, enclosingNamespace string, schemaMap map[string]interface{}, cb *codecBuilder) (*Codec, error) {
+	c, err := makeFixedCodec(st, enclosingNamespace, schemaMap)
+	if err != nil {
+		return nil, err
+	}
+	if c.size != 16 {
+		return nil, fmt.Errorf("cannot create uuid logical type when fixed size is not 16: %d", c.size)
+	}
+	asByteArray := uuidAsByteArray(cb)
+	c.binaryFromNative = uuidFixedFromNative(c.binaryFromNative, "binary")
+	c.textualFromNative = uuidFixedFromNative(c.textualFromNative, "textual")
+	c.nativeFromBinary = nativeFromUUIDFixed(c.nativeFromBinary, asByteArray)
+	c.nativeFromTextual = nativeFromUUIDFixed(c.nativeFromTextual, asByteArray)
+	c.nativeFromDefault = fixedNativeFromDefault(c)
+	return c, nil
+}
+
+func uuidFixedFromNative(fn fromNativeFn, encoding string) fromNativeFn {
+	return func(b []byte, d interface{}) ([]byte, error) {
+		u, err := uuidFromNative(d)
+		if err != nil {
+			return nil, fmt.Errorf("cannot encode %s uuid: %s", encoding, err)
+		}
+		return fn(b, u[:])
+	}
+}
+
+func nativeFromUUIDFixed(fn toNativeFn, asByteArray bool) toNativeFn {
+	return func(bytes []byte) (interface{}, []byte, error) {
+		l, b, err := fn(bytes)
+		if err != nil {
+			return l, b, err
+		}
+		var u [16]byte
+		copy(u[:], l.([]byte))
+		if asByteArray {
+			return u, b, nil
+		}
+		return formatUUID(u), b, nil
+	}
+}
+
+// fixedNativeFromDefault returns a function that converts the default value of
+// a fixed logical type, which is a string whose characters are the bytes of the
+// fixed value, into the native value decoded from those bytes.
+func fixedNativeFromDefault(c *Codec) func(interface{}) (interface{}, error) {
+	return func(d interface{}) (interface{}, error) {
+		s, ok := d.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected: string; received: %T", d)
+		}
+		buf := make([]byte, 0, len(s))
+		for _, r := range s {
+			if r > 0xff {
+				return nil, fmt.Errorf("characters of fixed default value ought to be bytes: %q", r)
+			}
+			buf = append(buf, byte(r))
+		}
+		if count := uint(len(buf)); count != c.size {
+			return nil, fmt.Errorf("fixed default value size ought to equal schema size: %d != %d", count, c.size)
+		}
+		native, _, err := c.nativeFromBinary(buf)
+		return native, err
+	}
+}
+
+// ////////////////////////////////////////////////////////////////////////////////////////////
+// duration logical type - to/from Duration, or from time.Duration
+// ////////////////////////////////////////////////////////////////////////////////////////////
+
+// durationSize is the size of the fixed type annotated by the duration logical
+// type, which holds three little-endian unsigned 32-bit integers.
+const durationSize = 12
+
+// Duration is the native Go type of the Avro duration logical type, which is an
+// amount of time defined by a number of months, days, and milliseconds. The
+// parts are independent of one another, because the number of days in a month,
+// and the number of milliseconds in a day, are not always the same.
+type Duration struct {
+	Months       uint32
+	Days         uint32
+	Milliseconds uint32
+}
+
+// durationFromNative returns the Duration provided as a Duration, or as a
+// time.Duration, which has no months nor days, and is truncated to whole
+// milliseconds.
+func durationFromNative(datum interface{}) (Duration, error) {
+	switch v := datum.(type) {
+	case Duration:
+		return v, nil
+	case time.Duration:
+		if v < 0 {
+			return Duration{}, fmt.Errorf("time.Duration ought to be non-negative: %s", v)
+		}
+		ms := int64(v / time.Millisecond)
+		if ms > math.MaxUint32 {
+			return Duration{}, fmt.Errorf("time.Duration ought to have at most %d milliseconds: %s", uint32(math.MaxUint32), v)
+		}
+		return Duration{Milliseconds: uint32(ms)}, nil
+	}
+	return Duration{}, fmt.Errorf("expected: Duration or time.Duration; received: %T", datum)
+}
+
+// String returns the duration in the ISO 8601 format, such as "P1M2DT3.5S"
+// for 1 month, 2 days, and 3500 milliseconds, or "PT0S" when it is zero. The
+// milliseconds are written as hours, minutes, and seconds, but never as days.
+func (d Duration) String() string {
+	b := []byte{'P'}
+	if d.Months > 0 {
+		b = strconv.AppendUint(b, uint64(d.Months), 10)
+		b = append(b, 'M')
+	}
+	if d.Days > 0 {
+		b = strconv.AppendUint(b, uint64(d.Days), 10)
+		b = append(b, 'D')
+	}
+	if d.Milliseconds > 0 || len(b) == 1 {
+		b = append(b, 'T')
+		ms := uint64(d.Milliseconds)
+		if hours := ms / 3600000; hours > 0 {
+			b = strconv.AppendUint(b, hours, 10)
+			b = append(b, 'H')
+		}
+		if minutes := ms / 60000 % 60; minutes > 0 {
+			b = strconv.AppendUint(b, minutes, 10)
+			b = append(b, 'M')
+		}
+		if seconds, fraction := ms/1000%60, ms%1000; seconds > 0 || fraction > 0 || ms == 0 {
+			b = strconv.AppendUint(b, seconds, 10)
+			if fraction > 0 {
+				b = append(b, '.')
+				b = append(b, strings.TrimRight(fmt.Sprintf("%03d", fraction), "0")...)
+			}
+			b = append(b, 'S')
+		}
+	}
+	return string(b)
+}
+
+// bytes returns the duration encoded as the bytes of its fixed type.
+func (d Duration) bytes() []byte {
+	buf := make([]byte, durationSize)
+	binary.LittleEndian.PutUint32(buf[0:], d.Months)
+	binary.LittleEndian.PutUint32(buf[4:], d.Days)
+	binary.LittleEndian.PutUint32(buf[8:], d.Milliseconds)
+	return buf
+}
+
+func makeDurationFixedCodec(st map[string]*Codec, enclosingNamespace string, schemaMap map[string]interface{}) (*Codec, error) {
+	c, err := makeFixedCodec(st, enclosingNamespace, schemaMap)
+	if err != nil {
+		return nil, err
+	}
+	if c.size != durationSize {
+		return nil, fmt.Errorf("cannot create duration logical type when fixed size is not %d: %d", durationSize, c.size)
+	}
+	c.binaryFromNative = durationFixedFromNative(c.binaryFromNative)
+	c.nativeFromBinary = nativeFromDurationFixed(c.nativeFromBinary)
+	c.textualFromNative = durationTextualFromNative(c.textualFromNative)
+	c.nativeFromTextual = nativeFromDurationFixed(c.nativeFromTextual)
+	c.nativeFromDefault = fixedNativeFromDefault(c)
+	return c, nil
+}
+
+func durationFixedFromNative(fn fromNativeFn) fromNativeFn {
+	return func(b []byte, d interface{}) ([]byte, error) {
+		switch d.(type) {
+		case []byte, string:
+			// NOTE: Raw bytes of the fixed type are still accepted.
+			return fn(b, d)
+		}
+		v, err := durationFromNative(d)
+		if err != nil {
+			return nil, fmt.Errorf("cannot encode binary duration: %s", err)
+		}
+		return fn(b, v.bytes())
+	}
+}
+
+func nativeFromDurationFixed(fn toNativeFn) toNativeFn {
+	return func(bytes []byte) (interface{}, []byte, error) {
+		l, b, err := fn(bytes)
+		if err != nil {
+			return l, b, err
+		}
+		buf := l.([]byte)
+		return Duration{
+			Months:       binary.LittleEndian.Uint32(buf[0:]),
+			Days:         binary.LittleEndian.Uint32(buf[4:]),
+			Milliseconds: binary.LittleEndian.Uint32(buf[8:]),
+		}, b, nil
+	}
+}
+
+// durationTextualFromNative encodes the duration as the JSON string of the
+// bytes of its fixed type, as for any fixed type.
+func durationTextualFromNative(fn fromNativeFn) fromNativeFn {
+	return func(b []byte, d interface{}) ([]byte, error) {
+		switch d.(type) {
+		case []byte, string:
+			return fn(b, d)
+		}
+		v, err := durationFromNative(d)
+		if err != nil {
+			return nil, fmt.Errorf("cannot encode textual duration: %s", err)
+		}
+		return fn(b, v.bytes())
+	}
+}
+
 func padBytes(bytes []byte, fixedSize uint) []byte {
 	s := int(fixedSize)
 	padded := make([]byte, s)
@@ -511,6 +511,141 @@ func TestValidatedStringLogicalTypeInRecordEncode(t *testing.T) {
 	}
 }
 
+func TestUUIDStringLogicalType(t *testing.T) {
+	schema := `{"type": "string", "logicalType": "uuid"}`
+	text := "f81d4fae-7dec-11d0-a765-00a0c91e6bf6"
+	encoded := append([]byte{72}, text...)
+	testSchemaValid(t, schema)
+	testBinaryCodecPass(t, schema, text, encoded)
+	testBinaryEncodePass(t, schema, [16]byte{0xf8, 0x1d, 0x4f, 0xae, 0x7d, 0xec, 0x11, 0xd0, 0xa7, 0x65, 0x00, 0xa0, 0xc9, 0x1e, 0x6b, 0xf6}, encoded)
+	testBinaryEncodeFail(t, schema, "not-a-uuid", "cannot encode binary uuid: UUID ought to have 36 characters")
+	testBinaryEncodeFail(t, schema, "f81d4fae_7dec-11d0-a765-00a0c91e6bf6", "UUID ought to have hyphens")
+	testBinaryEncodeFail(t, schema, "f81d4fae-7dec-11d0-a765-00a0c91e6bfg", "UUID ought to have hexadecimal digits")
+	testBinaryEncodeFail(t, schema, []byte(text), "expected: string or [16]byte; received: []uint8")
+	testBinaryDecodeFail(t, schema, []byte("abc"), "cannot decode binary uuid")
+
+	testTextCodecPass(t, schema, "F81D4FAE-7DEC-11D0-A765-00A0C91E6BF6", []byte(`"F81D4FAE-7DEC-11D0-A765-00A0C91E6BF6"`))
+	testTextEncodeFail(t, schema, "abc", "cannot encode textual uuid")
+	testTextDecodeFail(t, schema, []byte(`"abc"`), "cannot decode textual uuid")
+
+	// A string that is not a UUID is decoded as a string member of a union
+	// by the standard JSON codec.
+	union := `["null", {"type": "string", "logicalType": "uuid"}, "string"]`
+	testJSONDecodePass(t, union, Union("string.uuid", text), []byte(`"`+text+`"`))
+	testJSONDecodePass(t, union, Union("string", "abc"), []byte(`"abc"`))
+	testNativeToTextualJSONPass(t, union, Union("string.uuid", text), []byte(`"`+text+`"`))
+}
+
+func TestUUIDFixedLogicalType(t *testing.T) {
+	schema := `{"type": "fixed", "name": "id", "size": 16, "logicalType": "uuid"}`
+	text := "00112233-4455-6677-8899-aabbccddeeff"
+	encoded := []byte("\x00\x11\x22\x33\x44\x55\x66\x77\x88\x99\xaa\xbb\xcc\xdd\xee\xff")
+	testSchemaValid(t, schema)
+	testSchemaInvalid(t, `{"type": "fixed", "name": "id", "size": 12, "logicalType": "uuid"}`, "cannot create uuid logical type when fixed size is not 16: 12")
+	testBinaryCodecPass(t, schema, text, encoded)
+	testBinaryEncodeFail(t, schema, "abc", "cannot encode binary uuid")
+	testBinaryDecodeFail(t, schema, encoded[:15], "short buffer")
+
+	testTextCodecPass(t, schema, text, []byte(`"\u0000\u0011\"3DUfw\u0088\u0099\u00AA\u00BB\u00CC\u00DD\u00EE\u00FF"`))
+	testTextEncodeFail(t, schema, 42, "cannot encode textual uuid")
+
+	// The named type may be referred to by its name.
+	testBinaryCodecPass(t, `{"type": "record", "name": "r", "fields": [{"name": "a", "type": `+schema+`}, {"name": "b", "type": "id"}]}`,
+		map[string]interface{}{"a": text, "b": text}, append(append([]byte{}, encoded...), encoded...))
+}
+
+func TestUUIDFixedLogicalTypeInRecordDecodeWithDefault(t *testing.T) {
+	schema := `{"type": "record", "name": "r", "fields": [{"name": "id", "type": {"type": "fixed", "name": "id", "size": 16, "logicalType": "uuid"}, "default": "\u0000\u0011\"3DUfw\u0088\u0099\u00aa\u00bb\u00cc\u00dd\u00ee\u00ff"}]}`
+	testBinaryCodecPass(t, schema, map[string]interface{}{"id": "00112233-4455-6677-8899-aabbccddeeff"}, []byte("\x00\x11\x22\x33\x44\x55\x66\x77\x88\x99\xaa\xbb\xcc\xdd\xee\xff"))
+	testBinaryEncodePass(t, schema, map[string]interface{}{}, []byte("\x00\x11\x22\x33\x44\x55\x66\x77\x88\x99\xaa\xbb\xcc\xdd\xee\xff"))
+	testSchemaInvalid(t, `{"type": "record", "name": "r", "fields": [{"name": "id", "type": {"type": "fixed", "name": "id", "size": 16, "logicalType": "uuid"}, "default": "short"}]}`,
+		`field "id": default value ought to decode: fixed default value size ought to equal schema size: 5 != 16`)
+}
+
+func TestUUIDLogicalTypeByteArrayOption(t *testing.T) {
+	text := "f81d4fae-7dec-11d0-a765-00a0c91e6bf6"
+	u := [16]byte{0xf8, 0x1d, 0x4f, 0xae, 0x7d, 0xec, 0x11, 0xd0, 0xa7, 0x65, 0x00, 0xa0, 0xc9, 0x1e, 0x6b, 0xf6}
+	option := DefaultCodecOption()
+	option.EnableUUIDByteArray = true
+	for _, schema := range []string{
+		`{"type": "string", "logicalType": "uuid"}`,
+		`{"type": "fixed", "name": "id", "size": 16, "logicalType": "uuid"}`,
+	} {
+		codec, err := NewCodecWithOptions(schema, option)
+		ensureError(t, err)
+		buf, err := codec.BinaryFromNative(nil, text)
+		ensureError(t, err)
+		datum, _, err := codec.NativeFromBinary(buf)
+		ensureError(t, err)
+		if actual, expected := datum, interface{}(u); actual != expected {
+			t.Errorf("schema: %s; GOT: %#v; WANT: %#v", schema, actual, expected)
+		}
+		buf, err = codec.TextualFromNative(nil, u)
+		ensureError(t, err)
+		datum, _, err = codec.NativeFromTextual(buf)
+		ensureError(t, err)
+		if actual, expected := datum, interface{}(u); actual != expected {
+			t.Errorf("schema: %s; GOT: %#v; WANT: %#v", schema, actual, expected)
+		}
+	}
+}
+
+func TestDurationFixedLogicalType(t *testing.T) {
+	schema := `{"type": "fixed", "name": "d", "size": 12, "logicalType": "duration"}`
+	d := Duration{Months: 1, Days: 2, Milliseconds: 3}
+	encoded := []byte{1, 0, 0, 0, 2, 0, 0, 0, 3, 0, 0, 0}
+	testSchemaValid(t, schema)
+	testSchemaInvalid(t, `{"type": "fixed", "name": "d", "size": 16, "logicalType": "duration"}`, "cannot create duration logical type when fixed size is not 12: 16")
+	testBinaryCodecPass(t, schema, d, encoded)
+	testBinaryCodecPass(t, schema, Duration{Months: math.MaxUint32, Milliseconds: 256}, []byte{255, 255, 255, 255, 0, 0, 0, 0, 0, 1, 0, 0})
+	testBinaryEncodePass(t, schema, 90*time.Minute+time.Microsecond, []byte{0, 0, 0, 0, 0, 0, 0, 0, 0xc0, 0x65, 0x52, 0x00})
+	testBinaryEncodePass(t, schema, encoded, encoded)
+	testBinaryEncodeFail(t, schema, -time.Second, "cannot encode binary duration: time.Duration ought to be non-negative")
+	testBinaryEncodeFail(t, schema, 50*24*time.Hour, "cannot encode binary duration: time.Duration ought to have at most 4294967295 milliseconds")
+	testBinaryEncodeFail(t, schema, 42, "cannot encode binary duration: expected: Duration or time.Duration; received: int")
+	testBinaryDecodeFail(t, schema, encoded[:11], "short buffer")
+
+	// The textual encoding is the string of the fixed bytes.
+	testTextCodecPass(t, schema, d, []byte(`"\u0001\u0000\u0000\u0000\u0002\u0000\u0000\u0000\u0003\u0000\u0000\u0000"`))
+	testTextEncodePass(t, schema, time.Second, []byte(`"\u0000\u0000\u0000\u0000\u0000\u0000\u0000\u0000\u00E8\u0003\u0000\u0000"`))
+	testTextDecodeFail(t, schema, []byte(`{"months":1,"days":2,"milliseconds":3}`), "cannot decode textual bytes")
+	testTextDecodeFail(t, schema, []byte(`"\u0001"`), "short buffer")
+	testTextEncodeFail(t, schema, 42, "cannot encode textual duration")
+
+	// A duration in a union is named by the name of its fixed type.
+	testBinaryCodecPass(t, `["null", `+schema+`]`, Union("d", d), append([]byte{2}, encoded...))
+}
+
+func TestDurationString(t *testing.T) {
+	cases := []struct {
+		d        Duration
+		expected string
+	}{
+		{Duration{}, "PT0S"},
+		{Duration{Months: 1, Days: 2, Milliseconds: 3}, "P1M2DT0.003S"},
+		{Duration{Months: 14}, "P14M"},
+		{Duration{Days: 1}, "P1D"},
+		{Duration{Milliseconds: 3500}, "PT3.5S"},
+		{Duration{Milliseconds: 3661000}, "PT1H1M1S"},
+		{Duration{Days: 3, Milliseconds: 90 * 60000}, "P3DT1H30M"},
+		{Duration{Milliseconds: 100 * 3600000}, "PT100H"},
+	}
+	for _, c := range cases {
+		if actual := c.d.String(); actual != c.expected {
+			t.Errorf("GOT: %v; WANT: %v", actual, c.expected)
+		}
+	}
+}
+
+func TestDurationFixedLogicalTypeInRecordDecodeWithDefault(t *testing.T) {
+	schema := `{"type": "record", "name": "r", "fields": [{"name": "d", "type": {"type": "fixed", "name": "d", "size": 12, "logicalType": "duration"}, "default": "\u0001\u0000\u0000\u0000\u0002\u0000\u0000\u0000\u0003\u0000\u0000\u0000"}]}`
+	encoded := []byte{1, 0, 0, 0, 2, 0, 0, 0, 3, 0, 0, 0}
+	testBinaryEncodePass(t, schema, map[string]interface{}{}, encoded)
+	testBinaryDecodePass(t, schema, map[string]interface{}{"d": Duration{Months: 1, Days: 2, Milliseconds: 3}}, encoded)
+	testSchemaInvalid(t, `{"type": "record", "name": "r", "fields": [{"name": "d", "type": {"type": "fixed", "name": "d", "size": 12, "logicalType": "duration"}, "default": "short"}]}`,
+		`field "d": default value ought to decode: fixed default value size ought to equal schema size: 5 != 12`)
+}
+
 func ExampleUnion_logicalType() {
 	// Supported logical types and their native go types:
 	// * timestamp-millis - time.Time
@@ -519,6 +654,8 @@ func ExampleUnion_logicalType() {
 	// * time-micros      - time.Duration
 	// * date             - int
 	// * decimal          - big.Rat
+	// * uuid             - string, or [16]byte
+	// * duration         - goavro.Duration
 	codec, err := NewCodec(`["null", {"type": "long", "logicalType": "timestamp-millis"}]`)
 	if err != nil {
 		fmt.Println(err)
@@ -29,85 +29,21 @@ func makeMapCodec(st map[string]*Codec, namespace string, schemaMap map[string]i
 	}
 
 	return &Codec{
-		typeName: &name{"map", nullNamespace},
-		nativeFromBinary: func(buf []byte) (interface{}, []byte, error) {
-			var err error
-			var value interface{}
-
-			// block count and block size
-			if value, buf, err = longNativeFromBinary(buf); err != nil {
-				return nil, nil, fmt.Errorf("cannot decode binary map block count: %s", err)
-			}
-			blockCount := value.(int64)
-			if blockCount < 0 {
-				// NOTE: A negative block count implies there is a long encoded
-				// block size following the negative block count. We have no use
-				// for the block size in this decoder, so we read and discard
-				// the value.
-				if blockCount == math.MinInt64 {
-					// The minimum number for any signed numerical type can
-					// never be made positive
-					return nil, nil, fmt.Errorf("cannot decode binary map with block count: %d", blockCount)
-				}
-				blockCount = -blockCount // convert to its positive equivalent
-				if _, buf, err = longNativeFromBinary(buf); err != nil {
-					return nil, nil, fmt.Errorf("cannot decode binary map block size: %s", err)
-				}
-			}
-			// Ensure block count does not exceed some sane value.
-			if blockCount > MaxBlockCount {
-				return nil, nil, fmt.Errorf("cannot decode binary map when block count exceeds MaxBlockCount: %d > %d", blockCount, MaxBlockCount)
-			}
-			// NOTE: While the attempt of a RAM optimization shown below is not
-			// necessary, many encoders will encode all items in a single block.
-			// We can optimize amount of RAM allocated by runtime for the array
-			// by initializing the array for that number of items.
-			mapValues := make(map[string]interface{}, blockCount)
-
-			for blockCount != 0 {
-				// Decode `blockCount` datum values from buffer
-				for i := int64(0); i < blockCount; i++ {
-					// first decode the key string
-					if value, buf, err = stringNativeFromBinary(buf); err != nil {
-						return nil, nil, fmt.Errorf("cannot decode binary map key: %s", err)
-					}
-					key := value.(string) // string decoder always returns a string
-					if _, ok := mapValues[key]; ok {
-						return nil, nil, fmt.Errorf("cannot decode binary map: duplicate key: %q", key)
-					}
-					// then decode the value
-					if value, buf, err = valueCodec.nativeFromBinary(buf); err != nil {
-						return nil, nil, fmt.Errorf("cannot decode binary map value for key %q: %s", key, err)
-					}
-					mapValues[key] = value
-				}
-				// Decode next blockCount from buffer, because there may be more blocks
-				if value, buf, err = longNativeFromBinary(buf); err != nil {
-					return nil, nil, fmt.Errorf("cannot decode binary map block count: %s", err)
-				}
-				blockCount = value.(int64)
-				if blockCount < 0 {
-					// NOTE: A negative block count implies there is a long
-					// encoded block size following the negative block count. We
-					// have no use for the block size in this decoder, so we
-					// read and discard the value.
-					if blockCount == math.MinInt64 {
-						// The minimum number for any signed numerical type can
-						// never be made positive
-						return nil, nil, fmt.Errorf("cannot decode binary map with block count: %d", blockCount)
-					}
-					blockCount = -blockCount // convert to its positive equivalent
-					if _, buf, err = longNativeFromBinary(buf); err != nil {
-						return nil, nil, fmt.Errorf("cannot decode binary map block size: %s", err)
-					}
-				}
-				// Ensure block count does not exceed some sane value.
-				if blockCount > MaxBlockCount {
-					return nil, nil, fmt.Errorf("cannot decode binary map when block count exceeds MaxBlockCount: %d > %d", blockCount, MaxBlockCount)
-				}
+		typeName:  &name{"map", nullNamespace},
+		kind:      "map",
+		itemCodec: valueCodec,
+		// NOTE: Value codec functions are looked up when invoked, because a
+		// recursive record value codec has yet to be filled in at this point.
+		nativeFromBinary: mapNativeFromBinary(func(buf []byte) (interface{}, []byte, error) {
+			return valueCodec.nativeFromBinary(buf)
+		}),
+		skipBinary: blocksSkipBinary("map", func(buf []byte) ([]byte, error) {
+			buf, err := stringSkipBinary(buf)
+			if err != nil {
+				return nil, fmt.Errorf("cannot decode binary map key: %s", err)
 			}
-			return mapValues, buf, nil
-		},
+			return valueCodec.skipBinary(buf)
+		}),
 		binaryFromNative: func(buf []byte, datum interface{}) ([]byte, error) {
 			mapValues, err := convertMap(datum)
 			if err != nil {
@@ -141,7 +77,7 @@ func makeMapCodec(st map[string]*Codec, namespace string, schemaMap map[string]i
 			return longBinaryFromNative(buf, 0) // append tailing 0 block count to signal end of Map
 		},
 		nativeFromTextual: func(buf []byte) (interface{}, []byte, error) {
-			return genericMapTextDecoder(buf, valueCodec, nil, false) // codecFromKey == nil, ignoreExtraFields == false
+			return genericMapTextDecoder(buf, valueCodec, nil, nil, false) // codecFromKey == nil, ignoreExtraFields == false
 		},
 		textualFromNative: func(buf []byte, datum interface{}) ([]byte, error) {
 			return genericMapTextEncoder(buf, datum, valueCodec, nil)
@@ -149,14 +85,98 @@ func makeMapCodec(st map[string]*Codec, namespace string, schemaMap map[string]i
 	}, nil
 }
 
+// mapNativeFromBinary returns a function that decodes a binary map, using
+// valueNativeFromBinary to decode each of its values.
+func mapNativeFromBinary(valueNativeFromBinary toNativeFn) toNativeFn {
+	return func(buf []byte) (interface{}, []byte, error) {
+		var err error
+		var value interface{}
+
+		// block count and block size
+		if value, buf, err = longNativeFromBinary(buf); err != nil {
+			return nil, nil, fmt.Errorf("cannot decode binary map block count: %s", err)
+		}
+		blockCount := value.(int64)
+		if blockCount < 0 {
+			// NOTE: A negative block count implies there is a long encoded
+			// block size following the negative block count. We have no use
+			// for the block size in this decoder, so we read and discard
+			// the value.
+			if blockCount == math.MinInt64 {
+				// The minimum number for any signed numerical type can
+				// never be made positive
+				return nil, nil, fmt.Errorf("cannot decode binary map with block count: %d", blockCount)
+			}
+			blockCount = -blockCount // convert to its positive equivalent
+			if _, buf, err = longNativeFromBinary(buf); err != nil {
+				return nil, nil, fmt.Errorf("cannot decode binary map block size: %s", err)
+			}
+		}
+		// Ensure block count does not exceed some sane value.
+		if blockCount > MaxBlockCount {
+			return nil, nil, fmt.Errorf("cannot decode binary map when block count exceeds MaxBlockCount: %d > %d", blockCount, MaxBlockCount)
+		}
+		// NOTE: While the attempt of a RAM optimization shown below is not
+		// necessary, many encoders will encode all items in a single block.
+		// We can optimize amount of RAM allocated by runtime for the array
+		// by initializing the array for that number of items.
+		mapValues := make(map[string]interface{}, blockCount)
+
+		for blockCount != 0 {
+			// Decode `blockCount` datum values from buffer
+			for i := int64(0); i < blockCount; i++ {
+				// first decode the key string
+				if value, buf, err = stringNativeFromBinary(buf); err != nil {
+					return nil, nil, fmt.Errorf("cannot decode binary map key: %s", err)
+				}
+				key := value.(string) // string decoder always returns a string
+				if _, ok := mapValues[key]; ok {
+					return nil, nil, fmt.Errorf("cannot decode binary map: duplicate key: %q", key)
+				}
+				// then decode the value
+				if value, buf, err = valueNativeFromBinary(buf); err != nil {
+					return nil, nil, fmt.Errorf("cannot decode binary map value for key %q: %s", key, err)
+				}
+				mapValues[key] = value
+			}
+			// Decode next blockCount from buffer, because there may be more blocks
+			if value, buf, err = longNativeFromBinary(buf); err != nil {
+				return nil, nil, fmt.Errorf("cannot decode binary map block count: %s", err)
+			}
+			blockCount = value.(int64)
+			if blockCount < 0 {
+				// NOTE: A negative block count implies there is a long
+				// encoded block size following the negative block count. We
+				// have no use for the block size in this decoder, so we
+				// read and discard the value.
+				if blockCount == math.MinInt64 {
+					// The minimum number for any signed numerical type can
+					// never be made positive
+					return nil, nil, fmt.Errorf("cannot decode binary map with block count: %d", blockCount)
+				}
+				blockCount = -blockCount // convert to its positive equivalent
+				if _, buf, err = longNativeFromBinary(buf); err != nil {
+					return nil, nil, fmt.Errorf("cannot decode binary map block size: %s", err)
+				}
+			}
+			// Ensure block count does not exceed some sane value.
+			if blockCount > MaxBlockCount {
+				return nil, nil, fmt.Errorf("cannot decode binary map when block count exceeds MaxBlockCount: %d > %d", blockCount, MaxBlockCount)
+			}
+		}
+		return mapValues, buf, nil
+	}
+}
+
 // genericMapTextDecoder decodes a JSON text blob to a native Go map, using the
 // codecs from codecFromKey, and if a key is not found in that map, from
 // defaultCodec if provided. If defaultCodec is nil, this function returns an
 // error if it encounters a map key that is not present in codecFromKey, unless
 // ignoreExtraFields is true, in which case the unknown field is skipped.
 // If codecFromKey is nil, every map value will be decoded using defaultCodec, if
-// possible.
-func genericMapTextDecoder(buf []byte, defaultCodec *Codec, codecFromKey map[string]*Codec, ignoreExtraFields bool) (map[string]interface{}, []byte, error) {
+// possible. When a key is not found in codecFromKey, but is found in keyFromAlias,
+// the value is decoded and stored using the key for that alias.
+func genericMapTextDecoder(buf []byte, defaultCodec *Codec, codecFromKey map[string]*Codec, keyFromAlias map[string]string, ignoreExtraFields bool) (map[string]interface{}, []byte, error) {
 	var value interface{}
 	var err error
 	var b byte
@@ -183,6 +203,11 @@ func genericMapTextDecoder(buf []byte, defaultCodec *Codec, codecFromKey map[str
 			return nil, nil, fmt.Errorf("cannot decode textual map: expected key: %s", err)
 		}
 		key := value.(string)
+		if _, ok := codecFromKey[key]; !ok {
+			if aliasedKey, ok := keyFromAlias[key]; ok {
+				key = aliasedKey
+			}
+		}
 		// Is key already used?
 		if _, ok := mapValues[key]; ok {
 			return nil, nil, fmt.Errorf("cannot decode textual map: duplicate key: %q", key)
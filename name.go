@@ -58,6 +58,17 @@ func checkString(s string) error {
 	return nil
 }
 
+// CheckName returns an ErrInvalidName when the name violates the Avro naming
+// rules. A full name, such as "com.example.User", is checked one component at a
+// time. Names of record fields and enum symbols ought to have one component.
+func CheckName(name string) error {
+	if name == nullNamespace {
+		return checkNameComponent(name)
+	}
+	_, err := newName(name, nullNamespace, nullNamespace)
+	return err
+}
+
 // name describes an Avro name in terms of its full name and namespace.
 type name struct {
 	fullName  string // the instance's Avro name
@@ -130,6 +141,33 @@ func newNameFromSchemaMap(enclosingNamespace string, schemaMap map[string]interf
 	return newName(nameString, namespaceString, enclosingNamespace)
 }
 
+// aliasesFromSchemaMap returns the full names of the aliases of a named type,
+// resolving aliases that are not fully qualified relative to the namespace of
+// the type they alias.
+func aliasesFromSchemaMap(namespace string, schemaMap map[string]interface{}) ([]string, error) {
+	value, ok := schemaMap["aliases"]
+	if !ok {
+		return nil, nil
+	}
+	values, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("schema aliases, if provided, ought to be an array of strings; received: %T: %v", value, value)
+	}
+	aliases := make([]string, len(values))
+	for i, v := range values {
+		s, ok := v.(string)
+		if !ok || s == nullNamespace {
+			return nil, fmt.Errorf("schema alias ought to be non-empty string; received: %T: %v", v, v)
+		}
+		n, err := newName(s, namespace, nullNamespace)
+		if err != nil {
+			return nil, fmt.Errorf("schema alias ought to be valid name: %s", err)
+		}
+		aliases[i] = n.fullName
+	}
+	return aliases, nil
+}
+
 func (n *name) String() string {
 	return n.fullName
 }
@@ -12,6 +12,7 @@ package goavro
 // NOTE: part of goavro package because it tests private functionality
 
 import (
+	"bytes"
 	"fmt"
 	"testing"
 )
@@ -122,3 +123,60 @@ func TestShortName(t *testing.T) {
 		}
 	}
 }
+
+func TestNameAliases(t *testing.T) {
+	codec, err := NewCodec(`{"type":"record","name":"Person","namespace":"com.example","aliases":["Human","org.old.Person"],"fields":[{"name":"friend","type":["null","Human"]}]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual, expected := fmt.Sprintf("%v", codec.Aliases()), "[com.example.Human org.old.Person]"; actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+
+	// type referred to by its alias resolves to the aliased type
+	datum := map[string]interface{}{"friend": Union("com.example.Person", map[string]interface{}{"friend": nil})}
+	buf, err := codec.BinaryFromNative(nil, datum)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual, expected := buf, []byte{0x02, 0x00}; !bytes.Equal(actual, expected) {
+		t.Errorf("GOT: %#v; WANT: %#v", actual, expected)
+	}
+
+	codec, err = NewCodec(`"string"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual := codec.Aliases(); actual != nil {
+		t.Errorf("GOT: %v; WANT: %v", actual, nil)
+	}
+}
+
+func TestNameAliasesInvalid(t *testing.T) {
+	testSchemaInvalid(t, `{"type":"fixed","name":"f1","size":4,"aliases":"f0"}`, "schema aliases, if provided, ought to be an array of strings")
+	testSchemaInvalid(t, `{"type":"fixed","name":"f1","size":4,"aliases":[13]}`, "schema alias ought to be non-empty string")
+	testSchemaInvalid(t, `{"type":"enum","name":"e1","symbols":["a"],"aliases":["&e0"]}`, "schema alias ought to be valid name")
+	testSchemaInvalid(t, `{"type":"record","name":"r1","fields":[{"name":"f1","type":"int","aliases":[""]}]}`, `Record "r1" field "f1" ought to have valid aliases`)
+}
+
+func TestCheckName(t *testing.T) {
+	for _, valid := range []string{"a", "_A1", "com.example.User"} {
+		ensureError(t, CheckName(valid))
+	}
+	cases := []struct {
+		name, expected string
+	}{
+		{"", "schema name ought to be non-empty string"},
+		{"1a", "schema name ought to start with [A-Za-z_]: 1a"},
+		{"a-b", "schema name ought to have second and remaining characters contain only [A-Za-z0-9_]: a-b"},
+		{"com..User", "schema name ought to be non-empty string"},
+		{"com.1.User", "schema name ought to start with [A-Za-z_]: 1"},
+	}
+	for _, c := range cases {
+		err := CheckName(c.name)
+		ensureError(t, err, c.expected)
+		if _, ok := err.(*ErrInvalidName); !ok {
+			t.Errorf("GOT: %#v; WANT: %T", err, &ErrInvalidName{})
+		}
+	}
+}
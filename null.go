@@ -20,6 +20,8 @@ var nullBytes = []byte("null")
 
 func nullNativeFromBinary(buf []byte) (interface{}, []byte, error) { return nil, buf, nil }
 
+func nullSkipBinary(buf []byte) ([]byte, error) { return buf, nil }
+
 func nullBinaryFromNative(buf []byte, datum interface{}) ([]byte, error) {
 	if datum != nil {
 		return nil, fmt.Errorf("cannot encode binary null: expected: Go nil; received: %T", datum)
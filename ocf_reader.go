@@ -69,6 +69,26 @@ func (ocfr *OCFReader) Codec() *Codec {
 	return ocfr.header.codec
 }
 
+// SetProjection changes the OCFReader to decode only the requested fields of
+// each datum, skipping over the others, as described by Codec.Projection. After
+// SetProjection returns, Codec returns the projected Codec.
+//
+//	ocfr, err := goavro.NewOCFReader(br)
+//	if err != nil {
+//	    return err
+//	}
+//	if err = ocfr.SetProjection("id", "user.name"); err != nil {
+//	    return err
+//	}
+func (ocfr *OCFReader) SetProjection(paths ...string) error {
+	projection, err := ocfr.header.codec.Projection(paths...)
+	if err != nil {
+		return fmt.Errorf("cannot set OCFReader projection: %s", err)
+	}
+	ocfr.header.codec = projection
+	return nil
+}
+
 // CompressionName returns the name of the compression algorithm found within
 // the OCF file.
 func (ocfr *OCFReader) CompressionName() string {
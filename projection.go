@@ -0,0 +1,242 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package goavro
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Projection returns a Codec that decodes binary data of this Codec's schema
+// into native values that include only the requested record fields. Every other
+// field is skipped over without being decoded, which makes reading a few fields
+// of wide records much faster than using NativeFromBinary.
+//
+// Each path is a sequence of record field names separated by periods, such as
+// "user.address.city". Paths pass through unions, arrays, and maps without
+// naming them: a path through an array applies to each of its items, a path
+// through a map applies to each of its values, and a path through a union
+// applies to each of its members that are records, arrays, maps, or unions.
+// Other union members, such as null, are decoded as usual. When a path ends at
+// a field, the field is decoded in full.
+//
+// The returned Codec uses this Codec's schema, and encodes and decodes textual
+// data the same as this Codec. Only its binary decoding is affected, so it may
+// be used with an OCFReader or Decoder, but its native values cannot always be
+// encoded using the schema, because they lack the fields not requested.
+//
+//	codec, err := goavro.NewCodec(`{"type":"record","name":"r","fields":[
+//	    {"name":"a","type":"long"},
+//	    {"name":"b","type":{"type":"array","items":{"type":"record","name":"i","fields":[
+//	        {"name":"c","type":"string"},{"name":"d","type":"string"}]}}}]}`)
+//	if err != nil {
+//	    fmt.Println(err)
+//	}
+//	projection, err := codec.Projection("b.d")
+//	if err != nil {
+//	    fmt.Println(err)
+//	}
+//	// projection.NativeFromBinary returns values such as:
+//	// map[string]interface{}{"b": []interface{}{map[string]interface{}{"d": "x"}}}
+func (c *Codec) Projection(paths ...string) (*Codec, error) {
+	if c.writer != nil {
+		return nil, fmt.Errorf("cannot project Codec created by NewCodecForResolution")
+	}
+	root := make(projectionNode)
+	for _, path := range paths {
+		if err := root.add(path); err != nil {
+			return nil, fmt.Errorf("cannot project path %q: %s", path, err)
+		}
+	}
+	decoder, err := projectedNativeFromBinary(c, root)
+	if err != nil {
+		return nil, fmt.Errorf("cannot project Codec: %s", err)
+	}
+	return &Codec{
+		soeHeader:       c.soeHeader,
+		schemaOriginal:  c.schemaOriginal,
+		schemaCanonical: c.schemaCanonical,
+		typeName:        c.typeName,
+		aliases:         c.aliases,
+
+		kind:        c.kind,
+		fields:      c.fields,
+		symbols:     c.symbols,
+		enumDefault: c.enumDefault,
+		size:        c.size,
+		itemCodec:   c.itemCodec,
+		members:     c.members,
+
+		nativeFromTextual: c.nativeFromTextual,
+		binaryFromNative:  c.binaryFromNative,
+		nativeFromBinary:  decoder,
+		skipBinary:        c.skipBinary,
+		textualFromNative: c.textualFromNative,
+
+		Rabin: c.Rabin,
+	}, nil
+}
+
+// projectionNode maps the names of the requested fields of a record to the
+// fields requested from their values. A nil projectionNode requests the entire
+// value.
+type projectionNode map[string]projectionNode
+
+// add requests the fields named by the path.
+func (n projectionNode) add(path string) error {
+	if path == "" {
+		return fmt.Errorf("path ought to be non-empty")
+	}
+	names := strings.Split(path, ".")
+	for i, name := range names {
+		if name == "" {
+			return fmt.Errorf("path ought to have non-empty field names")
+		}
+		child, ok := n[name]
+		if ok && child == nil {
+			return nil // entire field already requested
+		}
+		if i == len(names)-1 {
+			n[name] = nil
+			return nil
+		}
+		if !ok {
+			child = make(projectionNode)
+			n[name] = child
+		}
+		n = child
+	}
+	return nil
+}
+
+// projectedNativeFromBinary returns a function that decodes binary data of the
+// Codec into native values that include only the fields requested by the
+// projectionNode.
+func projectedNativeFromBinary(c *Codec, n projectionNode) (toNativeFn, error) {
+	if n == nil {
+		return c.nativeFromBinary, nil
+	}
+	switch c.kind {
+	case "record":
+		return projectedRecordNativeFromBinary(c, n)
+	case "union":
+		return projectedUnionNativeFromBinary(c, n)
+	case "array":
+		itemNativeFromBinary, err := projectedNativeFromBinary(c.itemCodec, n)
+		if err != nil {
+			return nil, err
+		}
+		return arrayNativeFromBinary(itemNativeFromBinary), nil
+	case "map":
+		valueNativeFromBinary, err := projectedNativeFromBinary(c.itemCodec, n)
+		if err != nil {
+			return nil, err
+		}
+		return mapNativeFromBinary(valueNativeFromBinary), nil
+	}
+	return nil, fmt.Errorf("cannot project fields of %s", c.kind)
+}
+
+func projectedRecordNativeFromBinary(c *Codec, n projectionNode) (toNativeFn, error) {
+	// NOTE: A nil decoder marks a field that is skipped.
+	decoders := make([]toNativeFn, len(c.fields))
+	var found int
+	for i, field := range c.fields {
+		child, ok := n[field.name]
+		if !ok {
+			continue
+		}
+		decoder, err := projectedNativeFromBinary(field.codec, child)
+		if err != nil {
+			return nil, fmt.Errorf("record %q field %q: %s", c.typeName, field.name, err)
+		}
+		decoders[i] = decoder
+		found++
+	}
+	if found < len(n) {
+		for name := range n {
+			if !c.hasField(name) {
+				return nil, fmt.Errorf("record %q has no field %q", c.typeName, name)
+			}
+		}
+	}
+	fields := c.fields
+
+	return func(buf []byte) (interface{}, []byte, error) {
+		recordMap := make(map[string]interface{}, found)
+		for i, decoder := range decoders {
+			var err error
+			if decoder == nil {
+				buf, err = fields[i].codec.skipBinary(buf)
+			} else {
+				var value interface{}
+				if value, buf, err = decoder(buf); err == nil {
+					recordMap[fields[i].name] = value
+				}
+			}
+			if err != nil {
+				return nil, nil, fmt.Errorf("cannot decode binary record %q field %q: %s", c.typeName, fields[i].name, err)
+			}
+		}
+		return recordMap, buf, nil
+	}, nil
+}
+
+// hasField returns true when the record Codec has a field with the name.
+func (c *Codec) hasField(name string) bool {
+	for _, field := range c.fields {
+		if field.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func projectedUnionNativeFromBinary(c *Codec, n projectionNode) (toNativeFn, error) {
+	decoders := make([]toNativeFn, len(c.members.codecFromIndex))
+	var found bool
+	for i, member := range c.members.codecFromIndex {
+		switch member.kind {
+		case "record", "union", "array", "map":
+			decoder, err := projectedNativeFromBinary(member, n)
+			if err != nil {
+				return nil, fmt.Errorf("union member %q: %s", c.members.allowedTypes[i], err)
+			}
+			decoders[i] = decoder
+			found = true
+		default:
+			decoders[i] = member.nativeFromBinary
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("cannot project fields of union without record, array, or map members: %v", c.members.allowedTypes)
+	}
+	allowedTypes := c.members.allowedTypes
+
+	return func(buf []byte) (interface{}, []byte, error) {
+		index, buf, err := longFromBinary(buf)
+		if err != nil {
+			return nil, nil, err
+		}
+		if index < 0 || index >= int64(len(decoders)) {
+			return nil, nil, fmt.Errorf("cannot decode binary union: index ought to be between 0 and %d; read index: %d", len(decoders)-1, index)
+		}
+		var decoded interface{}
+		decoded, buf, err = decoders[index](buf)
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot decode binary union item %d: %s", index+1, err)
+		}
+		if decoded == nil {
+			// do not wrap a nil value in a map
+			return nil, buf, nil
+		}
+		return Union(allowedTypes[index], decoded), buf, nil
+	}, nil
+}
@@ -0,0 +1,247 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package goavro
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+const projectionTestSchema = `{"type":"record","name":"Event","fields":[
+	{"name":"id","type":"long"},
+	{"name":"payload","type":"bytes"},
+	{"name":"tags","type":{"type":"map","values":"string"}},
+	{"name":"user","type":["null",{"type":"record","name":"User","fields":[
+		{"name":"name","type":"string"},
+		{"name":"address","type":{"type":"record","name":"Address","fields":[
+			{"name":"city","type":"string"},
+			{"name":"zip","type":"string"}]}},
+		{"name":"emails","type":{"type":"array","items":"string"}}]}]},
+	{"name":"items","type":{"type":"array","items":{"type":"record","name":"Item","fields":[
+		{"name":"sku","type":"string"},
+		{"name":"qty","type":"int"},
+		{"name":"meta","type":{"type":"map","values":{"type":"record","name":"Meta","fields":[
+			{"name":"k","type":"string"},
+			{"name":"v","type":"string"}]}}}]}}},
+	{"name":"score","type":"double"}]}`
+
+func projectionTestDatum() map[string]interface{} {
+	return map[string]interface{}{
+		"id":      int64(7),
+		"payload": []byte("some payload"),
+		"tags":    map[string]interface{}{"a": "b"},
+		"user": Union("User", map[string]interface{}{
+			"name":    "Alice",
+			"address": map[string]interface{}{"city": "Paris", "zip": "75001"},
+			"emails":  []interface{}{"alice@example.com"},
+		}),
+		"items": []interface{}{
+			map[string]interface{}{
+				"sku":  "s1",
+				"qty":  int32(2),
+				"meta": map[string]interface{}{"m": map[string]interface{}{"k": "k1", "v": "v1"}},
+			},
+			map[string]interface{}{
+				"sku":  "s2",
+				"qty":  int32(3),
+				"meta": map[string]interface{}{},
+			},
+		},
+		"score": 1.5,
+	}
+}
+
+func testProjection(t *testing.T, datum interface{}, paths []string, expected interface{}) {
+	t.Helper()
+	codec, err := NewCodec(projectionTestSchema)
+	ensureError(t, err)
+	buf, err := codec.BinaryFromNative(nil, datum)
+	ensureError(t, err)
+	buf = append(buf, 0xFF) // ensure bytes after the datum are returned
+
+	projection, err := codec.Projection(paths...)
+	ensureError(t, err)
+	actual, remaining, err := projection.NativeFromBinary(buf)
+	ensureError(t, err)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("GOT: %#v; WANT: %#v", actual, expected)
+	}
+	if !bytes.Equal(remaining, []byte{0xFF}) {
+		t.Errorf("GOT: %#v; WANT: %#v", remaining, []byte{0xFF})
+	}
+}
+
+func TestProjectionTopLevelFields(t *testing.T) {
+	testProjection(t, projectionTestDatum(), []string{"id", "score"}, map[string]interface{}{
+		"id":    int64(7),
+		"score": 1.5,
+	})
+}
+
+func TestProjectionWithoutPaths(t *testing.T) {
+	testProjection(t, projectionTestDatum(), nil, map[string]interface{}{})
+}
+
+func TestProjectionThroughUnion(t *testing.T) {
+	testProjection(t, projectionTestDatum(), []string{"user.address.city"}, map[string]interface{}{
+		"user": Union("User", map[string]interface{}{
+			"address": map[string]interface{}{"city": "Paris"},
+		}),
+	})
+
+	datum := projectionTestDatum()
+	datum["user"] = nil
+	testProjection(t, datum, []string{"user.address.city"}, map[string]interface{}{
+		"user": nil,
+	})
+}
+
+func TestProjectionThroughArrayAndMap(t *testing.T) {
+	testProjection(t, projectionTestDatum(), []string{"items.sku", "items.meta.v"}, map[string]interface{}{
+		"items": []interface{}{
+			map[string]interface{}{
+				"sku":  "s1",
+				"meta": map[string]interface{}{"m": map[string]interface{}{"v": "v1"}},
+			},
+			map[string]interface{}{
+				"sku":  "s2",
+				"meta": map[string]interface{}{},
+			},
+		},
+	})
+}
+
+func TestProjectionEntireField(t *testing.T) {
+	datum := projectionTestDatum()
+	// NOTE: A path to a field requests all of it, even when a longer path
+	// through the field is also requested.
+	testProjection(t, datum, []string{"user.name", "user", "tags"}, map[string]interface{}{
+		"tags": datum["tags"],
+		"user": datum["user"],
+	})
+}
+
+func TestProjectionSkipsBlocksWithSize(t *testing.T) {
+	codec, err := NewCodec(`{"type":"record","name":"r","fields":[{"name":"a","type":{"type":"array","items":"int"}},{"name":"b","type":"int"}]}`)
+	ensureError(t, err)
+	projection, err := codec.Projection("b")
+	ensureError(t, err)
+
+	// block count -2, block size 2, items 1 and 2, end of blocks, then b
+	actual, remaining, err := projection.NativeFromBinary([]byte{0x03, 0x04, 0x02, 0x04, 0x00, 0x06})
+	ensureError(t, err)
+	if expected := map[string]interface{}{"b": int32(3)}; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("GOT: %#v; WANT: %#v", actual, expected)
+	}
+	if len(remaining) != 0 {
+		t.Errorf("GOT: %#v; WANT: %#v", remaining, []byte{})
+	}
+
+	// block size larger than remaining bytes
+	_, _, err = projection.NativeFromBinary([]byte{0x03, 0x08, 0x02, 0x04, 0x00, 0x06})
+	ensureError(t, err, "cannot decode binary record", `field "a"`, "short buffer")
+}
+
+func TestProjectionShortBuffer(t *testing.T) {
+	codec, err := NewCodec(projectionTestSchema)
+	ensureError(t, err)
+	buf, err := codec.BinaryFromNative(nil, projectionTestDatum())
+	ensureError(t, err)
+	projection, err := codec.Projection("score")
+	ensureError(t, err)
+	_, _, err = projection.NativeFromBinary(buf[:len(buf)-1])
+	ensureError(t, err, "cannot decode binary record", `field "score"`)
+	_, _, err = projection.NativeFromBinary(buf[:3])
+	ensureError(t, err, "cannot decode binary record", `field "payload"`)
+}
+
+func TestProjectionErrors(t *testing.T) {
+	codec, err := NewCodec(projectionTestSchema)
+	ensureError(t, err)
+
+	_, err = codec.Projection("")
+	ensureError(t, err, "cannot project path", "ought to be non-empty")
+
+	_, err = codec.Projection("user..name")
+	ensureError(t, err, "cannot project path", "non-empty field names")
+
+	_, err = codec.Projection("nope")
+	ensureError(t, err, "cannot project Codec", `record "Event" has no field "nope"`)
+
+	_, err = codec.Projection("user.address.country")
+	ensureError(t, err, "cannot project Codec", `record "Address" has no field "country"`)
+
+	_, err = codec.Projection("id.value")
+	ensureError(t, err, "cannot project Codec", `field "id"`, "cannot project fields of long")
+
+	_, err = codec.Projection("user.emails.address")
+	ensureError(t, err, `field "emails"`, "cannot project fields of string")
+
+	union, err := NewCodec(`["null","string"]`)
+	ensureError(t, err)
+	_, err = union.Projection("a")
+	ensureError(t, err, "cannot project fields of union without record, array, or map members")
+
+	resolved, err := NewCodecForResolution(projectionTestSchema, projectionTestSchema, nil)
+	ensureError(t, err)
+	_, err = resolved.Projection("id")
+	ensureError(t, err, "cannot project Codec created by NewCodecForResolution")
+}
+
+func TestProjectionKeepsSchema(t *testing.T) {
+	codec, err := NewCodec(projectionTestSchema)
+	ensureError(t, err)
+	projection, err := codec.Projection("id")
+	ensureError(t, err)
+	if actual, expected := projection.CanonicalSchema(), codec.CanonicalSchema(); actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+	if actual, expected := projection.Rabin, codec.Rabin; actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+}
+
+func TestOCFReaderSetProjection(t *testing.T) {
+	bb := new(bytes.Buffer)
+	ocfw, err := NewOCFWriter(OCFConfig{W: bb, Schema: projectionTestSchema})
+	ensureError(t, err)
+	datum := projectionTestDatum()
+	ensureError(t, ocfw.Append([]interface{}{datum, datum, datum}))
+
+	ocfr, err := NewOCFReader(bb)
+	ensureError(t, err)
+
+	err = ocfr.SetProjection("nope")
+	ensureError(t, err, "cannot set OCFReader projection", `has no field "nope"`)
+
+	ensureError(t, ocfr.SetProjection("id", "items.qty"))
+
+	expected := map[string]interface{}{
+		"id": int64(7),
+		"items": []interface{}{
+			map[string]interface{}{"qty": int32(2)},
+			map[string]interface{}{"qty": int32(3)},
+		},
+	}
+	var count int
+	for ocfr.Scan() {
+		actual, err := ocfr.Read()
+		ensureError(t, err)
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("GOT: %#v; WANT: %#v", actual, expected)
+		}
+		count++
+	}
+	ensureError(t, ocfr.Err())
+	if count != 3 {
+		t.Errorf("GOT: %v; WANT: %v", count, 3)
+	}
+}
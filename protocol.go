@@ -0,0 +1,354 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package goavro
+
+import (
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Protocol is an Avro protocol, as read from a .avpr file, or compiled from
+// Avro IDL by the idl package. It declares named types, and messages whose
+// requests, responses, and errors may refer to them. A Protocol is immutable,
+// and may be used in multiple go routines simultaneously.
+type Protocol struct {
+	name      string
+	namespace string
+	doc       string
+	text      string
+	md5       [md5.Size]byte
+	types     []*Codec
+	named     map[string]*Codec // named types by their full names
+	messages  []*Message
+	byName    map[string]*Message
+}
+
+// Message is a message of a Protocol.
+type Message struct {
+	name     string
+	doc      string
+	oneWay   bool
+	request  *Codec
+	response *Codec
+	errors   *Codec
+}
+
+// NewProtocol returns a Protocol for the JSON text of the protocol
+// specification.
+//
+// The types of the protocol and of its messages are built using a single
+// symbol table, in the same way NewCodec builds the types of a schema, so they
+// may refer to the named types declared before them. The codec of each named
+// type, and of the request, response, and errors of each message, has the
+// standalone schema of its type, which defines the named types it refers to.
+//
+//	protocol, err := goavro.NewProtocol(avpr)
+//	if err != nil {
+//	    return err
+//	}
+//	message, ok := protocol.Message("send")
+//	if !ok {
+//	    return errors.New("protocol has no send message")
+//	}
+//	buf, err := message.Request().BinaryFromNative(nil, map[string]interface{}{
+//	    "to":   "someone@example.com",
+//	    "body": "Hello!",
+//	})
+func NewProtocol(protocolSpecification string) (*Protocol, error) {
+	return NewProtocolWithOptions(protocolSpecification, nil)
+}
+
+// NewProtocolWithOptions returns a Protocol for the JSON text of the protocol
+// specification, whose Codecs are created using the codec options, as by
+// NewCodecWithOptions.
+func NewProtocolWithOptions(protocolSpecification string, option *CodecOption) (*Protocol, error) {
+	if option == nil {
+		option = DefaultCodecOption()
+	}
+	cb := &codecBuilder{
+		buildCodecForTypeDescribedByMap,
+		buildCodecForTypeDescribedByString,
+		buildCodecForTypeDescribedBySlice,
+		option,
+	}
+
+	// NOTE: Building Codecs may modify the decoded schemas, so the schema
+	// trees are parsed from a second copy.
+	var protocolMap, treeMap map[string]interface{}
+	if err := json.Unmarshal([]byte(protocolSpecification), &protocolMap); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal protocol JSON: %s", err)
+	}
+	_ = json.Unmarshal([]byte(protocolSpecification), &treeMap) // already decoded once
+
+	p := &Protocol{
+		text:   protocolSpecification,
+		named:  make(map[string]*Codec),
+		byName: make(map[string]*Message),
+	}
+	var ok bool
+	if p.name, ok = protocolMap["protocol"].(string); !ok || p.name == "" {
+		return nil, fmt.Errorf("cannot create Protocol: protocol ought to have a name: %v", protocolMap["protocol"])
+	}
+	if namespace, ok := protocolMap["namespace"]; ok {
+		if p.namespace, ok = namespace.(string); !ok {
+			return nil, fmt.Errorf("cannot create Protocol: namespace ought to be string: %T", namespace)
+		}
+	}
+	p.doc, _ = protocolMap["doc"].(string)
+
+	st := newSymbolTable()
+	tp := &schemaTreeParser{named: make(map[string]Schema)}
+
+	if types, ok := protocolMap["types"]; ok {
+		list, ok := types.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("cannot create Protocol: types ought to be array: %T", types)
+		}
+		treeList := treeMap["types"].([]interface{})
+		for i, schema := range list {
+			errorToRecord(schema)
+			errorToRecord(treeList[i])
+			c, err := p.build(st, tp, schema, treeList[i], cb)
+			if err != nil {
+				return nil, fmt.Errorf("cannot create Protocol: type %d: %s", i+1, err)
+			}
+			if c.kind != "record" && c.kind != "enum" && c.kind != "fixed" {
+				return nil, fmt.Errorf("cannot create Protocol: type %d ought to be record, error, enum, or fixed: %s", i+1, c.kind)
+			}
+			p.types = append(p.types, c)
+			p.named[c.typeName.fullName] = c
+		}
+	}
+
+	if messages, ok := protocolMap["messages"]; ok {
+		messagesMap, ok := messages.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("cannot create Protocol: messages ought to be object: %T", messages)
+		}
+		treeMessages := treeMap["messages"].(map[string]interface{})
+		names := make([]string, 0, len(messagesMap))
+		for name := range messagesMap {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			message, err := p.buildMessage(st, tp, name, messagesMap[name], treeMessages[name], cb)
+			if err != nil {
+				return nil, fmt.Errorf("cannot create Protocol: message %q %s", name, err)
+			}
+			p.messages = append(p.messages, message)
+			p.byName[name] = message
+		}
+	}
+
+	text, err := javaProtocolText(protocolSpecification)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create Protocol: %s", err)
+	}
+	p.md5 = md5.Sum(text)
+	return p, nil
+}
+
+// build returns the Codec for the schema, built using the symbol table, with
+// the standalone schema of its tree.
+func (p *Protocol) build(st map[string]*Codec, tp *schemaTreeParser, schema, treeSchema interface{}, cb *codecBuilder) (*Codec, error) {
+	c, err := buildCodec(st, p.namespace, schema, cb)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := tp.parse(p.namespace, treeSchema)
+	if err != nil {
+		return nil, err // should not get here because schema was validated above
+	}
+	isNamed := c.kind == "record" || c.kind == "enum" || c.kind == "fixed"
+	if isNamed && c.schemaCanonical != "" {
+		return c, nil // named type already built has its schema
+	}
+	buf, err := marshalSchema(tree)
+	if err != nil {
+		return nil, err
+	}
+	if c.schemaCanonical != "" {
+		// NOTE: The Codecs of primitive types are shared by the symbol table,
+		// and their schemas are not JSON, so a new Codec is created.
+		if c, err = NewCodecFrom(string(buf), cb); err != nil {
+			return nil, err
+		}
+		return c, nil
+	}
+	var standalone interface{}
+	if err = json.Unmarshal(buf, &standalone); err != nil {
+		return nil, err
+	}
+	if err = c.setSchema(string(buf), standalone); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (p *Protocol) buildMessage(st map[string]*Codec, tp *schemaTreeParser, name string, message, treeMessage interface{}, cb *codecBuilder) (*Message, error) {
+	messageMap, ok := message.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("ought to be object: %T", message)
+	}
+	treeMap := treeMessage.(map[string]interface{})
+	m := &Message{name: name}
+	m.doc, _ = messageMap["doc"].(string)
+	if oneWay, ok := messageMap["one-way"]; ok {
+		if m.oneWay, ok = oneWay.(bool); !ok {
+			return nil, fmt.Errorf("one-way ought to be boolean: %T", oneWay)
+		}
+	}
+
+	// NOTE: The request of a message is a record whose fields are its
+	// parameters, which is named after the message. It is built using copies
+	// of the symbol tables, so its name does not conflict with the requests
+	// of other messages, or with types defined by responses and errors. It
+	// would replace a type of the same name its fields may refer to, so that
+	// is rejected.
+	request, ok := messageMap["request"].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("request ought to be array: %T", messageMap["request"])
+	}
+	requestName, err := newName(name, p.namespace, nullNamespace)
+	if err != nil {
+		return nil, fmt.Errorf("name ought to be valid: %s", err)
+	}
+	if _, ok := st[requestName.fullName]; ok {
+		return nil, fmt.Errorf("request record name ought not to be the name of a named type: %q", requestName.fullName)
+	}
+	requestSt := make(map[string]*Codec, len(st))
+	for k, v := range st {
+		requestSt[k] = v
+	}
+	requestTp := &schemaTreeParser{named: make(map[string]Schema, len(tp.named))}
+	for k, v := range tp.named {
+		requestTp.named[k] = v
+	}
+	m.request, err = p.build(requestSt, requestTp,
+		map[string]interface{}{"type": "record", "name": name, "fields": request},
+		map[string]interface{}{"type": "record", "name": name, "fields": treeMap["request"]},
+		cb)
+	if err != nil {
+		return nil, fmt.Errorf("request ought to be valid: %s", err)
+	}
+
+	response, ok := messageMap["response"]
+	if !ok {
+		return nil, fmt.Errorf("ought to have response")
+	}
+	if m.response, err = p.build(st, tp, response, treeMap["response"], cb); err != nil {
+		return nil, fmt.Errorf("response ought to be valid: %s", err)
+	}
+
+	// NOTE: The errors of a message are a union of a string, for system
+	// errors, followed by the declared errors.
+	errors := []interface{}{"string"}
+	treeErrors := []interface{}{"string"}
+	if declared, ok := messageMap["errors"]; ok {
+		list, ok := declared.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("errors ought to be array: %T", declared)
+		}
+		errors = append(errors, list...)
+		treeErrors = append(treeErrors, treeMap["errors"].([]interface{})...)
+	}
+	if m.errors, err = p.build(st, tp, errors, treeErrors, cb); err != nil {
+		return nil, fmt.Errorf("errors ought to be valid: %s", err)
+	}
+
+	if m.oneWay && (m.response.kind != "null" || len(errors) > 1) {
+		return nil, fmt.Errorf("ought to have null response and no errors when one-way")
+	}
+	return m, nil
+}
+
+// errorToRecord changes the type of an error schema to record, because error
+// types are encoded as records.
+func errorToRecord(schema interface{}) {
+	if schemaMap, ok := schema.(map[string]interface{}); ok && schemaMap["type"] == "error" {
+		schemaMap["type"] = "record"
+	}
+}
+
+// Name returns the name of the protocol.
+func (p *Protocol) Name() string { return p.name }
+
+// Namespace returns the namespace of the protocol, or the empty string when it
+// has none.
+func (p *Protocol) Namespace() string { return p.namespace }
+
+// Doc returns the documentation of the protocol.
+func (p *Protocol) Doc() string { return p.doc }
+
+// Text returns the JSON text of the protocol specification.
+func (p *Protocol) Text() string { return p.text }
+
+// MD5 returns the MD5 hash of the protocol, by which the handshake of Avro RPC
+// identifies it. As in the Java implementation, it is the hash of the JSON
+// text that Java writes for the protocol, which has no whitespace, defines
+// each named type where it first occurs, and keeps the order of messages and
+// custom properties, so it matches the hash Java peers compute, however the
+// specification is formatted.
+func (p *Protocol) MD5() []byte {
+	hash := make([]byte, md5.Size)
+	copy(hash, p.md5[:])
+	return hash
+}
+
+// Types returns the Codecs of the named types declared by the protocol, in the
+// order they are declared.
+func (p *Protocol) Types() []*Codec {
+	types := make([]*Codec, len(p.types))
+	copy(types, p.types)
+	return types
+}
+
+// Type returns the Codec of the named type declared by the protocol with the
+// full name.
+func (p *Protocol) Type(fullName string) (*Codec, bool) {
+	c, ok := p.named[fullName]
+	return c, ok
+}
+
+// Messages returns the messages of the protocol, sorted by their names.
+func (p *Protocol) Messages() []*Message {
+	messages := make([]*Message, len(p.messages))
+	copy(messages, p.messages)
+	return messages
+}
+
+// Message returns the message of the protocol with the name.
+func (p *Protocol) Message(name string) (*Message, bool) {
+	m, ok := p.byName[name]
+	return m, ok
+}
+
+// Name returns the name of the message.
+func (m *Message) Name() string { return m.name }
+
+// Doc returns the documentation of the message.
+func (m *Message) Doc() string { return m.doc }
+
+// OneWay returns true when the message has no response.
+func (m *Message) OneWay() bool { return m.oneWay }
+
+// Request returns the Codec of the request of the message, which is a record
+// whose fields are the parameters of the message, and is named after it.
+func (m *Message) Request() *Codec { return m.request }
+
+// Response returns the Codec of the response of the message.
+func (m *Message) Response() *Codec { return m.response }
+
+// Errors returns the Codec of the errors of the message, which is a union of
+// "string", for system errors, followed by the errors declared by the message.
+func (m *Message) Errors() *Codec { return m.errors }
@@ -0,0 +1,256 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package goavro
+
+import (
+	"bytes"
+	"crypto/md5"
+	"reflect"
+	"testing"
+)
+
+const testProtocol = `{
+  "protocol": "Mail",
+  "namespace": "com.example",
+  "doc": "Sends mail.",
+  "types": [
+    {"type": "enum", "name": "Priority", "symbols": ["LOW", "HIGH"]},
+    {"type": "record", "name": "Message", "fields": [
+      {"name": "to", "type": "string"},
+      {"name": "body", "type": "string"},
+      {"name": "priority", "type": "Priority", "default": "LOW"}
+    ]},
+    {"type": "error", "name": "Bounced", "fields": [{"name": "reason", "type": "string"}]}
+  ],
+  "messages": {
+    "send": {
+      "doc": "Sends a message.",
+      "request": [{"name": "message", "type": "Message"}, {"name": "copies", "type": "int", "default": 1}],
+      "response": ["null", "com.example.Priority"],
+      "errors": ["Bounced"]
+    },
+    "ping": {"request": [], "response": "null", "one-way": true}
+  }
+}`
+
+func TestProtocol(t *testing.T) {
+	p, err := NewProtocol(testProtocol)
+	ensureError(t, err)
+
+	if actual, expected := p.Name(), "Mail"; actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+	if actual, expected := p.Namespace(), "com.example"; actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+	if actual, expected := p.Doc(), "Sends mail."; actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+	if actual, expected := p.Text(), testProtocol; actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+	// The hash is of the text the Java implementation writes for the
+	// protocol, which keeps the order of its messages.
+	text := `{"protocol":"Mail","namespace":"com.example","doc":"Sends mail.","types":[` +
+		`{"type":"enum","name":"Priority","symbols":["LOW","HIGH"]},` +
+		`{"type":"record","name":"Message","fields":[{"name":"to","type":"string"},{"name":"body","type":"string"},{"name":"priority","type":"Priority","default":"LOW"}]},` +
+		`{"type":"error","name":"Bounced","fields":[{"name":"reason","type":"string"}]}],"messages":{` +
+		`"send":{"doc":"Sends a message.","request":[{"name":"message","type":"Message"},{"name":"copies","type":"int","default":1}],"response":["null","Priority"],"errors":["Bounced"]},` +
+		`"ping":{"request":[],"response":"null","one-way":true}}}`
+	if actual, expected := p.MD5(), md5.Sum([]byte(text)); !bytes.Equal(actual, expected[:]) {
+		t.Errorf("GOT: %x; WANT: %x", actual, expected)
+	}
+	other, err := NewProtocol(text)
+	ensureError(t, err)
+	if actual, expected := other.MD5(), p.MD5(); !bytes.Equal(actual, expected) {
+		t.Errorf("GOT: %x; WANT: %x", actual, expected)
+	}
+
+	var names []string
+	for _, c := range p.Types() {
+		names = append(names, c.TypeName().fullName)
+	}
+	if actual, expected := names, []string{"com.example.Priority", "com.example.Message", "com.example.Bounced"}; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+
+	// Each named type has a standalone schema.
+	message, ok := p.Type("com.example.Message")
+	if !ok {
+		t.Fatalf("GOT: %v; WANT: %v", ok, true)
+	}
+	if actual, expected := message.Schema(), `{"type":"record","name":"com.example.Message","fields":[{"name":"to","type":"string"},{"name":"body","type":"string"},{"name":"priority","type":{"type":"enum","name":"com.example.Priority","symbols":["LOW","HIGH"]},"default":"LOW"}]}`; actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+	standalone, err := NewCodec(message.Schema())
+	ensureError(t, err)
+	if actual, expected := message.Rabin, standalone.Rabin; actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+	if _, ok = p.Type("com.example.Missing"); ok {
+		t.Errorf("GOT: %v; WANT: %v", ok, false)
+	}
+
+	var messageNames []string
+	for _, m := range p.Messages() {
+		messageNames = append(messageNames, m.Name())
+	}
+	if actual, expected := messageNames, []string{"ping", "send"}; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+
+	send, ok := p.Message("send")
+	if !ok {
+		t.Fatalf("GOT: %v; WANT: %v", ok, true)
+	}
+	if actual, expected := send.Doc(), "Sends a message."; actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+	if send.OneWay() {
+		t.Errorf("GOT: %v; WANT: %v", send.OneWay(), false)
+	}
+	buf, err := send.Request().BinaryFromNative(nil, map[string]interface{}{
+		"message": map[string]interface{}{"to": "a", "body": "b"},
+	})
+	ensureError(t, err)
+	if actual, expected := buf, []byte{2, 'a', 2, 'b', 0, 2}; !bytes.Equal(actual, expected) {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+	if actual, expected := send.Request().TypeName().fullName, "com.example.send"; actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+	buf, err = send.Response().BinaryFromNative(nil, Union("com.example.Priority", "HIGH"))
+	ensureError(t, err)
+	if actual, expected := buf, []byte{2, 2}; !bytes.Equal(actual, expected) {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+	if actual, expected := send.Errors().Schema(), `["string",{"type":"record","name":"com.example.Bounced","fields":[{"name":"reason","type":"string"}]}]`; actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+	datum, _, err := send.Errors().NativeFromBinary([]byte{2, 2, 'x'})
+	ensureError(t, err)
+	if actual, expected := datum, Union("com.example.Bounced", map[string]interface{}{"reason": "x"}); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+
+	ping, _ := p.Message("ping")
+	if !ping.OneWay() {
+		t.Errorf("GOT: %v; WANT: %v", ping.OneWay(), true)
+	}
+	if actual, expected := ping.Errors().Schema(), `["string"]`; actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+	if actual, expected := ping.Response().Schema(), `"null"`; actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+	if _, ok = p.Message("missing"); ok {
+		t.Errorf("GOT: %v; WANT: %v", ok, false)
+	}
+}
+
+func TestProtocolJavaText(t *testing.T) {
+	p, err := NewProtocol(`{
+  "protocol": "Events", "namespace": "a.b", "javaAnnotation": "x",
+  "types": [
+    {"type": "fixed", "name": "Hash", "size": 4, "aliases": ["Digest", "c.Old"]},
+    {"type": "record", "name": "c.Event", "doc": "Tab\there \"quoted\" ü", "fields": [
+      {"name": "id", "type": {"type": "string", "logicalType": "uuid"}},
+      {"name": "at", "type": {"type": "long"}, "order": "descending"},
+      {"name": "score", "type": "double", "default": 1e7},
+      {"name": "small", "type": "double", "default": 0.00012},
+      {"name": "hash", "type": "a.b.Hash"},
+      {"name": "kind", "type": {"type": "enum", "name": "Kind", "symbols": ["A", "B"], "default": "A", "note": 1}},
+      {"name": "tags", "type": {"type": "map", "values": {"type": "array", "items": "string"}}, "default": {"z": [], "a": ["\u0001"]}, "aliases": ["labels"], "custom": true},
+      {"name": "parent", "type": ["null", "Event"], "default": null}
+    ]}
+  ],
+  "messages": {
+    "log": {
+      "request": [{"name": "event", "type": "c.Event", "order": "ignore"}, {"name": "extra", "type": {"type": "record", "name": "Extra", "fields": []}}],
+      "response": {"type": "long", "logicalType": "timestamp-millis"},
+      "tag": [1, 2.50]
+    }
+  }
+}`)
+	ensureError(t, err)
+
+	// Names are relative to the enclosing namespace, custom properties follow
+	// the attributes in the order they are specified, numbers and strings are
+	// written as by Java, and the type defined by the message is defined by
+	// the types.
+	text := `{"protocol":"Events","namespace":"a.b","javaAnnotation":"x","types":[` +
+		`{"type":"fixed","name":"Hash","size":4,"aliases":["Digest","c.Old"]},` +
+		`{"type":"record","name":"Event","namespace":"c","doc":"Tab\there \"quoted\" ü","fields":[` +
+		`{"name":"id","type":{"type":"string","logicalType":"uuid"}},` +
+		`{"name":"at","type":"long","order":"descending"},` +
+		`{"name":"score","type":"double","default":1.0E7},` +
+		`{"name":"small","type":"double","default":1.2E-4},` +
+		`{"name":"hash","type":"a.b.Hash"},` +
+		`{"name":"kind","type":{"type":"enum","name":"Kind","symbols":["A","B"],"default":"A","note":1}},` +
+		`{"name":"tags","type":{"type":"map","values":{"type":"array","items":"string"}},"default":{"z":[],"a":["\u0001"]},"aliases":["labels"],"custom":true},` +
+		`{"name":"parent","type":["null","Event"],"default":null}]},` +
+		`{"type":"record","name":"Extra","fields":[]}],"messages":{` +
+		`"log":{"tag":[1,2.5],"request":[{"name":"event","type":"c.Event"},{"name":"extra","type":"Extra"}],"response":{"type":"long","logicalType":"timestamp-millis"}}}}`
+	actual, err := javaProtocolText(p.Text())
+	ensureError(t, err)
+	if string(actual) != text {
+		t.Errorf("GOT: %s; WANT: %s", actual, text)
+	}
+	if actual, expected := p.MD5(), md5.Sum([]byte(text)); !bytes.Equal(actual, expected[:]) {
+		t.Errorf("GOT: %x; WANT: %x", actual, expected)
+	}
+}
+
+func TestJavaDoubleString(t *testing.T) {
+	cases := []struct {
+		value    float64
+		expected string
+	}{
+		{0, "0.0"},
+		{1, "1.0"},
+		{-1.5, "-1.5"},
+		{100, "100.0"},
+		{0.001, "0.001"},
+		{0.0001, "1.0E-4"},
+		{1234567.5, "1234567.5"},
+		{1e7, "1.0E7"},
+		{1.5e300, "1.5E300"},
+	}
+	for _, c := range cases {
+		if actual := javaDoubleString(c.value); actual != c.expected {
+			t.Errorf("GOT: %v; WANT: %v", actual, c.expected)
+		}
+	}
+}
+
+func TestProtocolErrors(t *testing.T) {
+	cases := []struct {
+		text, expected string
+	}{
+		{`{`, "cannot unmarshal protocol JSON"},
+		{`{"namespace":"a"}`, "cannot create Protocol: protocol ought to have a name"},
+		{`{"protocol":"P","namespace":1}`, "namespace ought to be string"},
+		{`{"protocol":"P","types":{}}`, "types ought to be array"},
+		{`{"protocol":"P","types":["string"]}`, "type 1 ought to be record, error, enum, or fixed: string"},
+		{`{"protocol":"P","types":["Missing"]}`, `type 1: unknown type name: "Missing"`},
+		{`{"protocol":"P","messages":[]}`, "messages ought to be object"},
+		{`{"protocol":"P","messages":{"m":{"response":"null"}}}`, `message "m" request ought to be array`},
+		{`{"protocol":"P","messages":{"m":{"request":[]}}}`, `message "m" ought to have response`},
+		{`{"protocol":"P","messages":{"m":{"request":[{"name":"a","type":"Missing"}],"response":"null"}}}`, `message "m" request ought to be valid`},
+		{`{"protocol":"P","messages":{"m":{"request":[],"response":"Missing"}}}`, `message "m" response ought to be valid`},
+		{`{"protocol":"P","messages":{"m":{"request":[],"response":"null","errors":["Missing"]}}}`, `message "m" errors ought to be valid`},
+		{`{"protocol":"P","messages":{"m":{"request":[],"response":"int","one-way":true}}}`, `message "m" ought to have null response and no errors when one-way`},
+		{`{"protocol":"P","namespace":"n","types":[{"type":"record","name":"ping","fields":[]}],"messages":{"ping":{"request":[{"name":"p","type":"ping"}],"response":"null"}}}`, `message "ping" request record name ought not to be the name of a named type: "n.ping"`},
+	}
+	for _, c := range cases {
+		_, err := NewProtocol(c.text)
+		ensureError(t, err, c.expected)
+	}
+}
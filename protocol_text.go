@@ -0,0 +1,796 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package goavro
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// The text of a protocol whose MD5 hash identifies it in the handshake of Avro
+// RPC is the text the Java Protocol.toString method returns, which the Java
+// implementation hashes, so this file reproduces how the Java implementation
+// parses a protocol and writes it back as JSON. The text has no whitespace,
+// and has the attributes of the protocol, its messages, and its types in a
+// fixed order, followed by their custom properties in the order they are
+// specified. Each named type is defined in the "types" attribute where it
+// first occurs, including the types defined by messages, and is referred to by
+// its name relative to the enclosing namespace elsewhere.
+
+// Attributes that are not custom properties, by what they are attributes of.
+var (
+	javaProtocolReserved = javaReserved("namespace", "protocol", "doc", "messages", "types", "error")
+	javaMessageReserved  = javaReserved("doc", "response", "request", "errors", "one-way")
+	javaSchemaReserved   = javaReserved("doc", "fields", "items", "name", "namespace", "size", "symbols", "values", "type", "aliases")
+	javaEnumReserved     = javaReserved("doc", "fields", "items", "name", "namespace", "size", "symbols", "values", "type", "aliases", "default")
+	javaFieldReserved    = javaReserved("default", "doc", "name", "order", "type", "aliases")
+)
+
+func javaReserved(names ...string) map[string]struct{} {
+	reserved := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		reserved[name] = struct{}{}
+	}
+	return reserved
+}
+
+// javaTypeNames are the names of the Java Schema.Type values, which a name is
+// not shortened to, even in its own namespace.
+var javaTypeNames = javaReserved("record", "enum", "array", "map", "union", "fixed", "string", "bytes", "int", "long", "float", "double", "boolean", "null")
+
+// jsonObject is a JSON object whose keys keep the order in which they are
+// specified. When a key is repeated, its last value is kept, at the position
+// of its first occurrence, as by the Jackson library.
+type jsonObject struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// get returns the value of the key, or nil when there is none.
+func (o *jsonObject) get(key string) interface{} {
+	return o.values[key]
+}
+
+// text returns the value of the key when it is a string, as by the textValue
+// method of a Jackson node.
+func (o *jsonObject) text(key string) (string, bool) {
+	s, ok := o.values[key].(string)
+	return s, ok
+}
+
+// decodeOrderedJSON decodes the JSON text, with objects as *jsonObject values,
+// and numbers as json.Number values.
+func decodeOrderedJSON(text string) (interface{}, error) {
+	decoder := json.NewDecoder(strings.NewReader(text))
+	decoder.UseNumber()
+	return decodeOrderedValue(decoder)
+}
+
+func decodeOrderedValue(decoder *json.Decoder) (interface{}, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token {
+	case json.Delim('{'):
+		o := &jsonObject{values: make(map[string]interface{})}
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeOrderedValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := o.values[key.(string)]; !ok {
+				o.keys = append(o.keys, key.(string))
+			}
+			o.values[key.(string)] = value
+		}
+		_, err = decoder.Token() // closing delimiter
+		return o, err
+	case json.Delim('['):
+		a := []interface{}{}
+		for decoder.More() {
+			value, err := decodeOrderedValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			a = append(a, value)
+		}
+		_, err = decoder.Token() // closing delimiter
+		return a, err
+	}
+	return token, nil
+}
+
+// javaProperty is a custom property of a protocol, message, schema, or field.
+type javaProperty struct {
+	key   string
+	value interface{}
+}
+
+func javaProperties(o *jsonObject, reserved map[string]struct{}) []javaProperty {
+	var properties []javaProperty
+	for _, key := range o.keys {
+		if _, ok := reserved[key]; !ok {
+			properties = append(properties, javaProperty{key, o.values[key]})
+		}
+	}
+	return properties
+}
+
+// javaName is the name of a named type, or an alias of it, with a namespace
+// of "" for the null namespace.
+type javaName struct {
+	name, namespace string
+}
+
+func newJavaName(name, namespace string) javaName {
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		namespace, name = name[:i], name[i+1:]
+	}
+	return javaName{name, namespace}
+}
+
+func (n javaName) fullName() string {
+	if n.namespace == nullNamespace {
+		return n.name
+	}
+	return n.namespace + "." + n.name
+}
+
+// qualified returns the name to write in the namespace, as by the Java
+// Name.getQualified method.
+func (n javaName) qualified(namespace string) string {
+	if _, ok := javaTypeNames[n.name]; n.namespace != nullNamespace && n.namespace == namespace && !ok {
+		return n.name
+	}
+	return n.fullName()
+}
+
+// javaSchema is a schema, as parsed by the Java Schema.parse method.
+type javaSchema struct {
+	kind        string // primitive type name, or record, error, enum, fixed, array, map, or union
+	name        javaName
+	doc         *string
+	fields      []*javaField
+	symbols     []string
+	enumDefault *string
+	size        interface{}
+	items       *javaSchema // items of arrays, and values of maps
+	members     []*javaSchema
+	aliases     []javaName
+	properties  []javaProperty
+}
+
+type javaField struct {
+	name       string
+	schema     *javaSchema
+	doc        *string
+	value      interface{}
+	hasDefault bool
+	order      string
+	aliases    []string
+	properties []javaProperty
+}
+
+type javaMessage struct {
+	name       string
+	doc        *string
+	properties []javaProperty
+	request    []*javaField
+	oneWay     bool
+	response   *javaSchema
+	errors     []*javaSchema
+}
+
+// javaProtocolParser parses a protocol as the Java Protocol.parse method does.
+type javaProtocolParser struct {
+	namespace string                 // default namespace of the names being parsed
+	named     map[string]*javaSchema // named types by their full names
+	order     []*javaSchema          // named types in the order they are defined
+}
+
+// javaText returns optional text attributes, such as "doc", which the Java
+// implementation ignores when they are not strings.
+func javaText(o *jsonObject, key string) *string {
+	if s, ok := o.text(key); ok {
+		return &s
+	}
+	return nil
+}
+
+func javaAliases(o *jsonObject) []string {
+	list, _ := o.get("aliases").([]interface{})
+	var aliases []string
+	seen := make(map[string]struct{}, len(list))
+	for _, alias := range list {
+		if s, ok := alias.(string); ok {
+			if _, ok = seen[s]; !ok {
+				seen[s] = struct{}{}
+				aliases = append(aliases, s)
+			}
+		}
+	}
+	return aliases
+}
+
+func (jp *javaProtocolParser) lookup(name string) (*javaSchema, error) {
+	if s, ok := jp.named[newJavaName(name, jp.namespace).fullName()]; ok {
+		return s, nil
+	}
+	if s, ok := jp.named[name]; ok {
+		return s, nil
+	}
+	return nil, fmt.Errorf("unknown type name: %q", name)
+}
+
+func (jp *javaProtocolParser) define(s *javaSchema) {
+	jp.named[s.name.fullName()] = s
+	jp.order = append(jp.order, s)
+}
+
+func (jp *javaProtocolParser) parse(schema interface{}) (*javaSchema, error) {
+	switch v := schema.(type) {
+	case string:
+		if isPrimitiveTypeName(v) {
+			return &javaSchema{kind: v}, nil
+		}
+		return jp.lookup(v)
+	case []interface{}:
+		s := &javaSchema{kind: "union"}
+		for _, member := range v {
+			m, err := jp.parse(member)
+			if err != nil {
+				return nil, err
+			}
+			s.members = append(s.members, m)
+		}
+		return s, nil
+	case *jsonObject:
+		return jp.parseObject(v)
+	}
+	return nil, fmt.Errorf("unknown schema: %v", schema)
+}
+
+func isPrimitiveTypeName(name string) bool {
+	switch name {
+	case "null", "boolean", "int", "long", "float", "double", "bytes", "string":
+		return true
+	}
+	return false
+}
+
+func (jp *javaProtocolParser) parseObject(o *jsonObject) (*javaSchema, error) {
+	kind, ok := o.text("type")
+	if !ok {
+		return jp.parse(o.get("type"))
+	}
+	s := &javaSchema{kind: kind}
+	savedNamespace := jp.namespace
+	defer func() { jp.namespace = savedNamespace }()
+
+	switch kind {
+	case "record", "error", "enum", "fixed":
+		namespace, ok := o.text("namespace")
+		if !ok {
+			namespace = jp.namespace
+		}
+		name, _ := o.text("name")
+		s.name = newJavaName(name, namespace)
+		jp.namespace = s.name.namespace
+		s.doc = javaText(o, "doc")
+	}
+
+	reserved := javaSchemaReserved
+	switch kind {
+	case "record", "error":
+		jp.define(s)
+		fields, _ := o.get("fields").([]interface{})
+		for _, field := range fields {
+			f, err := jp.parseField(field)
+			if err != nil {
+				return nil, err
+			}
+			s.fields = append(s.fields, f)
+		}
+	case "enum":
+		reserved = javaEnumReserved
+		symbols, _ := o.get("symbols").([]interface{})
+		for _, symbol := range symbols {
+			text, _ := symbol.(string)
+			s.symbols = append(s.symbols, text)
+		}
+		s.enumDefault = javaText(o, "default")
+		jp.define(s)
+	case "fixed":
+		s.size = o.get("size")
+		jp.define(s)
+	case "array", "map":
+		key := "items"
+		if kind == "map" {
+			key = "values"
+		}
+		items, err := jp.parse(o.get(key))
+		if err != nil {
+			return nil, err
+		}
+		s.items = items
+	default:
+		if !isPrimitiveTypeName(kind) {
+			// NOTE: An object whose type is a name refers to the named type,
+			// and its other attributes are ignored.
+			return jp.lookup(kind)
+		}
+	}
+	s.properties = javaProperties(o, reserved)
+	for _, alias := range javaAliases(o) {
+		name := newJavaName(alias, s.name.namespace)
+		if !containsJavaName(s.aliases, name) {
+			s.aliases = append(s.aliases, name)
+		}
+	}
+	return s, nil
+}
+
+func containsJavaName(names []javaName, name javaName) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (jp *javaProtocolParser) parseField(field interface{}) (*javaField, error) {
+	o, ok := field.(*jsonObject)
+	if !ok {
+		return nil, fmt.Errorf("field ought to be object: %v", field)
+	}
+	f := &javaField{order: "ascending"}
+	f.name, _ = o.text("name")
+	f.doc = javaText(o, "doc")
+	var err error
+	if f.schema, err = jp.parse(o.get("type")); err != nil {
+		return nil, err
+	}
+	if order, ok := o.text("order"); ok {
+		f.order = strings.ToLower(order)
+	}
+	f.value, f.hasDefault = o.values["default"]
+	if text, ok := f.value.(string); ok && (f.schema.kind == "float" || f.schema.kind == "double") {
+		// NOTE: Textual defaults of floating point fields, such as "NaN", are
+		// parsed as numbers.
+		if value, err := strconv.ParseFloat(text, 64); err == nil {
+			f.value = value
+		}
+	}
+	f.aliases = javaAliases(o)
+	f.properties = javaProperties(o, javaFieldReserved)
+	return f, nil
+}
+
+// parseMessage parses a message as the Java Protocol.parseMessage method does,
+// which ignores the order of request parameters.
+func (jp *javaProtocolParser) parseMessage(name string, message interface{}) (*javaMessage, error) {
+	o, ok := message.(*jsonObject)
+	if !ok {
+		return nil, fmt.Errorf("message %q ought to be object: %v", name, message)
+	}
+	m := &javaMessage{name: name, doc: javaText(o, "doc"), properties: javaProperties(o, javaMessageReserved)}
+	request, _ := o.get("request").([]interface{})
+	for _, field := range request {
+		f, err := jp.parseField(field)
+		if err != nil {
+			return nil, err
+		}
+		f.order = "ascending"
+		m.request = append(m.request, f)
+	}
+	m.oneWay, _ = o.get("one-way").(bool)
+	if response, ok := o.values["response"]; ok {
+		var err error
+		if m.response, err = jp.parse(response); err != nil {
+			return nil, err
+		}
+	}
+	errors, _ := o.get("errors").([]interface{})
+	for _, name := range errors {
+		s, err := jp.parse(name)
+		if err != nil {
+			return nil, err
+		}
+		m.errors = append(m.errors, s)
+	}
+	return m, nil
+}
+
+// javaProtocolText returns the text the Java Protocol.toString method returns
+// for the protocol specification.
+func javaProtocolText(protocolSpecification string) ([]byte, error) {
+	decoded, err := decodeOrderedJSON(protocolSpecification)
+	if err != nil {
+		return nil, err
+	}
+	protocol, ok := decoded.(*jsonObject)
+	if !ok {
+		return nil, fmt.Errorf("protocol ought to be object: %T", decoded)
+	}
+	name, _ := protocol.text("protocol")
+	namespace, hasNamespace := protocol.text("namespace")
+
+	jp := &javaProtocolParser{namespace: namespace, named: make(map[string]*javaSchema)}
+	types, _ := protocol.get("types").([]interface{})
+	for _, schema := range types {
+		if _, err = jp.parse(schema); err != nil {
+			return nil, err
+		}
+	}
+	var messages []*javaMessage
+	if messagesObject, ok := protocol.get("messages").(*jsonObject); ok {
+		for _, key := range messagesObject.keys {
+			m, err := jp.parseMessage(key, messagesObject.values[key])
+			if err != nil {
+				return nil, err
+			}
+			messages = append(messages, m)
+		}
+	}
+
+	w := &javaProtocolWriter{namespace: namespace, defined: make(map[string]struct{})}
+	w.buf.WriteByte('{')
+	w.stringAttribute("protocol", name)
+	if hasNamespace {
+		w.stringAttribute("namespace", namespace)
+	}
+	if doc := javaText(protocol, "doc"); doc != nil {
+		w.stringAttribute("doc", *doc)
+	}
+	w.properties(javaProperties(protocol, javaProtocolReserved))
+	w.attribute("types")
+	w.buf.WriteByte('[')
+	var count int
+	for _, s := range jp.order {
+		if _, ok := w.defined[s.name.fullName()]; ok {
+			continue // defined where it first occurs in another type
+		}
+		if count++; count > 1 {
+			w.buf.WriteByte(',')
+		}
+		w.write(s)
+	}
+	w.buf.WriteByte(']')
+
+	// NOTE: Every named type is defined by the types, so messages only refer
+	// to them.
+	w.attribute("messages")
+	w.buf.WriteByte('{')
+	for _, m := range messages {
+		w.attribute(m.name)
+		w.buf.WriteByte('{')
+		if m.doc != nil {
+			w.stringAttribute("doc", *m.doc)
+		}
+		w.properties(m.properties)
+		w.attribute("request")
+		w.fields(m.request)
+		if m.oneWay {
+			w.stringAttribute("response", "null")
+			w.attribute("one-way")
+			w.buf.WriteString("true")
+		} else {
+			w.attribute("response")
+			w.write(m.response)
+			if len(m.errors) > 0 {
+				w.attribute("errors")
+				w.write(&javaSchema{kind: "union", members: m.errors})
+			}
+		}
+		w.buf.WriteByte('}')
+	}
+	w.buf.WriteString("}}")
+	return w.buf.Bytes(), nil
+}
+
+// javaProtocolWriter writes JSON text as the Jackson generator used by the
+// Java toJson methods of protocols and schemas does.
+type javaProtocolWriter struct {
+	buf       bytes.Buffer
+	namespace string              // namespace names are written relative to
+	defined   map[string]struct{} // full names of the named types written
+}
+
+// attribute writes the key of an attribute, preceded by a comma unless it is
+// the first of its object.
+func (w *javaProtocolWriter) attribute(key string) {
+	if b := w.buf.Bytes(); len(b) > 0 && b[len(b)-1] != '{' && b[len(b)-1] != '[' {
+		w.buf.WriteByte(',')
+	}
+	w.quote(key)
+	w.buf.WriteByte(':')
+}
+
+func (w *javaProtocolWriter) stringAttribute(key, value string) {
+	w.attribute(key)
+	w.quote(value)
+}
+
+func (w *javaProtocolWriter) properties(properties []javaProperty) {
+	for _, p := range properties {
+		w.attribute(p.key)
+		w.value(p.value)
+	}
+}
+
+func (w *javaProtocolWriter) write(s *javaSchema) {
+	switch s.kind {
+	case "record", "error", "enum", "fixed":
+		fullName := s.name.fullName()
+		if _, ok := w.defined[fullName]; ok {
+			w.quote(s.name.qualified(w.namespace))
+			return
+		}
+		w.defined[fullName] = struct{}{}
+		w.buf.WriteByte('{')
+		w.stringAttribute("type", s.kind)
+		w.stringAttribute("name", s.name.name)
+		if s.name.namespace != w.namespace {
+			w.stringAttribute("namespace", s.name.namespace)
+		}
+		if s.doc != nil {
+			w.stringAttribute("doc", *s.doc)
+		}
+		switch s.kind {
+		case "enum":
+			w.attribute("symbols")
+			w.buf.WriteByte('[')
+			for i, symbol := range s.symbols {
+				if i > 0 {
+					w.buf.WriteByte(',')
+				}
+				w.quote(symbol)
+			}
+			w.buf.WriteByte(']')
+			if s.enumDefault != nil {
+				w.stringAttribute("default", *s.enumDefault)
+			}
+		case "fixed":
+			w.attribute("size")
+			w.value(s.size)
+		default:
+			// NOTE: The names in the fields are relative to the namespace of
+			// the record.
+			savedNamespace := w.namespace
+			w.namespace = s.name.namespace
+			w.attribute("fields")
+			w.fields(s.fields)
+			w.namespace = savedNamespace
+		}
+		w.properties(s.properties)
+		if len(s.aliases) > 0 {
+			w.attribute("aliases")
+			w.buf.WriteByte('[')
+			for i, alias := range s.aliases {
+				if i > 0 {
+					w.buf.WriteByte(',')
+				}
+				w.quote(alias.qualified(s.name.namespace))
+			}
+			w.buf.WriteByte(']')
+		}
+		w.buf.WriteByte('}')
+	case "array", "map":
+		w.buf.WriteByte('{')
+		w.stringAttribute("type", s.kind)
+		if s.kind == "array" {
+			w.attribute("items")
+		} else {
+			w.attribute("values")
+		}
+		w.write(s.items)
+		w.properties(s.properties)
+		w.buf.WriteByte('}')
+	case "union":
+		w.buf.WriteByte('[')
+		for i, member := range s.members {
+			if i > 0 {
+				w.buf.WriteByte(',')
+			}
+			w.write(member)
+		}
+		w.buf.WriteByte(']')
+	default:
+		if len(s.properties) == 0 {
+			w.quote(s.kind)
+			return
+		}
+		w.buf.WriteByte('{')
+		w.stringAttribute("type", s.kind)
+		w.properties(s.properties)
+		w.buf.WriteByte('}')
+	}
+}
+
+func (w *javaProtocolWriter) fields(fields []*javaField) {
+	w.buf.WriteByte('[')
+	for i, f := range fields {
+		if i > 0 {
+			w.buf.WriteByte(',')
+		}
+		w.buf.WriteByte('{')
+		w.stringAttribute("name", f.name)
+		w.attribute("type")
+		w.write(f.schema)
+		if f.doc != nil {
+			w.stringAttribute("doc", *f.doc)
+		}
+		if f.hasDefault {
+			w.attribute("default")
+			w.value(f.value)
+		}
+		if f.order != "ascending" {
+			w.stringAttribute("order", f.order)
+		}
+		if len(f.aliases) > 0 {
+			w.attribute("aliases")
+			w.buf.WriteByte('[')
+			for i, alias := range f.aliases {
+				if i > 0 {
+					w.buf.WriteByte(',')
+				}
+				w.quote(alias)
+			}
+			w.buf.WriteByte(']')
+		}
+		w.properties(f.properties)
+		w.buf.WriteByte('}')
+	}
+	w.buf.WriteByte(']')
+}
+
+// value writes a JSON value decoded by decodeOrderedJSON.
+func (w *javaProtocolWriter) value(value interface{}) {
+	switch v := value.(type) {
+	case nil:
+		w.buf.WriteString("null")
+	case bool:
+		w.buf.WriteString(strconv.FormatBool(v))
+	case string:
+		w.quote(v)
+	case json.Number:
+		w.number(v)
+	case float64:
+		w.double(v)
+	case []interface{}:
+		w.buf.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				w.buf.WriteByte(',')
+			}
+			w.value(item)
+		}
+		w.buf.WriteByte(']')
+	case *jsonObject:
+		w.buf.WriteByte('{')
+		for _, key := range v.keys {
+			w.attribute(key)
+			w.value(v.values[key])
+		}
+		w.buf.WriteByte('}')
+	}
+}
+
+// number writes a JSON number as Jackson does after reading it: integers as
+// they are specified, without the sign of a negative zero, and other numbers
+// as doubles.
+func (w *javaProtocolWriter) number(n json.Number) {
+	text := string(n)
+	if !strings.ContainsAny(text, ".eE") {
+		if text == "-0" {
+			text = "0"
+		}
+		w.buf.WriteString(text)
+		return
+	}
+	f, _ := strconv.ParseFloat(text, 64) // already decoded as JSON
+	w.double(f)
+}
+
+// double writes the number as the Java Double.toString method formats it,
+// which Jackson uses for doubles, quoting values that are not JSON numbers.
+func (w *javaProtocolWriter) double(f float64) {
+	switch {
+	case math.IsNaN(f):
+		w.buf.WriteString(`"NaN"`)
+		return
+	case math.IsInf(f, 1):
+		w.buf.WriteString(`"Infinity"`)
+		return
+	case math.IsInf(f, -1):
+		w.buf.WriteString(`"-Infinity"`)
+		return
+	}
+	w.buf.WriteString(javaDoubleString(f))
+}
+
+// javaDoubleString returns the number formatted as by the Java Double.toString
+// method: in decimal notation with at least one digit after the point when its
+// magnitude is at least 10^-3 and less than 10^7, and otherwise in scientific
+// notation, such as "1.0E7".
+func javaDoubleString(f float64) string {
+	if f == 0 {
+		if math.Signbit(f) {
+			return "-0.0"
+		}
+		return "0.0"
+	}
+	var sign string
+	if f < 0 {
+		sign, f = "-", -f
+	}
+	// The shortest digits that identify the number, and its decimal exponent.
+	mantissa := strconv.FormatFloat(f, 'e', -1, 64)
+	i := strings.IndexByte(mantissa, 'e')
+	exponent, _ := strconv.Atoi(mantissa[i+1:])
+	digits := strings.Replace(mantissa[:i], ".", "", 1)
+
+	if f >= 1e-3 && f < 1e7 {
+		if exponent < 0 {
+			return sign + "0." + strings.Repeat("0", -exponent-1) + digits
+		}
+		if len(digits) <= exponent+1 {
+			return sign + digits + strings.Repeat("0", exponent+1-len(digits)) + ".0"
+		}
+		return sign + digits[:exponent+1] + "." + digits[exponent+1:]
+	}
+	if len(digits) == 1 {
+		digits += "0"
+	}
+	return sign + digits[:1] + "." + digits[1:] + "E" + strconv.Itoa(exponent)
+}
+
+// quote writes the string as a JSON string, escaping only quotation marks,
+// reverse solidi, and control characters, as Jackson does.
+func (w *javaProtocolWriter) quote(s string) {
+	const hex = "0123456789ABCDEF"
+	w.buf.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"', '\\':
+			w.buf.WriteByte('\\')
+			w.buf.WriteByte(c)
+		case '\b':
+			w.buf.WriteString(`\b`)
+		case '\t':
+			w.buf.WriteString(`\t`)
+		case '\n':
+			w.buf.WriteString(`\n`)
+		case '\f':
+			w.buf.WriteString(`\f`)
+		case '\r':
+			w.buf.WriteString(`\r`)
+		default:
+			if c < 0x20 {
+				w.buf.WriteString(`\u00`)
+				w.buf.WriteByte(hex[c>>4])
+				w.buf.WriteByte(hex[c&0xF])
+			} else {
+				w.buf.WriteByte(c)
+			}
+		}
+	}
+	w.buf.WriteByte('"')
+}
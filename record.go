@@ -13,6 +13,15 @@ import (
 	"fmt"
 )
 
+// recordField describes a single field of a record schema.
+type recordField struct {
+	name         string
+	aliases      []string
+	codec        *Codec
+	defaultValue interface{} // native default value, valid only when hasDefault
+	hasDefault   bool
+}
+
 func makeRecordCodec(st map[string]*Codec, enclosingNamespace string, schemaMap map[string]interface{}, cb *codecBuilder) (*Codec, error) {
 	// NOTE: To support recursive data types, create the codec and register it
 	// using the specified name, and fill in the codec functions later.
@@ -31,7 +40,9 @@ func makeRecordCodec(st map[string]*Codec, enclosingNamespace string, schemaMap
 	}
 
 	codecFromFieldName := make(map[string]*Codec)
+	fieldNameFromAlias := make(map[string]string)
 	codecFromIndex := make([]*Codec, len(fieldSchemas))
+	fieldFromIndex := make([]*recordField, len(fieldSchemas))
 	nameFromIndex := make([]string, len(fieldSchemas))
 	defaultValueFromName := make(map[string]interface{}, len(fieldSchemas))
 
@@ -59,6 +70,13 @@ func makeRecordCodec(st map[string]*Codec, enclosingNamespace string, schemaMap
 		if _, ok := codecFromFieldName[fieldName]; ok {
 			return nil, fmt.Errorf("Record %q field %d ought to have unique name: %q", c.typeName, i+1, fieldName)
 		}
+		fieldAliases, err := fieldAliasesFromSchemaMap(fieldSchemaMap)
+		if err != nil {
+			return nil, fmt.Errorf("Record %q field %q ought to have valid aliases: %s", c.typeName, fieldName, err)
+		}
+		for _, alias := range fieldAliases {
+			fieldNameFromAlias[alias] = fieldName
+		}
 
 		if defaultValue, ok := fieldSchemaMap["default"]; ok {
 			typeNameShort := fieldCodec.typeName.short()
@@ -132,6 +150,11 @@ func makeRecordCodec(st map[string]*Codec, enclosingNamespace string, schemaMap
 					if err != nil {
 						return nil, fmt.Errorf("Record %q field %q: default value ought to decode from textual: %w", c.typeName, fieldName, err)
 					}
+				} else if fieldCodec.nativeFromDefault != nil {
+					defaultValue, err = fieldCodec.nativeFromDefault(defaultValue)
+					if err != nil {
+						return nil, fmt.Errorf("Record %q field %q: default value ought to decode: %s", c.typeName, fieldName, err)
+					}
 				} else {
 					debug("fieldName: %q; type: %q; defaultValue: %T(%#v)\n", fieldName, c.typeName, defaultValue, defaultValue)
 				}
@@ -148,8 +171,13 @@ func makeRecordCodec(st map[string]*Codec, enclosingNamespace string, schemaMap
 		nameFromIndex[i] = fieldName
 		codecFromIndex[i] = fieldCodec
 		codecFromFieldName[fieldName] = fieldCodec
+		defaultValue, hasDefault := defaultValueFromName[fieldName]
+		fieldFromIndex[i] = &recordField{name: fieldName, aliases: fieldAliases, codec: fieldCodec, defaultValue: defaultValue, hasDefault: hasDefault}
 	}
 
+	c.kind = "record"
+	c.fields = fieldFromIndex
+
 	c.binaryFromNative = func(buf []byte, datum interface{}) ([]byte, error) {
 		valueMap, ok := datum.(map[string]interface{})
 		if !ok {
@@ -179,6 +207,16 @@ func makeRecordCodec(st map[string]*Codec, enclosingNamespace string, schemaMap
 		return buf, nil
 	}
 
+	c.skipBinary = func(buf []byte) ([]byte, error) {
+		for i, fieldCodec := range codecFromIndex {
+			var err error
+			if buf, err = fieldCodec.skipBinary(buf); err != nil {
+				return nil, fmt.Errorf("cannot decode binary record %q field %q: %s", c.typeName, nameFromIndex[i], err)
+			}
+		}
+		return buf, nil
+	}
+
 	c.nativeFromBinary = func(buf []byte) (interface{}, []byte, error) {
 		recordMap := make(map[string]interface{}, len(codecFromIndex))
 		for i, fieldCodec := range codecFromIndex {
@@ -202,8 +240,9 @@ func makeRecordCodec(st map[string]*Codec, enclosingNamespace string, schemaMap
 		var err error
 		// NOTE: Setting `defaultCodec == nil` instructs genericMapTextDecoder
 		// to return an error when a field name is not found in the
-		// codecFromFieldName map, unless ignoreExtraFields is true.
-		mapValues, buf, err = genericMapTextDecoder(buf, nil, codecFromFieldName, ignoreExtraFields)
+		// codecFromFieldName map, unless ignoreExtraFields is true. Fields
+		// may also be named by any of their aliases.
+		mapValues, buf, err = genericMapTextDecoder(buf, nil, codecFromFieldName, fieldNameFromAlias, ignoreExtraFields)
 		if err != nil {
 			return nil, nil, fmt.Errorf("cannot decode textual record %q: %s", c.typeName, err)
 		}
@@ -251,3 +290,28 @@ func makeRecordCodec(st map[string]*Codec, enclosingNamespace string, schemaMap
 
 	return c, nil
 }
+
+// fieldAliasesFromSchemaMap returns the aliases of a record field. Unlike the
+// aliases of named types, field aliases are simple names without namespaces.
+func fieldAliasesFromSchemaMap(fieldSchemaMap map[string]interface{}) ([]string, error) {
+	value, ok := fieldSchemaMap["aliases"]
+	if !ok {
+		return nil, nil
+	}
+	values, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("aliases ought to be an array of strings; received: %T: %v", value, value)
+	}
+	aliases := make([]string, len(values))
+	for i, v := range values {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("alias ought to be string; received: %T: %v", v, v)
+		}
+		if err := checkNameComponent(s); err != nil {
+			return nil, err
+		}
+		aliases[i] = s
+	}
+	return aliases, nil
+}
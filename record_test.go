@@ -385,6 +385,13 @@ func TestRecordTextCodecPass(t *testing.T) {
 	testTextDecodePass(t, `{"name":"r1","type":"record","fields":[{"name":"string","type":"string"},{"name":"bytes","type":"bytes"}]}`, map[string]interface{}{"string": silly, "bytes": []byte(silly)}, []byte(` { "string" : "\u0001\u2318 " , "bytes" : "\u0001\u00E2\u008C\u0098 " }`))
 }
 
+func TestRecordTextDecodeFieldAliases(t *testing.T) {
+	schema := `{"name":"r1","type":"record","fields":[{"name":"name","type":"string","aliases":["fullName","label"]},{"name":"age","type":"int"}]}`
+	testTextDecodePass(t, schema, map[string]interface{}{"name": "Bob", "age": 42}, []byte(`{"fullName":"Bob","age":42}`))
+	testTextDecodePass(t, schema, map[string]interface{}{"name": "Bob", "age": 42}, []byte(`{"age":42,"label":"Bob"}`))
+	testTextDecodeFail(t, schema, []byte(`{"name":"Bob","fullName":"Bob","age":42}`), "duplicate key")
+}
+
 func TestRecordIgnoreExtraFieldsFromTextual(t *testing.T) {
 	schema := `{"name":"r1","type":"record","fields":[{"name":"name","type":"string"},{"name":"age","type":"int"}]}`
 
@@ -0,0 +1,498 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+// Package registry is a client of the Confluent Schema Registry REST API,
+// which returns ready to use goavro Codec values, and caches the schemas that
+// never change, such as those looked up by their ID.
+//
+// The package also provides MockRegistry, an in-memory Schema Registry served
+// over HTTP on the loopback interface, so programs using the Client may be
+// tested offline.
+//
+//	client, err := registry.NewClient(registry.Config{URL: "http://localhost:8081"})
+//	if err != nil {
+//	    return err
+//	}
+//	id, err := client.Register("orders-value", schema)
+//	if err != nil {
+//	    return err
+//	}
+//	codec, err := client.CodecByID(id)
+package registry
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/linkedin/goavro/v2"
+)
+
+// contentType is the media type of the requests and responses of version 1 of
+// the Schema Registry API.
+const contentType = "application/vnd.schemaregistry.v1+json"
+
+// LatestVersion may be provided in place of a version number to refer to the
+// latest version of the schema of a subject.
+const LatestVersion = -1
+
+// Compatibility levels of the Schema Registry, which determine the schemas a
+// new version of the schema of a subject is checked against before it is
+// registered.
+const (
+	CompatibilityNone               = "NONE"
+	CompatibilityBackward           = "BACKWARD"
+	CompatibilityBackwardTransitive = "BACKWARD_TRANSITIVE"
+	CompatibilityForward            = "FORWARD"
+	CompatibilityForwardTransitive  = "FORWARD_TRANSITIVE"
+	CompatibilityFull               = "FULL"
+	CompatibilityFullTransitive     = "FULL_TRANSITIVE"
+)
+
+// Config specifies the Schema Registry used by a Client.
+type Config struct {
+	// URL is the base URL of the Schema Registry, such as
+	// "http://localhost:8081".
+	URL string
+
+	// HTTPClient is used to send requests to the Schema Registry. When nil,
+	// http.DefaultClient is used.
+	HTTPClient *http.Client
+
+	// Username and Password, when Username is not empty, are sent with each
+	// request using HTTP basic authentication.
+	Username, Password string
+
+	// Framing is the format of the headers whose schema IDs the Client looks
+	// up as a goavro.CodecLookup: goavro.FramingConfluent, or
+	// goavro.FramingApicurio when the registry is an Apicurio Registry serving
+	// its Confluent compatible API. When nil, goavro.FramingConfluent is used.
+	Framing goavro.Framing
+}
+
+// Schema is one version of the schema of a subject.
+type Schema struct {
+	Subject string
+	Version int
+	ID      int
+	Schema  string
+	Codec   *goavro.Codec
+}
+
+// Error is returned when the Schema Registry responds to a request with an
+// error, so callers may tell, for instance, when a subject does not exist.
+type Error struct {
+	Op         string `json:"-"`          // operation that failed, such as "cannot get subjects"
+	StatusCode int    `json:"-"`          // HTTP status code of the response
+	Code       int    `json:"error_code"` // Schema Registry error code, such as 40401
+	Message    string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	if e.Op == "" {
+		return fmt.Sprintf("schema registry error %d: %s", e.Code, e.Message)
+	}
+	return fmt.Sprintf("%s: schema registry error %d: %s", e.Op, e.Code, e.Message)
+}
+
+// wrapError returns err prefixed by the formatted operation that failed. An
+// Error is returned as is, with the operation recorded in it, so callers may
+// inspect it.
+func wrapError(err error, format string, a ...interface{}) error {
+	op := fmt.Sprintf(format, a...)
+	if registryError, ok := err.(*Error); ok {
+		registryError.Op = op
+		return registryError
+	}
+	return fmt.Errorf("%s: %s", op, err)
+}
+
+// Client sends requests to a Schema Registry. It is safe to use a Client from
+// multiple goroutines.
+type Client struct {
+	url                string
+	httpClient         *http.Client
+	username, password string
+	framing            goavro.Framing
+
+	mu       sync.RWMutex
+	codecs   map[int]*goavro.Codec     // schema ID -> Codec
+	ids      map[subjectSchema]int     // schema ID of schemas registered under subjects, by text and canonical JSON form
+	versions map[subjectVersion]Schema // schemas of versions of subjects
+}
+
+type subjectSchema struct {
+	subject, schema string
+}
+
+type subjectVersion struct {
+	subject string
+	version int
+}
+
+// NewClient returns a Client of the Schema Registry specified by config.
+func NewClient(config Config) (*Client, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("cannot create Client when URL is empty")
+	}
+	if _, err := url.Parse(config.URL); err != nil {
+		return nil, fmt.Errorf("cannot create Client: %s", err)
+	}
+	httpClient := config.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	framing := config.Framing
+	if framing == nil {
+		framing = goavro.FramingConfluent
+	}
+	return &Client{
+		url:        strings.TrimSuffix(config.URL, "/"),
+		httpClient: httpClient,
+		username:   config.Username,
+		password:   config.Password,
+		framing:    framing,
+		codecs:     make(map[int]*goavro.Codec),
+		ids:        make(map[subjectSchema]int),
+		versions:   make(map[subjectVersion]Schema),
+	}, nil
+}
+
+// Subjects returns the names of the registered subjects.
+func (c *Client) Subjects() ([]string, error) {
+	var subjects []string
+	if err := c.do(http.MethodGet, "/subjects", nil, &subjects); err != nil {
+		return nil, wrapError(err, "cannot get subjects")
+	}
+	return subjects, nil
+}
+
+// Versions returns the version numbers of the schemas registered under the
+// subject.
+func (c *Client) Versions(subject string) ([]int, error) {
+	var versions []int
+	if err := c.do(http.MethodGet, "/subjects/"+url.PathEscape(subject)+"/versions", nil, &versions); err != nil {
+		return nil, wrapError(err, "cannot get versions of subject %q", subject)
+	}
+	return versions, nil
+}
+
+// CodecByID returns the Codec for the schema with the ID, which is how the
+// schema of a datum is identified in the Confluent wire format.
+func (c *Client) CodecByID(id int) (*goavro.Codec, error) {
+	c.mu.RLock()
+	codec, ok := c.codecs[id]
+	c.mu.RUnlock()
+	if ok {
+		return codec, nil
+	}
+
+	var response struct {
+		Schema string `json:"schema"`
+	}
+	if err := c.do(http.MethodGet, "/schemas/ids/"+strconv.Itoa(id), nil, &response); err != nil {
+		return nil, wrapError(err, "cannot get schema %d", id)
+	}
+	codec, err := goavro.NewCodec(response.Schema)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get schema %d: %s", id, err)
+	}
+	return c.cacheCodec(id, codec), nil
+}
+
+// LookupCodec returns the Codec of the schema with the ID in a header of the
+// Framing of the Client, so a Client may be the CodecLookup of a
+// goavro.FramedDecoder. It returns an error for headers of other Framings,
+// without sending a request, because their IDs do not refer to the schemas of
+// the registry.
+//
+//	decoder := goavro.NewFramedDecoder(client)
+func (c *Client) LookupCodec(framing goavro.Framing, id uint64) (*goavro.Codec, error) {
+	if framing != c.framing {
+		return nil, fmt.Errorf("cannot look up schema of %s header in schema registry whose Framing is %s", framing, c.framing)
+	}
+	if uint64(int(id)) != id || int(id) < 0 {
+		return nil, fmt.Errorf("cannot look up schema %d: ID ought to fit in an int", id)
+	}
+	return c.CodecByID(int(id))
+}
+
+// Schema returns the version of the schema of the subject, or the latest one
+// when version is LatestVersion.
+func (c *Client) Schema(subject string, version int) (*Schema, error) {
+	key := subjectVersion{subject, version}
+	if version != LatestVersion {
+		c.mu.RLock()
+		schema, ok := c.versions[key]
+		c.mu.RUnlock()
+		if ok {
+			return &schema, nil
+		}
+	}
+
+	var response struct {
+		Subject string `json:"subject"`
+		Version int    `json:"version"`
+		ID      int    `json:"id"`
+		Schema  string `json:"schema"`
+	}
+	if err := c.do(http.MethodGet, "/subjects/"+url.PathEscape(subject)+"/versions/"+versionPath(version), nil, &response); err != nil {
+		return nil, wrapError(err, "cannot get version %s of subject %q", versionPath(version), subject)
+	}
+	codec, err := c.codec(response.ID, response.Schema)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get version %s of subject %q: %s", versionPath(version), subject, err)
+	}
+	schema := Schema{Subject: response.Subject, Version: response.Version, ID: response.ID, Schema: response.Schema, Codec: codec}
+
+	c.mu.Lock()
+	c.versions[subjectVersion{subject, schema.Version}] = schema
+	c.mu.Unlock()
+	return &schema, nil
+}
+
+// Register registers the schema under the subject, unless it is already
+// registered, and returns its ID. The Schema Registry refuses to register a
+// schema that is incompatible with the earlier versions of the subject, as
+// determined by the compatibility level of the subject.
+func (c *Client) Register(subject, schema string) (int, error) {
+	key, id, ok := c.cachedID(subject, schema)
+	if ok {
+		return id, nil
+	}
+	codec, err := goavro.NewCodec(schema)
+	if err != nil {
+		return 0, fmt.Errorf("cannot register schema under subject %q: %s", subject, err)
+	}
+
+	var response struct {
+		ID int `json:"id"`
+	}
+	if err = c.do(http.MethodPost, "/subjects/"+url.PathEscape(subject)+"/versions", schemaRequest(schema), &response); err != nil {
+		return 0, wrapError(err, "cannot register schema under subject %q", subject)
+	}
+	c.cacheCodec(response.ID, codec)
+	c.cacheID(subject, schema, key, response.ID)
+	return response.ID, nil
+}
+
+// Lookup returns the version of the subject that has the schema. It returns an
+// Error when the schema is not registered under the subject.
+func (c *Client) Lookup(subject, schema string) (*Schema, error) {
+	key, _, _ := c.cachedID(subject, schema)
+
+	var response struct {
+		Subject string `json:"subject"`
+		Version int    `json:"version"`
+		ID      int    `json:"id"`
+	}
+	if err := c.do(http.MethodPost, "/subjects/"+url.PathEscape(subject), schemaRequest(schema), &response); err != nil {
+		return nil, wrapError(err, "cannot look up schema under subject %q", subject)
+	}
+	codec, err := c.codec(response.ID, schema)
+	if err != nil {
+		return nil, fmt.Errorf("cannot look up schema under subject %q: %s", subject, err)
+	}
+	c.cacheID(subject, schema, key, response.ID)
+	return &Schema{Subject: response.Subject, Version: response.Version, ID: response.ID, Schema: schema, Codec: codec}, nil
+}
+
+// IsCompatible returns true when the schema is compatible with the version of
+// the schema of the subject, or the latest one when version is LatestVersion,
+// as determined by the compatibility level of the subject.
+func (c *Client) IsCompatible(subject, schema string, version int) (bool, error) {
+	var response struct {
+		IsCompatible bool `json:"is_compatible"`
+	}
+	if err := c.do(http.MethodPost, "/compatibility/subjects/"+url.PathEscape(subject)+"/versions/"+versionPath(version), schemaRequest(schema), &response); err != nil {
+		return false, wrapError(err, "cannot check compatibility with version %s of subject %q", versionPath(version), subject)
+	}
+	return response.IsCompatible, nil
+}
+
+// Compatibility returns the compatibility level of the subject, or the global
+// compatibility level when subject is empty.
+func (c *Client) Compatibility(subject string) (string, error) {
+	var response struct {
+		CompatibilityLevel string `json:"compatibilityLevel"`
+	}
+	if err := c.do(http.MethodGet, configPath(subject), nil, &response); err != nil {
+		return "", wrapError(err, "cannot get compatibility level")
+	}
+	return response.CompatibilityLevel, nil
+}
+
+// SetCompatibility sets the compatibility level of the subject, or the global
+// compatibility level when subject is empty.
+func (c *Client) SetCompatibility(subject, level string) error {
+	request := struct {
+		Compatibility string `json:"compatibility"`
+	}{level}
+	if err := c.do(http.MethodPut, configPath(subject), request, nil); err != nil {
+		return wrapError(err, "cannot set compatibility level")
+	}
+	return nil
+}
+
+// codec returns the cached Codec for the schema ID, or creates one from the
+// schema.
+func (c *Client) codec(id int, schema string) (*goavro.Codec, error) {
+	c.mu.RLock()
+	codec, ok := c.codecs[id]
+	c.mu.RUnlock()
+	if ok {
+		return codec, nil
+	}
+	codec, err := goavro.NewCodec(schema)
+	if err != nil {
+		return nil, err
+	}
+	return c.cacheCodec(id, codec), nil
+}
+
+// cacheCodec caches the Codec for the schema ID, unless another goroutine
+// already did, and returns the cached Codec.
+func (c *Client) cacheCodec(id int, codec *goavro.Codec) *goavro.Codec {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cached, ok := c.codecs[id]; ok {
+		return cached
+	}
+	c.codecs[id] = codec
+	return codec
+}
+
+// do sends a request with the JSON encoding of body, when not nil, and decodes
+// the JSON response into response, when not nil.
+func (c *Client) do(method, path string, body, response interface{}) error {
+	var r io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(encoded)
+	}
+	req, err := http.NewRequest(method, c.url+path, r)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", contentType)
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	encoded, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		registryError := &Error{StatusCode: resp.StatusCode}
+		if err = json.Unmarshal(encoded, registryError); err != nil || registryError.Message == "" {
+			registryError.Code = resp.StatusCode
+			registryError.Message = http.StatusText(resp.StatusCode)
+		}
+		return registryError
+	}
+	if response == nil {
+		return nil
+	}
+	if err = json.Unmarshal(encoded, response); err != nil {
+		return fmt.Errorf("cannot decode response: %s", err)
+	}
+	return nil
+}
+
+func schemaRequest(schema string) interface{} {
+	return struct {
+		Schema string `json:"schema"`
+	}{schema}
+}
+
+// cachedID returns the cached ID of the schema registered under the subject,
+// looking it up first by the schema as provided, and then by its canonical
+// JSON form, which is also returned as the key to cache the ID by.
+func (c *Client) cachedID(subject, schema string) (subjectSchema, int, bool) {
+	c.mu.RLock()
+	id, ok := c.ids[subjectSchema{subject, schema}]
+	c.mu.RUnlock()
+	if ok {
+		return subjectSchema{subject, schema}, id, true
+	}
+	canonical, err := canonicalJSON(schema)
+	if err != nil {
+		// NOTE: The schema is invalid, which compiling it will report.
+		return subjectSchema{subject, schema}, 0, false
+	}
+	key := subjectSchema{subject, canonical}
+	c.mu.RLock()
+	id, ok = c.ids[key]
+	c.mu.RUnlock()
+	return key, id, ok
+}
+
+// cacheID caches the ID of the schema registered under the subject, both by the
+// schema as provided, and by its canonical JSON form.
+func (c *Client) cacheID(subject, schema string, key subjectSchema, id int) {
+	c.mu.Lock()
+	c.ids[subjectSchema{subject, schema}] = id
+	c.ids[key] = id
+	c.mu.Unlock()
+}
+
+// canonicalJSON returns the schema without insignificant whitespace, and with
+// the keys of its objects sorted, so equivalent texts of a schema have the
+// same canonical JSON form.
+//
+// NOTE: The Parsing Canonical Form of the Avro specification is not used,
+// because it drops attributes the Schema Registry tells schemas apart by, such
+// as default values.
+func canonicalJSON(schema string) (string, error) {
+	decoder := json.NewDecoder(strings.NewReader(schema))
+	decoder.UseNumber()
+	var parsed interface{}
+	if err := decoder.Decode(&parsed); err != nil {
+		return "", err
+	}
+	canonical, err := json.Marshal(parsed)
+	if err != nil {
+		return "", err
+	}
+	return string(canonical), nil
+}
+
+func versionPath(version int) string {
+	if version == LatestVersion {
+		return "latest"
+	}
+	return strconv.Itoa(version)
+}
+
+func configPath(subject string) string {
+	if subject == "" {
+		return "/config"
+	}
+	return "/config/" + url.PathEscape(subject)
+}
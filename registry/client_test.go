@@ -0,0 +1,316 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package registry
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/linkedin/goavro/v2"
+)
+
+const (
+	testSchemaV1 = `{"type":"record","name":"Order","namespace":"com.example","fields":[{"name":"id","type":"long"}]}`
+	testSchemaV2 = `{"type":"record","name":"Order","namespace":"com.example","fields":[{"name":"id","type":"long"},{"name":"note","type":"string","default":""}]}`
+	testSchemaV3 = `{"type":"record","name":"Order","namespace":"com.example","fields":[{"name":"id","type":"long"},{"name":"total","type":"double"}]}`
+)
+
+func ensureError(tb testing.TB, err error, contains ...string) {
+	tb.Helper()
+	if len(contains) == 0 || (len(contains) == 1 && contains[0] == "") {
+		if err != nil {
+			tb.Fatalf("GOT: %v; WANT: %v", err, contains)
+		}
+		return
+	}
+	if err == nil {
+		tb.Errorf("GOT: %v; WANT: %v", err, contains)
+		return
+	}
+	for _, stub := range contains {
+		if stub != "" && !strings.Contains(err.Error(), stub) {
+			tb.Errorf("GOT: %v; WANT: %q", err, stub)
+		}
+	}
+}
+
+// ensureRegistryError ensures the error is an Error with the code, and returns
+// it.
+func ensureRegistryError(tb testing.TB, err error, code int) *Error {
+	tb.Helper()
+	registryError, ok := err.(*Error)
+	if !ok {
+		tb.Fatalf("GOT: %#v; WANT: %T", err, registryError)
+	}
+	if registryError.Code != code {
+		tb.Errorf("GOT: %v; WANT: %v", registryError.Code, code)
+	}
+	return registryError
+}
+
+// countingTransport counts the requests sent using it.
+type countingTransport struct {
+	count int32
+}
+
+func (ct *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&ct.count, 1)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func newCountingClient(t *testing.T, mock *MockRegistry) (*Client, *countingTransport) {
+	transport := new(countingTransport)
+	client, err := NewClient(Config{URL: mock.URL + "/", HTTPClient: &http.Client{Transport: transport}})
+	ensureError(t, err)
+	return client, transport
+}
+
+func TestNewClient(t *testing.T) {
+	_, err := NewClient(Config{})
+	ensureError(t, err, "cannot create Client when URL is empty")
+	_, err = NewClient(Config{URL: ":"})
+	ensureError(t, err, "cannot create Client")
+}
+
+func TestClientRegisterAndCodecByID(t *testing.T) {
+	mock := NewMockRegistry()
+	defer mock.Close()
+	client, transport := newCountingClient(t, mock)
+
+	id, err := client.Register("orders-value", testSchemaV1)
+	ensureError(t, err)
+	if id != 1 {
+		t.Errorf("GOT: %v; WANT: %v", id, 1)
+	}
+
+	// Registering the same schema again is answered from the cache.
+	again, err := client.Register("orders-value", testSchemaV1)
+	ensureError(t, err)
+	if again != id {
+		t.Errorf("GOT: %v; WANT: %v", again, id)
+	}
+	if count := atomic.LoadInt32(&transport.count); count != 1 {
+		t.Errorf("GOT: %v; WANT: %v", count, 1)
+	}
+
+	// So is the same schema written with different whitespace.
+	spaced, err := client.Register("orders-value", strings.Replace(testSchemaV1, ",", ", ", -1))
+	ensureError(t, err)
+	if spaced != id {
+		t.Errorf("GOT: %v; WANT: %v", spaced, id)
+	}
+	if count := atomic.LoadInt32(&transport.count); count != 1 {
+		t.Errorf("GOT: %v; WANT: %v", count, 1)
+	}
+
+	// A Codec for a schema this Client registered is already cached.
+	codec, err := client.CodecByID(id)
+	ensureError(t, err)
+	if count := atomic.LoadInt32(&transport.count); count != 1 {
+		t.Errorf("GOT: %v; WANT: %v", count, 1)
+	}
+
+	// Another Client looks up the schema once.
+	other, transport := newCountingClient(t, mock)
+	for i := 0; i < 3; i++ {
+		otherCodec, err := other.CodecByID(id)
+		ensureError(t, err)
+		if actual, expected := otherCodec.CanonicalSchema(), codec.CanonicalSchema(); actual != expected {
+			t.Errorf("GOT: %v; WANT: %v", actual, expected)
+		}
+	}
+	if count := atomic.LoadInt32(&transport.count); count != 1 {
+		t.Errorf("GOT: %v; WANT: %v", count, 1)
+	}
+
+	buf, err := codec.BinaryFromNative(nil, map[string]interface{}{"id": int64(42)})
+	ensureError(t, err)
+	datum, _, err := codec.NativeFromBinary(buf)
+	ensureError(t, err)
+	if expected := map[string]interface{}{"id": int64(42)}; !reflect.DeepEqual(datum, expected) {
+		t.Errorf("GOT: %v; WANT: %v", datum, expected)
+	}
+
+	_, err = other.CodecByID(99)
+	ensureError(t, err, "cannot get schema 99")
+	ensureRegistryError(t, err, 40403)
+}
+
+func TestClientSubjectsAndVersions(t *testing.T) {
+	mock := NewMockRegistry()
+	defer mock.Close()
+	client := mock.Client()
+
+	_, err := client.Versions("orders-value")
+	ensureRegistryError(t, err, 40401)
+
+	_, err = client.Register("orders-value", testSchemaV1)
+	ensureError(t, err)
+	id2, err := client.Register("orders-value", testSchemaV2)
+	ensureError(t, err)
+	_, err = client.Register("orders/key", `"string"`)
+	ensureError(t, err)
+
+	subjects, err := client.Subjects()
+	ensureError(t, err)
+	if expected := []string{"orders-value", "orders/key"}; !reflect.DeepEqual(subjects, expected) {
+		t.Errorf("GOT: %v; WANT: %v", subjects, expected)
+	}
+
+	versions, err := client.Versions("orders-value")
+	ensureError(t, err)
+	if expected := []int{1, 2}; !reflect.DeepEqual(versions, expected) {
+		t.Errorf("GOT: %v; WANT: %v", versions, expected)
+	}
+
+	latest, err := client.Schema("orders-value", LatestVersion)
+	ensureError(t, err)
+	if latest.Subject != "orders-value" || latest.Version != 2 || latest.ID != id2 || latest.Schema != testSchemaV2 || latest.Codec == nil {
+		t.Errorf("GOT: %+v; WANT: version 2 with ID %d", latest, id2)
+	}
+
+	first, err := client.Schema("orders-value", 1)
+	ensureError(t, err)
+	if first.Version != 1 || first.Schema != testSchemaV1 {
+		t.Errorf("GOT: %+v; WANT: version 1", first)
+	}
+
+	_, err = client.Schema("orders-value", 3)
+	ensureRegistryError(t, err, 40402)
+
+	found, err := client.Lookup("orders-value", testSchemaV1)
+	ensureError(t, err)
+	if found.Version != 1 || found.ID != first.ID || found.Codec == nil {
+		t.Errorf("GOT: %+v; WANT: version 1 with ID %d", found, first.ID)
+	}
+
+	_, err = client.Lookup("orders-value", testSchemaV3)
+	ensureRegistryError(t, err, 40403)
+
+	_, err = client.Lookup("orders-value", `"nope"`)
+	ensureError(t, err, "cannot look up schema", "unknown type name")
+}
+
+func TestClientCompatibility(t *testing.T) {
+	mock := NewMockRegistry()
+	defer mock.Close()
+	client := mock.Client()
+
+	level, err := client.Compatibility("")
+	ensureError(t, err)
+	if level != CompatibilityBackward {
+		t.Errorf("GOT: %v; WANT: %v", level, CompatibilityBackward)
+	}
+
+	_, err = client.Register("orders-value", testSchemaV1)
+	ensureError(t, err)
+
+	// A new required field cannot read data written without it.
+	compatible, err := client.IsCompatible("orders-value", testSchemaV3, LatestVersion)
+	ensureError(t, err)
+	if compatible {
+		t.Errorf("GOT: %v; WANT: %v", compatible, false)
+	}
+	_, err = client.Register("orders-value", testSchemaV3)
+	ensureError(t, err, `cannot register schema under subject "orders-value": schema registry error 409`)
+	if registryError := ensureRegistryError(t, err, 409); registryError.StatusCode != http.StatusConflict {
+		t.Errorf("GOT: %v; WANT: %v", registryError.StatusCode, http.StatusConflict)
+	}
+
+	compatible, err = client.IsCompatible("orders-value", testSchemaV2, 1)
+	ensureError(t, err)
+	if !compatible {
+		t.Errorf("GOT: %v; WANT: %v", compatible, true)
+	}
+
+	ensureError(t, client.SetCompatibility("orders-value", CompatibilityNone))
+	level, err = client.Compatibility("orders-value")
+	ensureError(t, err)
+	if level != CompatibilityNone {
+		t.Errorf("GOT: %v; WANT: %v", level, CompatibilityNone)
+	}
+	_, err = client.Register("orders-value", testSchemaV3)
+	ensureError(t, err)
+
+	err = client.SetCompatibility("", "SOMETIMES")
+	ensureRegistryError(t, err, 42203)
+}
+
+func TestClientBasicAuthAndErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); !ok || username != "user" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get("Accept") != contentType {
+			w.WriteHeader(http.StatusNotAcceptable)
+			return
+		}
+		_, _ = w.Write([]byte(`["a"`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Config{URL: server.URL})
+	ensureError(t, err)
+	_, err = client.Subjects()
+	ensureError(t, err, "cannot get subjects", "schema registry error 401: Unauthorized")
+
+	client, err = NewClient(Config{URL: server.URL, Username: "user", Password: "secret"})
+	ensureError(t, err)
+	_, err = client.Subjects()
+	ensureError(t, err, "cannot get subjects", "cannot decode response")
+}
+
+func TestClientLookupCodec(t *testing.T) {
+	mock := NewMockRegistry()
+	defer mock.Close()
+	client := mock.Client()
+
+	id, err := client.Register("orders-value", testSchemaV1)
+	ensureError(t, err)
+	codec, err := client.CodecByID(id)
+	ensureError(t, err)
+
+	datum := map[string]interface{}{"id": int64(42)}
+	buf, err := codec.FramedFromNative(nil, goavro.FramingConfluent, uint64(id), datum)
+	ensureError(t, err)
+	decoded, _, err := goavro.NewFramedDecoder(client).NativeFromFramed(buf)
+	ensureError(t, err)
+	if !reflect.DeepEqual(decoded, datum) {
+		t.Errorf("GOT: %v; WANT: %v", decoded, datum)
+	}
+
+	_, err = client.LookupCodec(goavro.FramingSOE, codec.Rabin)
+	ensureError(t, err, "cannot look up schema of single-object encoding header in schema registry whose Framing is Confluent")
+	_, err = client.LookupCodec(goavro.FramingApicurio, uint64(id))
+	ensureError(t, err, "cannot look up schema of Apicurio header in schema registry whose Framing is Confluent")
+	_, err = client.LookupCodec(goavro.FramingConfluent, 99)
+	ensureRegistryError(t, err, 40403)
+
+	// A Client of an Apicurio Registry looks up only the global ID of the
+	// Apicurio header, not the ID 0 of the Confluent header it parses as.
+	transport := new(countingTransport)
+	apicurio, err := NewClient(Config{URL: mock.URL, HTTPClient: &http.Client{Transport: transport}, Framing: goavro.FramingApicurio})
+	ensureError(t, err)
+	buf, err = codec.FramedFromNative(nil, goavro.FramingApicurio, uint64(id), datum)
+	ensureError(t, err)
+	decoded, _, err = goavro.NewFramedDecoder(apicurio).NativeFromFramed(buf)
+	ensureError(t, err)
+	if !reflect.DeepEqual(decoded, datum) {
+		t.Errorf("GOT: %v; WANT: %v", decoded, datum)
+	}
+	if count := atomic.LoadInt32(&transport.count); count != 1 {
+		t.Errorf("GOT: %v; WANT: %v", count, 1)
+	}
+}
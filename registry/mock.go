@@ -0,0 +1,329 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package registry
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/linkedin/goavro/v2"
+)
+
+// MockRegistry is an in-memory Schema Registry served over HTTP on the
+// loopback interface, for testing programs that use a Client without a real
+// Schema Registry. It serves the parts of the Schema Registry API used by
+// Client, and checks the compatibility of new schemas using
+// goavro.CheckBackwardTransitive and its siblings.
+//
+//	mock := registry.NewMockRegistry()
+//	defer mock.Close()
+//	client := mock.Client()
+type MockRegistry struct {
+	*httptest.Server
+
+	mu            sync.Mutex
+	schemas       []string          // schemas[id-1] is the schema with the ID
+	ids           map[string]int    // canonical JSON form of schema -> ID
+	subjects      map[string][]int  // subject -> IDs of its versions, which start at 1
+	compatibility map[string]string // subject -> compatibility level; "" is the global level
+}
+
+// NewMockRegistry starts and returns a MockRegistry, whose global
+// compatibility level is CompatibilityBackward. The caller should call Close
+// when finished, to shut it down.
+func NewMockRegistry() *MockRegistry {
+	m := &MockRegistry{
+		ids:           make(map[string]int),
+		subjects:      make(map[string][]int),
+		compatibility: map[string]string{"": CompatibilityBackward},
+	}
+	m.Server = httptest.NewServer(m)
+	return m
+}
+
+// Client returns a new Client of the MockRegistry.
+func (m *MockRegistry) Client() *Client {
+	client, _ := NewClient(Config{URL: m.URL, HTTPClient: m.Server.Client()}) // URL is always valid
+	return client
+}
+
+// mockError is the body of an error response.
+type mockError struct {
+	status  int
+	Code    int    `json:"error_code"`
+	Message string `json:"message"`
+}
+
+var (
+	errMockNotFound        = &mockError{http.StatusNotFound, 404, "HTTP 404 Not Found"}
+	errMockSubjectNotFound = &mockError{http.StatusNotFound, 40401, "Subject not found."}
+	errMockVersionNotFound = &mockError{http.StatusNotFound, 40402, "Version not found."}
+	errMockSchemaNotFound  = &mockError{http.StatusNotFound, 40403, "Schema not found"}
+	errMockIncompatible    = &mockError{http.StatusConflict, 409, "Schema being registered is incompatible with an earlier schema"}
+	errMockInvalidVersion  = &mockError{http.StatusUnprocessableEntity, 42202, "The specified version is not a valid version id."}
+	errMockInvalidLevel    = &mockError{http.StatusUnprocessableEntity, 42203, "Invalid compatibility level."}
+	errMockInvalidSchema   = &mockError{http.StatusUnprocessableEntity, 42201, "Input schema is an invalid Avro schema"}
+)
+
+// ServeHTTP serves a request of the Schema Registry API.
+func (m *MockRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var segments []string
+	for _, segment := range strings.Split(strings.Trim(r.URL.EscapedPath(), "/"), "/") {
+		unescaped, err := url.PathUnescape(segment)
+		if err != nil {
+			m.respond(w, nil, errMockNotFound)
+			return
+		}
+		segments = append(segments, unescaped)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var response interface{}
+	var merr *mockError
+	route := r.Method + " " + segments[0]
+	switch {
+	case route == "GET subjects" && len(segments) == 1:
+		response = m.subjectNames()
+	case route == "GET subjects" && len(segments) == 3 && segments[2] == "versions":
+		response, merr = m.versions(segments[1])
+	case route == "GET subjects" && len(segments) == 4 && segments[2] == "versions":
+		response, merr = m.version(segments[1], segments[3])
+	case route == "POST subjects" && len(segments) == 3 && segments[2] == "versions":
+		response, merr = m.register(segments[1], r)
+	case route == "POST subjects" && len(segments) == 2:
+		response, merr = m.lookup(segments[1], r)
+	case route == "GET schemas" && len(segments) == 3 && segments[1] == "ids":
+		response, merr = m.schemaByID(segments[2])
+	case route == "POST compatibility" && len(segments) == 5 && segments[1] == "subjects" && segments[3] == "versions":
+		response, merr = m.checkCompatibility(segments[2], segments[4], r)
+	case route == "GET config" && len(segments) <= 2:
+		response = map[string]string{"compatibilityLevel": m.level(strings.Join(segments[1:], ""))}
+	case route == "PUT config" && len(segments) <= 2:
+		response, merr = m.setLevel(strings.Join(segments[1:], ""), r)
+	default:
+		merr = errMockNotFound
+	}
+	m.respond(w, response, merr)
+}
+
+func (m *MockRegistry) respond(w http.ResponseWriter, response interface{}, merr *mockError) {
+	w.Header().Set("Content-Type", contentType)
+	if merr != nil {
+		w.WriteHeader(merr.status)
+		response = merr
+	}
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+func (m *MockRegistry) subjectNames() []string {
+	names := make([]string, 0, len(m.subjects))
+	for subject := range m.subjects {
+		names = append(names, subject)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (m *MockRegistry) versions(subject string) (interface{}, *mockError) {
+	ids, ok := m.subjects[subject]
+	if !ok {
+		return nil, errMockSubjectNotFound
+	}
+	versions := make([]int, len(ids))
+	for i := range ids {
+		versions[i] = i + 1
+	}
+	return versions, nil
+}
+
+func (m *MockRegistry) version(subject, version string) (interface{}, *mockError) {
+	ids, ok := m.subjects[subject]
+	if !ok {
+		return nil, errMockSubjectNotFound
+	}
+	index, merr := versionIndex(ids, version)
+	if merr != nil {
+		return nil, merr
+	}
+	return m.schemaResponse(subject, index), nil
+}
+
+func (m *MockRegistry) schemaResponse(subject string, index int) interface{} {
+	id := m.subjects[subject][index]
+	return map[string]interface{}{
+		"subject": subject,
+		"version": index + 1,
+		"id":      id,
+		"schema":  m.schemas[id-1],
+	}
+}
+
+// versionIndex returns the index into the IDs of the versions of a subject of
+// the version, which is either a version number or "latest".
+func versionIndex(ids []int, version string) (int, *mockError) {
+	if version == "latest" {
+		return len(ids) - 1, nil
+	}
+	number, err := strconv.Atoi(version)
+	if err != nil || number < 1 {
+		return 0, errMockInvalidVersion
+	}
+	if number > len(ids) {
+		return 0, errMockVersionNotFound
+	}
+	return number - 1, nil
+}
+
+func (m *MockRegistry) register(subject string, r *http.Request) (interface{}, *mockError) {
+	schema, key, merr := readSchema(r)
+	if merr != nil {
+		return nil, merr
+	}
+	ids := m.subjects[subject]
+	if id, ok := m.ids[key]; ok {
+		for _, existing := range ids {
+			if existing == id {
+				return map[string]int{"id": id}, nil
+			}
+		}
+	}
+
+	history := make([]string, len(ids))
+	for i, id := range ids {
+		history[i] = m.schemas[id-1]
+	}
+	level := m.level(subject)
+	if !strings.HasSuffix(level, "_TRANSITIVE") && len(history) > 1 {
+		history = history[len(history)-1:]
+	}
+	if !compatible(level, schema, history) {
+		return nil, errMockIncompatible
+	}
+
+	id, ok := m.ids[key]
+	if !ok {
+		m.schemas = append(m.schemas, schema)
+		id = len(m.schemas)
+		m.ids[key] = id
+	}
+	m.subjects[subject] = append(ids, id)
+	return map[string]int{"id": id}, nil
+}
+
+func (m *MockRegistry) lookup(subject string, r *http.Request) (interface{}, *mockError) {
+	_, key, merr := readSchema(r)
+	if merr != nil {
+		return nil, merr
+	}
+	ids, ok := m.subjects[subject]
+	if !ok {
+		return nil, errMockSubjectNotFound
+	}
+	if id, ok := m.ids[key]; ok {
+		for index, existing := range ids {
+			if existing == id {
+				return m.schemaResponse(subject, index), nil
+			}
+		}
+	}
+	return nil, errMockSchemaNotFound
+}
+
+func (m *MockRegistry) schemaByID(id string) (interface{}, *mockError) {
+	number, err := strconv.Atoi(id)
+	if err != nil || number < 1 || number > len(m.schemas) {
+		return nil, errMockSchemaNotFound
+	}
+	return map[string]string{"schema": m.schemas[number-1]}, nil
+}
+
+func (m *MockRegistry) checkCompatibility(subject, version string, r *http.Request) (interface{}, *mockError) {
+	schema, _, merr := readSchema(r)
+	if merr != nil {
+		return nil, merr
+	}
+	ids, ok := m.subjects[subject]
+	if !ok {
+		return nil, errMockSubjectNotFound
+	}
+	index, merr := versionIndex(ids, version)
+	if merr != nil {
+		return nil, merr
+	}
+	history := []string{m.schemas[ids[index]-1]}
+	return map[string]bool{"is_compatible": compatible(m.level(subject), schema, history)}, nil
+}
+
+// level returns the compatibility level of the subject, which is the global
+// level unless one was set for the subject.
+func (m *MockRegistry) level(subject string) string {
+	if level, ok := m.compatibility[subject]; ok {
+		return level
+	}
+	return m.compatibility[""]
+}
+
+func (m *MockRegistry) setLevel(subject string, r *http.Request) (interface{}, *mockError) {
+	var request struct {
+		Compatibility string `json:"compatibility"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return nil, errMockInvalidLevel
+	}
+	switch request.Compatibility {
+	case CompatibilityNone, CompatibilityBackward, CompatibilityBackwardTransitive, CompatibilityForward, CompatibilityForwardTransitive, CompatibilityFull, CompatibilityFullTransitive:
+	default:
+		return nil, errMockInvalidLevel
+	}
+	m.compatibility[subject] = request.Compatibility
+	return request, nil
+}
+
+// readSchema returns the valid schema in the body of the request, along with
+// its canonical JSON form, by which the Schema Registry tells schemas apart.
+func readSchema(r *http.Request) (string, string, *mockError) {
+	var request struct {
+		Schema string `json:"schema"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return "", "", errMockInvalidSchema
+	}
+	if _, err := goavro.NewCodec(request.Schema); err != nil {
+		return "", "", &mockError{http.StatusUnprocessableEntity, 42201, "Input schema is an invalid Avro schema: " + err.Error()}
+	}
+	key, _ := canonicalJSON(request.Schema) // valid schema is valid JSON
+	return request.Schema, key, nil
+}
+
+// compatible returns true when the schema is compatible with each of the
+// historical schemas at the compatibility level.
+func compatible(level, schema string, history []string) bool {
+	var report *goavro.CompatibilityReport
+	var err error
+	switch level {
+	case CompatibilityBackward, CompatibilityBackwardTransitive:
+		report, err = goavro.CheckBackwardTransitive(schema, history)
+	case CompatibilityForward, CompatibilityForwardTransitive:
+		report, err = goavro.CheckForwardTransitive(schema, history)
+	case CompatibilityFull, CompatibilityFullTransitive:
+		report, err = goavro.CheckFullTransitive(schema, history)
+	default:
+		return true
+	}
+	return err == nil && report.Compatible()
+}
@@ -0,0 +1,63 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/linkedin/goavro/v2"
+)
+
+// SubjectNameStrategy returns the subject under which the schema of the
+// Codec is registered, for the keys or the values of the messages of a Kafka
+// topic.
+type SubjectNameStrategy func(topic string, isKey bool, codec *goavro.Codec) (string, error)
+
+// TopicNameStrategy names subjects after the topic, such as "orders-key" and
+// "orders-value", so each topic has one schema for its keys and one for its
+// values. It is the default strategy of Confluent serializers.
+func TopicNameStrategy(topic string, isKey bool, _ *goavro.Codec) (string, error) {
+	if isKey {
+		return topic + "-key", nil
+	}
+	return topic + "-value", nil
+}
+
+// RecordNameStrategy names subjects after the full name of the record of the
+// schema, such as "com.example.Order", so a topic may have records of several
+// schemas, and each record has the same schema in every topic.
+func RecordNameStrategy(_ string, _ bool, codec *goavro.Codec) (string, error) {
+	return recordName(codec)
+}
+
+// TopicRecordNameStrategy names subjects after the topic and the full name of
+// the record of the schema, such as "orders-com.example.Order", so a topic may
+// have records of several schemas.
+func TopicRecordNameStrategy(topic string, _ bool, codec *goavro.Codec) (string, error) {
+	name, err := recordName(codec)
+	if err != nil {
+		return "", err
+	}
+	return topic + "-" + name, nil
+}
+
+func recordName(codec *goavro.Codec) (string, error) {
+	var schema struct {
+		Type string `json:"type"`
+	}
+	// NOTE: Schemas of primitive types and unions are not JSON objects, and fail
+	// to unmarshal into a struct.
+	if err := json.Unmarshal([]byte(codec.CanonicalSchema()), &schema); err != nil || schema.Type != "record" {
+		return "", fmt.Errorf("cannot name subject after schema that is not a record: %s", codec.CanonicalSchema())
+	}
+	typeName := codec.TypeName()
+	return typeName.String(), nil
+}
@@ -0,0 +1,46 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package registry
+
+import (
+	"testing"
+
+	"github.com/linkedin/goavro/v2"
+)
+
+func testSubjectName(t *testing.T, strategy SubjectNameStrategy, schema string, isKey bool, expected string) {
+	t.Helper()
+	codec, err := goavro.NewCodec(schema)
+	ensureError(t, err)
+	actual, err := strategy("orders", isKey, codec)
+	ensureError(t, err)
+	if actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+}
+
+func TestTopicNameStrategy(t *testing.T) {
+	testSubjectName(t, TopicNameStrategy, `"string"`, true, "orders-key")
+	testSubjectName(t, TopicNameStrategy, testSchemaV1, false, "orders-value")
+}
+
+func TestRecordNameStrategy(t *testing.T) {
+	testSubjectName(t, RecordNameStrategy, testSchemaV1, false, "com.example.Order")
+	testSubjectName(t, TopicRecordNameStrategy, testSchemaV1, true, "orders-com.example.Order")
+
+	for _, schema := range []string{`"string"`, `["null","long"]`, `{"type":"enum","name":"e","symbols":["a"]}`} {
+		codec, err := goavro.NewCodec(schema)
+		ensureError(t, err)
+		_, err = RecordNameStrategy("orders", false, codec)
+		ensureError(t, err, "cannot name subject after schema that is not a record")
+		_, err = TopicRecordNameStrategy("orders", false, codec)
+		ensureError(t, err, "cannot name subject after schema that is not a record")
+	}
+}
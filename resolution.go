@@ -0,0 +1,451 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package goavro
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NewCodecForResolution returns a Codec that decodes binary Avro data encoded
+// using writerSchema into native Go data shaped by readerSchema, in accordance
+// with the schema resolution rules of the Avro specification.
+//
+// Named types and record fields are matched by name, or by the aliases the
+// reader schema declares for them. Fields present only in the writer schema
+// are skipped, and fields present only in the reader schema are set to their
+// default value, which the reader schema must therefore provide. Arrays, maps,
+// and unions are resolved recursively. Mismatched schemas cause an error when
+// the Codec is created, except for union members that cannot be resolved,
+// which cause an error only when data using that member is decoded.
+//
+// The returned Codec reports the reader schema from its Schema and
+// CanonicalSchema methods, and uses it for textual encoding and decoding.
+// Binary and single-object encoding use the writer schema, so that data
+// encoded by the Codec may also be decoded by it, and its Rabin field is the
+// fingerprint of the writer schema.
+//
+//	codec, err := goavro.NewCodecForResolution(
+//	    `{"type":"record","name":"r1","fields":[{"name":"a","type":"int"},{"name":"b","type":"string"}]}`,
+//	    `{"type":"record","name":"r1","fields":[{"name":"b","type":"string"},{"name":"c","type":"long","default":13}]}`,
+//	    nil)
+//	if err != nil {
+//	    fmt.Println(err)
+//	}
+//	native, _, err := codec.NativeFromBinary([]byte{0x02, 0x06, 'f', 'o', 'o'})
+//	if err != nil {
+//	    fmt.Println(err)
+//	}
+//	fmt.Println(native)
+//	// Output: map[b:foo c:13]
+func NewCodecForResolution(writerSchema, readerSchema string, option *CodecOption) (*Codec, error) {
+	writer, err := NewCodecWithOptions(writerSchema, option)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create writer codec: %s", err)
+	}
+	reader, err := NewCodecWithOptions(readerSchema, option)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create reader codec: %s", err)
+	}
+	return newResolvingCodec(writer, reader)
+}
+
+// newResolvingCodec returns a Codec that decodes binary data encoded with the
+// writer Codec into native data shaped by the reader Codec.
+func newResolvingCodec(writer, reader *Codec) (*Codec, error) {
+	r := &resolver{decoders: make(map[codecPair]toNativeFn)}
+	decoder, err := r.resolve(writer, reader)
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve writer schema with reader schema: %s", err)
+	}
+	return &Codec{
+		soeHeader:       writer.soeHeader,
+		schemaOriginal:  reader.schemaOriginal,
+		schemaCanonical: reader.schemaCanonical,
+		typeName:        reader.typeName,
+		aliases:         reader.aliases,
+
+		kind:        reader.kind,
+		fields:      reader.fields,
+		symbols:     reader.symbols,
+		enumDefault: reader.enumDefault,
+		size:        reader.size,
+		itemCodec:   reader.itemCodec,
+		members:     reader.members,
+		writer:      writer,
+
+		nativeFromTextual: reader.nativeFromTextual,
+		binaryFromNative:  writer.binaryFromNative,
+		nativeFromBinary:  decoder,
+		skipBinary:        writer.skipBinary,
+		textualFromNative: reader.textualFromNative,
+
+		// NOTE: The fingerprint identifies the writer schema, like the header
+		// of single-object encoding, because data is encoded using it.
+		Rabin: writer.Rabin,
+	}, nil
+}
+
+// codecPair identifies the resolution of one writer Codec into one reader
+// Codec.
+type codecPair struct {
+	writer, reader *Codec
+}
+
+// resolver builds binary decoders that read data encoded using a writer schema
+// and return native data shaped by a reader schema.
+type resolver struct {
+	// NOTE: Resolving recursive record schemas would never terminate without
+	// remembering the decoders already being built.
+	decoders map[codecPair]toNativeFn
+}
+
+// resolve returns a binary decoder for data encoded using writer that returns
+// native data shaped by reader.
+func (r *resolver) resolve(writer, reader *Codec) (toNativeFn, error) {
+	if decoder, ok := r.decoders[codecPair{writer, reader}]; ok {
+		return decoder, nil
+	}
+	if writer.kind == "union" {
+		return r.resolveWriterUnion(writer, reader)
+	}
+	if reader.kind == "union" {
+		return r.resolveReaderUnion(writer, reader)
+	}
+	if writer.kind != reader.kind {
+		if decoder := promotion(writer, reader); decoder != nil {
+			return decoder, nil
+		}
+		return nil, fmt.Errorf("writer %s cannot be read as reader %s", writer.typeName, reader.typeName)
+	}
+
+	switch reader.kind {
+	case "record":
+		return r.resolveRecord(writer, reader)
+	case "enum":
+		return resolveEnum(writer, reader)
+	case "fixed":
+		if !namesMatch(writer, reader) {
+			return nil, fmt.Errorf("writer fixed %q ought to have the same name as reader fixed %q", writer.typeName, reader.typeName)
+		}
+		if writer.size != reader.size {
+			return nil, fmt.Errorf("writer fixed %q ought to have the same size as reader fixed: %d != %d", writer.typeName, writer.size, reader.size)
+		}
+		return reader.nativeFromBinary, nil
+	case "array":
+		itemDecoder, err := r.resolve(writer.itemCodec, reader.itemCodec)
+		if err != nil {
+			return nil, fmt.Errorf("array items: %s", err)
+		}
+		return arrayNativeFromBinary(itemDecoder), nil
+	case "map":
+		valueDecoder, err := r.resolve(writer.itemCodec, reader.itemCodec)
+		if err != nil {
+			return nil, fmt.Errorf("map values: %s", err)
+		}
+		return mapNativeFromBinary(valueDecoder), nil
+	default:
+		// Both are the same primitive type, although possibly with different
+		// logical types. The reader decides how to represent the value.
+		return reader.nativeFromBinary, nil
+	}
+}
+
+// promotions holds, for each pair of writer and reader primitive types for
+// which the Avro specification permits promoting the writer's type to the
+// reader's type, the decoder of the writer's primitive type along with the
+// conversion of its values to the reader's type. Pairs without a decoder have
+// the same binary encoding, so the reader decodes the value itself.
+var promotions = map[[2]string]struct {
+	decoder toNativeFn
+	convert func(interface{}) interface{}
+}{
+	{"int", "long"}:     {},
+	{"int", "float"}:    {intNativeFromBinary, func(v interface{}) interface{} { return float32(v.(int32)) }},
+	{"int", "double"}:   {intNativeFromBinary, func(v interface{}) interface{} { return float64(v.(int32)) }},
+	{"long", "float"}:   {longNativeFromBinary, func(v interface{}) interface{} { return float32(v.(int64)) }},
+	{"long", "double"}:  {longNativeFromBinary, func(v interface{}) interface{} { return float64(v.(int64)) }},
+	{"float", "double"}: {floatNativeFromBinary, func(v interface{}) interface{} { return float64(v.(float32)) }},
+	{"string", "bytes"}: {},
+	{"bytes", "string"}: {},
+}
+
+// promotion returns a binary decoder that reads a value of the writer's
+// primitive type and promotes it to the reader's primitive type, as permitted by
+// the Avro specification, or nil when the writer's type cannot be promoted to
+// the reader's type.
+func promotion(writer, reader *Codec) toNativeFn {
+	p, ok := promotions[[2]string{writer.kind, reader.kind}]
+	if !ok {
+		return nil
+	}
+	if p.decoder == nil {
+		// NOTE: The reader decodes the value, and converts it to the native
+		// form of its logical type, if any, such as when an int is read as a
+		// long with a timestamp-millis logical type.
+		return reader.nativeFromBinary
+	}
+	return func(buf []byte) (interface{}, []byte, error) {
+		value, buf, err := p.decoder(buf)
+		if err != nil {
+			return nil, nil, err
+		}
+		return p.convert(value), buf, nil
+	}
+}
+
+// namesMatch returns true when the writer and reader named types have the same
+// unqualified name, or when the unqualified name of one of the reader's aliases
+// is the unqualified name of the writer.
+func namesMatch(writer, reader *Codec) bool {
+	writerName := writer.typeName.short()
+	if writerName == reader.typeName.short() {
+		return true
+	}
+	for _, alias := range reader.aliases {
+		if index := strings.LastIndexByte(alias, '.'); index > -1 {
+			alias = alias[index+1:]
+		}
+		if alias == writerName {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *resolver) resolveRecord(writer, reader *Codec) (toNativeFn, error) {
+	if !namesMatch(writer, reader) {
+		return nil, fmt.Errorf("writer record %q ought to have the same name as reader record %q", writer.typeName, reader.typeName)
+	}
+
+	// NOTE: Register this decoder before resolving the fields, so recursive
+	// references to this record resolve to it.
+	var decoder toNativeFn
+	key := codecPair{writer, reader}
+	r.decoders[key] = func(buf []byte) (interface{}, []byte, error) { return decoder(buf) }
+
+	// NOTE: When this record cannot be resolved, a decoder built while
+	// resolving its fields may still refer to it, so leave behind a decoder
+	// that returns the error.
+	fail := func(err error) (toNativeFn, error) {
+		delete(r.decoders, key)
+		decoder = func(_ []byte) (interface{}, []byte, error) {
+			return nil, nil, fmt.Errorf("cannot decode binary record %q: %s", writer.typeName, err)
+		}
+		return nil, err
+	}
+
+	fieldDecoders := make([]toNativeFn, len(writer.fields))
+	readerNames := make([]string, len(writer.fields)) // empty when writer field is skipped
+	found := make(map[string]struct{}, len(writer.fields))
+
+	for i, writerField := range writer.fields {
+		readerField := reader.readerFieldForWriterField(writerField.name)
+		if readerField != nil {
+			if _, ok := found[readerField.name]; ok {
+				readerField = nil // already read from another writer field
+			}
+		}
+		if readerField == nil {
+			// Writer field is not in reader: decode and discard its value.
+			fieldDecoders[i] = writerField.codec.nativeFromBinary
+			continue
+		}
+		fieldDecoder, err := r.resolve(writerField.codec, readerField.codec)
+		if err != nil {
+			return fail(fmt.Errorf("record %q field %q: %s", reader.typeName, readerField.name, err))
+		}
+		fieldDecoders[i] = fieldDecoder
+		readerNames[i] = readerField.name
+		found[readerField.name] = struct{}{}
+	}
+
+	// Reader fields not written by the writer are set to their default values.
+	// NOTE: Default values are stored in their binary encoded form, and
+	// decoded for each datum, so no two decoded records share mutable values.
+	var defaultFields []*recordField
+	var defaultValues [][]byte
+	for _, readerField := range reader.fields {
+		if _, ok := found[readerField.name]; ok {
+			continue
+		}
+		if !readerField.hasDefault {
+			return fail(fmt.Errorf("record %q field %q: reader field missing from writer ought to have a default value", reader.typeName, readerField.name))
+		}
+		encoded, err := readerField.codec.binaryFromNative(nil, readerField.defaultValue)
+		if err != nil {
+			return fail(fmt.Errorf("record %q field %q: default value ought to encode using field schema: %s", reader.typeName, readerField.name, err))
+		}
+		defaultFields = append(defaultFields, readerField)
+		defaultValues = append(defaultValues, encoded)
+	}
+
+	decoder = func(buf []byte) (interface{}, []byte, error) {
+		recordMap := make(map[string]interface{}, len(reader.fields))
+		for i, fieldDecoder := range fieldDecoders {
+			var value interface{}
+			var err error
+			value, buf, err = fieldDecoder(buf)
+			if err != nil {
+				return nil, nil, fmt.Errorf("cannot decode binary record %q field %q: %s", writer.typeName, writer.fields[i].name, err)
+			}
+			if readerNames[i] != "" {
+				recordMap[readerNames[i]] = value
+			}
+		}
+		for i, readerField := range defaultFields {
+			value, _, err := readerField.codec.nativeFromBinary(defaultValues[i])
+			if err != nil {
+				return nil, nil, fmt.Errorf("cannot decode binary record %q field %q default value: %s", reader.typeName, readerField.name, err)
+			}
+			recordMap[readerField.name] = value
+		}
+		return recordMap, buf, nil
+	}
+	return decoder, nil
+}
+
+// readerFieldForWriterField returns the record field with the specified name,
+// or failing that, the record field with the specified name as one of its
+// aliases. It returns nil when the record has no such field.
+func (c *Codec) readerFieldForWriterField(fieldName string) *recordField {
+	for _, field := range c.fields {
+		if field.name == fieldName {
+			return field
+		}
+	}
+	for _, field := range c.fields {
+		for _, alias := range field.aliases {
+			if alias == fieldName {
+				return field
+			}
+		}
+	}
+	return nil
+}
+
+func resolveEnum(writer, reader *Codec) (toNativeFn, error) {
+	if !namesMatch(writer, reader) {
+		return nil, fmt.Errorf("writer enum %q ought to have the same name as reader enum %q", writer.typeName, reader.typeName)
+	}
+	readerSymbols := make(map[string]struct{}, len(reader.symbols))
+	for _, symbol := range reader.symbols {
+		readerSymbols[symbol] = struct{}{}
+	}
+	defaultSymbol := reader.enumDefault
+
+	return func(buf []byte) (interface{}, []byte, error) {
+		var value interface{}
+		var err error
+
+		if value, buf, err = longNativeFromBinary(buf); err != nil {
+			return nil, nil, fmt.Errorf("cannot decode binary enum %q index: %s", writer.typeName, err)
+		}
+		index := value.(int64)
+		if index < 0 || index >= int64(len(writer.symbols)) {
+			return nil, nil, fmt.Errorf("cannot decode binary enum %q: index ought to be between 0 and %d; read index: %d", writer.typeName, len(writer.symbols)-1, index)
+		}
+		symbol := writer.symbols[index]
+		if _, ok := readerSymbols[symbol]; !ok {
+			if defaultSymbol == "" {
+				return nil, nil, fmt.Errorf("cannot decode binary enum %q: writer symbol ought to be member of reader symbols, or reader enum ought to have a default: %v; %q", reader.typeName, reader.symbols, symbol)
+			}
+			symbol = defaultSymbol
+		}
+		return symbol, buf, nil
+	}, nil
+}
+
+// resolveWriterUnion resolves each member of the writer union against the
+// reader, which may or may not be a union itself.
+func (r *resolver) resolveWriterUnion(writer, reader *Codec) (toNativeFn, error) {
+	members := writer.members.codecFromIndex
+	memberDecoders := make([]toNativeFn, len(members))
+	var resolved int
+
+	for i, writerMember := range members {
+		var memberDecoder toNativeFn
+		var err error
+		if reader.kind == "union" {
+			memberDecoder, err = r.resolveReaderUnion(writerMember, reader)
+		} else {
+			memberDecoder, err = r.resolve(writerMember, reader)
+		}
+		if err != nil {
+			// NOTE: The specification only considers it an error when data
+			// actually uses a writer union member the reader cannot resolve.
+			memberDecoders[i] = unresolvedUnionMember(i, err)
+			continue
+		}
+		memberDecoders[i] = memberDecoder
+		resolved++
+	}
+	if resolved == 0 {
+		return nil, fmt.Errorf("no writer union member %v can be read as reader %s", writer.members.allowedTypes, reader.typeName)
+	}
+
+	return func(buf []byte) (interface{}, []byte, error) {
+		var decoded interface{}
+		var err error
+
+		decoded, buf, err = longNativeFromBinary(buf)
+		if err != nil {
+			return nil, nil, err
+		}
+		index := decoded.(int64) // longDecoder always returns int64, so elide error checking
+		if index < 0 || index >= int64(len(memberDecoders)) {
+			return nil, nil, fmt.Errorf("cannot decode binary union: index ought to be between 0 and %d; read index: %d", len(memberDecoders)-1, index)
+		}
+		return memberDecoders[index](buf)
+	}, nil
+}
+
+// unresolvedUnionMember returns a decoder that always fails, for a writer union
+// member that cannot be read as the reader.
+func unresolvedUnionMember(index int, err error) toNativeFn {
+	return func(_ []byte) (interface{}, []byte, error) {
+		return nil, nil, fmt.Errorf("cannot decode binary union item %d: %s", index+1, err)
+	}
+}
+
+// resolveReaderUnion resolves a writer, which is not a union, against the first
+// member of the reader union that matches it. Members of the same type as the
+// writer are preferred over members the writer's type may be promoted to.
+func (r *resolver) resolveReaderUnion(writer, reader *Codec) (toNativeFn, error) {
+	for _, sameKind := range []bool{true, false} {
+		for i, readerMember := range reader.members.codecFromIndex {
+			if sameKind != (readerMember.kind == writer.kind) {
+				continue
+			}
+			memberDecoder, err := r.resolve(writer, readerMember)
+			if err != nil {
+				continue
+			}
+			return unionMemberDecoder(reader.members.allowedTypes[i], memberDecoder), nil
+		}
+	}
+	return nil, fmt.Errorf("writer %s ought to match a reader union member: %v", writer.typeName, reader.members.allowedTypes)
+}
+
+// unionMemberDecoder returns a decoder that wraps the values decoded by
+// memberDecoder in a union using memberName.
+func unionMemberDecoder(memberName string, memberDecoder toNativeFn) toNativeFn {
+	return func(buf []byte) (interface{}, []byte, error) {
+		decoded, buf, err := memberDecoder(buf)
+		if err != nil {
+			return nil, nil, err
+		}
+		if decoded == nil {
+			// do not wrap a nil value in a map
+			return nil, buf, nil
+		}
+		return Union(memberName, decoded), buf, nil
+	}
+}
@@ -0,0 +1,389 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package goavro
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// testResolutionPass encodes datum using the writer schema, then ensures it
+// decodes to expected using the reader schema.
+func testResolutionPass(t *testing.T, writerSchema, readerSchema string, datum, expected interface{}) {
+	t.Helper()
+	writer, err := NewCodec(writerSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf, err := writer.BinaryFromNative(nil, datum)
+	if err != nil {
+		t.Fatal(err)
+	}
+	codec, err := NewCodecForResolution(writerSchema, readerSchema, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, remaining, err := codec.NativeFromBinary(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual, want := len(remaining), 0; actual != want {
+		t.Errorf("GOT: %#v; WANT: %#v", actual, want)
+	}
+	if actual, want := fmt.Sprintf("%v", decoded), fmt.Sprintf("%v", expected); actual != want {
+		t.Errorf("GOT: %v; WANT: %v", actual, want)
+	}
+}
+
+func testResolutionInvalid(t *testing.T, writerSchema, readerSchema string, errorMessage string) {
+	t.Helper()
+	_, err := NewCodecForResolution(writerSchema, readerSchema, nil)
+	ensureError(t, err, errorMessage)
+}
+
+func ExampleNewCodecForResolution() {
+	codec, err := NewCodecForResolution(
+		`{"type":"record","name":"r1","fields":[{"name":"a","type":"int"},{"name":"b","type":"string"}]}`,
+		`{"type":"record","name":"r1","fields":[{"name":"b","type":"string"},{"name":"c","type":"long","default":13}]}`,
+		nil)
+	if err != nil {
+		fmt.Println(err)
+	}
+	native, _, err := codec.NativeFromBinary([]byte{0x02, 0x06, 'f', 'o', 'o'})
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(native)
+	// Output: map[b:foo c:13]
+}
+
+func TestResolutionInvalidSchemas(t *testing.T) {
+	testResolutionInvalid(t, `"integer"`, `"int"`, "cannot create writer codec")
+	testResolutionInvalid(t, `"int"`, `"integer"`, "cannot create reader codec")
+	testResolutionInvalid(t, `"int"`, `"string"`, "writer int cannot be read as reader string")
+}
+
+func TestResolutionPrimitive(t *testing.T) {
+	testResolutionPass(t, `"int"`, `"int"`, 3, 3)
+	testResolutionPass(t, `"string"`, `"string"`, "foo", "foo")
+	testResolutionPass(t, `{"type":"long","logicalType":"timestamp-millis"}`, `"long"`, 1234, 1234)
+}
+
+func TestResolutionRecordFields(t *testing.T) {
+	writerSchema := `{"type":"record","name":"r1","fields":[{"name":"a","type":"int"},{"name":"b","type":"string"},{"name":"c","type":{"type":"array","items":"long"}}]}`
+
+	t.Run("same", func(t *testing.T) {
+		testResolutionPass(t, writerSchema, writerSchema,
+			map[string]interface{}{"a": 1, "b": "foo", "c": []interface{}{2, 3}},
+			map[string]interface{}{"a": 1, "b": "foo", "c": []interface{}{2, 3}})
+	})
+	t.Run("reordered", func(t *testing.T) {
+		testResolutionPass(t, writerSchema,
+			`{"type":"record","name":"r1","fields":[{"name":"c","type":{"type":"array","items":"long"}},{"name":"b","type":"string"},{"name":"a","type":"int"}]}`,
+			map[string]interface{}{"a": 1, "b": "foo", "c": []interface{}{2, 3}},
+			map[string]interface{}{"a": 1, "b": "foo", "c": []interface{}{2, 3}})
+	})
+	t.Run("removed", func(t *testing.T) {
+		testResolutionPass(t, writerSchema,
+			`{"type":"record","name":"r1","fields":[{"name":"b","type":"string"}]}`,
+			map[string]interface{}{"a": 1, "b": "foo", "c": []interface{}{2, 3}},
+			map[string]interface{}{"b": "foo"})
+	})
+	t.Run("added with default", func(t *testing.T) {
+		testResolutionPass(t, writerSchema,
+			`{"type":"record","name":"r1","fields":[{"name":"a","type":"int"},{"name":"d","type":["null","string"],"default":null},{"name":"e","type":{"type":"map","values":"int"},"default":{"x":1}}]}`,
+			map[string]interface{}{"a": 1, "b": "foo", "c": []interface{}{2, 3}},
+			map[string]interface{}{"a": 1, "d": nil, "e": map[string]interface{}{"x": 1}})
+	})
+	t.Run("added without default", func(t *testing.T) {
+		testResolutionInvalid(t, writerSchema,
+			`{"type":"record","name":"r1","fields":[{"name":"a","type":"int"},{"name":"d","type":"string"}]}`,
+			`record "r1" field "d": reader field missing from writer ought to have a default value`)
+	})
+	t.Run("changed type", func(t *testing.T) {
+		testResolutionInvalid(t, writerSchema,
+			`{"type":"record","name":"r1","fields":[{"name":"a","type":"string"}]}`,
+			`record "r1" field "a": writer int cannot be read as reader string`)
+	})
+	t.Run("different name", func(t *testing.T) {
+		testResolutionInvalid(t, writerSchema,
+			`{"type":"record","name":"r2","fields":[{"name":"a","type":"int"}]}`,
+			`writer record "r1" ought to have the same name as reader record "r2"`)
+	})
+}
+
+func TestResolutionRecordDefaultsNotShared(t *testing.T) {
+	codec, err := NewCodecForResolution(
+		`{"type":"record","name":"r1","fields":[]}`,
+		`{"type":"record","name":"r1","fields":[{"name":"a","type":{"type":"array","items":"int"},"default":[1]}]}`,
+		nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, _, err := codec.NativeFromBinary(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first.(map[string]interface{})["a"].([]interface{})[0] = 42
+	second, _, err := codec.NativeFromBinary(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual, want := fmt.Sprintf("%v", second), "map[a:[1]]"; actual != want {
+		t.Errorf("GOT: %v; WANT: %v", actual, want)
+	}
+}
+
+func TestResolutionNestedRecord(t *testing.T) {
+	testResolutionPass(t,
+		`{"type":"record","name":"outer","fields":[{"name":"inner","type":{"type":"record","name":"inner","fields":[{"name":"a","type":"int"},{"name":"b","type":"int"}]}},{"name":"items","type":{"type":"array","items":"inner"}}]}`,
+		`{"type":"record","name":"outer","fields":[{"name":"items","type":{"type":"array","items":{"type":"record","name":"inner","fields":[{"name":"b","type":"int"},{"name":"c","type":"string","default":"x"}]}}},{"name":"inner","type":"inner"}]}`,
+		map[string]interface{}{
+			"inner": map[string]interface{}{"a": 1, "b": 2},
+			"items": []interface{}{map[string]interface{}{"a": 3, "b": 4}},
+		},
+		map[string]interface{}{
+			"inner": map[string]interface{}{"b": 2, "c": "x"},
+			"items": []interface{}{map[string]interface{}{"b": 4, "c": "x"}},
+		})
+}
+
+func TestResolutionMap(t *testing.T) {
+	testResolutionPass(t,
+		`{"type":"map","values":{"type":"record","name":"r1","fields":[{"name":"a","type":"int"},{"name":"b","type":"int"}]}}`,
+		`{"type":"map","values":{"type":"record","name":"r1","fields":[{"name":"b","type":"int"}]}}`,
+		map[string]interface{}{"k": map[string]interface{}{"a": 1, "b": 2}},
+		map[string]interface{}{"k": map[string]interface{}{"b": 2}})
+}
+
+func TestResolutionEnum(t *testing.T) {
+	testResolutionPass(t,
+		`{"type":"enum","name":"e1","symbols":["alpha","bravo"]}`,
+		`{"type":"enum","name":"e1","symbols":["bravo","charlie","alpha"]}`,
+		"alpha", "alpha")
+
+	codec, err := NewCodecForResolution(
+		`{"type":"enum","name":"e1","symbols":["alpha","bravo"]}`,
+		`{"type":"enum","name":"e1","symbols":["alpha"]}`,
+		nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = codec.NativeFromBinary([]byte{0x02})
+	ensureError(t, err, "writer symbol ought to be member of reader symbols, or reader enum ought to have a default")
+
+	testResolutionPass(t,
+		`{"type":"enum","name":"e1","symbols":["alpha","bravo","charlie"]}`,
+		`{"type":"enum","name":"e1","symbols":["unknown","alpha"],"default":"unknown"}`,
+		"charlie", "unknown")
+	testResolutionPass(t,
+		`{"type":"record","name":"r1","fields":[{"name":"e","type":{"type":"enum","name":"e1","symbols":["alpha","bravo"]}}]}`,
+		`{"type":"record","name":"r1","fields":[{"name":"e","type":{"type":"enum","name":"e1","symbols":["alpha","other"],"default":"other"}}]}`,
+		map[string]interface{}{"e": "bravo"},
+		map[string]interface{}{"e": "other"})
+}
+
+func TestResolutionFixed(t *testing.T) {
+	testResolutionPass(t, `{"type":"fixed","name":"f1","size":2}`, `{"type":"fixed","name":"f1","size":2}`, []byte("ab"), []byte("ab"))
+	testResolutionInvalid(t, `{"type":"fixed","name":"f1","size":2}`, `{"type":"fixed","name":"f1","size":3}`, "ought to have the same size")
+	testResolutionInvalid(t, `{"type":"fixed","name":"f1","size":2}`, `{"type":"fixed","name":"f2","size":2}`, "ought to have the same name")
+}
+
+func TestResolutionUnion(t *testing.T) {
+	t.Run("reordered", func(t *testing.T) {
+		testResolutionPass(t, `["null","int","string"]`, `["string","null","int"]`,
+			Union("int", 3), Union("int", 3))
+		testResolutionPass(t, `["null","int","string"]`, `["string","null","int"]`,
+			nil, nil)
+	})
+	t.Run("writer not union", func(t *testing.T) {
+		testResolutionPass(t, `"string"`, `["null","string"]`, "foo", Union("string", "foo"))
+		testResolutionInvalid(t, `"string"`, `["null","int"]`, "writer string ought to match a reader union member")
+	})
+	t.Run("reader not union", func(t *testing.T) {
+		testResolutionPass(t, `["null","string"]`, `"string"`, Union("string", "foo"), "foo")
+
+		codec, err := NewCodecForResolution(`["null","string"]`, `"string"`, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _, err = codec.NativeFromBinary([]byte{0x00})
+		ensureError(t, err, "cannot decode binary union item 1")
+
+		testResolutionInvalid(t, `["null","int"]`, `"string"`, "no writer union member")
+	})
+	t.Run("record members", func(t *testing.T) {
+		testResolutionPass(t,
+			`["null",{"type":"record","name":"r1","fields":[{"name":"a","type":"int"},{"name":"b","type":"int"}]}]`,
+			`[{"type":"record","name":"r1","fields":[{"name":"b","type":"int"}]},"null"]`,
+			Union("r1", map[string]interface{}{"a": 1, "b": 2}),
+			Union("r1", map[string]interface{}{"b": 2}))
+	})
+}
+
+func TestResolutionRecursiveRecord(t *testing.T) {
+	testResolutionPass(t,
+		`{"type":"record","name":"LongList","fields":[{"name":"value","type":"long"},{"name":"next","type":["null","LongList"]}]}`,
+		`{"type":"record","name":"LongList","fields":[{"name":"next","type":["null","LongList"]},{"name":"extra","type":"int","default":7}]}`,
+		map[string]interface{}{
+			"value": 1,
+			"next": Union("LongList", map[string]interface{}{
+				"value": 2,
+				"next":  nil,
+			}),
+		},
+		map[string]interface{}{
+			"extra": 7,
+			"next": Union("LongList", map[string]interface{}{
+				"extra": 7,
+				"next":  nil,
+			}),
+		})
+}
+
+func TestResolutionEncodesUsingWriterSchema(t *testing.T) {
+	codec, err := NewCodecForResolution(
+		`{"type":"record","name":"r1","fields":[{"name":"a","type":"int"},{"name":"b","type":"string"}]}`,
+		`{"type":"record","name":"r1","fields":[{"name":"b","type":"string"}]}`,
+		nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf, err := codec.BinaryFromNative(nil, map[string]interface{}{"a": 1, "b": "foo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, _, err := codec.NativeFromBinary(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual, want := fmt.Sprintf("%v", decoded), "map[b:foo]"; actual != want {
+		t.Errorf("GOT: %v; WANT: %v", actual, want)
+	}
+	// The fingerprint is of the writer schema, which encoded the datum.
+	writer, err := NewCodec(`{"type":"record","name":"r1","fields":[{"name":"a","type":"int"},{"name":"b","type":"string"}]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual, want := codec.Rabin, writer.Rabin; actual != want {
+		t.Errorf("GOT: %x; WANT: %x", actual, want)
+	}
+	single, err := codec.SingleFromNative(nil, map[string]interface{}{"a": 1, "b": "foo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual, want := binary.LittleEndian.Uint64(single[2:]), codec.Rabin; actual != want {
+		t.Errorf("GOT: %x; WANT: %x", actual, want)
+	}
+}
+
+func TestResolutionAliases(t *testing.T) {
+	t.Run("renamed record", func(t *testing.T) {
+		testResolutionPass(t,
+			`{"type":"record","name":"com.example.Old","fields":[{"name":"a","type":"int"}]}`,
+			`{"type":"record","name":"com.example.New","aliases":["Old"],"fields":[{"name":"a","type":"int"}]}`,
+			map[string]interface{}{"a": 1},
+			map[string]interface{}{"a": 1})
+	})
+	t.Run("renamed enum and fixed", func(t *testing.T) {
+		testResolutionPass(t,
+			`{"type":"enum","name":"e0","symbols":["alpha"]}`,
+			`{"type":"enum","name":"e1","aliases":["e0"],"symbols":["alpha"]}`,
+			"alpha", "alpha")
+		testResolutionPass(t,
+			`{"type":"fixed","name":"f0","size":2}`,
+			`{"type":"fixed","name":"f1","aliases":["f0"],"size":2}`,
+			[]byte("ab"), []byte("ab"))
+	})
+	t.Run("renamed field", func(t *testing.T) {
+		testResolutionPass(t,
+			`{"type":"record","name":"r1","fields":[{"name":"a","type":"int"},{"name":"b","type":"string"}]}`,
+			`{"type":"record","name":"r1","fields":[{"name":"b","type":"string"},{"name":"c","type":"int","aliases":["a"]}]}`,
+			map[string]interface{}{"a": 1, "b": "foo"},
+			map[string]interface{}{"b": "foo", "c": 1})
+	})
+	t.Run("renamed union member", func(t *testing.T) {
+		testResolutionPass(t,
+			`["null",{"type":"record","name":"r0","fields":[{"name":"a","type":"int"}]}]`,
+			`["null",{"type":"record","name":"r1","aliases":["r0"],"fields":[{"name":"a","type":"int"}]}]`,
+			Union("r0", map[string]interface{}{"a": 1}),
+			Union("r1", map[string]interface{}{"a": 1}))
+	})
+	t.Run("writer aliases ignored", func(t *testing.T) {
+		testResolutionInvalid(t,
+			`{"type":"record","name":"r0","aliases":["r1"],"fields":[]}`,
+			`{"type":"record","name":"r1","fields":[]}`,
+			`writer record "r0" ought to have the same name as reader record "r1"`)
+	})
+}
+
+func TestResolutionPromotion(t *testing.T) {
+	testResolutionPass(t, `"int"`, `"long"`, 3, int64(3))
+	testResolutionPass(t, `"int"`, `"float"`, 3, float32(3))
+	testResolutionPass(t, `"int"`, `"double"`, 3, float64(3))
+	testResolutionPass(t, `"long"`, `"float"`, 3, float32(3))
+	testResolutionPass(t, `"long"`, `"double"`, 3, float64(3))
+	testResolutionPass(t, `"float"`, `"double"`, 3.5, float64(3.5))
+	testResolutionPass(t, `"string"`, `"bytes"`, "foo", []byte("foo"))
+	testResolutionPass(t, `"bytes"`, `"string"`, []byte("foo"), "foo")
+
+	testResolutionInvalid(t, `"long"`, `"int"`, "writer long cannot be read as reader int")
+	testResolutionInvalid(t, `"double"`, `"float"`, "writer double cannot be read as reader float")
+	testResolutionInvalid(t, `"int"`, `"string"`, "writer int cannot be read as reader string")
+
+	codec, err := NewCodecForResolution(`"int"`, `"long"`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, _, err := codec.NativeFromBinary([]byte{0x06})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := decoded.(int64); !ok {
+		t.Errorf("GOT: %T; WANT: %T", decoded, int64(0))
+	}
+}
+
+func TestResolutionPromotionLogicalType(t *testing.T) {
+	testResolutionPass(t, `"int"`, `{"type":"long","logicalType":"timestamp-millis"}`, 1500, time.Unix(1, 500000000).UTC())
+	testResolutionPass(t, `"bytes"`, `{"type":"string","logicalType":"uuid"}`,
+		[]byte("8c5e2d4a-5e33-4c5e-9b1a-0b3c2f6f1d2e"), "8c5e2d4a-5e33-4c5e-9b1a-0b3c2f6f1d2e")
+	testResolutionPass(t, `"bytes"`, `{"type":"bytes","logicalType":"decimal","precision":4,"scale":2}`,
+		[]byte{0x04, 0xd2}, big.NewRat(1234, 100))
+	testResolutionPass(t, `"string"`, `{"type":"bytes","logicalType":"decimal","precision":4,"scale":2}`,
+		"\x04\xd2", big.NewRat(1234, 100))
+}
+
+func TestResolutionPromotionNested(t *testing.T) {
+	t.Run("record field", func(t *testing.T) {
+		testResolutionPass(t,
+			`{"type":"record","name":"r1","fields":[{"name":"a","type":"int"},{"name":"b","type":"bytes"}]}`,
+			`{"type":"record","name":"r1","fields":[{"name":"a","type":"double"},{"name":"b","type":"string"}]}`,
+			map[string]interface{}{"a": 3, "b": []byte("foo")},
+			map[string]interface{}{"a": float64(3), "b": "foo"})
+	})
+	t.Run("array items", func(t *testing.T) {
+		testResolutionPass(t, `{"type":"array","items":"float"}`, `{"type":"array","items":"double"}`,
+			[]interface{}{1.5, 2.5}, []interface{}{float64(1.5), float64(2.5)})
+	})
+	t.Run("map values", func(t *testing.T) {
+		testResolutionPass(t, `{"type":"map","values":"long"}`, `{"type":"map","values":"float"}`,
+			map[string]interface{}{"k": 3}, map[string]interface{}{"k": float32(3)})
+	})
+	t.Run("union members", func(t *testing.T) {
+		testResolutionPass(t, `["null","int"]`, `["null","long"]`, Union("int", 3), Union("long", int64(3)))
+		testResolutionPass(t, `"int"`, `["null","double","long"]`, 3, Union("double", float64(3)))
+		testResolutionPass(t, `"int"`, `["null","double","int"]`, 3, Union("int", int32(3)))
+		testResolutionPass(t, `["null","string"]`, `"bytes"`, Union("string", "foo"), []byte("foo"))
+	})
+}
@@ -0,0 +1,143 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+// Package schema builds Avro schemas programmatically, rather than by
+// concatenating JSON text, and returns them as JSON text, as schema trees, or
+// as ready to use Codecs.
+//
+//	codec, err := schema.Record("com.example.User").
+//	    Doc("A user of the service.").
+//	    Field("id", schema.Long()).
+//	    Field("name", schema.String(), schema.Default("")).
+//	    OptionalField("email", schema.String()).
+//	    Field("roles", schema.Array(schema.Enum("Role", "ADMIN", "MEMBER"))).
+//	    Build()
+//
+// Names of named types that do not contain a dot are in the namespace of the
+// enclosing named type, as in JSON schemas. A builder of a named type may be
+// used more than once in a schema, including within itself to build a
+// recursive type, and is defined where it first occurs.
+//
+// Names are checked using goavro.CheckName, and default values are checked
+// against the types of their fields when the Codec is built, so a mistake is
+// reported by Build, JSON, or Tree along with where it was made.
+package schema
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/linkedin/goavro/v2"
+)
+
+// Type is a builder of an Avro type.
+type Type interface {
+	// node returns the schema tree of the type, reusing the nodes of the named
+	// types already built.
+	node(b *treeBuilder, enclosingNamespace string) (goavro.Schema, error)
+}
+
+// treeBuilder builds the schema tree of a Type.
+type treeBuilder struct {
+	nodes map[Type]goavro.Schema
+}
+
+// Tree returns the schema tree of the type.
+func Tree(t Type) (goavro.Schema, error) {
+	if t == nil {
+		return nil, fmt.Errorf("cannot build schema: type ought to be non-nil")
+	}
+	b := &treeBuilder{nodes: make(map[Type]goavro.Schema)}
+	tree, err := t.node(b, "")
+	if err != nil {
+		return nil, fmt.Errorf("cannot build schema: %s", err)
+	}
+	return tree, nil
+}
+
+// Codec returns a Codec for the type.
+func Codec(t Type) (*goavro.Codec, error) {
+	tree, err := Tree(t)
+	if err != nil {
+		return nil, err
+	}
+	codec, err := goavro.NewCodecFromSchema(tree)
+	if err != nil {
+		return nil, fmt.Errorf("cannot build schema: %s", err)
+	}
+	return codec, nil
+}
+
+// JSON returns the JSON text of the schema of the type, after ensuring a Codec
+// may be created for it.
+func JSON(t Type) (string, error) {
+	codec, err := Codec(t)
+	if err != nil {
+		return "", err
+	}
+	return codec.Schema(), nil
+}
+
+// child returns the schema tree of a type nested in another, which is
+// described by what for error messages.
+func (b *treeBuilder) child(t Type, enclosingNamespace, what string) (goavro.Schema, error) {
+	if t == nil {
+		return nil, fmt.Errorf("%s ought to be non-nil", what)
+	}
+	return t.node(b, enclosingNamespace)
+}
+
+// properties returns a copy of the custom properties, or nil when there are
+// none.
+func properties(p map[string]interface{}) map[string]interface{} {
+	if len(p) == 0 {
+		return nil
+	}
+	c := make(map[string]interface{}, len(p))
+	for k, v := range p {
+		c[k] = v
+	}
+	return c
+}
+
+// fullName returns the full name of a named type, relative to the enclosing
+// namespace when it does not contain a dot, after checking it.
+func fullName(typeName, name, enclosingNamespace string) (string, error) {
+	if err := goavro.CheckName(name); err != nil {
+		return "", fmt.Errorf("%s ought to have valid name: %s", typeName, err)
+	}
+	if strings.IndexByte(name, '.') > -1 || enclosingNamespace == "" {
+		return name, nil
+	}
+	return enclosingNamespace + "." + name, nil
+}
+
+// namespaceOf returns the namespace of the full name.
+func namespaceOf(fullName string) string {
+	if index := strings.LastIndexByte(fullName, '.'); index > -1 {
+		return fullName[:index]
+	}
+	return ""
+}
+
+// aliases returns the full names of the aliases of a named type, relative to
+// its namespace when they do not contain a dot, after checking them.
+func aliases(typeName, name string, names []string) ([]string, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	full := make([]string, len(names))
+	for i, alias := range names {
+		if err := goavro.CheckName(alias); err != nil {
+			return nil, fmt.Errorf("%s %q alias ought to be valid name: %s", typeName, name, err)
+		}
+		full[i], _ = fullName(typeName, alias, namespaceOf(name)) // alias already checked
+	}
+	return full, nil
+}
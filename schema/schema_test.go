@@ -0,0 +1,201 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package schema
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/linkedin/goavro/v2"
+)
+
+func ensureError(tb testing.TB, err error, contains ...string) {
+	tb.Helper()
+	if len(contains) == 0 || (len(contains) == 1 && contains[0] == "") {
+		if err != nil {
+			tb.Fatalf("GOT: %v; WANT: %v", err, contains)
+		}
+		return
+	}
+	if err == nil {
+		tb.Errorf("GOT: %v; WANT: %v", err, contains)
+		return
+	}
+	for _, stub := range contains {
+		if stub != "" && !strings.Contains(err.Error(), stub) {
+			tb.Errorf("GOT: %v; WANT: %q", err, stub)
+		}
+	}
+}
+
+func ExampleRecord() {
+	text, err := Record("com.example.User").
+		Field("id", Long()).
+		OptionalField("email", String()).
+		JSON()
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(text)
+	// Output: {"type":"record","name":"com.example.User","fields":[{"name":"id","type":"long"},{"name":"email","type":["null","string"],"default":null}]}
+}
+
+func TestRecordBuild(t *testing.T) {
+	role := Enum("Role", "ADMIN", "MEMBER", "UNKNOWN").Default("UNKNOWN").Doc("Role of a user.")
+	address := Record("Address").
+		Field("city", String()).
+		Field("zip", String(), Default("00000"), FieldDoc("Postal code."))
+	codec, err := Record("com.example.User").
+		Doc("A user.").
+		Alias("Person").
+		Prop("owner", "team-a").
+		Field("id", Long(), Order("descending")).
+		Field("name", String(), Default(""), FieldAliases("fullName")).
+		OptionalField("email", String()).
+		Field("roles", Array(role), Default([]interface{}{})).
+		Field("primary", role, Default("MEMBER")).
+		Field("home", address).
+		OptionalField("work", address).
+		Field("born", Date()).
+		Field("seen", TimestampMillis()).
+		Field("balance", Decimal(9, 2)).
+		Field("digest", Fixed("md5", 16)).
+		Field("labels", Map(String().Prop("avro.java.string", "String")), FieldProp("pii", false)).
+		Build()
+	ensureError(t, err)
+
+	// Relative names are in the namespace of the enclosing record.
+	tree, err := codec.SchemaTree()
+	ensureError(t, err)
+	record := tree.(*goavro.RecordSchema)
+	if actual, expected := record.Fields[4].Type.(*goavro.EnumSchema).Name, "com.example.Role"; actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+	if actual, expected := record.Fields[5].Type.(*goavro.RecordSchema).Name, "com.example.Address"; actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+	if actual, expected := record.Aliases, []string{"com.example.Person"}; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+	if actual, expected := record.Fields[0].Order, "descending"; actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+	if actual, expected := record.Fields[11].Properties, map[string]interface{}{"pii": false}; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+
+	// Defaults are filled in when decoding.
+	buf, err := codec.BinaryFromNative(nil, map[string]interface{}{
+		"id":      int64(1),
+		"home":    map[string]interface{}{"city": "Paris"},
+		"born":    int32(0),
+		"seen":    int64(0),
+		"balance": big.NewRat(0, 1),
+		"digest":  make([]byte, 16),
+		"labels":  map[string]interface{}{},
+	})
+	ensureError(t, err)
+	datum, _, err := codec.NativeFromBinary(buf)
+	ensureError(t, err)
+	user := datum.(map[string]interface{})
+	if actual, expected := user["primary"], "MEMBER"; actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+	if actual, expected := user["home"], map[string]interface{}{"city": "Paris", "zip": "00000"}; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+	if user["email"] != nil || user["work"] != nil {
+		t.Errorf("GOT: %v, %v; WANT: nil, nil", user["email"], user["work"])
+	}
+}
+
+func TestRecursiveRecord(t *testing.T) {
+	list := Record("LongList")
+	list.Field("value", Long()).OptionalField("next", list)
+	text, err := list.JSON()
+	ensureError(t, err)
+	if expected := `{"type":"record","name":"LongList","fields":[{"name":"value","type":"long"},{"name":"next","type":["null","LongList"],"default":null}]}`; text != expected {
+		t.Errorf("GOT: %v; WANT: %v", text, expected)
+	}
+}
+
+func TestBuildOtherTypes(t *testing.T) {
+	cases := []struct {
+		t        Type
+		expected string
+	}{
+		{Null(), `"null"`},
+		{Union(Null(), Int(), Array(Boolean())), `["null","int",{"type":"array","items":"boolean"}]`},
+		{Map(Float()).Prop("x", 1), `{"type":"map","values":"float","x":1}`},
+		{Fixed("a.f", 4).Decimal(8, 2), `{"type":"fixed","name":"a.f","size":4,"logicalType":"decimal","precision":8,"scale":2}`},
+		{Enum("e", "A").Alias("old"), `{"type":"enum","name":"e","aliases":["old"],"symbols":["A"]}`},
+		{Double().LogicalType("custom"), `{"type":"double","logicalType":"custom"}`},
+		{TimeMicros(), `{"type":"long","logicalType":"time-micros"}`},
+	}
+	for _, c := range cases {
+		text, err := JSON(c.t)
+		ensureError(t, err)
+		if text != c.expected {
+			t.Errorf("GOT: %v; WANT: %v", text, c.expected)
+		}
+	}
+
+	codec, err := Fixed("f", 2).Build()
+	ensureError(t, err)
+	if _, err = codec.BinaryFromNative(nil, []byte{1, 2}); err != nil {
+		t.Error(err)
+	}
+	tree, err := Enum("e", "A").Tree()
+	ensureError(t, err)
+	if _, ok := tree.(*goavro.EnumSchema); !ok {
+		t.Errorf("GOT: %T; WANT: %T", tree, &goavro.EnumSchema{})
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	cases := []struct {
+		t        Type
+		expected string
+	}{
+		{nil, "cannot build schema: type ought to be non-nil"},
+		{Record("1User"), "cannot build schema: Record ought to have valid name: schema name ought to start with [A-Za-z_]: 1User"},
+		{Record("com..User"), "Record ought to have valid name"},
+		{Record("r").Alias("a-b"), `Record "r" alias ought to be valid name`},
+		{Record("r").Field("a-b", Int()), `Record "r" field 1 ought to have valid name: "a-b"`},
+		{Record("r").Field("a.b", Int()), `Record "r" field 1 ought to have valid name: "a.b"`},
+		{Record("r").Field("a", Int()).Field("a", Long()), `Record "r" field 2 ought to have unique name: "a"`},
+		{Record("r").Field("a", Int(), FieldAliases("1a")), `Record "r" field "a" ought to have valid aliases`},
+		{Record("r").Field("a", Int(), Order("up")), `Record "r" field "a" order ought to be ascending, descending, or ignore: "up"`},
+		{Record("r").Field("a", nil), `Record "r" field "a" type ought to be non-nil`},
+		{Record("r").Field("a", Int(), Default("x")), `Record "r" field "a": default value ought to have a number type`},
+		{Record("r").Field("a", Record("r")), `Record "r" ought to be defined once`},
+		{Enum("e"), `Enum "e" ought to have symbols`},
+		{Enum("e", "A", "1"), `Enum "e" symbol 2 ought to be valid name: "1"`},
+		{Enum("e", "A", "A"), `Enum "e" symbol 2 ought to be unique: "A"`},
+		{Enum("e", "A").Default("B"), `Enum "e" default ought to be one of its symbols: "B"`},
+		{Fixed("f", 0), `Fixed "f" size ought to be greater than 0: 0`},
+		{Fixed("f-", 1), "Fixed ought to have valid name"},
+		{Array(nil), "array items ought to be non-nil"},
+		{Map(nil), "map values ought to be non-nil"},
+		{Union(), "union ought to have members"},
+		{Union(Int(), nil), "union member 2 ought to be non-nil"},
+		{Union(Int(), Int()), "cannot build schema"},
+		{Record("r").Prop("fields", 1), `property ought not to be reserved attribute: "fields"`},
+	}
+	for _, c := range cases {
+		_, err := Codec(c.t)
+		ensureError(t, err, c.expected)
+		_, err = JSON(c.t)
+		ensureError(t, err, c.expected)
+	}
+}
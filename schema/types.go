@@ -0,0 +1,512 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package schema
+
+import (
+	"fmt"
+
+	"github.com/linkedin/goavro/v2"
+)
+
+////////////////////////////////////////
+// Primitive types
+////////////////////////////////////////
+
+// PrimitiveBuilder builds a primitive type, optionally annotated with a
+// logical type.
+type PrimitiveBuilder struct {
+	primitive   string
+	logicalType *goavro.LogicalType
+	properties  map[string]interface{}
+}
+
+func primitive(name string) *PrimitiveBuilder { return &PrimitiveBuilder{primitive: name} }
+
+// Null returns a builder of the null type.
+func Null() *PrimitiveBuilder { return primitive("null") }
+
+// Boolean returns a builder of the boolean type.
+func Boolean() *PrimitiveBuilder { return primitive("boolean") }
+
+// Int returns a builder of the int type.
+func Int() *PrimitiveBuilder { return primitive("int") }
+
+// Long returns a builder of the long type.
+func Long() *PrimitiveBuilder { return primitive("long") }
+
+// Float returns a builder of the float type.
+func Float() *PrimitiveBuilder { return primitive("float") }
+
+// Double returns a builder of the double type.
+func Double() *PrimitiveBuilder { return primitive("double") }
+
+// Bytes returns a builder of the bytes type.
+func Bytes() *PrimitiveBuilder { return primitive("bytes") }
+
+// String returns a builder of the string type.
+func String() *PrimitiveBuilder { return primitive("string") }
+
+// Date returns a builder of the int type with the date logical type.
+func Date() *PrimitiveBuilder { return Int().LogicalType("date") }
+
+// TimeMillis returns a builder of the int type with the time-millis logical
+// type.
+func TimeMillis() *PrimitiveBuilder { return Int().LogicalType("time-millis") }
+
+// TimeMicros returns a builder of the long type with the time-micros logical
+// type.
+func TimeMicros() *PrimitiveBuilder { return Long().LogicalType("time-micros") }
+
+// TimestampMillis returns a builder of the long type with the timestamp-millis
+// logical type.
+func TimestampMillis() *PrimitiveBuilder { return Long().LogicalType("timestamp-millis") }
+
+// TimestampMicros returns a builder of the long type with the timestamp-micros
+// logical type.
+func TimestampMicros() *PrimitiveBuilder { return Long().LogicalType("timestamp-micros") }
+
+// Decimal returns a builder of the bytes type with the decimal logical type of
+// the precision and scale.
+func Decimal(precision, scale int) *PrimitiveBuilder {
+	b := Bytes()
+	b.logicalType = &goavro.LogicalType{Name: "decimal", Precision: precision, Scale: scale}
+	return b
+}
+
+// LogicalType annotates the type with the logical type, and returns the
+// builder.
+func (p *PrimitiveBuilder) LogicalType(name string) *PrimitiveBuilder {
+	p.logicalType = &goavro.LogicalType{Name: name}
+	return p
+}
+
+// Prop sets a custom property of the type, and returns the builder.
+func (p *PrimitiveBuilder) Prop(key string, value interface{}) *PrimitiveBuilder {
+	p.properties = setProp(p.properties, key, value)
+	return p
+}
+
+func (p *PrimitiveBuilder) node(_ *treeBuilder, _ string) (goavro.Schema, error) {
+	s := &goavro.PrimitiveSchema{Primitive: p.primitive, Properties: properties(p.properties)}
+	if p.logicalType != nil {
+		logicalType := *p.logicalType
+		s.LogicalType = &logicalType
+	}
+	return s, nil
+}
+
+func setProp(properties map[string]interface{}, key string, value interface{}) map[string]interface{} {
+	if properties == nil {
+		properties = make(map[string]interface{})
+	}
+	properties[key] = value
+	return properties
+}
+
+////////////////////////////////////////
+// Records
+////////////////////////////////////////
+
+// RecordBuilder builds a record type.
+type RecordBuilder struct {
+	name       string
+	doc        string
+	aliases    []string
+	fields     []*fieldBuilder
+	properties map[string]interface{}
+}
+
+type fieldBuilder struct {
+	field goavro.Field
+	t     Type
+}
+
+// FieldOption sets an optional attribute of a record field.
+type FieldOption func(*goavro.Field)
+
+// Default returns a FieldOption that sets the default value of the field, as it
+// would be written in a JSON schema: nil for null, float64 or int for numbers,
+// a string for bytes and fixed values, a map[string]interface{} for records
+// and maps, and a []interface{} for arrays. The default value of a union field
+// is a value of its first member.
+func Default(value interface{}) FieldOption {
+	return func(f *goavro.Field) { f.Default, f.HasDefault = value, true }
+}
+
+// FieldDoc returns a FieldOption that sets the documentation of the field.
+func FieldDoc(doc string) FieldOption {
+	return func(f *goavro.Field) { f.Doc = doc }
+}
+
+// Order returns a FieldOption that sets the sort order of the field:
+// "ascending", "descending", or "ignore".
+func Order(order string) FieldOption {
+	return func(f *goavro.Field) { f.Order = order }
+}
+
+// FieldAliases returns a FieldOption that sets the aliases of the field.
+func FieldAliases(aliases ...string) FieldOption {
+	return func(f *goavro.Field) { f.Aliases = append([]string(nil), aliases...) }
+}
+
+// FieldProp returns a FieldOption that sets a custom property of the field.
+func FieldProp(key string, value interface{}) FieldOption {
+	return func(f *goavro.Field) { f.Properties = setProp(f.Properties, key, value) }
+}
+
+// Record returns a builder of a record type with the name.
+func Record(name string) *RecordBuilder { return &RecordBuilder{name: name} }
+
+// Doc sets the documentation of the record, and returns the builder.
+func (r *RecordBuilder) Doc(doc string) *RecordBuilder {
+	r.doc = doc
+	return r
+}
+
+// Alias adds aliases of the record, and returns the builder.
+func (r *RecordBuilder) Alias(aliases ...string) *RecordBuilder {
+	r.aliases = append(r.aliases, aliases...)
+	return r
+}
+
+// Prop sets a custom property of the record, and returns the builder.
+func (r *RecordBuilder) Prop(key string, value interface{}) *RecordBuilder {
+	r.properties = setProp(r.properties, key, value)
+	return r
+}
+
+// Field adds a field of the type to the record, and returns the builder.
+func (r *RecordBuilder) Field(name string, t Type, options ...FieldOption) *RecordBuilder {
+	f := &fieldBuilder{field: goavro.Field{Name: name}, t: t}
+	for _, option := range options {
+		option(&f.field)
+	}
+	r.fields = append(r.fields, f)
+	return r
+}
+
+// OptionalField adds a field to the record whose type is a union of null and
+// the type, with a default value of null, and returns the builder.
+func (r *RecordBuilder) OptionalField(name string, t Type, options ...FieldOption) *RecordBuilder {
+	return r.Field(name, Union(Null(), t), append([]FieldOption{Default(nil)}, options...)...)
+}
+
+// Build returns a Codec for the record.
+func (r *RecordBuilder) Build() (*goavro.Codec, error) { return Codec(r) }
+
+// JSON returns the JSON text of the schema of the record.
+func (r *RecordBuilder) JSON() (string, error) { return JSON(r) }
+
+// Tree returns the schema tree of the record.
+func (r *RecordBuilder) Tree() (goavro.Schema, error) { return Tree(r) }
+
+func (r *RecordBuilder) node(b *treeBuilder, enclosingNamespace string) (goavro.Schema, error) {
+	if s, ok := b.nodes[r]; ok {
+		return s, nil
+	}
+	name, err := fullName("Record", r.name, enclosingNamespace)
+	if err != nil {
+		return nil, err
+	}
+	aliases, err := aliases("Record", name, r.aliases)
+	if err != nil {
+		return nil, err
+	}
+	s := &goavro.RecordSchema{
+		Name:       name,
+		Aliases:    aliases,
+		Doc:        r.doc,
+		Fields:     make([]*goavro.Field, len(r.fields)),
+		Properties: properties(r.properties),
+	}
+	// NOTE: Remember the record before building its fields, which may refer to
+	// it.
+	b.nodes[r] = s
+
+	names := make(map[string]struct{}, len(r.fields))
+	for i, f := range r.fields {
+		if err := goavro.CheckName(f.field.Name); err != nil || len(namespaceOf(f.field.Name)) > 0 {
+			return nil, fmt.Errorf("Record %q field %d ought to have valid name: %q", name, i+1, f.field.Name)
+		}
+		if _, ok := names[f.field.Name]; ok {
+			return nil, fmt.Errorf("Record %q field %d ought to have unique name: %q", name, i+1, f.field.Name)
+		}
+		names[f.field.Name] = struct{}{}
+		for _, alias := range f.field.Aliases {
+			if err := goavro.CheckName(alias); err != nil {
+				return nil, fmt.Errorf("Record %q field %q ought to have valid aliases: %s", name, f.field.Name, err)
+			}
+		}
+		switch f.field.Order {
+		case "", "ascending", "descending", "ignore":
+		default:
+			return nil, fmt.Errorf("Record %q field %q order ought to be ascending, descending, or ignore: %q", name, f.field.Name, f.field.Order)
+		}
+
+		field := f.field
+		field.Properties = properties(f.field.Properties)
+		if field.Type, err = b.child(f.t, namespaceOf(name), fmt.Sprintf("Record %q field %q type", name, f.field.Name)); err != nil {
+			return nil, err
+		}
+		s.Fields[i] = &field
+	}
+	return s, nil
+}
+
+////////////////////////////////////////
+// Enums and fixed types
+////////////////////////////////////////
+
+// EnumBuilder builds an enum type.
+type EnumBuilder struct {
+	name       string
+	doc        string
+	aliases    []string
+	symbols    []string
+	defaultSym string
+	properties map[string]interface{}
+}
+
+// Enum returns a builder of an enum type with the name and symbols.
+func Enum(name string, symbols ...string) *EnumBuilder {
+	return &EnumBuilder{name: name, symbols: append([]string(nil), symbols...)}
+}
+
+// Doc sets the documentation of the enum, and returns the builder.
+func (e *EnumBuilder) Doc(doc string) *EnumBuilder {
+	e.doc = doc
+	return e
+}
+
+// Alias adds aliases of the enum, and returns the builder.
+func (e *EnumBuilder) Alias(aliases ...string) *EnumBuilder {
+	e.aliases = append(e.aliases, aliases...)
+	return e
+}
+
+// Default sets the symbol used when reading a symbol the enum does not have,
+// and returns the builder.
+func (e *EnumBuilder) Default(symbol string) *EnumBuilder {
+	e.defaultSym = symbol
+	return e
+}
+
+// Prop sets a custom property of the enum, and returns the builder.
+func (e *EnumBuilder) Prop(key string, value interface{}) *EnumBuilder {
+	e.properties = setProp(e.properties, key, value)
+	return e
+}
+
+// Build returns a Codec for the enum.
+func (e *EnumBuilder) Build() (*goavro.Codec, error) { return Codec(e) }
+
+// JSON returns the JSON text of the schema of the enum.
+func (e *EnumBuilder) JSON() (string, error) { return JSON(e) }
+
+// Tree returns the schema tree of the enum.
+func (e *EnumBuilder) Tree() (goavro.Schema, error) { return Tree(e) }
+
+func (e *EnumBuilder) node(b *treeBuilder, enclosingNamespace string) (goavro.Schema, error) {
+	if s, ok := b.nodes[e]; ok {
+		return s, nil
+	}
+	name, err := fullName("Enum", e.name, enclosingNamespace)
+	if err != nil {
+		return nil, err
+	}
+	aliases, err := aliases("Enum", name, e.aliases)
+	if err != nil {
+		return nil, err
+	}
+	if len(e.symbols) == 0 {
+		return nil, fmt.Errorf("Enum %q ought to have symbols", name)
+	}
+	seen := make(map[string]struct{}, len(e.symbols))
+	for i, symbol := range e.symbols {
+		if err := goavro.CheckName(symbol); err != nil || len(namespaceOf(symbol)) > 0 {
+			return nil, fmt.Errorf("Enum %q symbol %d ought to be valid name: %q", name, i+1, symbol)
+		}
+		if _, ok := seen[symbol]; ok {
+			return nil, fmt.Errorf("Enum %q symbol %d ought to be unique: %q", name, i+1, symbol)
+		}
+		seen[symbol] = struct{}{}
+	}
+	if _, ok := seen[e.defaultSym]; e.defaultSym != "" && !ok {
+		return nil, fmt.Errorf("Enum %q default ought to be one of its symbols: %q", name, e.defaultSym)
+	}
+	s := &goavro.EnumSchema{
+		Name:       name,
+		Aliases:    aliases,
+		Doc:        e.doc,
+		Symbols:    append([]string(nil), e.symbols...),
+		Default:    e.defaultSym,
+		Properties: properties(e.properties),
+	}
+	b.nodes[e] = s
+	return s, nil
+}
+
+// FixedBuilder builds a fixed type.
+type FixedBuilder struct {
+	name        string
+	doc         string
+	aliases     []string
+	size        int
+	logicalType *goavro.LogicalType
+	properties  map[string]interface{}
+}
+
+// Fixed returns a builder of a fixed type with the name and size in bytes.
+func Fixed(name string, size int) *FixedBuilder { return &FixedBuilder{name: name, size: size} }
+
+// Doc sets the documentation of the fixed type, and returns the builder.
+func (f *FixedBuilder) Doc(doc string) *FixedBuilder {
+	f.doc = doc
+	return f
+}
+
+// Alias adds aliases of the fixed type, and returns the builder.
+func (f *FixedBuilder) Alias(aliases ...string) *FixedBuilder {
+	f.aliases = append(f.aliases, aliases...)
+	return f
+}
+
+// LogicalType annotates the fixed type with the logical type, and returns the
+// builder.
+func (f *FixedBuilder) LogicalType(name string) *FixedBuilder {
+	f.logicalType = &goavro.LogicalType{Name: name}
+	return f
+}
+
+// Decimal annotates the fixed type with the decimal logical type of the
+// precision and scale, and returns the builder.
+func (f *FixedBuilder) Decimal(precision, scale int) *FixedBuilder {
+	f.logicalType = &goavro.LogicalType{Name: "decimal", Precision: precision, Scale: scale}
+	return f
+}
+
+// Prop sets a custom property of the fixed type, and returns the builder.
+func (f *FixedBuilder) Prop(key string, value interface{}) *FixedBuilder {
+	f.properties = setProp(f.properties, key, value)
+	return f
+}
+
+// Build returns a Codec for the fixed type.
+func (f *FixedBuilder) Build() (*goavro.Codec, error) { return Codec(f) }
+
+// JSON returns the JSON text of the schema of the fixed type.
+func (f *FixedBuilder) JSON() (string, error) { return JSON(f) }
+
+// Tree returns the schema tree of the fixed type.
+func (f *FixedBuilder) Tree() (goavro.Schema, error) { return Tree(f) }
+
+func (f *FixedBuilder) node(b *treeBuilder, enclosingNamespace string) (goavro.Schema, error) {
+	if s, ok := b.nodes[f]; ok {
+		return s, nil
+	}
+	name, err := fullName("Fixed", f.name, enclosingNamespace)
+	if err != nil {
+		return nil, err
+	}
+	aliases, err := aliases("Fixed", name, f.aliases)
+	if err != nil {
+		return nil, err
+	}
+	if f.size <= 0 {
+		return nil, fmt.Errorf("Fixed %q size ought to be greater than 0: %d", name, f.size)
+	}
+	s := &goavro.FixedSchema{
+		Name:       name,
+		Aliases:    aliases,
+		Doc:        f.doc,
+		Size:       f.size,
+		Properties: properties(f.properties),
+	}
+	if f.logicalType != nil {
+		logicalType := *f.logicalType
+		s.LogicalType = &logicalType
+	}
+	b.nodes[f] = s
+	return s, nil
+}
+
+////////////////////////////////////////
+// Arrays, maps, and unions
+////////////////////////////////////////
+
+// ArrayBuilder builds an array type.
+type ArrayBuilder struct {
+	items      Type
+	properties map[string]interface{}
+}
+
+// Array returns a builder of an array type with items of the type.
+func Array(items Type) *ArrayBuilder { return &ArrayBuilder{items: items} }
+
+// Prop sets a custom property of the array, and returns the builder.
+func (a *ArrayBuilder) Prop(key string, value interface{}) *ArrayBuilder {
+	a.properties = setProp(a.properties, key, value)
+	return a
+}
+
+func (a *ArrayBuilder) node(b *treeBuilder, enclosingNamespace string) (goavro.Schema, error) {
+	items, err := b.child(a.items, enclosingNamespace, "array items")
+	if err != nil {
+		return nil, err
+	}
+	return &goavro.ArraySchema{Items: items, Properties: properties(a.properties)}, nil
+}
+
+// MapBuilder builds a map type.
+type MapBuilder struct {
+	values     Type
+	properties map[string]interface{}
+}
+
+// Map returns a builder of a map type with values of the type.
+func Map(values Type) *MapBuilder { return &MapBuilder{values: values} }
+
+// Prop sets a custom property of the map, and returns the builder.
+func (m *MapBuilder) Prop(key string, value interface{}) *MapBuilder {
+	m.properties = setProp(m.properties, key, value)
+	return m
+}
+
+func (m *MapBuilder) node(b *treeBuilder, enclosingNamespace string) (goavro.Schema, error) {
+	values, err := b.child(m.values, enclosingNamespace, "map values")
+	if err != nil {
+		return nil, err
+	}
+	return &goavro.MapSchema{Values: values, Properties: properties(m.properties)}, nil
+}
+
+// UnionBuilder builds a union type.
+type UnionBuilder struct {
+	members []Type
+}
+
+// Union returns a builder of a union type of the member types.
+func Union(members ...Type) *UnionBuilder {
+	return &UnionBuilder{members: append([]Type(nil), members...)}
+}
+
+func (u *UnionBuilder) node(b *treeBuilder, enclosingNamespace string) (goavro.Schema, error) {
+	if len(u.members) == 0 {
+		return nil, fmt.Errorf("union ought to have members")
+	}
+	s := &goavro.UnionSchema{Members: make([]goavro.Schema, len(u.members))}
+	for i, member := range u.members {
+		var err error
+		if s.Members[i], err = b.child(member, enclosingNamespace, fmt.Sprintf("union member %d", i+1)); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
@@ -0,0 +1,715 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package goavro
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Schema is a node of the tree of an Avro schema, as returned by
+// Codec.SchemaTree and accepted by NewCodecFromSchema. It is one of
+// *PrimitiveSchema, *RecordSchema, *EnumSchema, *FixedSchema, *ArraySchema,
+// *MapSchema, or *UnionSchema.
+//
+// Named types occur once in the tree for each time they are referred to, as
+// the same pointer, so the tree of a recursive schema has cycles. When a tree
+// is marshaled to JSON, the first occurrence of each named type defines it, and
+// later occurrences refer to it by name.
+type Schema interface {
+	// Type returns the Avro type of the schema: the name of a primitive type,
+	// "record", "enum", "fixed", "array", "map", or "union".
+	Type() string
+
+	// MarshalJSON returns the JSON text of the schema.
+	MarshalJSON() ([]byte, error)
+
+	// schema ensures only the types of this package implement Schema.
+	schema()
+}
+
+// LogicalType annotates a primitive or fixed schema with a logical type, such
+// as "timestamp-millis" or "decimal". Precision and Scale only apply to the
+// "decimal" logical type.
+type LogicalType struct {
+	Name      string
+	Precision int
+	Scale     int
+}
+
+// PrimitiveSchema is the schema of one of the primitive types: "null",
+// "boolean", "int", "long", "float", "double", "bytes", or "string".
+type PrimitiveSchema struct {
+	Primitive   string
+	LogicalType *LogicalType
+	Properties  map[string]interface{}
+}
+
+// RecordSchema is the schema of a record type. Name and Aliases are full
+// names, which include the namespace of the type.
+type RecordSchema struct {
+	Name       string
+	Aliases    []string
+	Doc        string
+	Fields     []*Field
+	Properties map[string]interface{}
+}
+
+// Field is a field of a record type. Default holds the JSON default value of
+// the field as decoded by the encoding/json package, and only applies when
+// HasDefault is true. Order is "ascending", "descending", "ignore", or empty
+// when the schema does not specify the sort order of the field.
+type Field struct {
+	Name       string
+	Type       Schema
+	Doc        string
+	Default    interface{}
+	HasDefault bool
+	Order      string
+	Aliases    []string
+	Properties map[string]interface{}
+}
+
+// EnumSchema is the schema of an enum type. Name and Aliases are full names,
+// which include the namespace of the type. Default is the symbol used when
+// reading a symbol not among Symbols, or empty when there is none.
+type EnumSchema struct {
+	Name       string
+	Aliases    []string
+	Doc        string
+	Symbols    []string
+	Default    string
+	Properties map[string]interface{}
+}
+
+// FixedSchema is the schema of a fixed type. Name and Aliases are full names,
+// which include the namespace of the type.
+type FixedSchema struct {
+	Name        string
+	Aliases     []string
+	Doc         string
+	Size        int
+	LogicalType *LogicalType
+	Properties  map[string]interface{}
+}
+
+// ArraySchema is the schema of an array type.
+type ArraySchema struct {
+	Items      Schema
+	Properties map[string]interface{}
+}
+
+// MapSchema is the schema of a map type, whose keys are strings.
+type MapSchema struct {
+	Values     Schema
+	Properties map[string]interface{}
+}
+
+// UnionSchema is the schema of a union type.
+type UnionSchema struct {
+	Members []Schema
+}
+
+// Type returns the name of the primitive type.
+func (s *PrimitiveSchema) Type() string { return s.Primitive }
+
+// Type returns "record".
+func (s *RecordSchema) Type() string { return "record" }
+
+// Type returns "enum".
+func (s *EnumSchema) Type() string { return "enum" }
+
+// Type returns "fixed".
+func (s *FixedSchema) Type() string { return "fixed" }
+
+// Type returns "array".
+func (s *ArraySchema) Type() string { return "array" }
+
+// Type returns "map".
+func (s *MapSchema) Type() string { return "map" }
+
+// Type returns "union".
+func (s *UnionSchema) Type() string { return "union" }
+
+func (s *PrimitiveSchema) schema() {}
+func (s *RecordSchema) schema()    {}
+func (s *EnumSchema) schema()      {}
+func (s *FixedSchema) schema()     {}
+func (s *ArraySchema) schema()     {}
+func (s *MapSchema) schema()       {}
+func (s *UnionSchema) schema()     {}
+
+// MarshalJSON returns the JSON text of the schema.
+func (s *PrimitiveSchema) MarshalJSON() ([]byte, error) { return marshalSchema(s) }
+
+// MarshalJSON returns the JSON text of the schema.
+func (s *RecordSchema) MarshalJSON() ([]byte, error) { return marshalSchema(s) }
+
+// MarshalJSON returns the JSON text of the schema.
+func (s *EnumSchema) MarshalJSON() ([]byte, error) { return marshalSchema(s) }
+
+// MarshalJSON returns the JSON text of the schema.
+func (s *FixedSchema) MarshalJSON() ([]byte, error) { return marshalSchema(s) }
+
+// MarshalJSON returns the JSON text of the schema.
+func (s *ArraySchema) MarshalJSON() ([]byte, error) { return marshalSchema(s) }
+
+// MarshalJSON returns the JSON text of the schema.
+func (s *MapSchema) MarshalJSON() ([]byte, error) { return marshalSchema(s) }
+
+// MarshalJSON returns the JSON text of the schema.
+func (s *UnionSchema) MarshalJSON() ([]byte, error) { return marshalSchema(s) }
+
+// SchemaTree returns the tree of the schema used to create the Codec, including
+// the documentation, default values, aliases, sort orders, and custom
+// properties the Parsing Canonical Form omits. Each call returns a new tree,
+// which the caller may modify, for instance to create a Codec for a derived
+// schema using NewCodecFromSchema.
+//
+//	tree, err := codec.SchemaTree()
+//	if err != nil {
+//	    return err
+//	}
+//	record := tree.(*goavro.RecordSchema)
+//	for _, field := range record.Fields {
+//	    fmt.Println(field.Name, field.Type.Type(), field.Doc)
+//	}
+func (c *Codec) SchemaTree() (Schema, error) {
+	var schema interface{}
+	if err := json.Unmarshal([]byte(c.schemaOriginal), &schema); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal schema JSON: %s", err)
+	}
+	p := &schemaTreeParser{named: make(map[string]Schema)}
+	tree, err := p.parse(nullNamespace, schema)
+	if err != nil {
+		return nil, fmt.Errorf("cannot build schema tree: %s", err)
+	}
+	return tree, nil
+}
+
+// NewCodecFromSchema returns a Codec for the schema of the tree.
+//
+//	codec, err := goavro.NewCodecFromSchema(&goavro.RecordSchema{
+//	    Name: "com.example.Point",
+//	    Fields: []*goavro.Field{
+//	        {Name: "x", Type: &goavro.PrimitiveSchema{Primitive: "double"}},
+//	        {Name: "y", Type: &goavro.PrimitiveSchema{Primitive: "double"}},
+//	    },
+//	})
+func NewCodecFromSchema(schema Schema) (*Codec, error) {
+	if schema == nil {
+		return nil, fmt.Errorf("cannot create Codec from nil schema")
+	}
+	buf, err := marshalSchema(schema)
+	if err != nil {
+		return nil, err
+	}
+	return NewCodec(string(buf))
+}
+
+////////////////////////////////////////
+// Parsing JSON schemas into trees
+////////////////////////////////////////
+
+// schemaTreeParser builds the tree of a JSON schema decoded by encoding/json.
+type schemaTreeParser struct {
+	named map[string]Schema // named types by their full names and aliases
+}
+
+var primitiveTypeNames = map[string]struct{}{
+	"null": {}, "boolean": {}, "int": {}, "long": {}, "float": {}, "double": {}, "bytes": {}, "string": {},
+}
+
+func (p *schemaTreeParser) parse(enclosingNamespace string, schema interface{}) (Schema, error) {
+	switch v := schema.(type) {
+	case string:
+		return p.reference(enclosingNamespace, v)
+	case []interface{}:
+		union := &UnionSchema{Members: make([]Schema, len(v))}
+		for i, member := range v {
+			s, err := p.parse(enclosingNamespace, member)
+			if err != nil {
+				return nil, err
+			}
+			union.Members[i] = s
+		}
+		return union, nil
+	case map[string]interface{}:
+		return p.parseMap(enclosingNamespace, v)
+	}
+	return nil, fmt.Errorf("unknown schema type: %T", schema)
+}
+
+// reference returns the primitive type or the named type with the name,
+// resolving names that are not fully qualified relative to the enclosing
+// namespace as NewCodec does.
+func (p *schemaTreeParser) reference(enclosingNamespace, typeName string) (Schema, error) {
+	if _, ok := primitiveTypeNames[typeName]; ok {
+		return &PrimitiveSchema{Primitive: typeName}, nil
+	}
+	if s, ok := p.named[typeName]; ok {
+		return s, nil
+	}
+	if enclosingNamespace != nullNamespace {
+		if s, ok := p.named[enclosingNamespace+"."+typeName]; ok {
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown type name: %q", typeName)
+}
+
+func (p *schemaTreeParser) parseMap(enclosingNamespace string, schemaMap map[string]interface{}) (Schema, error) {
+	t, ok := schemaMap["type"]
+	if !ok {
+		return nil, fmt.Errorf("missing type: %v", schemaMap)
+	}
+	typeName, ok := t.(string)
+	if !ok {
+		return p.parse(enclosingNamespace, t)
+	}
+
+	switch typeName {
+	case "record":
+		return p.parseRecord(enclosingNamespace, schemaMap)
+	case "enum":
+		return p.parseEnum(enclosingNamespace, schemaMap)
+	case "fixed":
+		return p.parseFixed(enclosingNamespace, schemaMap)
+	case "array":
+		items, err := p.parse(enclosingNamespace, schemaMap["items"])
+		if err != nil {
+			return nil, fmt.Errorf("array ought to have valid items: %s", err)
+		}
+		return &ArraySchema{Items: items, Properties: schemaProperties(schemaMap, "type", "items")}, nil
+	case "map":
+		values, err := p.parse(enclosingNamespace, schemaMap["values"])
+		if err != nil {
+			return nil, fmt.Errorf("map ought to have valid values: %s", err)
+		}
+		return &MapSchema{Values: values, Properties: schemaProperties(schemaMap, "type", "values")}, nil
+	}
+	if _, ok := primitiveTypeNames[typeName]; ok {
+		logicalType, reserved := parseLogicalType(schemaMap)
+		return &PrimitiveSchema{
+			Primitive:   typeName,
+			LogicalType: logicalType,
+			Properties:  schemaProperties(schemaMap, append(reserved, "type")...),
+		}, nil
+	}
+	return p.reference(enclosingNamespace, typeName)
+}
+
+// define registers the named type by its full name and aliases, and returns
+// its name and aliases.
+func (p *schemaTreeParser) define(enclosingNamespace string, schemaMap map[string]interface{}, s Schema) (*name, []string, error) {
+	n, err := newNameFromSchemaMap(enclosingNamespace, schemaMap)
+	if err != nil {
+		return nil, nil, err
+	}
+	aliases, err := aliasesFromSchemaMap(n.namespace, schemaMap)
+	if err != nil {
+		return nil, nil, err
+	}
+	p.named[n.fullName] = s
+	for _, alias := range aliases {
+		if _, ok := p.named[alias]; !ok {
+			p.named[alias] = s
+		}
+	}
+	return n, aliases, nil
+}
+
+func (p *schemaTreeParser) parseRecord(enclosingNamespace string, schemaMap map[string]interface{}) (Schema, error) {
+	record := &RecordSchema{
+		Doc:        stringFromSchemaMap(schemaMap, "doc"),
+		Properties: schemaProperties(schemaMap, "type", "name", "namespace", "aliases", "doc", "fields"),
+	}
+	// NOTE: Define the record before parsing its fields, which may refer to it.
+	n, aliases, err := p.define(enclosingNamespace, schemaMap, record)
+	if err != nil {
+		return nil, fmt.Errorf("Record ought to have valid name: %s", err)
+	}
+	record.Name, record.Aliases = n.fullName, aliases
+
+	fields, ok := schemaMap["fields"].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("Record %q fields ought to be non-nil array: %v", record.Name, schemaMap["fields"])
+	}
+	record.Fields = make([]*Field, len(fields))
+	for i, f := range fields {
+		fieldMap, ok := f.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("Record %q field %d ought to be valid Avro named type; received: %v", record.Name, i+1, f)
+		}
+		fieldType, err := p.parse(n.namespace, fieldMap["type"])
+		if err != nil {
+			return nil, fmt.Errorf("Record %q field %d ought to be valid Avro named type: %s", record.Name, i+1, err)
+		}
+		field := &Field{
+			Name:       stringFromSchemaMap(fieldMap, "name"),
+			Type:       fieldType,
+			Doc:        stringFromSchemaMap(fieldMap, "doc"),
+			Order:      stringFromSchemaMap(fieldMap, "order"),
+			Properties: schemaProperties(fieldMap, "name", "type", "doc", "default", "order", "aliases"),
+		}
+		field.Default, field.HasDefault = fieldMap["default"]
+		if values, ok := fieldMap["aliases"].([]interface{}); ok {
+			for _, v := range values {
+				if alias, ok := v.(string); ok {
+					field.Aliases = append(field.Aliases, alias)
+				}
+			}
+		}
+		record.Fields[i] = field
+	}
+	return record, nil
+}
+
+func (p *schemaTreeParser) parseEnum(enclosingNamespace string, schemaMap map[string]interface{}) (Schema, error) {
+	enum := &EnumSchema{
+		Doc:        stringFromSchemaMap(schemaMap, "doc"),
+		Default:    stringFromSchemaMap(schemaMap, "default"),
+		Properties: schemaProperties(schemaMap, "type", "name", "namespace", "aliases", "doc", "symbols", "default"),
+	}
+	n, aliases, err := p.define(enclosingNamespace, schemaMap, enum)
+	if err != nil {
+		return nil, fmt.Errorf("Enum ought to have valid name: %s", err)
+	}
+	enum.Name, enum.Aliases = n.fullName, aliases
+	symbols, _ := schemaMap["symbols"].([]interface{})
+	enum.Symbols = make([]string, 0, len(symbols))
+	for _, symbol := range symbols {
+		if s, ok := symbol.(string); ok {
+			enum.Symbols = append(enum.Symbols, s)
+		}
+	}
+	return enum, nil
+}
+
+func (p *schemaTreeParser) parseFixed(enclosingNamespace string, schemaMap map[string]interface{}) (Schema, error) {
+	logicalType, reserved := parseLogicalType(schemaMap)
+	fixed := &FixedSchema{
+		Doc:         stringFromSchemaMap(schemaMap, "doc"),
+		LogicalType: logicalType,
+		Properties:  schemaProperties(schemaMap, append(reserved, "type", "name", "namespace", "aliases", "doc", "size")...),
+	}
+	n, aliases, err := p.define(enclosingNamespace, schemaMap, fixed)
+	if err != nil {
+		return nil, fmt.Errorf("Fixed ought to have valid name: %s", err)
+	}
+	fixed.Name, fixed.Aliases = n.fullName, aliases
+	if size, ok := schemaMap["size"].(float64); ok {
+		fixed.Size = int(size)
+	}
+	return fixed, nil
+}
+
+// parseLogicalType returns the logical type of the primitive or fixed schema,
+// or nil when it has none, along with the attributes of the schema it used.
+func parseLogicalType(schemaMap map[string]interface{}) (*LogicalType, []string) {
+	logicalTypeName, ok := schemaMap["logicalType"].(string)
+	if !ok {
+		return nil, nil
+	}
+	logicalType := &LogicalType{Name: logicalTypeName}
+	if logicalTypeName != "decimal" {
+		return logicalType, []string{"logicalType"}
+	}
+	if precision, ok := schemaMap["precision"].(float64); ok {
+		logicalType.Precision = int(precision)
+	}
+	if scale, ok := schemaMap["scale"].(float64); ok {
+		logicalType.Scale = int(scale)
+	}
+	return logicalType, []string{"logicalType", "precision", "scale"}
+}
+
+func stringFromSchemaMap(schemaMap map[string]interface{}, key string) string {
+	s, _ := schemaMap[key].(string)
+	return s
+}
+
+// schemaProperties returns the attributes of the schema other than the
+// reserved ones, or nil when there are none.
+func schemaProperties(schemaMap map[string]interface{}, reserved ...string) map[string]interface{} {
+	var properties map[string]interface{}
+	for key, value := range schemaMap {
+		if isReservedAttribute(key, reserved) {
+			continue
+		}
+		if properties == nil {
+			properties = make(map[string]interface{})
+		}
+		properties[key] = value
+	}
+	return properties
+}
+
+func isReservedAttribute(key string, reserved []string) bool {
+	for _, r := range reserved {
+		if key == r {
+			return true
+		}
+	}
+	return false
+}
+
+////////////////////////////////////////
+// Marshaling trees into JSON schemas
+////////////////////////////////////////
+
+// marshalSchema returns the JSON text of the schema of the tree.
+func marshalSchema(schema Schema) ([]byte, error) {
+	w := &schemaTreeWriter{defined: make(map[string]Schema)}
+	if err := w.write(nullNamespace, schema); err != nil {
+		return nil, fmt.Errorf("cannot marshal schema: %s", err)
+	}
+	return w.buf.Bytes(), nil
+}
+
+// schemaTreeWriter writes the JSON text of a tree, defining each named type at
+// its first occurrence.
+type schemaTreeWriter struct {
+	buf     bytes.Buffer
+	defined map[string]Schema
+}
+
+// quote writes the JSON text of the value.
+func (w *schemaTreeWriter) quote(value interface{}) error {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	w.buf.Write(encoded)
+	return nil
+}
+
+// attribute writes a comma, when not the first attribute, followed by the key.
+func (w *schemaTreeWriter) attribute(key string) {
+	if w.buf.Bytes()[w.buf.Len()-1] != '{' {
+		w.buf.WriteByte(',')
+	}
+	_ = w.quote(key) // marshaling a string cannot fail
+	w.buf.WriteByte(':')
+}
+
+func (w *schemaTreeWriter) write(enclosingNamespace string, schema Schema) error {
+	switch s := schema.(type) {
+	case *PrimitiveSchema:
+		return w.writePrimitive(s)
+	case *RecordSchema:
+		return w.writeRecord(enclosingNamespace, s)
+	case *EnumSchema:
+		return w.writeEnum(enclosingNamespace, s)
+	case *FixedSchema:
+		return w.writeFixed(enclosingNamespace, s)
+	case *ArraySchema:
+		if s.Items == nil {
+			return fmt.Errorf("array ought to have items")
+		}
+		w.buf.WriteString(`{"type":"array","items":`)
+		if err := w.write(enclosingNamespace, s.Items); err != nil {
+			return err
+		}
+		return w.properties(s.Properties, "type", "items")
+	case *MapSchema:
+		if s.Values == nil {
+			return fmt.Errorf("map ought to have values")
+		}
+		w.buf.WriteString(`{"type":"map","values":`)
+		if err := w.write(enclosingNamespace, s.Values); err != nil {
+			return err
+		}
+		return w.properties(s.Properties, "type", "values")
+	case *UnionSchema:
+		w.buf.WriteByte('[')
+		for i, member := range s.Members {
+			if i > 0 {
+				w.buf.WriteByte(',')
+			}
+			if member == nil {
+				return fmt.Errorf("union member %d ought to be non-nil", i+1)
+			}
+			if err := w.write(enclosingNamespace, member); err != nil {
+				return err
+			}
+		}
+		w.buf.WriteByte(']')
+		return nil
+	}
+	return fmt.Errorf("unknown schema: %T", schema)
+}
+
+// properties writes the custom properties in lexical order, and closes the
+// JSON object.
+func (w *schemaTreeWriter) properties(properties map[string]interface{}, reserved ...string) error {
+	keys := make([]string, 0, len(properties))
+	for key := range properties {
+		if isReservedAttribute(key, reserved) {
+			return fmt.Errorf("property ought not to be reserved attribute: %q", key)
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		w.attribute(key)
+		if err := w.quote(properties[key]); err != nil {
+			return fmt.Errorf("cannot marshal property %q: %s", key, err)
+		}
+	}
+	w.buf.WriteByte('}')
+	return nil
+}
+
+func (w *schemaTreeWriter) writePrimitive(s *PrimitiveSchema) error {
+	if _, ok := primitiveTypeNames[s.Primitive]; !ok {
+		return fmt.Errorf("unknown primitive type name: %q", s.Primitive)
+	}
+	if s.LogicalType == nil && len(s.Properties) == 0 {
+		return w.quote(s.Primitive)
+	}
+	w.buf.WriteString(`{"type":`)
+	_ = w.quote(s.Primitive) // marshaling a string cannot fail
+	reserved := w.logicalType(s.LogicalType)
+	return w.properties(s.Properties, append(reserved, "type")...)
+}
+
+// logicalType writes the attributes of the logical type, when not nil, and
+// returns their keys.
+func (w *schemaTreeWriter) logicalType(logicalType *LogicalType) []string {
+	if logicalType == nil {
+		return nil
+	}
+	w.attribute("logicalType")
+	_ = w.quote(logicalType.Name) // marshaling a string cannot fail
+	if logicalType.Name != "decimal" {
+		return []string{"logicalType"}
+	}
+	w.attribute("precision")
+	_ = w.quote(logicalType.Precision)
+	w.attribute("scale")
+	_ = w.quote(logicalType.Scale)
+	return []string{"logicalType", "precision", "scale"}
+}
+
+// named writes the beginning of the definition of a named type, and returns
+// its namespace, or writes its name and returns false when it was already
+// defined.
+func (w *schemaTreeWriter) named(enclosingNamespace, typeName, fullName string, aliases []string, doc string, schema Schema) (string, bool, error) {
+	n, err := newName(fullName, nullNamespace, nullNamespace)
+	if err != nil {
+		return "", false, fmt.Errorf("%s ought to have valid name: %s", typeName, err)
+	}
+	if defined, ok := w.defined[n.fullName]; ok {
+		if defined != schema {
+			return "", false, fmt.Errorf("%s %q ought to be defined once", typeName, n.fullName)
+		}
+		return "", false, w.quote(n.fullName)
+	}
+	w.defined[n.fullName] = schema
+
+	w.buf.WriteString(`{"type":`)
+	_ = w.quote(strings.ToLower(typeName)) // marshaling a string cannot fail
+	w.attribute("name")
+	_ = w.quote(n.fullName)
+	if n.namespace == nullNamespace && enclosingNamespace != nullNamespace {
+		// NOTE: Without an explicit null namespace, the name of the type would
+		// be relative to the enclosing namespace.
+		w.attribute("namespace")
+		w.buf.WriteString(`""`)
+	}
+	if len(aliases) > 0 {
+		w.attribute("aliases")
+		_ = w.quote(aliases) // marshaling strings cannot fail
+	}
+	if doc != "" {
+		w.attribute("doc")
+		_ = w.quote(doc)
+	}
+	return n.namespace, true, nil
+}
+
+func (w *schemaTreeWriter) writeRecord(enclosingNamespace string, s *RecordSchema) error {
+	namespace, ok, err := w.named(enclosingNamespace, "Record", s.Name, s.Aliases, s.Doc, s)
+	if !ok || err != nil {
+		return err
+	}
+	w.attribute("fields")
+	w.buf.WriteByte('[')
+	for i, field := range s.Fields {
+		if i > 0 {
+			w.buf.WriteByte(',')
+		}
+		if field == nil || field.Type == nil {
+			return fmt.Errorf("Record %q field %d ought to have type", s.Name, i+1)
+		}
+		w.buf.WriteString(`{"name":`)
+		_ = w.quote(field.Name) // marshaling a string cannot fail
+		w.attribute("type")
+		if err = w.write(namespace, field.Type); err != nil {
+			return err
+		}
+		if field.Doc != "" {
+			w.attribute("doc")
+			_ = w.quote(field.Doc)
+		}
+		if field.HasDefault {
+			w.attribute("default")
+			if err = w.quote(field.Default); err != nil {
+				return fmt.Errorf("cannot marshal default value of Record %q field %q: %s", s.Name, field.Name, err)
+			}
+		}
+		if field.Order != "" {
+			w.attribute("order")
+			_ = w.quote(field.Order)
+		}
+		if len(field.Aliases) > 0 {
+			w.attribute("aliases")
+			_ = w.quote(field.Aliases)
+		}
+		if err = w.properties(field.Properties, "name", "type", "doc", "default", "order", "aliases"); err != nil {
+			return err
+		}
+	}
+	w.buf.WriteByte(']')
+	return w.properties(s.Properties, "type", "name", "namespace", "aliases", "doc", "fields")
+}
+
+func (w *schemaTreeWriter) writeEnum(enclosingNamespace string, s *EnumSchema) error {
+	_, ok, err := w.named(enclosingNamespace, "Enum", s.Name, s.Aliases, s.Doc, s)
+	if !ok || err != nil {
+		return err
+	}
+	w.attribute("symbols")
+	symbols := s.Symbols
+	if symbols == nil {
+		symbols = []string{}
+	}
+	_ = w.quote(symbols) // marshaling strings cannot fail
+	if s.Default != "" {
+		w.attribute("default")
+		_ = w.quote(s.Default)
+	}
+	return w.properties(s.Properties, "type", "name", "namespace", "aliases", "doc", "symbols", "default")
+}
+
+func (w *schemaTreeWriter) writeFixed(enclosingNamespace string, s *FixedSchema) error {
+	_, ok, err := w.named(enclosingNamespace, "Fixed", s.Name, s.Aliases, s.Doc, s)
+	if !ok || err != nil {
+		return err
+	}
+	w.attribute("size")
+	_ = w.quote(s.Size)
+	reserved := w.logicalType(s.LogicalType)
+	return w.properties(s.Properties, append(reserved, "type", "name", "namespace", "aliases", "doc", "size")...)
+}
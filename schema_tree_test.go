@@ -0,0 +1,256 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package goavro
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+const schemaTreeTestSchema = `{
+  "type": "record",
+  "name": "Event",
+  "namespace": "com.example",
+  "doc": "An event.",
+  "aliases": ["OldEvent"],
+  "owner": "team-a",
+  "fields": [
+    {"name": "id", "type": {"type": "string", "logicalType": "uuid"}, "doc": "Identifier.", "order": "descending", "aliases": ["key"], "pii": false},
+    {"name": "amount", "type": {"type": "bytes", "logicalType": "decimal", "precision": 9, "scale": 2}, "default": "\u0000"},
+    {"name": "kind", "type": {"type": "enum", "name": "Kind", "symbols": ["A", "B", "UNKNOWN"], "default": "UNKNOWN", "doc": "Kind of event."}},
+    {"name": "hash", "type": {"type": "fixed", "name": "md5", "namespace": "com.example.hash", "size": 16}},
+    {"name": "tags", "type": {"type": "map", "values": "string", "java-class": "java.util.HashMap"}, "default": {}},
+    {"name": "next", "type": ["null", "Event"], "default": null},
+    {"name": "kinds", "type": {"type": "array", "items": "Kind"}},
+    {"name": "hashes", "type": {"type": "array", "items": "com.example.hash.md5"}, "default": []},
+    {"name": "when", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+    {"name": "count", "type": "int", "default": 3}
+  ]
+}`
+
+func ExampleCodec_SchemaTree() {
+	codec, err := NewCodec(`{"type":"record","name":"Point","fields":[{"name":"x","type":"double","doc":"Abscissa."},{"name":"y","type":"double","doc":"Ordinate."}]}`)
+	if err != nil {
+		fmt.Println(err)
+	}
+	tree, err := codec.SchemaTree()
+	if err != nil {
+		fmt.Println(err)
+	}
+	for _, field := range tree.(*RecordSchema).Fields {
+		fmt.Println(field.Name, field.Type.Type(), field.Doc)
+	}
+	// Output:
+	// x double Abscissa.
+	// y double Ordinate.
+}
+
+func TestSchemaTree(t *testing.T) {
+	codec, err := NewCodec(schemaTreeTestSchema)
+	ensureError(t, err)
+	tree, err := codec.SchemaTree()
+	ensureError(t, err)
+
+	event, ok := tree.(*RecordSchema)
+	if !ok {
+		t.Fatalf("GOT: %T; WANT: %T", tree, event)
+	}
+	if event.Name != "com.example.Event" || event.Doc != "An event." {
+		t.Errorf("GOT: %q, %q; WANT: %q, %q", event.Name, event.Doc, "com.example.Event", "An event.")
+	}
+	if expected := []string{"com.example.OldEvent"}; !reflect.DeepEqual(event.Aliases, expected) {
+		t.Errorf("GOT: %v; WANT: %v", event.Aliases, expected)
+	}
+	if expected := map[string]interface{}{"owner": "team-a"}; !reflect.DeepEqual(event.Properties, expected) {
+		t.Errorf("GOT: %v; WANT: %v", event.Properties, expected)
+	}
+	if len(event.Fields) != 10 {
+		t.Fatalf("GOT: %v; WANT: %v", len(event.Fields), 10)
+	}
+
+	id := event.Fields[0]
+	expectedID := &Field{
+		Name:       "id",
+		Type:       &PrimitiveSchema{Primitive: "string", LogicalType: &LogicalType{Name: "uuid"}},
+		Doc:        "Identifier.",
+		Order:      "descending",
+		Aliases:    []string{"key"},
+		Properties: map[string]interface{}{"pii": false},
+	}
+	if !reflect.DeepEqual(id, expectedID) {
+		t.Errorf("GOT: %#v; WANT: %#v", id, expectedID)
+	}
+
+	amount := event.Fields[1]
+	if expected := (&LogicalType{Name: "decimal", Precision: 9, Scale: 2}); !reflect.DeepEqual(amount.Type.(*PrimitiveSchema).LogicalType, expected) {
+		t.Errorf("GOT: %v; WANT: %v", amount.Type.(*PrimitiveSchema).LogicalType, expected)
+	}
+	if !amount.HasDefault || amount.Default != "\x00" {
+		t.Errorf("GOT: %v, %q; WANT: %v, %q", amount.HasDefault, amount.Default, true, "\x00")
+	}
+
+	kind := event.Fields[2].Type.(*EnumSchema)
+	expectedKind := &EnumSchema{Name: "com.example.Kind", Doc: "Kind of event.", Symbols: []string{"A", "B", "UNKNOWN"}, Default: "UNKNOWN"}
+	if !reflect.DeepEqual(kind, expectedKind) {
+		t.Errorf("GOT: %#v; WANT: %#v", kind, expectedKind)
+	}
+	if event.Fields[2].HasDefault {
+		t.Errorf("GOT: %v; WANT: %v", event.Fields[2].HasDefault, false)
+	}
+
+	hash := event.Fields[3].Type.(*FixedSchema)
+	if hash.Name != "com.example.hash.md5" || hash.Size != 16 {
+		t.Errorf("GOT: %q, %d; WANT: %q, %d", hash.Name, hash.Size, "com.example.hash.md5", 16)
+	}
+
+	tags := event.Fields[4].Type.(*MapSchema)
+	if tags.Values.Type() != "string" || tags.Properties["java-class"] != "java.util.HashMap" {
+		t.Errorf("GOT: %#v; WANT: map of string with java-class", tags)
+	}
+
+	// Named types referred to by name are the same node as their definitions.
+	next := event.Fields[5].Type.(*UnionSchema)
+	if len(next.Members) != 2 || next.Members[0].Type() != "null" || next.Members[1] != Schema(event) {
+		t.Errorf("GOT: %#v; WANT: union of null and the record", next)
+	}
+	if items := event.Fields[6].Type.(*ArraySchema).Items; items != Schema(kind) {
+		t.Errorf("GOT: %#v; WANT: %#v", items, kind)
+	}
+	if items := event.Fields[7].Type.(*ArraySchema).Items; items != Schema(hash) {
+		t.Errorf("GOT: %#v; WANT: %#v", items, hash)
+	}
+
+	if logicalType := event.Fields[8].Type.(*PrimitiveSchema).LogicalType; logicalType == nil || logicalType.Name != "timestamp-millis" {
+		t.Errorf("GOT: %v; WANT: timestamp-millis", logicalType)
+	}
+	if count := event.Fields[9]; !count.HasDefault || count.Default != float64(3) {
+		t.Errorf("GOT: %v, %v; WANT: %v, %v", count.HasDefault, count.Default, true, 3)
+	}
+}
+
+func TestSchemaTreeRoundTrip(t *testing.T) {
+	schemas := []string{
+		`"null"`,
+		`{"type":"long","logicalType":"timestamp-micros"}`,
+		`["null","int",{"type":"array","items":"string"}]`,
+		`{"type":"fixed","name":"f","size":4,"logicalType":"decimal","precision":8,"scale":0}`,
+		`{"type":"record","name":"LongList","fields":[{"name":"next","type":["null","LongList"],"default":null}]}`,
+		`{"type":"record","name":"a.R","fields":[{"name":"s","type":{"type":"record","name":"S","namespace":"b","fields":[{"name":"t","type":{"type":"enum","name":"T","symbols":["X"]}}]}},{"name":"t","type":"b.T"}]}`,
+		schemaTreeTestSchema,
+	}
+	for i, schema := range schemas {
+		codec, err := NewCodec(schema)
+		ensureError(t, err)
+		tree, err := codec.SchemaTree()
+		ensureError(t, err)
+		roundTrip, err := NewCodecFromSchema(tree)
+		ensureError(t, err)
+		// NOTE: The canonical form of the test schema, with relative names in
+		// nested namespaces, differs from that of the tree, whose names are
+		// all full names.
+		if actual, expected := roundTrip.CanonicalSchema(), codec.CanonicalSchema(); i < len(schemas)-1 && actual != expected {
+			t.Errorf("GOT: %v; WANT: %v", actual, expected)
+		}
+
+		// Nothing is lost marshaling the tree.
+		again, err := roundTrip.SchemaTree()
+		ensureError(t, err)
+		if actual, expected := mustMarshalSchema(t, again), mustMarshalSchema(t, tree); actual != expected {
+			t.Errorf("GOT: %v; WANT: %v", actual, expected)
+		}
+	}
+}
+
+func mustMarshalSchema(t *testing.T, schema Schema) string {
+	t.Helper()
+	buf, err := json.Marshal(schema)
+	ensureError(t, err)
+	return string(buf)
+}
+
+func TestSchemaTreeMarshal(t *testing.T) {
+	point := &RecordSchema{
+		Name: "com.example.Point",
+		Doc:  "A point.",
+		Fields: []*Field{
+			{Name: "x", Type: &PrimitiveSchema{Primitive: "double"}, HasDefault: true, Default: 0},
+			{Name: "label", Type: &PrimitiveSchema{Primitive: "string", Properties: map[string]interface{}{"avro.java.string": "String"}}},
+		},
+	}
+	line := &RecordSchema{
+		Name: "Line",
+		Fields: []*Field{
+			{Name: "from", Type: point},
+			{Name: "to", Type: point},
+		},
+	}
+	if actual, expected := mustMarshalSchema(t, line), `{"type":"record","name":"Line","fields":[`+
+		`{"name":"from","type":{"type":"record","name":"com.example.Point","doc":"A point.","fields":[`+
+		`{"name":"x","type":"double","default":0},`+
+		`{"name":"label","type":{"type":"string","avro.java.string":"String"}}]}},`+
+		`{"name":"to","type":"com.example.Point"}]}`; actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+
+	codec, err := NewCodecFromSchema(line)
+	ensureError(t, err)
+	buf, err := codec.BinaryFromNative(nil, map[string]interface{}{
+		"from": map[string]interface{}{"label": "a"},
+		"to":   map[string]interface{}{"x": 1.0, "label": "b"},
+	})
+	ensureError(t, err)
+	datum, _, err := codec.NativeFromBinary(buf)
+	ensureError(t, err)
+	if expected := map[string]interface{}{
+		"from": map[string]interface{}{"x": 0.0, "label": "a"},
+		"to":   map[string]interface{}{"x": 1.0, "label": "b"},
+	}; !reflect.DeepEqual(datum, expected) {
+		t.Errorf("GOT: %v; WANT: %v", datum, expected)
+	}
+
+	// A type without a namespace nested in a type with one has an explicit null
+	// namespace.
+	outer := &RecordSchema{Name: "a.Outer", Fields: []*Field{
+		{Name: "e", Type: &EnumSchema{Name: "E", Symbols: []string{"X"}}},
+	}}
+	if actual, expected := mustMarshalSchema(t, outer), `{"type":"record","name":"a.Outer","fields":[{"name":"e","type":{"type":"enum","name":"E","namespace":"","symbols":["X"]}}]}`; actual != expected {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+}
+
+func TestSchemaTreeMarshalErrors(t *testing.T) {
+	_, err := NewCodecFromSchema(nil)
+	ensureError(t, err, "cannot create Codec from nil schema")
+
+	cases := []struct {
+		schema   Schema
+		expected string
+	}{
+		{&PrimitiveSchema{Primitive: "integer"}, `unknown primitive type name: "integer"`},
+		{&ArraySchema{}, "array ought to have items"},
+		{&MapSchema{}, "map ought to have values"},
+		{&UnionSchema{Members: []Schema{nil}}, "union member 1 ought to be non-nil"},
+		{&RecordSchema{Name: "1r"}, "Record ought to have valid name"},
+		{&RecordSchema{Name: "r", Fields: []*Field{{Name: "a"}}}, `Record "r" field 1 ought to have type`},
+		{&RecordSchema{Name: "r", Fields: []*Field{{Name: "a", Type: &FixedSchema{Name: "r", Size: 1}}}}, `Fixed "r" ought to be defined once`},
+		{&RecordSchema{Name: "r", Properties: map[string]interface{}{"fields": 1}}, `property ought not to be reserved attribute: "fields"`},
+		{&MapSchema{Values: &PrimitiveSchema{Primitive: "int"}, Properties: map[string]interface{}{"bad": func() {}}}, `cannot marshal property "bad"`},
+	}
+	for _, c := range cases {
+		_, err := NewCodecFromSchema(c.schema)
+		ensureError(t, err, "cannot marshal schema", c.expected)
+	}
+
+	// The tree is marshaled, but the schema is not valid.
+	_, err = NewCodecFromSchema(&EnumSchema{Name: "e", Symbols: []string{"A"}, Default: "B"})
+	ensureError(t, err, "default")
+}
@@ -0,0 +1,97 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package goavro
+
+import (
+	"fmt"
+	"io"
+	"math"
+)
+
+// SkipBinary returns the bytes that follow the binary encoded datum at the
+// start of the provided byte slice, without decoding the datum into its native
+// form. It does not allocate memory, unless it returns an error, so it is much
+// faster than NativeFromBinary for counting datums or finding where each one
+// ends. On error, it returns the original byte slice, and the error message.
+//
+// Arrays and maps whose blocks were encoded along with their size in bytes are
+// skipped over a block at a time, without walking their items.
+//
+//	codec, err := goavro.NewCodec(`"string"`)
+//	if err != nil {
+//	    fmt.Println(err)
+//	}
+//	buf := []byte{0x06, 'a', 'b', 'c', 0x02, 'd'}
+//	var count int
+//	for len(buf) > 0 {
+//	    if buf, err = codec.SkipBinary(buf); err != nil {
+//	        fmt.Println(err)
+//	        break
+//	    }
+//	    count++
+//	}
+//	fmt.Println(count)
+//	// Output: 2
+func (c *Codec) SkipBinary(buf []byte) ([]byte, error) {
+	newBuf, err := c.skipBinary(buf)
+	if err != nil {
+		return buf, err // if error, return original byte slice
+	}
+	return newBuf, nil
+}
+
+// blocksSkipBinary returns a function that skips the blocks of a binary array
+// or map, using itemSkipBinary to skip each of its items when a block was
+// encoded without its size.
+func blocksSkipBinary(kind string, itemSkipBinary func([]byte) ([]byte, error)) func([]byte) ([]byte, error) {
+	return func(buf []byte) ([]byte, error) {
+		for {
+			blockCount, rest, err := longFromBinary(buf)
+			if err != nil {
+				return nil, fmt.Errorf("cannot decode binary %s block count: %s", kind, err)
+			}
+			buf = rest
+			if blockCount == 0 {
+				return buf, nil
+			}
+			if blockCount < 0 {
+				// NOTE: A negative block count implies there is a long
+				// encoded block size following the negative block count,
+				// which allows skipping the entire block at once.
+				if blockCount == math.MinInt64 {
+					// The minimum number for any signed numerical type can
+					// never be made positive
+					return nil, fmt.Errorf("cannot decode binary %s with block count: %d", kind, blockCount)
+				}
+				var blockSize int64
+				if blockSize, buf, err = longFromBinary(buf); err != nil {
+					return nil, fmt.Errorf("cannot decode binary %s block size: %s", kind, err)
+				}
+				if blockSize < 0 {
+					return nil, fmt.Errorf("cannot decode binary %s with block size: %d", kind, blockSize)
+				}
+				if blockSize > int64(len(buf)) {
+					return nil, fmt.Errorf("cannot decode binary %s block: %s", kind, io.ErrShortBuffer)
+				}
+				buf = buf[blockSize:]
+				continue
+			}
+			// Ensure block count does not exceed some sane value.
+			if blockCount > MaxBlockCount {
+				return nil, fmt.Errorf("cannot decode binary %s when block count exceeds MaxBlockCount: %d > %d", kind, blockCount, MaxBlockCount)
+			}
+			for i := int64(0); i < blockCount; i++ {
+				if buf, err = itemSkipBinary(buf); err != nil {
+					return nil, fmt.Errorf("cannot decode binary %s item %d: %s", kind, i+1, err)
+				}
+			}
+		}
+	}
+}
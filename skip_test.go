@@ -0,0 +1,179 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package goavro
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func ExampleCodec_SkipBinary() {
+	codec, err := NewCodec(`"string"`)
+	if err != nil {
+		fmt.Println(err)
+	}
+	buf := []byte{0x06, 'a', 'b', 'c', 0x02, 'd'}
+	var count int
+	for len(buf) > 0 {
+		if buf, err = codec.SkipBinary(buf); err != nil {
+			fmt.Println(err)
+			break
+		}
+		count++
+	}
+	fmt.Println(count)
+	// Output: 2
+}
+
+// testSkipBinary ensures SkipBinary consumes the same bytes as
+// NativeFromBinary, and fails for every truncation of a datum that is not
+// empty.
+func testSkipBinary(t *testing.T, codec *Codec, datum interface{}) {
+	t.Helper()
+	buf, err := codec.BinaryFromNative(nil, datum)
+	ensureError(t, err)
+	trailer := []byte{0xFF, 0xFE}
+	buf = append(buf, trailer...)
+
+	remaining, err := codec.SkipBinary(buf)
+	ensureError(t, err)
+	if !bytes.Equal(remaining, trailer) {
+		t.Errorf("schema: %s; GOT: %#v; WANT: %#v", codec.Schema(), remaining, trailer)
+	}
+
+	size := len(buf) - len(trailer)
+	for i := 0; i < size; i++ {
+		if _, err = codec.SkipBinary(buf[:i]); err == nil {
+			t.Errorf("schema: %s; size %d of %d; GOT: %v; WANT: %v", codec.Schema(), i, size, err, "error")
+		}
+	}
+}
+
+func TestSkipBinary(t *testing.T) {
+	cases := []struct {
+		schema string
+		datum  interface{}
+	}{
+		{`"null"`, nil},
+		{`"boolean"`, true},
+		{`"int"`, int32(-123456)},
+		{`"long"`, int64(1) << 60},
+		{`"float"`, float32(3.5)},
+		{`"double"`, 3.5},
+		{`"bytes"`, []byte("some bytes")},
+		{`"string"`, "some string"},
+		{`{"type":"fixed","name":"f","size":3}`, []byte("abc")},
+		{`{"type":"enum","name":"e","symbols":["A","B"]}`, "B"},
+		{`{"type":"array","items":"string"}`, []interface{}{"a", "bc", "def"}},
+		{`{"type":"array","items":"string"}`, []interface{}{}},
+		{`{"type":"map","values":"long"}`, map[string]interface{}{"a": int64(1), "b": int64(2)}},
+		{`["null","string"]`, nil},
+		{`["null","string"]`, Union("string", "some string")},
+		{`{"type":"record","name":"r","fields":[{"name":"a","type":"int"},{"name":"b","type":{"type":"array","items":{"type":"map","values":"string"}}}]}`,
+			map[string]interface{}{"a": int32(1), "b": []interface{}{map[string]interface{}{"k": "v"}}}},
+		{`{"type":"record","name":"LongList","fields":[{"name":"next","type":["null","LongList"],"default":null}]}`,
+			map[string]interface{}{"next": Union("LongList", map[string]interface{}{"next": nil})}},
+		{`{"type":"long","logicalType":"timestamp-millis"}`, time.Unix(1e9, 0)},
+		{`{"type":"int","logicalType":"date"}`, time.Unix(1e9, 0)},
+		{`{"type":"bytes","logicalType":"decimal","precision":4,"scale":2}`, big.NewRat(1234, 100)},
+		{`{"type":"fixed","name":"d","size":4,"logicalType":"decimal","precision":4,"scale":2}`, big.NewRat(1234, 100)},
+		{`{"type":"string","name":"lower","logicalType":"validated-string","pattern":"^[a-z]+$"}`, "abc"},
+	}
+	for _, c := range cases {
+		codec, err := NewCodec(c.schema)
+		ensureError(t, err)
+		testSkipBinary(t, codec, c.datum)
+	}
+}
+
+func TestSkipBinaryStandardJSON(t *testing.T) {
+	codec, err := NewCodecForStandardJSONFull(`["null","string"]`)
+	ensureError(t, err)
+	testSkipBinary(t, codec, Union("string", "some string"))
+}
+
+func TestSkipBinaryResolution(t *testing.T) {
+	codec, err := NewCodecForResolution(`"int"`, `"long"`, nil)
+	ensureError(t, err)
+	buf, err := codec.SkipBinary([]byte{0xF0, 0x01, 0xFF})
+	ensureError(t, err)
+	if expected := []byte{0xFF}; !bytes.Equal(buf, expected) {
+		t.Errorf("GOT: %#v; WANT: %#v", buf, expected)
+	}
+}
+
+func TestSkipBinaryBlocksWithSize(t *testing.T) {
+	codec, err := NewCodec(`{"type":"map","values":"int"}`)
+	ensureError(t, err)
+
+	// block count -1, block size 3, key "a", value 1, then block count -1,
+	// block size 3, key "b", value 2, then end of blocks
+	buf := []byte{0x01, 0x06, 0x02, 'a', 0x02, 0x01, 0x06, 0x02, 'b', 0x04, 0x00, 0xFF}
+	remaining, err := codec.SkipBinary(buf)
+	ensureError(t, err)
+	if expected := []byte{0xFF}; !bytes.Equal(remaining, expected) {
+		t.Errorf("GOT: %#v; WANT: %#v", remaining, expected)
+	}
+
+	// NOTE: The items of a block with a size are not walked, so a block
+	// with a size is skipped even when its items are invalid.
+	remaining, err = codec.SkipBinary([]byte{0x01, 0x08, 0xFF, 0xFF, 0xFF, 0xFF, 0x00})
+	ensureError(t, err)
+	if len(remaining) != 0 {
+		t.Errorf("GOT: %#v; WANT: %#v", remaining, []byte{})
+	}
+
+	_, err = codec.SkipBinary([]byte{0x01, 0x08, 0x02, 'a', 0x02})
+	ensureError(t, err, "cannot decode binary map block", "short buffer")
+
+	_, err = codec.SkipBinary([]byte{0x01, 0x01, 0x00})
+	ensureError(t, err, "cannot decode binary map with block size: -1")
+
+	_, err = codec.SkipBinary([]byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x01})
+	ensureError(t, err, "cannot decode binary map with block count")
+}
+
+func TestSkipBinaryErrors(t *testing.T) {
+	codec, err := NewCodec(`{"type":"record","name":"r","fields":[{"name":"a","type":["null","string"]}]}`)
+	ensureError(t, err)
+
+	buf := []byte{0x04}
+	remaining, err := codec.SkipBinary(buf)
+	ensureError(t, err, `cannot decode binary record "r" field "a"`, "index ought to be between 0 and 1")
+	if !bytes.Equal(remaining, buf) {
+		t.Errorf("GOT: %#v; WANT: %#v", remaining, buf)
+	}
+
+	_, err = codec.SkipBinary([]byte{0x02, 0x01})
+	ensureError(t, err, `cannot decode binary record "r" field "a"`, "cannot decode binary string", "negative size")
+
+	codec, err = NewCodec(`{"type":"array","items":"int"}`)
+	ensureError(t, err)
+	_, err = codec.SkipBinary([]byte{0x02, 0x80})
+	ensureError(t, err, "cannot decode binary array item 1", "short buffer")
+}
+
+func TestSkipBinaryAllocations(t *testing.T) {
+	codec, err := NewCodec(projectionTestSchema)
+	ensureError(t, err)
+	buf, err := codec.BinaryFromNative(nil, projectionTestDatum())
+	ensureError(t, err)
+	allocs := testing.AllocsPerRun(100, func() {
+		if _, err := codec.SkipBinary(buf); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("GOT: %v; WANT: %v", allocs, 0)
+	}
+}
@@ -0,0 +1,693 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package goavro
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// The following Go types are passed to and from the Codec unchanged, because
+// the Codec accepts and returns them as native values for logical types.
+var (
+	bigRatType   = reflect.TypeOf((*big.Rat)(nil))
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+)
+
+// BinaryFromStruct appends the binary encoded byte slice representation of the
+// provided Go value to buf, in the same way as BinaryFromNative, after
+// translating the Go value into its native form.
+//
+// Exported struct fields are mapped to record fields by name, or using the name
+// in the field's `avro` tag. Fields tagged with `avro:"-"` are ignored, and
+// record fields without a corresponding struct field are encoded using their
+// default values. Pointers are used for nullable unions, slices for arrays,
+// maps with string keys for maps, byte arrays for fixed types, time.Time for
+// dates and timestamps, time.Duration for times of day, and *big.Rat for
+// decimals.
+//
+//	type User struct {
+//	    ID    int64   `avro:"id"`
+//	    Email *string `avro:"email"`
+//	}
+//
+//	codec, err := goavro.NewCodec(`{"type":"record","name":"User","fields":[{"name":"id","type":"long"},{"name":"email","type":["null","string"]}]}`)
+//	if err != nil {
+//	    fmt.Println(err)
+//	}
+//	buf, err := codec.BinaryFromStruct(nil, User{ID: 42})
+//	if err != nil {
+//	    fmt.Println(err)
+//	}
+//	fmt.Printf("%#v\n", buf)
+//	// Output: []byte{0x54, 0x0}
+//
+// Translating a Go type requires inspecting it using reflection, but this is
+// performed only the first time a Codec translates a particular Go type.
+func (c *Codec) BinaryFromStruct(buf []byte, v interface{}) ([]byte, error) {
+	native, err := c.nativeFromStruct(v)
+	if err != nil {
+		return buf, fmt.Errorf("cannot encode binary from %T: %s", v, err)
+	}
+	return c.BinaryFromNative(buf, native)
+}
+
+// StructFromBinary decodes binary encoded data from buf into the Go value
+// pointed to by v, and returns the remaining bytes of buf. The Go value is
+// translated from native form using the same rules as BinaryFromStruct.
+func (c *Codec) StructFromBinary(buf []byte, v interface{}) ([]byte, error) {
+	rv, sc, err := c.structConverterForPointer(v)
+	if err != nil {
+		return buf, fmt.Errorf("cannot decode binary into %T: %s", v, err)
+	}
+	native, newBuf, err := c.NativeFromBinary(buf)
+	if err != nil {
+		return buf, err
+	}
+	if err = sc.fromNative(native, rv.Elem()); err != nil {
+		return buf, fmt.Errorf("cannot decode binary into %T: %s", v, err)
+	}
+	return newBuf, nil
+}
+
+// TextualFromStruct appends the textual encoded byte slice representation of
+// the provided Go value to buf, in the same way as TextualFromNative, after
+// translating the Go value into its native form using the same rules as
+// BinaryFromStruct.
+func (c *Codec) TextualFromStruct(buf []byte, v interface{}) ([]byte, error) {
+	native, err := c.nativeFromStruct(v)
+	if err != nil {
+		return buf, fmt.Errorf("cannot encode textual from %T: %s", v, err)
+	}
+	return c.TextualFromNative(buf, native)
+}
+
+// StructFromTextual decodes textual encoded data from buf into the Go value
+// pointed to by v, and returns the remaining bytes of buf. The Go value is
+// translated from native form using the same rules as BinaryFromStruct.
+func (c *Codec) StructFromTextual(buf []byte, v interface{}) ([]byte, error) {
+	rv, sc, err := c.structConverterForPointer(v)
+	if err != nil {
+		return buf, fmt.Errorf("cannot decode textual into %T: %s", v, err)
+	}
+	native, newBuf, err := c.NativeFromTextual(buf)
+	if err != nil {
+		return buf, err
+	}
+	if err = sc.fromNative(native, rv.Elem()); err != nil {
+		return buf, fmt.Errorf("cannot decode textual into %T: %s", v, err)
+	}
+	return newBuf, nil
+}
+
+func (c *Codec) nativeFromStruct(v interface{}) (interface{}, error) {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() {
+		return nil, fmt.Errorf("ought to be non-nil value")
+	}
+	sc, err := c.structConverter(rv.Type())
+	if err != nil {
+		return nil, err
+	}
+	return sc.toNative(rv)
+}
+
+func (c *Codec) structConverterForPointer(v interface{}) (reflect.Value, *structConverter, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return rv, nil, fmt.Errorf("ought to be non-nil pointer")
+	}
+	sc, err := c.structConverter(rv.Type().Elem())
+	return rv, sc, err
+}
+
+// structConverter returns the structConverter that translates values of the Go
+// type to and from the native values of this Codec, building and caching it the
+// first time it is needed.
+func (c *Codec) structConverter(t reflect.Type) (*structConverter, error) {
+	if sc, ok := c.structConverters.Load(t); ok {
+		return sc.(*structConverter), nil
+	}
+	b := &structConverterBuilder{converters: make(map[structConverterKey]*structConverter)}
+	sc, err := b.build(c, t)
+	if err != nil {
+		return nil, err
+	}
+	actual, _ := c.structConverters.LoadOrStore(t, sc)
+	return actual.(*structConverter), nil
+}
+
+// structConverter translates values of one Go type to and from the native
+// values of one Codec.
+type structConverter struct {
+	toNative   func(reflect.Value) (interface{}, error)
+	fromNative func(interface{}, reflect.Value) error // v is settable
+}
+
+type structConverterKey struct {
+	codec *Codec
+	typ   reflect.Type
+}
+
+type structConverterBuilder struct {
+	// NOTE: Building converters for recursive types would never terminate
+	// without remembering the converters already being built.
+	converters map[structConverterKey]*structConverter
+}
+
+func (b *structConverterBuilder) build(c *Codec, t reflect.Type) (*structConverter, error) {
+	if sc, ok := b.converters[structConverterKey{c, t}]; ok {
+		return sc, nil
+	}
+	if t.Kind() == reflect.Interface {
+		return passThroughConverter(t), nil
+	}
+	if c.kind == "union" {
+		return b.buildUnion(c, t)
+	}
+	if t == bigRatType || t == durationType || t == timeType {
+		return passThroughConverter(t), nil
+	}
+	if t.Kind() == reflect.Ptr {
+		return b.buildPointer(c, t)
+	}
+
+	switch c.kind {
+	case "record":
+		return b.buildRecord(c, t)
+	case "array":
+		return b.buildArray(c, t)
+	case "map":
+		return b.buildMap(c, t)
+	case "fixed":
+		return buildFixedConverter(c, t)
+	case "enum", "string":
+		if t.Kind() == reflect.String || isByteSlice(t) {
+			return stringConverter(t), nil
+		}
+	case "bytes":
+		if t.Kind() == reflect.String || isByteSlice(t) {
+			return bytesConverter(t), nil
+		}
+	case "boolean":
+		if t.Kind() == reflect.Bool {
+			return booleanConverter, nil
+		}
+	case "int", "long":
+		switch t.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return intConverter, nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return uintConverter, nil
+		}
+	case "float", "double":
+		if t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64 {
+			return floatConverter, nil
+		}
+	}
+	return nil, fmt.Errorf("cannot use Go type %s for Avro %s", t, c.typeName)
+}
+
+func (b *structConverterBuilder) buildRecord(c *Codec, t reflect.Type) (*structConverter, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("cannot use Go type %s for Avro record %q: expected struct", t, c.typeName)
+	}
+
+	// NOTE: Register this converter before building the field converters, so
+	// recursive references to this record use it.
+	key := structConverterKey{c, t}
+	sc := new(structConverter)
+	b.converters[key] = sc
+
+	// NOTE: When this record cannot be converted, a converter built while
+	// converting its fields may still refer to it, so leave behind functions
+	// that return the error.
+	fail := func(err error) (*structConverter, error) {
+		delete(b.converters, key)
+		sc.toNative = func(_ reflect.Value) (interface{}, error) { return nil, err }
+		sc.fromNative = func(_ interface{}, _ reflect.Value) error { return err }
+		return nil, err
+	}
+
+	type fieldConverter struct {
+		index int
+		name  string
+		sc    *structConverter
+	}
+	var fieldConverters []fieldConverter
+
+	for _, field := range c.fields {
+		index, ok := structFieldIndex(t, field.name)
+		if !ok {
+			continue // encoded using its default value, and ignored when decoded
+		}
+		fieldSC, err := b.build(field.codec, t.Field(index).Type)
+		if err != nil {
+			return fail(fmt.Errorf("record %q field %q: %s", c.typeName, field.name, err))
+		}
+		fieldConverters = append(fieldConverters, fieldConverter{index: index, name: field.name, sc: fieldSC})
+	}
+
+	sc.toNative = func(v reflect.Value) (interface{}, error) {
+		recordMap := make(map[string]interface{}, len(fieldConverters))
+		for _, fc := range fieldConverters {
+			value, err := fc.sc.toNative(v.Field(fc.index))
+			if err != nil {
+				return nil, fmt.Errorf("record %q field %q: %s", c.typeName, fc.name, err)
+			}
+			recordMap[fc.name] = value
+		}
+		return recordMap, nil
+	}
+	sc.fromNative = func(native interface{}, v reflect.Value) error {
+		recordMap, ok := native.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("record %q: expected map[string]interface{}; received: %T", c.typeName, native)
+		}
+		for _, fc := range fieldConverters {
+			value, ok := recordMap[fc.name]
+			if !ok {
+				continue
+			}
+			if err := fc.sc.fromNative(value, v.Field(fc.index)); err != nil {
+				return fmt.Errorf("record %q field %q: %s", c.typeName, fc.name, err)
+			}
+		}
+		return nil
+	}
+	return sc, nil
+}
+
+// structFieldIndex returns the index of the exported field of the struct type
+// that corresponds to the named record field. A field whose `avro` tag names
+// the record field is preferred, followed by an untagged field with the same
+// name, and finally an untagged field whose name matches ignoring case.
+func structFieldIndex(t reflect.Type, fieldName string) (int, bool) {
+	fold := -1
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue // unexported
+		}
+		tagName, _ := parseStructTag(field.Tag.Get("avro"))
+		switch {
+		case tagName == "-":
+			continue
+		case tagName != "":
+			if tagName == fieldName {
+				return i, true
+			}
+		case field.Name == fieldName:
+			return i, true
+		case fold == -1 && strings.EqualFold(field.Name, fieldName):
+			fold = i
+		}
+	}
+	return fold, fold != -1
+}
+
+// parseStructTag returns the name from an `avro` struct tag, followed by the
+// remaining comma separated options.
+func parseStructTag(tag string) (string, string) {
+	if index := strings.IndexByte(tag, ','); index > -1 {
+		return tag[:index], tag[index+1:]
+	}
+	return tag, ""
+}
+
+func (b *structConverterBuilder) buildUnion(c *Codec, t reflect.Type) (*structConverter, error) {
+	elemType := t
+	nullable := t.Kind() == reflect.Ptr && t != bigRatType
+	if nullable {
+		elemType = t.Elem()
+	}
+
+	type memberConverter struct {
+		name string
+		sc   *structConverter
+	}
+	var memberConverters []memberConverter
+	var hasNull bool
+
+	for i, member := range c.members.codecFromIndex {
+		if member.kind == "null" {
+			hasNull = true
+			continue
+		}
+		memberSC, err := b.build(member, elemType)
+		if err != nil {
+			continue // Go type cannot hold this member
+		}
+		memberConverters = append(memberConverters, memberConverter{name: c.members.allowedTypes[i], sc: memberSC})
+	}
+	if len(memberConverters) == 0 {
+		return nil, fmt.Errorf("cannot use Go type %s for any Avro union member: %v", t, c.members.allowedTypes)
+	}
+
+	return &structConverter{
+		toNative: func(v reflect.Value) (interface{}, error) {
+			if nullable && !v.IsNil() {
+				v = v.Elem()
+			}
+			// NOTE: A nil *big.Rat is also null, because it is never
+			// unwrapped like other pointers.
+			if v.Kind() == reflect.Ptr && v.IsNil() {
+				if !hasNull {
+					return nil, fmt.Errorf("cannot encode nil %s: union has no null member: %v", t, c.members.allowedTypes)
+				}
+				return nil, nil
+			}
+			// NOTE: Values are always encoded using the first member the Go
+			// type can hold.
+			mc := memberConverters[0]
+			value, err := mc.sc.toNative(v)
+			if err != nil {
+				return nil, err
+			}
+			return Union(mc.name, value), nil
+		},
+		fromNative: func(native interface{}, v reflect.Value) error {
+			if native == nil {
+				v.Set(reflect.Zero(t))
+				return nil
+			}
+			unionMap, ok := native.(map[string]interface{})
+			if !ok || len(unionMap) != 1 {
+				return fmt.Errorf("union: expected map[string]interface{} with single key; received: %T", native)
+			}
+			for name, value := range unionMap {
+				for _, mc := range memberConverters {
+					if mc.name != name {
+						continue
+					}
+					if !nullable {
+						return mc.sc.fromNative(value, v)
+					}
+					ptr := reflect.New(elemType)
+					if err := mc.sc.fromNative(value, ptr.Elem()); err != nil {
+						return err
+					}
+					v.Set(ptr)
+					return nil
+				}
+				return fmt.Errorf("cannot decode union member %q into Go type %s", name, t)
+			}
+			return nil // should not get here because map has one key
+		},
+	}, nil
+}
+
+func (b *structConverterBuilder) buildPointer(c *Codec, t reflect.Type) (*structConverter, error) {
+	elemSC, err := b.build(c, t.Elem())
+	if err != nil {
+		return nil, err
+	}
+	return &structConverter{
+		toNative: func(v reflect.Value) (interface{}, error) {
+			if v.IsNil() {
+				return nil, fmt.Errorf("cannot encode nil %s as Avro %s", t, c.typeName)
+			}
+			return elemSC.toNative(v.Elem())
+		},
+		fromNative: func(native interface{}, v reflect.Value) error {
+			ptr := reflect.New(t.Elem())
+			if err := elemSC.fromNative(native, ptr.Elem()); err != nil {
+				return err
+			}
+			v.Set(ptr)
+			return nil
+		},
+	}, nil
+}
+
+func (b *structConverterBuilder) buildArray(c *Codec, t reflect.Type) (*structConverter, error) {
+	if t.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("cannot use Go type %s for Avro array: expected slice", t)
+	}
+	itemSC, err := b.build(c.itemCodec, t.Elem())
+	if err != nil {
+		return nil, fmt.Errorf("array items: %s", err)
+	}
+	return &structConverter{
+		toNative: func(v reflect.Value) (interface{}, error) {
+			items := make([]interface{}, v.Len())
+			for i := range items {
+				item, err := itemSC.toNative(v.Index(i))
+				if err != nil {
+					return nil, fmt.Errorf("array item %d: %s", i+1, err)
+				}
+				items[i] = item
+			}
+			return items, nil
+		},
+		fromNative: func(native interface{}, v reflect.Value) error {
+			items, ok := native.([]interface{})
+			if !ok {
+				return fmt.Errorf("array: expected []interface{}; received: %T", native)
+			}
+			slice := reflect.MakeSlice(t, len(items), len(items))
+			for i, item := range items {
+				if err := itemSC.fromNative(item, slice.Index(i)); err != nil {
+					return fmt.Errorf("array item %d: %s", i+1, err)
+				}
+			}
+			v.Set(slice)
+			return nil
+		},
+	}, nil
+}
+
+func (b *structConverterBuilder) buildMap(c *Codec, t reflect.Type) (*structConverter, error) {
+	if t.Kind() != reflect.Map || t.Key().Kind() != reflect.String {
+		return nil, fmt.Errorf("cannot use Go type %s for Avro map: expected map with string keys", t)
+	}
+	valueSC, err := b.build(c.itemCodec, t.Elem())
+	if err != nil {
+		return nil, fmt.Errorf("map values: %s", err)
+	}
+	return &structConverter{
+		toNative: func(v reflect.Value) (interface{}, error) {
+			values := make(map[string]interface{}, v.Len())
+			iter := v.MapRange()
+			for iter.Next() {
+				key := iter.Key().String()
+				value, err := valueSC.toNative(iter.Value())
+				if err != nil {
+					return nil, fmt.Errorf("map value for key %q: %s", key, err)
+				}
+				values[key] = value
+			}
+			return values, nil
+		},
+		fromNative: func(native interface{}, v reflect.Value) error {
+			values, ok := native.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("map: expected map[string]interface{}; received: %T", native)
+			}
+			m := reflect.MakeMapWithSize(t, len(values))
+			for key, value := range values {
+				elem := reflect.New(t.Elem()).Elem()
+				if err := valueSC.fromNative(value, elem); err != nil {
+					return fmt.Errorf("map value for key %q: %s", key, err)
+				}
+				m.SetMapIndex(reflect.ValueOf(key).Convert(t.Key()), elem)
+			}
+			v.Set(m)
+			return nil
+		},
+	}, nil
+}
+
+func buildFixedConverter(c *Codec, t reflect.Type) (*structConverter, error) {
+	if isByteSlice(t) {
+		return bytesConverter(t), nil
+	}
+	if t.Kind() != reflect.Array || t.Elem().Kind() != reflect.Uint8 {
+		return nil, fmt.Errorf("cannot use Go type %s for Avro fixed %q: expected byte array", t, c.typeName)
+	}
+	if uint(t.Len()) != c.size {
+		return nil, fmt.Errorf("cannot use Go type %s for Avro fixed %q: size ought to be %d", t, c.typeName, c.size)
+	}
+	return &structConverter{
+		toNative: func(v reflect.Value) (interface{}, error) {
+			buf := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(buf), v)
+			return buf, nil
+		},
+		fromNative: func(native interface{}, v reflect.Value) error {
+			buf, ok := native.([]byte)
+			if !ok || len(buf) != v.Len() {
+				return fmt.Errorf("fixed %q: expected %d bytes; received: %T", c.typeName, v.Len(), native)
+			}
+			reflect.Copy(v, reflect.ValueOf(buf))
+			return nil
+		},
+	}, nil
+}
+
+func isByteSlice(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8
+}
+
+// passThroughConverter returns a converter that passes values to and from the
+// Codec unchanged.
+func passThroughConverter(t reflect.Type) *structConverter {
+	return &structConverter{
+		toNative: func(v reflect.Value) (interface{}, error) {
+			return v.Interface(), nil
+		},
+		fromNative: func(native interface{}, v reflect.Value) error {
+			if native == nil {
+				v.Set(reflect.Zero(t))
+				return nil
+			}
+			nv := reflect.ValueOf(native)
+			if !nv.Type().AssignableTo(t) {
+				return fmt.Errorf("cannot decode %T into Go type %s", native, t)
+			}
+			v.Set(nv)
+			return nil
+		},
+	}
+}
+
+func stringConverter(t reflect.Type) *structConverter {
+	return &structConverter{
+		toNative: func(v reflect.Value) (interface{}, error) {
+			if v.Kind() == reflect.String {
+				return v.String(), nil
+			}
+			return string(v.Bytes()), nil
+		},
+		fromNative: func(native interface{}, v reflect.Value) error {
+			s, ok := native.(string)
+			if !ok {
+				return fmt.Errorf("cannot decode %T into Go type %s", native, t)
+			}
+			if v.Kind() == reflect.String {
+				v.SetString(s)
+			} else {
+				v.SetBytes([]byte(s))
+			}
+			return nil
+		},
+	}
+}
+
+func bytesConverter(t reflect.Type) *structConverter {
+	return &structConverter{
+		toNative: func(v reflect.Value) (interface{}, error) {
+			if v.Kind() == reflect.String {
+				return []byte(v.String()), nil
+			}
+			return v.Bytes(), nil
+		},
+		fromNative: func(native interface{}, v reflect.Value) error {
+			buf, ok := native.([]byte)
+			if !ok {
+				return fmt.Errorf("cannot decode %T into Go type %s", native, t)
+			}
+			if v.Kind() == reflect.String {
+				v.SetString(string(buf))
+			} else {
+				// NOTE: Decoded bytes refer to the buffer being decoded, which
+				// the caller may reuse.
+				v.SetBytes(append([]byte(nil), buf...))
+			}
+			return nil
+		},
+	}
+}
+
+var booleanConverter = &structConverter{
+	toNative: func(v reflect.Value) (interface{}, error) {
+		return v.Bool(), nil
+	},
+	fromNative: func(native interface{}, v reflect.Value) error {
+		b, ok := native.(bool)
+		if !ok {
+			return fmt.Errorf("cannot decode %T into Go type %s", native, v.Type())
+		}
+		v.SetBool(b)
+		return nil
+	},
+}
+
+var intConverter = &structConverter{
+	toNative: func(v reflect.Value) (interface{}, error) {
+		return v.Int(), nil
+	},
+	fromNative: func(native interface{}, v reflect.Value) error {
+		var i int64
+		switch n := native.(type) {
+		case int32:
+			i = int64(n)
+		case int64:
+			i = n
+		default:
+			return fmt.Errorf("cannot decode %T into Go type %s", native, v.Type())
+		}
+		if v.OverflowInt(i) {
+			return fmt.Errorf("cannot decode %d into Go type %s: value overflows", i, v.Type())
+		}
+		v.SetInt(i)
+		return nil
+	},
+}
+
+var uintConverter = &structConverter{
+	toNative: func(v reflect.Value) (interface{}, error) {
+		u := v.Uint()
+		if i := int64(u); i >= 0 {
+			return i, nil
+		}
+		return nil, fmt.Errorf("cannot encode Go %s: value overflows long: %d", v.Type(), u)
+	},
+	fromNative: func(native interface{}, v reflect.Value) error {
+		var i int64
+		switch n := native.(type) {
+		case int32:
+			i = int64(n)
+		case int64:
+			i = n
+		default:
+			return fmt.Errorf("cannot decode %T into Go type %s", native, v.Type())
+		}
+		if i < 0 || v.OverflowUint(uint64(i)) {
+			return fmt.Errorf("cannot decode %d into Go type %s: value overflows", i, v.Type())
+		}
+		v.SetUint(uint64(i))
+		return nil
+	},
+}
+
+var floatConverter = &structConverter{
+	toNative: func(v reflect.Value) (interface{}, error) {
+		if v.Kind() == reflect.Float32 {
+			return float32(v.Float()), nil
+		}
+		return v.Float(), nil
+	},
+	fromNative: func(native interface{}, v reflect.Value) error {
+		switch n := native.(type) {
+		case float32:
+			v.SetFloat(float64(n))
+		case float64:
+			v.SetFloat(n)
+		default:
+			return fmt.Errorf("cannot decode %T into Go type %s", native, v.Type())
+		}
+		return nil
+	},
+}
@@ -0,0 +1,325 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package goavro
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type structTestUser struct {
+	ID       int64             `avro:"id"`
+	Name     string            `avro:"name"`
+	Email    *string           `avro:"email"`
+	Tags     []string          `avro:"tags"`
+	Scores   map[string]int32  `avro:"scores"`
+	Hash     [4]byte           `avro:"hash"`
+	Created  time.Time         `avro:"created"`
+	Balance  *big.Rat          `avro:"balance"`
+	Color    string            `avro:"color"`
+	Friend   *structTestFriend `avro:"friend"`
+	Ignored  string            `avro:"-"`
+	Untagged float64
+}
+
+type structTestFriend struct {
+	Name string
+}
+
+const structTestUserSchema = `{
+  "type": "record",
+  "name": "User",
+  "fields": [
+    {"name": "id", "type": "long"},
+    {"name": "name", "type": "string"},
+    {"name": "email", "type": ["null", "string"], "default": null},
+    {"name": "tags", "type": {"type": "array", "items": "string"}},
+    {"name": "scores", "type": {"type": "map", "values": "int"}},
+    {"name": "hash", "type": {"type": "fixed", "name": "Hash", "size": 4}},
+    {"name": "created", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+    {"name": "balance", "type": {"type": "bytes", "logicalType": "decimal", "precision": 10, "scale": 2}},
+    {"name": "color", "type": {"type": "enum", "name": "Color", "symbols": ["RED", "GREEN"]}},
+    {"name": "friend", "type": ["null", {"type": "record", "name": "Friend", "fields": [{"name": "name", "type": "string"}]}]},
+    {"name": "untagged", "type": "double"},
+    {"name": "extra", "type": "int", "default": 7}
+  ]
+}`
+
+func ExampleCodec_BinaryFromStruct() {
+	type User struct {
+		ID    int64   `avro:"id"`
+		Email *string `avro:"email"`
+	}
+
+	codec, err := NewCodec(`{"type":"record","name":"User","fields":[{"name":"id","type":"long"},{"name":"email","type":["null","string"]}]}`)
+	if err != nil {
+		fmt.Println(err)
+	}
+	buf, err := codec.BinaryFromStruct(nil, User{ID: 42})
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Printf("%#v\n", buf)
+
+	var user User
+	if _, err = codec.StructFromBinary(buf, &user); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(user.ID, user.Email)
+	// Output:
+	// []byte{0x54, 0x0}
+	// 42 <nil>
+}
+
+func TestStructRoundTrip(t *testing.T) {
+	codec, err := NewCodec(structTestUserSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	email := "bob@example.com"
+	user := structTestUser{
+		ID:       42,
+		Name:     "Bob",
+		Email:    &email,
+		Tags:     []string{"a", "b"},
+		Scores:   map[string]int32{"math": 90},
+		Hash:     [4]byte{1, 2, 3, 4},
+		Created:  time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		Balance:  big.NewRat(1234, 100),
+		Color:    "GREEN",
+		Friend:   &structTestFriend{Name: "Alice"},
+		Ignored:  "ignored",
+		Untagged: 1.5,
+	}
+
+	t.Run("binary", func(t *testing.T) {
+		buf, err := codec.BinaryFromStruct(nil, &user)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// struct encoding ought to produce the same bytes as native encoding
+		expected, err := codec.BinaryFromNative(nil, map[string]interface{}{
+			"id":       int64(42),
+			"name":     "Bob",
+			"email":    Union("string", email),
+			"tags":     []interface{}{"a", "b"},
+			"scores":   map[string]interface{}{"math": int32(90)},
+			"hash":     []byte{1, 2, 3, 4},
+			"created":  user.Created,
+			"balance":  user.Balance,
+			"color":    "GREEN",
+			"friend":   Union("Friend", map[string]interface{}{"name": "Alice"}),
+			"untagged": 1.5,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buf, expected) {
+			t.Errorf("GOT: %#v; WANT: %#v", buf, expected)
+		}
+
+		var decoded structTestUser
+		remaining, err := codec.StructFromBinary(append(buf, 0xff), &decoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(remaining, []byte{0xff}) {
+			t.Errorf("GOT: %#v; WANT: %#v", remaining, []byte{0xff})
+		}
+		want := user
+		want.Ignored = ""
+		if !reflect.DeepEqual(decoded, want) {
+			t.Errorf("GOT: %#v; WANT: %#v", decoded, want)
+		}
+	})
+
+	t.Run("textual", func(t *testing.T) {
+		buf, err := codec.TextualFromStruct(nil, user)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded structTestUser
+		if _, err = codec.StructFromTextual(buf, &decoded); err != nil {
+			t.Fatal(err)
+		}
+		want := user
+		want.Ignored = ""
+		if !reflect.DeepEqual(decoded, want) {
+			t.Errorf("GOT: %#v; WANT: %#v", decoded, want)
+		}
+	})
+}
+
+func TestStructNilPointers(t *testing.T) {
+	codec, err := NewCodec(structTestUserSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := structTestUser{Created: time.Unix(0, 0).UTC(), Balance: big.NewRat(0, 1), Color: "RED"}
+	buf, err := codec.BinaryFromStruct(nil, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := structTestUser{Email: new(string), Friend: new(structTestFriend)}
+	if _, err = codec.StructFromBinary(buf, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Email != nil || decoded.Friend != nil {
+		t.Errorf("GOT: %v, %v; WANT: nil pointers", decoded.Email, decoded.Friend)
+	}
+}
+
+func TestStructNilDecimal(t *testing.T) {
+	type Payment struct {
+		Amount *big.Rat `avro:"amount"`
+	}
+	codec, err := NewCodec(`{"type":"record","name":"Payment","fields":[{"name":"amount","type":["null",{"type":"bytes","logicalType":"decimal","precision":10,"scale":2}]}]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf, err := codec.BinaryFromStruct(nil, Payment{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual, expected := buf, []byte{0}; !bytes.Equal(actual, expected) {
+		t.Errorf("GOT: %v; WANT: %v", actual, expected)
+	}
+	decoded := Payment{Amount: big.NewRat(1, 2)}
+	if _, err = codec.StructFromBinary(buf, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Amount != nil {
+		t.Errorf("GOT: %v; WANT: nil", decoded.Amount)
+	}
+
+	buf, err = codec.BinaryFromStruct(nil, Payment{Amount: big.NewRat(5, 2)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = codec.StructFromBinary(buf, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Amount == nil || decoded.Amount.Cmp(big.NewRat(5, 2)) != 0 {
+		t.Errorf("GOT: %v; WANT: %v", decoded.Amount, big.NewRat(5, 2))
+	}
+
+	// Without a null member, a nil *big.Rat cannot be encoded.
+	codec, err = NewCodec(`{"type":"record","name":"Payment","fields":[{"name":"amount","type":{"type":"bytes","logicalType":"decimal","precision":10,"scale":2}}]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = codec.BinaryFromStruct(nil, Payment{})
+	ensureError(t, err, "received nil *big.Rat")
+}
+
+type structTestLongList struct {
+	Value int64
+	Next  *structTestLongList
+}
+
+func TestStructRecursive(t *testing.T) {
+	codec, err := NewCodec(`{"type":"record","name":"LongList","fields":[{"name":"value","type":"long"},{"name":"next","type":["null","LongList"]}]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := structTestLongList{Value: 1, Next: &structTestLongList{Value: 2, Next: &structTestLongList{Value: 3}}}
+	buf, err := codec.BinaryFromStruct(nil, list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []byte{0x02, 0x02, 0x04, 0x02, 0x06, 0x00}; !bytes.Equal(buf, expected) {
+		t.Errorf("GOT: %#v; WANT: %#v", buf, expected)
+	}
+	var decoded structTestLongList
+	if _, err = codec.StructFromBinary(buf, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, list) {
+		t.Errorf("GOT: %#v; WANT: %#v", decoded, list)
+	}
+}
+
+func TestStructConverterCached(t *testing.T) {
+	codec, err := NewCodec(`{"type":"record","name":"Friend","fields":[{"name":"name","type":"string"}]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t1, err := codec.structConverter(reflect.TypeOf(structTestFriend{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t2, err := codec.structConverter(reflect.TypeOf(structTestFriend{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if t1 != t2 {
+		t.Errorf("GOT: %p; WANT: %p", t2, t1)
+	}
+}
+
+func TestStructPrimitives(t *testing.T) {
+	codec, err := NewCodec(`"int"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf, err := codec.BinaryFromStruct(nil, uint8(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var i8 int8
+	if _, err = codec.StructFromBinary(buf, &i8); err != nil {
+		t.Fatal(err)
+	}
+	if i8 != 3 {
+		t.Errorf("GOT: %v; WANT: %v", i8, 3)
+	}
+	_, err = codec.StructFromBinary([]byte{0x80, 0x04}, &i8)
+	ensureError(t, err, "value overflows")
+
+	var i int
+	_, err = codec.StructFromBinary(buf, i)
+	ensureError(t, err, "ought to be non-nil pointer")
+}
+
+func TestStructErrors(t *testing.T) {
+	codec, err := NewCodec(structTestUserSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = codec.BinaryFromStruct(nil, struct {
+		ID string `avro:"id"`
+	}{})
+	ensureError(t, err, `record "User" field "id": cannot use Go type string for Avro long`)
+
+	_, err = codec.BinaryFromStruct(nil, struct {
+		Hash [3]byte `avro:"hash"`
+	}{})
+	ensureError(t, err, `size ought to be 4`)
+
+	_, err = codec.BinaryFromStruct(nil, struct {
+		Friend *string `avro:"friend"`
+	}{})
+	ensureError(t, err, `cannot use Go type *string for any Avro union member`)
+
+	_, err = codec.BinaryFromStruct(nil, struct {
+		Name *string `avro:"name"`
+	}{})
+	ensureError(t, err, `cannot encode nil *string as Avro string`)
+
+	// missing record fields without defaults ought to fail when encoding
+	_, err = codec.BinaryFromStruct(nil, struct{}{})
+	ensureError(t, err, `schema does not specify default value and no value provided`)
+}
@@ -0,0 +1,429 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package goavro
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// SchemaOption customizes the schema derived from a Go type by SchemaFromType.
+type SchemaOption func(*typeSchemaBuilder)
+
+// SchemaNamespace returns a SchemaOption that places every named type of the
+// derived schema in the provided namespace, rather than in a namespace named
+// after the Go package that declares the type.
+func SchemaNamespace(namespace string) SchemaOption {
+	return func(b *typeSchemaBuilder) {
+		b.namespace = &namespace
+	}
+}
+
+// SchemaDecimal returns a SchemaOption that sets the precision and scale of
+// the decimal logical type derived from *big.Rat, for struct fields whose tag
+// does not specify them. Without this option, the precision is 38 and the
+// scale is 9.
+func SchemaDecimal(precision, scale int) SchemaOption {
+	return func(b *typeSchemaBuilder) {
+		b.precision, b.scale = precision, scale
+	}
+}
+
+// SchemaFromType returns the Avro schema for values of the provided Go type,
+// using the same rules as BinaryFromStruct to map Go types to Avro types, so a
+// Codec created from the schema translates values of the Go type.
+//
+// Structs become records named after the Go type, in a namespace named after
+// the Go package that declares it. Exported struct fields become record fields
+// named by their `avro` tag, or by the Go field name when the tag has no name,
+// and fields tagged with `avro:"-"` are skipped. Pointers become unions of
+// null and the pointed to type, with a default value of null for record
+// fields. Byte arrays become fixed types, time.Time becomes a long with the
+// timestamp-millis logical type, time.Duration becomes a long with the
+// time-micros logical type, and *big.Rat becomes bytes with the decimal
+// logical type. When a named Go type occurs more than once, including when a
+// type refers to itself, later occurrences refer to the first by name.
+//
+// The `avro` tag of a struct field may follow its name with comma separated
+// options. The "logicalType", "precision", and "scale" options override the
+// logical type derived for time.Time, time.Duration, and *big.Rat values. The
+// "nullable" option makes a *big.Rat, which is otherwise never nil, a union of
+// null and decimal like other pointers. The "doc" option, which must be last
+// because its value may contain commas, sets the documentation of the record
+// field.
+//
+//	type User struct {
+//	    ID      int64      `avro:"id,doc=Unique identifier, never reused."`
+//	    Email   *string    `avro:"email"`
+//	    Born    time.Time  `avro:"born,logicalType=date"`
+//	    Manager *User      `avro:"manager"`
+//	}
+//
+//	schema, err := goavro.SchemaFromType(reflect.TypeOf(User{}), goavro.SchemaNamespace("com.example"))
+func SchemaFromType(t reflect.Type, options ...SchemaOption) (string, error) {
+	schema, err := schemaFromType(t, options)
+	if err != nil {
+		return "", err
+	}
+	if _, err = NewCodec(schema); err != nil {
+		return "", fmt.Errorf("cannot derive Avro schema for Go type %s: %s", t, err)
+	}
+	return schema, nil
+}
+
+// NewCodecForType returns a Codec for the Avro schema derived from the provided
+// Go type by SchemaFromType.
+func NewCodecForType(t reflect.Type, options ...SchemaOption) (*Codec, error) {
+	schema, err := schemaFromType(t, options)
+	if err != nil {
+		return nil, err
+	}
+	codec, err := NewCodec(schema)
+	if err != nil {
+		return nil, fmt.Errorf("cannot derive Avro schema for Go type %s: %s", t, err)
+	}
+	return codec, nil
+}
+
+func schemaFromType(t reflect.Type, options []SchemaOption) (string, error) {
+	if t == nil {
+		return "", fmt.Errorf("cannot derive Avro schema for nil Go type")
+	}
+	b := &typeSchemaBuilder{
+		precision: 38,
+		scale:     9,
+		names:     make(map[reflect.Type]string),
+		types:     make(map[string]reflect.Type),
+	}
+	for _, option := range options {
+		option(b)
+	}
+	if err := b.build(t, "", fieldOptions{}); err != nil {
+		return "", fmt.Errorf("cannot derive Avro schema for Go type %s: %s", t, err)
+	}
+	return b.buf.String(), nil
+}
+
+type typeSchemaBuilder struct {
+	buf              bytes.Buffer
+	namespace        *string // when nil, namespaces are named after Go packages
+	precision, scale int
+
+	// NOTE: Named types are defined the first time they occur, and referred to
+	// by their full names afterwards, which also terminates recursive types.
+	names map[reflect.Type]string
+	types map[string]reflect.Type
+}
+
+// fieldOptions holds the options of the `avro` tag of a struct field that
+// apply to the Avro type of the field.
+type fieldOptions struct {
+	logicalType       string
+	precision, scale  int
+	hasPrecisionScale bool
+	nullable          bool
+}
+
+// parseFieldOptions returns the documentation and the options of the Avro type
+// from the options of an `avro` struct tag.
+func parseFieldOptions(tagOptions string) (string, fieldOptions, error) {
+	var doc string
+	var options fieldOptions
+	var hasPrecision, hasScale bool
+	for tagOptions != "" {
+		option := tagOptions
+		if strings.HasPrefix(option, "doc=") {
+			doc, tagOptions = option[4:], "" // doc consumes the remaining options
+			break
+		}
+		if index := strings.IndexByte(option, ','); index > -1 {
+			option, tagOptions = option[:index], option[index+1:]
+		} else {
+			tagOptions = ""
+		}
+		if option == "nullable" {
+			options.nullable = true
+			continue
+		}
+		index := strings.IndexByte(option, '=')
+		if index == -1 {
+			return "", options, fmt.Errorf("tag option ought to be key=value: %q", option)
+		}
+		key, value := option[:index], option[index+1:]
+		var err error
+		switch key {
+		case "logicalType":
+			options.logicalType = value
+		case "precision":
+			options.precision, err = strconv.Atoi(value)
+			hasPrecision = true
+		case "scale":
+			options.scale, err = strconv.Atoi(value)
+			hasScale = true
+		default:
+			return "", options, fmt.Errorf("unknown tag option: %q", key)
+		}
+		if err != nil {
+			return "", options, fmt.Errorf("tag option %q ought to be an integer: %q", key, value)
+		}
+	}
+	if hasScale && !hasPrecision {
+		return "", options, fmt.Errorf("tag option %q ought to be accompanied by %q", "scale", "precision")
+	}
+	options.hasPrecisionScale = hasPrecision
+	return doc, options, nil
+}
+
+func (b *typeSchemaBuilder) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&b.buf, format, args...)
+}
+
+// quote writes s as a JSON string.
+func (b *typeSchemaBuilder) quote(s string) {
+	encoded, _ := json.Marshal(s) // marshaling a string cannot fail
+	b.buf.Write(encoded)
+}
+
+func (b *typeSchemaBuilder) build(t reflect.Type, enclosingNamespace string, options fieldOptions) error {
+	switch t {
+	case timeType:
+		return b.logical("long", "timestamp-millis", options, "date", "timestamp-millis", "timestamp-micros")
+	case durationType:
+		return b.logical("long", "time-micros", options, "time-millis", "time-micros")
+	case bigRatType:
+		if !options.nullable {
+			return b.decimal(options)
+		}
+		b.printf(`["null",`)
+		if err := b.decimal(options); err != nil {
+			return err
+		}
+		b.printf(`]`)
+		return nil
+	}
+	if options.nullable {
+		switch t.Kind() {
+		case reflect.Slice, reflect.Map:
+			if !isByteSlice(t) {
+				break // option applies to the element type
+			}
+			fallthrough
+		default:
+			return fmt.Errorf("cannot use tag option %q for Go type %s", "nullable", t)
+		}
+	}
+	if options.logicalType != "" || options.hasPrecisionScale {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Map:
+			if !isByteSlice(t) {
+				break // options apply to the element type
+			}
+			fallthrough
+		default:
+			return fmt.Errorf("cannot use logical type options for Go type %s", t)
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		b.quote("boolean")
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		b.quote("int")
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		b.quote("long")
+	case reflect.Float32:
+		b.quote("float")
+	case reflect.Float64:
+		b.quote("double")
+	case reflect.String:
+		b.quote("string")
+	case reflect.Ptr:
+		b.printf(`["null",`)
+		if err := b.build(t.Elem(), enclosingNamespace, options); err != nil {
+			return err
+		}
+		b.printf(`]`)
+	case reflect.Slice:
+		if isByteSlice(t) {
+			b.quote("bytes")
+			break
+		}
+		b.printf(`{"type":"array","items":`)
+		if err := b.build(t.Elem(), enclosingNamespace, options); err != nil {
+			return fmt.Errorf("array items: %s", err)
+		}
+		b.printf(`}`)
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return fmt.Errorf("cannot use Go type %s for Avro map: key ought to be string", t)
+		}
+		b.printf(`{"type":"map","values":`)
+		if err := b.build(t.Elem(), enclosingNamespace, options); err != nil {
+			return fmt.Errorf("map values: %s", err)
+		}
+		b.printf(`}`)
+	case reflect.Array:
+		if t.Elem().Kind() != reflect.Uint8 {
+			return fmt.Errorf("cannot use Go type %s for Avro fixed: elements ought to be bytes", t)
+		}
+		return b.named(t, enclosingNamespace, "fixed", func(string) error {
+			b.printf(`,"size":%d`, t.Len())
+			return nil
+		})
+	case reflect.Struct:
+		return b.named(t, enclosingNamespace, "record", func(namespace string) error {
+			return b.fields(t, namespace)
+		})
+	default:
+		return fmt.Errorf("cannot use Go type %s for any Avro type", t)
+	}
+	return nil
+}
+
+// logical writes a primitive type annotated with the logical type from the
+// field's options, which must be one of those allowed, or with the default
+// logical type.
+func (b *typeSchemaBuilder) logical(typeName, logicalType string, options fieldOptions, allowed ...string) error {
+	if options.hasPrecisionScale {
+		return fmt.Errorf("cannot use precision or scale options for Avro %s", logicalType)
+	}
+	if options.logicalType != "" {
+		logicalType = options.logicalType
+		var ok bool
+		for _, a := range allowed {
+			if a == logicalType {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return fmt.Errorf("logical type ought to be one of %q; received: %q", allowed, logicalType)
+		}
+	}
+	if logicalType == "date" || logicalType == "time-millis" {
+		typeName = "int"
+	}
+	b.printf(`{"type":%q,"logicalType":%q}`, typeName, logicalType)
+	return nil
+}
+
+func (b *typeSchemaBuilder) decimal(options fieldOptions) error {
+	if options.logicalType != "" && options.logicalType != "decimal" {
+		return fmt.Errorf("logical type ought to be %q; received: %q", "decimal", options.logicalType)
+	}
+	precision, scale := b.precision, b.scale
+	if options.hasPrecisionScale {
+		precision, scale = options.precision, options.scale
+	}
+	b.printf(`{"type":"bytes","logicalType":"decimal","precision":%d,"scale":%d}`, precision, scale)
+	return nil
+}
+
+// named writes the definition of the named type the first time it occurs, and
+// a reference to it afterwards. The body function writes the properties
+// specific to the kind of named type.
+func (b *typeSchemaBuilder) named(t reflect.Type, enclosingNamespace, kind string, body func(namespace string) error) error {
+	if fullName, ok := b.names[t]; ok {
+		b.quote(relativeName(fullName, enclosingNamespace))
+		return nil
+	}
+
+	name, namespace := t.Name(), enclosingNamespace
+	switch {
+	case name == "" && kind == "fixed":
+		name = "Fixed" + strconv.Itoa(t.Len())
+	case name == "":
+		return fmt.Errorf("cannot derive Avro %s name for unnamed Go type %s", kind, t)
+	case b.namespace != nil:
+		namespace = *b.namespace
+	default:
+		// NOTE: The string of a named type is qualified by its package name.
+		namespace = strings.TrimSuffix(t.String(), "."+name)
+	}
+	fullName := name
+	if namespace != "" {
+		fullName = namespace + "." + name
+	}
+	if other, ok := b.types[fullName]; ok {
+		return fmt.Errorf("Go types %s and %s ought to have different Avro names: %q", other, t, fullName)
+	}
+	b.names[t] = fullName
+	b.types[fullName] = t
+
+	b.printf(`{"type":%q,"name":`, kind)
+	b.quote(name)
+	if namespace != enclosingNamespace {
+		b.printf(`,"namespace":`)
+		b.quote(namespace)
+	}
+	if err := body(namespace); err != nil {
+		return fmt.Errorf("%s %q: %s", kind, fullName, err)
+	}
+	b.printf(`}`)
+	return nil
+}
+
+// relativeName returns the name by which the named type ought to be referred
+// to within the enclosing namespace.
+func relativeName(fullName, enclosingNamespace string) string {
+	if index := strings.LastIndexByte(fullName, '.'); index > -1 && fullName[:index] == enclosingNamespace {
+		return fullName[index+1:]
+	}
+	return fullName
+}
+
+// fields writes the fields of a record derived from the exported fields of a
+// struct.
+func (b *typeSchemaBuilder) fields(t reflect.Type, namespace string) error {
+	b.printf(`,"fields":[`)
+	var count int
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue // unexported
+		}
+		name, rest := parseStructTag(field.Tag.Get("avro"))
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		doc, options, err := parseFieldOptions(rest)
+		if err != nil {
+			return fmt.Errorf("field %q: %s", name, err)
+		}
+
+		if count > 0 {
+			b.printf(`,`)
+		}
+		count++
+		b.printf(`{"name":`)
+		b.quote(name)
+		if doc != "" {
+			b.printf(`,"doc":`)
+			b.quote(doc)
+		}
+		b.printf(`,"type":`)
+		if err = b.build(field.Type, namespace, options); err != nil {
+			return fmt.Errorf("field %q: %s", name, err)
+		}
+		if field.Type.Kind() == reflect.Ptr && (field.Type != bigRatType || options.nullable) {
+			b.printf(`,"default":null`)
+		}
+		b.printf(`}`)
+	}
+	b.printf(`]`)
+	return nil
+}
@@ -0,0 +1,232 @@
+// Copyright [2019] LinkedIn Corp. Licensed under the Apache License, Version
+// 2.0 (the "License"); you may not use this file except in compliance with the
+// License.  You may obtain a copy of the License at
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+// WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+
+package goavro
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func ExampleSchemaFromType() {
+	type User struct {
+		ID    int64   `avro:"id,doc=Unique identifier, never reused."`
+		Email *string `avro:"email"`
+	}
+
+	schema, err := SchemaFromType(reflect.TypeOf(User{}), SchemaNamespace("com.example"))
+	if err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(schema)
+	// Output: {"type":"record","name":"User","namespace":"com.example","fields":[{"name":"id","doc":"Unique identifier, never reused.","type":"long"},{"name":"email","type":["null","string"],"default":null}]}
+}
+
+type typeSchemaHash [4]byte
+
+type typeSchemaTree struct {
+	Value    int32             `avro:"value"`
+	Children []*typeSchemaTree `avro:"children"`
+}
+
+func TestSchemaFromType(t *testing.T) {
+	cases := []struct {
+		value   interface{}
+		options []SchemaOption
+		schema  string
+	}{
+		{true, nil, `"boolean"`},
+		{int8(0), nil, `"int"`},
+		{uint16(0), nil, `"int"`},
+		{0, nil, `"long"`},
+		{uint32(0), nil, `"long"`},
+		{float32(0), nil, `"float"`},
+		{0.0, nil, `"double"`},
+		{"", nil, `"string"`},
+		{[]byte(nil), nil, `"bytes"`},
+		{[]string(nil), nil, `{"type":"array","items":"string"}`},
+		{map[string]*int64(nil), nil, `{"type":"map","values":["null","long"]}`},
+		{[2]byte{}, nil, `{"type":"fixed","name":"Fixed2","size":2}`},
+		{typeSchemaHash{}, nil, `{"type":"fixed","name":"typeSchemaHash","namespace":"goavro","size":4}`},
+		{typeSchemaHash{}, []SchemaOption{SchemaNamespace("")}, `{"type":"fixed","name":"typeSchemaHash","size":4}`},
+		{time.Time{}, nil, `{"type":"long","logicalType":"timestamp-millis"}`},
+		{time.Duration(0), nil, `{"type":"long","logicalType":"time-micros"}`},
+		{big.NewRat(1, 2), nil, `{"type":"bytes","logicalType":"decimal","precision":38,"scale":9}`},
+		{big.NewRat(1, 2), []SchemaOption{SchemaDecimal(10, 2)}, `{"type":"bytes","logicalType":"decimal","precision":10,"scale":2}`},
+		{
+			typeSchemaTree{}, nil,
+			`{"type":"record","name":"typeSchemaTree","namespace":"goavro","fields":[{"name":"value","type":"int"},{"name":"children","type":{"type":"array","items":["null","typeSchemaTree"]}}]}`,
+		},
+	}
+	for _, c := range cases {
+		schema, err := SchemaFromType(reflect.TypeOf(c.value), c.options...)
+		if err != nil {
+			t.Errorf("%T: %s", c.value, err)
+			continue
+		}
+		if schema != c.schema {
+			t.Errorf("%T: GOT: %s; WANT: %s", c.value, schema, c.schema)
+		}
+	}
+}
+
+func TestSchemaFromTypeFields(t *testing.T) {
+	type Address struct {
+		Street string `avro:"street"`
+	}
+	type User struct {
+		ID         int64           `avro:"id,doc=Unique identifier."`
+		Born       time.Time       `avro:"born,logicalType=date"`
+		Seen       *time.Time      `avro:"seen,logicalType=timestamp-micros"`
+		Balance    *big.Rat        `avro:"balance,precision=10,scale=2"`
+		Refund     *big.Rat        `avro:"refund,nullable"`
+		Delays     []time.Duration `avro:"delays,logicalType=time-millis"`
+		Home       Address         `avro:"home"`
+		Work       *Address        `avro:"work"`
+		Untagged   string
+		Skipped    string `avro:"-"`
+		unexported string
+	}
+
+	schema, err := SchemaFromType(reflect.TypeOf(User{}), SchemaNamespace("com.example"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"type":"record","name":"User","namespace":"com.example","fields":[` +
+		`{"name":"id","doc":"Unique identifier.","type":"long"},` +
+		`{"name":"born","type":{"type":"int","logicalType":"date"}},` +
+		`{"name":"seen","type":["null",{"type":"long","logicalType":"timestamp-micros"}],"default":null},` +
+		`{"name":"balance","type":{"type":"bytes","logicalType":"decimal","precision":10,"scale":2}},` +
+		`{"name":"refund","type":["null",{"type":"bytes","logicalType":"decimal","precision":38,"scale":9}],"default":null},` +
+		`{"name":"delays","type":{"type":"array","items":{"type":"int","logicalType":"time-millis"}}},` +
+		`{"name":"home","type":{"type":"record","name":"Address","fields":[{"name":"street","type":"string"}]}},` +
+		`{"name":"work","type":["null","Address"],"default":null},` +
+		`{"name":"Untagged","type":"string"}]}`
+	if schema != expected {
+		t.Errorf("GOT: %s; WANT: %s", schema, expected)
+	}
+}
+
+func TestNewCodecForTypeRoundTrip(t *testing.T) {
+	codec, err := NewCodecForType(reflect.TypeOf(structTestUser{}), SchemaNamespace("com.example"), SchemaDecimal(10, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	email := "bob@example.com"
+	user := structTestUser{
+		ID:       42,
+		Name:     "Bob",
+		Email:    &email,
+		Tags:     []string{"a", "b"},
+		Scores:   map[string]int32{"math": 90},
+		Hash:     [4]byte{1, 2, 3, 4},
+		Created:  time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		Balance:  big.NewRat(1234, 100),
+		Color:    "GREEN",
+		Friend:   &structTestFriend{Name: "Alice"},
+		Untagged: 1.5,
+	}
+	buf, err := codec.BinaryFromStruct(nil, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded structTestUser
+	if _, err = codec.StructFromBinary(buf, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, user) {
+		t.Errorf("GOT: %#v; WANT: %#v", decoded, user)
+	}
+
+	list := structTestLongList{Value: 1, Next: &structTestLongList{Value: 2}}
+	codec, err = NewCodecForType(reflect.TypeOf(list))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf, err = codec.BinaryFromStruct(nil, list); err != nil {
+		t.Fatal(err)
+	}
+	var decodedList structTestLongList
+	if _, err = codec.StructFromBinary(buf, &decodedList); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decodedList, list) {
+		t.Errorf("GOT: %#v; WANT: %#v", decodedList, list)
+	}
+	type Refund struct {
+		Amount *big.Rat `avro:"amount,nullable"`
+	}
+	codec, err = NewCodecForType(reflect.TypeOf(Refund{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, refund := range []Refund{{}, {Amount: big.NewRat(3, 4)}} {
+		if buf, err = codec.BinaryFromStruct(nil, refund); err != nil {
+			t.Fatal(err)
+		}
+		var decodedRefund Refund
+		if _, err = codec.StructFromBinary(buf, &decodedRefund); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(decodedRefund, refund) {
+			t.Errorf("GOT: %#v; WANT: %#v", decodedRefund, refund)
+		}
+	}
+}
+
+func TestSchemaFromTypeErrors(t *testing.T) {
+	cases := []struct {
+		value interface{}
+		err   string
+	}{
+		{nil, "nil Go type"},
+		{struct{ A int }{}, "unnamed Go type"},
+		{map[int]string{}, "key ought to be string"},
+		{[2]int{}, "elements ought to be bytes"},
+		{make(chan int), "cannot use Go type chan int"},
+		{[]interface{}{}, "cannot use Go type interface {}"},
+	}
+	for _, c := range cases {
+		_, err := SchemaFromType(reflect.TypeOf(c.value))
+		ensureError(t, err, c.err)
+	}
+
+	type BadSyntax struct {
+		A int `avro:"a,precision"`
+	}
+	_, err := SchemaFromType(reflect.TypeOf(BadSyntax{}))
+	ensureError(t, err, `field "a": tag option ought to be key=value`)
+
+	type BadLogical struct {
+		A time.Time `avro:"a,logicalType=time-millis"`
+	}
+	_, err = SchemaFromType(reflect.TypeOf(BadLogical{}))
+	ensureError(t, err, `logical type ought to be one of`)
+
+	type BadOption struct {
+		A string `avro:"a,logicalType=uuid"`
+	}
+	_, err = SchemaFromType(reflect.TypeOf(BadOption{}))
+	ensureError(t, err, `cannot use logical type options for Go type string`)
+
+	type BadScale struct {
+		A *big.Rat `avro:"a,scale=2"`
+	}
+	_, err = SchemaFromType(reflect.TypeOf(BadScale{}))
+	ensureError(t, err, `ought to be accompanied by "precision"`)
+
+	type BadNullable struct {
+		A *string `avro:"a,nullable"`
+	}
+	_, err = SchemaFromType(reflect.TypeOf(BadNullable{}))
+	ensureError(t, err, `cannot use tag option "nullable" for Go type *string`)
+}
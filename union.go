@@ -114,6 +114,23 @@ func unionNativeFromBinary(cr *codecInfo) func(buf []byte) (interface{}, []byte,
 		return Union(cr.allowedTypes[index], decoded), buf, nil
 	}
 }
+
+func unionSkipBinary(cr *codecInfo) func(buf []byte) ([]byte, error) {
+	return func(buf []byte) ([]byte, error) {
+		index, buf, err := longFromBinary(buf)
+		if err != nil {
+			return nil, err
+		}
+		if index < 0 || index >= int64(len(cr.codecFromIndex)) {
+			return nil, fmt.Errorf("cannot decode binary union: index ought to be between 0 and %d; read index: %d", len(cr.codecFromIndex)-1, index)
+		}
+		if buf, err = cr.codecFromIndex[index].skipBinary(buf); err != nil {
+			return nil, fmt.Errorf("cannot decode binary union item %d: %s", index+1, err)
+		}
+		return buf, nil
+	}
+}
+
 func unionBinaryFromNative(cr *codecInfo) func(buf []byte, datum interface{}) ([]byte, error) {
 	return func(buf []byte, datum interface{}) ([]byte, error) {
 		switch v := datum.(type) {
@@ -152,7 +169,7 @@ func unionNativeFromTextual(cr *codecInfo) func(buf []byte) (interface{}, []byte
 		var datum interface{}
 		var err error
 		// For unions, we never ignore extra fields - the map keys represent type names
-		datum, buf, err = genericMapTextDecoder(buf, nil, cr.codecFromName, false)
+		datum, buf, err = genericMapTextDecoder(buf, nil, cr.codecFromName, nil, false)
 		if err != nil {
 			return nil, nil, fmt.Errorf("cannot decode textual union: %s", err)
 		}
@@ -245,7 +262,10 @@ func buildCodecForTypeDescribedBySlice(st map[string]*Codec, enclosingNamespace
 		schemaOriginal: cr.codecFromIndex[0].typeName.fullName,
 
 		typeName:          &name{"union", nullNamespace},
+		kind:              "union",
+		members:           &cr,
 		nativeFromBinary:  unionNativeFromBinary(&cr),
+		skipBinary:        unionSkipBinary(&cr),
 		binaryFromNative:  unionBinaryFromNative(&cr),
 		nativeFromTextual: unionNativeFromTextual(&cr),
 		textualFromNative: unionTextualFromNative(&cr),
@@ -295,7 +315,10 @@ func buildCodecForTypeDescribedBySliceOneWayJSON(st map[string]*Codec, enclosing
 		schemaOriginal: cr.codecFromIndex[0].typeName.fullName,
 
 		typeName:          &name{"union", nullNamespace},
+		kind:              "union",
+		members:           &cr,
 		nativeFromBinary:  unionNativeFromBinary(&cr),
+		skipBinary:        unionSkipBinary(&cr),
 		binaryFromNative:  unionBinaryFromNative(&cr),
 		nativeFromTextual: nativeAvroFromTextualJSON(&cr),
 		textualFromNative: unionTextualFromNative(&cr),
@@ -319,7 +342,10 @@ func buildCodecForTypeDescribedBySliceTwoWayJSON(st map[string]*Codec, enclosing
 		schemaOriginal: cr.codecFromIndex[0].typeName.fullName,
 
 		typeName:          &name{"union", nullNamespace},
+		kind:              "union",
+		members:           &cr,
 		nativeFromBinary:  unionNativeFromBinary(&cr),
+		skipBinary:        unionSkipBinary(&cr),
 		binaryFromNative:  unionBinaryFromNative(&cr),
 		nativeFromTextual: nativeAvroFromTextualJSON(&cr),
 		textualFromNative: textualJSONFromNativeAvro(&cr),